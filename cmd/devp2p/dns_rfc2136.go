@@ -0,0 +1,409 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of core-geth.
+//
+// core-geth is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// core-geth is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with core-geth. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/rand"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var (
+	rfc2136ServerFlag = &cli.StringFlag{
+		Name:    "server",
+		Usage:   "Address of the primary DNS server accepting dynamic updates (host:port)",
+		EnvVars: []string{"RFC2136_SERVER"},
+	}
+	rfc2136ZoneFlag = &cli.StringFlag{
+		Name:  "zone",
+		Usage: "Zone containing the tree (defaults to the parent domain of the tree)",
+	}
+	rfc2136TSIGKeyFlag = &cli.StringFlag{
+		Name:    "tsig-key",
+		Usage:   "Name of the TSIG key authenticating the updates",
+		EnvVars: []string{"RFC2136_TSIG_KEY"},
+	}
+	rfc2136TSIGSecretFlag = &cli.StringFlag{
+		Name:    "tsig-secret",
+		Usage:   "Base64 encoded secret of the TSIG key",
+		EnvVars: []string{"RFC2136_TSIG_SECRET"},
+	}
+	rfc2136TSIGAlgorithmFlag = &cli.StringFlag{
+		Name:  "tsig-algorithm",
+		Usage: "TSIG algorithm (hmac-sha1, hmac-sha256 or hmac-sha512)",
+		Value: "hmac-sha256",
+	}
+)
+
+const (
+	// rfc2136MaxUpdates is the maximum number of records changed by a single
+	// update message, keeping messages well below the TCP message size limit.
+	rfc2136MaxUpdates = 64
+
+	rfc2136Timeout = 30 * time.Second
+	tsigFudge      = 300 // seconds of clock skew tolerated by the server
+
+	opcodeUpdate dnsmessage.OpCode = 5
+	typeTSIG     dnsmessage.Type   = 250
+)
+
+var tsigAlgorithms = map[string]func() hash.Hash{
+	"hmac-sha1":   sha1.New,
+	"hmac-sha256": sha256.New,
+	"hmac-sha512": sha512.New,
+}
+
+// tsigKey is a shared secret authenticating dynamic updates, see RFC 8945.
+type tsigKey struct {
+	name      string
+	algorithm string
+	secret    []byte
+}
+
+// rfc2136Client deploys trees to a DNS server through dynamic updates.
+type rfc2136Client struct {
+	server string
+	zone   string
+	key    *tsigKey // nil for unauthenticated updates
+	now    func() time.Time
+}
+
+// rfc2136Update replaces the TXT records of a name, or deletes them if value is
+// empty.
+type rfc2136Update struct {
+	name  string
+	value string
+	ttl   uint32
+}
+
+// newRFC2136Client sets up a dynamic update client from command line flags.
+func newRFC2136Client(ctx *cli.Context) *rfc2136Client {
+	server := ctx.String(rfc2136ServerFlag.Name)
+	if server == "" {
+		exit(errors.New("need DNS server address to proceed"))
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	c := &rfc2136Client{
+		server: server,
+		zone:   ctx.String(rfc2136ZoneFlag.Name),
+		now:    time.Now,
+	}
+	if name := ctx.String(rfc2136TSIGKeyFlag.Name); name != "" {
+		algorithm := ctx.String(rfc2136TSIGAlgorithmFlag.Name)
+		if _, ok := tsigAlgorithms[algorithm]; !ok {
+			exit(fmt.Errorf("unsupported TSIG algorithm %q", algorithm))
+		}
+		secret, err := base64.StdEncoding.DecodeString(ctx.String(rfc2136TSIGSecretFlag.Name))
+		if err != nil || len(secret) == 0 {
+			exit(errors.New("need base64 encoded TSIG secret to proceed"))
+		}
+		c.key = &tsigKey{name: name, algorithm: algorithm, secret: secret}
+	}
+	return c
+}
+
+// deploy uploads the given tree to the DNS server, deleting the records of the
+// previously deployed tree which are no longer needed.
+func (c *rfc2136Client) deploy(name string, t *dnsdisc.Tree) error {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if c.zone == "" {
+		_, parent, found := strings.Cut(name, ".")
+		if !found {
+			return fmt.Errorf("can't derive zone of %q, set --%s", name, rfc2136ZoneFlag.Name)
+		}
+		c.zone = parent
+	}
+	c.zone = strings.ToLower(strings.TrimSuffix(c.zone, "."))
+	if !isSubdomain(name, c.zone) {
+		return fmt.Errorf("tree domain %q is not within zone %q", name, c.zone)
+	}
+	log.Info(fmt.Sprintf("Retrieving existing TXT records on %s", name))
+	existing, err := c.collectRecords(name)
+	if err != nil {
+		return err
+	}
+	updates := computeRFC2136Updates(name, t.ToTXT(name), existing)
+	if len(updates) == 0 {
+		log.Info("DNS tree is up to date", "name", name)
+		return nil
+	}
+	for i := 0; i < len(updates); i += rfc2136MaxUpdates {
+		batch := updates[i:min(i+rfc2136MaxUpdates, len(updates))]
+		log.Info(fmt.Sprintf("Submitting %d updates to %s", len(batch), c.server))
+		if err := c.submit(batch); err != nil {
+			return err
+		}
+	}
+	log.Info("Updated DNS entries", "zone", c.zone, "changes", len(updates))
+	return nil
+}
+
+// computeRFC2136Updates creates the updates turning the existing records into
+// the given ones. New leaves are added first, the root is updated next and
+// stale records are deleted last, so that resolvers always see a complete tree.
+func computeRFC2136Updates(name string, records, existing map[string]string) []rfc2136Update {
+	var (
+		lrecords        = make(map[string]string, len(records))
+		adds, deletions []rfc2136Update
+		root            *rfc2136Update
+	)
+	for path, value := range records {
+		lrecords[strings.ToLower(path)] = value
+	}
+	for path, value := range lrecords {
+		if old, ok := existing[path]; ok && old == value {
+			continue
+		}
+		if path == name {
+			root = &rfc2136Update{name: path, value: value, ttl: rootTTL}
+			continue
+		}
+		adds = append(adds, rfc2136Update{name: path, value: value, ttl: treeNodeTTL})
+	}
+	for path := range existing {
+		if _, ok := lrecords[path]; !ok {
+			deletions = append(deletions, rfc2136Update{name: path})
+		}
+	}
+	sort.Slice(adds, func(i, j int) bool { return adds[i].name < adds[j].name })
+	sort.Slice(deletions, func(i, j int) bool { return deletions[i].name < deletions[j].name })
+
+	updates := adds
+	if root != nil {
+		updates = append(updates, *root)
+	}
+	return append(updates, deletions...)
+}
+
+// collectRecords retrieves the TXT records of the tree currently deployed at
+// name from the DNS server, following the links between its entries.
+func (c *rfc2136Client) collectRecords(name string) (map[string]string, error) {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "tcp", c.server)
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), rfc2136Timeout)
+	defer cancel()
+
+	var (
+		existing = make(map[string]string)
+		queue    = []string{name}
+	)
+	for len(queue) > 0 {
+		domain := queue[0]
+		queue = queue[1:]
+		if _, ok := existing[domain]; ok {
+			continue
+		}
+		txts, err := resolver.LookupTXT(ctx, domain+".")
+		if err != nil {
+			var dnsErr *net.DNSError
+			if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+				continue
+			}
+			return nil, err
+		}
+		value := strings.Join(txts, "")
+		existing[domain] = value
+		for _, child := range treeEntryChildren(value) {
+			queue = append(queue, strings.ToLower(child)+"."+name)
+		}
+	}
+	return existing, nil
+}
+
+// treeEntryChildren returns the subdomains referenced by a root or branch entry.
+func treeEntryChildren(entry string) []string {
+	var children []string
+	switch {
+	case strings.HasPrefix(entry, "enrtree-root:v1 "):
+		for _, field := range strings.Fields(entry) {
+			if hash, ok := strings.CutPrefix(field, "e="); ok {
+				children = append(children, hash)
+			} else if hash, ok := strings.CutPrefix(field, "l="); ok {
+				children = append(children, hash)
+			}
+		}
+	case strings.HasPrefix(entry, "enrtree-branch:"):
+		for _, hash := range strings.Split(strings.TrimPrefix(entry, "enrtree-branch:"), ",") {
+			if hash != "" {
+				children = append(children, hash)
+			}
+		}
+	}
+	return children
+}
+
+// submit sends a single update message and waits for the server to apply it.
+func (c *rfc2136Client) submit(updates []rfc2136Update) error {
+	msg, err := c.buildUpdate(uint16(rand.Uint32()), updates)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", c.server, rfc2136Timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(rfc2136Timeout))
+
+	frame := binary.BigEndian.AppendUint16(nil, uint16(len(msg)))
+	if _, err := conn.Write(append(frame, msg...)); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(conn, frame); err != nil {
+		return fmt.Errorf("can't read update response: %v", err)
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(frame))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return fmt.Errorf("can't read update response: %v", err)
+	}
+	var p dnsmessage.Parser
+	header, err := p.Start(resp)
+	if err != nil {
+		return fmt.Errorf("invalid update response: %v", err)
+	}
+	if header.RCode != dnsmessage.RCodeSuccess {
+		return fmt.Errorf("update of zone %s rejected: %v", c.zone, header.RCode)
+	}
+	return nil
+}
+
+// buildUpdate creates an update message of the zone applying the given updates,
+// signed with the TSIG key if configured.
+func (c *rfc2136Client) buildUpdate(id uint16, updates []rfc2136Update) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, OpCode: opcodeUpdate})
+	zone, err := dnsmessage.NewName(c.zone + ".")
+	if err != nil {
+		return nil, err
+	}
+	// The zone section names the zone being updated, prerequisites are empty.
+	b.StartQuestions()
+	if err := b.Question(dnsmessage.Question{Name: zone, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	b.StartAuthorities()
+	for _, u := range updates {
+		name, err := dnsmessage.NewName(u.name + ".")
+		if err != nil {
+			return nil, err
+		}
+		// Delete the existing record set, then add the new value if any.
+		del := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassANY}
+		if err := b.UnknownResource(del, dnsmessage.UnknownResource{Type: dnsmessage.TypeTXT}); err != nil {
+			return nil, err
+		}
+		if u.value == "" {
+			continue
+		}
+		add := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: u.ttl}
+		if err := b.TXTResource(add, dnsmessage.TXTResource{TXT: splitTXTChunks(u.value)}); err != nil {
+			return nil, err
+		}
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		return nil, err
+	}
+	if c.key == nil {
+		return msg, nil
+	}
+	return c.key.sign(msg, id, c.now())
+}
+
+// sign appends a TSIG record authenticating the message, see RFC 8945 section 4.
+func (k *tsigKey) sign(msg []byte, id uint16, now time.Time) ([]byte, error) {
+	keyName, err := canonicalName(k.name)
+	if err != nil {
+		return nil, err
+	}
+	algName, err := canonicalName(k.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	signed := make([]byte, 8)
+	binary.BigEndian.PutUint64(signed, uint64(now.Unix()))
+	signed = signed[2:] // 48 bit time
+
+	// Digest the message followed by the TSIG variables.
+	mac := hmac.New(tsigAlgorithms[k.algorithm], k.secret)
+	mac.Write(msg)
+	mac.Write(keyName)
+	mac.Write([]byte{0, byte(dnsmessage.ClassANY), 0, 0, 0, 0}) // class, TTL
+	mac.Write(algName)
+	mac.Write(signed)
+	mac.Write([]byte{tsigFudge >> 8, tsigFudge & 0xff, 0, 0, 0, 0}) // fudge, error, other len
+	sum := mac.Sum(nil)
+
+	rdata := append(algName, signed...)
+	rdata = binary.BigEndian.AppendUint16(rdata, tsigFudge)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(sum)))
+	rdata = append(rdata, sum...)
+	rdata = binary.BigEndian.AppendUint16(rdata, id)
+	rdata = append(rdata, 0, 0, 0, 0) // error, other len
+
+	out := append([]byte{}, msg...)
+	out = append(out, keyName...)
+	out = binary.BigEndian.AppendUint16(out, uint16(typeTSIG))
+	out = binary.BigEndian.AppendUint16(out, uint16(dnsmessage.ClassANY))
+	out = binary.BigEndian.AppendUint32(out, 0)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(rdata)))
+	out = append(out, rdata...)
+
+	// Account for the TSIG record in the additional section count.
+	binary.BigEndian.PutUint16(out[10:], binary.BigEndian.Uint16(out[10:])+1)
+	return out, nil
+}
+
+// canonicalName returns the uncompressed, lowercase wire format of a domain name.
+func canonicalName(name string) ([]byte, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	var wire []byte
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if len(label) == 0 || len(label) > 63 {
+				return nil, fmt.Errorf("invalid domain name %q", name)
+			}
+			wire = append(wire, byte(len(label)))
+			wire = append(wire, label...)
+		}
+	}
+	return append(wire, 0), nil
+}
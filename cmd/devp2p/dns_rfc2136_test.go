@@ -0,0 +1,304 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of core-geth.
+//
+// core-geth is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// core-geth is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with core-geth. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// This test checks that updates add leaves before changing the root and delete
+// stale records last.
+func TestRFC2136UpdateOrder(t *testing.T) {
+	t.Parallel()
+	existing := map[string]string{
+		"n":      "enrtree-root:v1 e=AAA l=BBB seq=0 sig=x",
+		"aaa.n":  "enr:old",
+		"bbb.n":  "enrtree-branch:",
+		"keep.n": "enr:keep",
+	}
+	records := map[string]string{
+		"n":      "enrtree-root:v1 e=CCC l=BBB seq=1 sig=y",
+		"CCC.n":  "enrtree-branch:KEEP,DDD",
+		"DDD.n":  "enr:new",
+		"bbb.n":  "enrtree-branch:",
+		"KEEP.n": "enr:keep",
+	}
+	want := []rfc2136Update{
+		{name: "ccc.n", value: "enrtree-branch:KEEP,DDD", ttl: treeNodeTTL},
+		{name: "ddd.n", value: "enr:new", ttl: treeNodeTTL},
+		{name: "n", value: "enrtree-root:v1 e=CCC l=BBB seq=1 sig=y", ttl: rootTTL},
+		{name: "aaa.n"},
+	}
+	if have := computeRFC2136Updates("n", records, existing); !reflect.DeepEqual(have, want) {
+		t.Fatalf("wrong updates\nhave %+v\nwant %+v", have, want)
+	}
+	if have := computeRFC2136Updates("n", existing, existing); len(have) != 0 {
+		t.Fatalf("updates for unchanged tree: %+v", have)
+	}
+}
+
+func TestTreeEntryChildren(t *testing.T) {
+	t.Parallel()
+	tests := map[string][]string{
+		"enrtree-root:v1 e=AAA l=BBB seq=3 sig=x": {"AAA", "BBB"},
+		"enrtree-branch:AAA,BBB,CCC":              {"AAA", "BBB", "CCC"},
+		"enrtree-branch:":                         nil,
+		"enr:-HW4QOFzoVLaFJnNhbgMoDXPnOvcdVuj7pD": nil,
+		"enrtree://AM5FCQLWIZX2QFPNJAP7VUERCCRNGRHWZG3YYHIUV7BVDQ5FDPRT2@example.org": nil,
+	}
+	for entry, want := range tests {
+		if have := treeEntryChildren(entry); !reflect.DeepEqual(have, want) {
+			t.Errorf("%q: have %v, want %v", entry, have, want)
+		}
+	}
+}
+
+func TestWriteZonefile(t *testing.T) {
+	t.Parallel()
+	long := "enr:" + strings.Repeat("x", 300)
+	records := map[string]string{
+		"nodes.example.org":     "enrtree-root:v1 e=AAA l=BBB seq=1 sig=x",
+		"AAA.nodes.example.org": long,
+		"BBB.nodes.example.org": "enrtree-branch:",
+	}
+	var buf bytes.Buffer
+	if err := writeZonefile(&buf, "nodes.example.org", records); err != nil {
+		t.Fatal(err)
+	}
+	want := `$ORIGIN nodes.example.org.
+$TTL 2419200
+@ 1800 IN TXT "enrtree-root:v1 e=AAA l=BBB seq=1 sig=x"
+aaa IN TXT "` + long[:255] + `" "` + long[255:] + `"
+bbb IN TXT "enrtree-branch:"
+`
+	if buf.String() != want {
+		t.Fatalf("wrong zone file\nhave:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// This test deploys two trees in a row to a DNS server and checks that the
+// zone contains exactly the records of the second one afterwards.
+func TestRFC2136Deploy(t *testing.T) {
+	t.Parallel()
+	var (
+		domain = "all.nodes.example.org"
+		key    = &tsigKey{name: "deploy-key.", algorithm: "hmac-sha256", secret: []byte("secret")}
+		now    = time.Unix(1700000000, 0)
+		server = newTestDNSServer(t, key, now)
+		client = &rfc2136Client{server: server.addr, key: key, now: func() time.Time { return now }}
+	)
+	signer, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq, n := range []int{12, 5} {
+		tree, err := dnsdisc.MakeTree(uint(seq), testTreeNodes(n), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tree.Sign(signer, domain); err != nil {
+			t.Fatal(err)
+		}
+		if err := client.deploy(domain, tree); err != nil {
+			t.Fatalf("deploy %d failed: %v", seq, err)
+		}
+		want := make(map[string]string)
+		for name, value := range tree.ToTXT(domain) {
+			want[strings.ToLower(name)] = value
+		}
+		if have := server.records(); !reflect.DeepEqual(have, want) {
+			t.Fatalf("wrong records after deploy %d\nhave %v\nwant %v", seq, have, want)
+		}
+	}
+	if client.zone != "nodes.example.org" {
+		t.Fatalf("wrong zone: %q", client.zone)
+	}
+}
+
+func testTreeNodes(n int) []*enode.Node {
+	nodes := make([]*enode.Node, n)
+	for i := range nodes {
+		key, _ := crypto.GenerateKey()
+		var r enr.Record
+		enode.SignV4(&r, key)
+		node, err := enode.New(enode.ValidSchemes, &r)
+		if err != nil {
+			panic(err)
+		}
+		nodes[i] = node
+	}
+	return nodes
+}
+
+// testDNSServer is an authoritative DNS server over TCP accepting dynamic
+// updates signed with a TSIG key.
+type testDNSServer struct {
+	t    *testing.T
+	addr string
+	key  *tsigKey
+	now  time.Time
+
+	mu   sync.Mutex
+	zone map[string]string
+}
+
+func newTestDNSServer(t *testing.T, key *tsigKey, now time.Time) *testDNSServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	s := &testDNSServer{t: t, addr: l.Addr().String(), key: key, now: now, zone: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *testDNSServer) records() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := make(map[string]string, len(s.zone))
+	for name, value := range s.zone {
+		records[name] = value
+	}
+	return records
+}
+
+func (s *testDNSServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var size [2]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+		if _, err := io.ReadFull(conn, msg); err != nil {
+			return
+		}
+		resp, err := s.handle(msg)
+		if err != nil {
+			s.t.Errorf("invalid request: %v", err)
+			return
+		}
+		conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(resp))), resp...))
+	}
+}
+
+func (s *testDNSServer) handle(msg []byte) ([]byte, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil {
+		return nil, err
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil, err
+	}
+	p.SkipAllQuestions()
+	rh := dnsmessage.Header{ID: h.ID, Response: true, OpCode: h.OpCode, Authoritative: true}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var answers []string
+	if h.OpCode == opcodeUpdate {
+		if err := s.verify(msg, h.ID); err != nil {
+			return nil, err
+		}
+		p.SkipAllAnswers()
+		updates, err := p.AllAuthorities()
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range updates {
+			name := strings.TrimSuffix(u.Header.Name.String(), ".")
+			switch u.Header.Class {
+			case dnsmessage.ClassANY:
+				delete(s.zone, name)
+			case dnsmessage.ClassINET:
+				s.zone[name] = strings.Join(u.Body.(*dnsmessage.TXTResource).TXT, "")
+			}
+		}
+	} else if value, ok := s.zone[strings.ToLower(strings.TrimSuffix(q.Name.String(), "."))]; ok {
+		answers = splitTXTChunks(value)
+	} else {
+		rh.RCode = dnsmessage.RCodeNameError
+	}
+	b := dnsmessage.NewBuilder(nil, rh)
+	b.StartQuestions()
+	b.Question(q)
+	b.StartAnswers()
+	if answers != nil {
+		b.TXTResource(dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 60}, dnsmessage.TXTResource{TXT: answers})
+	}
+	return b.Finish()
+}
+
+// verify checks the TSIG record at the end of an update message.
+func (s *testDNSServer) verify(msg []byte, id uint16) error {
+	keyName, _ := canonicalName(s.key.name)
+	var p dnsmessage.Parser
+	if _, err := p.Start(msg); err != nil {
+		return err
+	}
+	p.SkipAllQuestions()
+	p.SkipAllAnswers()
+	p.SkipAllAuthorities()
+	additionals, err := p.AllAdditionals()
+	if err != nil {
+		return err
+	}
+	if len(additionals) != 1 || additionals[0].Header.Type != typeTSIG {
+		return errors.New("missing TSIG record")
+	}
+	tsig := additionals[0].Body.(*dnsmessage.UnknownResource).Data
+	unsigned := append([]byte{}, msg[:len(msg)-len(keyName)-10-len(tsig)]...)
+	binary.BigEndian.PutUint16(unsigned[10:], binary.BigEndian.Uint16(unsigned[10:])-1)
+
+	want, err := s.key.sign(unsigned, id, s.now)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(want, msg) {
+		return errors.New("invalid TSIG signature")
+	}
+	return nil
+}
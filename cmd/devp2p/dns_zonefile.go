@@ -0,0 +1,69 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of core-geth.
+//
+// core-geth is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// core-geth is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with core-geth. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// writeZonefile writes the TXT records of a tree deployed at domain in the
+// RFC 1035 master file format. The records are written relative to the domain
+// as origin, so the file can be $INCLUDEd in the zone of a parent domain.
+func writeZonefile(w io.Writer, domain string, records map[string]string) error {
+	names := make([]string, 0, len(records))
+	for name := range records {
+		if name != domain {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Fprintf(w, "$ORIGIN %s.\n", domain)
+	fmt.Fprintf(w, "$TTL %d\n", treeNodeTTL)
+	fmt.Fprintf(w, "@ %d IN TXT %s\n", rootTTL, zoneTXT(records[domain]))
+	for _, name := range names {
+		rel := strings.TrimSuffix(name, "."+domain)
+		if _, err := fmt.Fprintf(w, "%s IN TXT %s\n", strings.ToLower(rel), zoneTXT(records[name])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// zoneTXT returns the quoted character strings of a TXT record value.
+func zoneTXT(value string) string {
+	chunks := splitTXTChunks(value)
+	for i, chunk := range chunks {
+		chunks[i] = strconv.Quote(chunk)
+	}
+	return strings.Join(chunks, " ")
+}
+
+// splitTXTChunks splits value into character strings fitting a TXT record.
+func splitTXTChunks(value string) []string {
+	var chunks []string
+	for len(value) > 0 {
+		n := min(len(value), 255)
+		chunks = append(chunks, value[:n])
+		value = value[n:]
+	}
+	return chunks
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/console/prompt"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/dnsdisc"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/urfave/cli/v2"
@@ -44,6 +45,8 @@ var (
 			dnsCloudflareCommand,
 			dnsRoute53Command,
 			dnsRoute53NukeCommand,
+			dnsZonefileCommand,
+			dnsRFC2136Command,
 		},
 	}
 	dnsSyncCommand = &cli.Command{
@@ -97,6 +100,25 @@ var (
 			route53RegionFlag,
 		},
 	}
+	dnsZonefileCommand = &cli.Command{
+		Name:      "to-zonefile",
+		Usage:     "Write DNS TXT records of a discovery tree as a zone file",
+		ArgsUsage: "<tree-directory> <output-directory>",
+		Action:    dnsToZonefile,
+	}
+	dnsRFC2136Command = &cli.Command{
+		Name:      "to-rfc2136",
+		Usage:     "Deploy DNS TXT records to a DNS server through dynamic updates (RFC 2136)",
+		ArgsUsage: "<tree-directory>",
+		Action:    dnsToRFC2136,
+		Flags: []cli.Flag{
+			rfc2136ServerFlag,
+			rfc2136ZoneFlag,
+			rfc2136TSIGKeyFlag,
+			rfc2136TSIGSecretFlag,
+			rfc2136TSIGAlgorithmFlag,
+		},
+	}
 )
 
 var (
@@ -242,6 +264,46 @@ func dnsToRoute53(ctx *cli.Context) error {
 	return client.deploy(domain, t)
 }
 
+// dnsToZonefile performs dnsZonefileCommand.
+func dnsToZonefile(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return errors.New("need tree definition directory and output directory as arguments")
+	}
+	domain, t, err := loadTreeDefinitionForExport(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	outdir := ctx.Args().Get(1)
+	if err := os.MkdirAll(outdir, 0755); err != nil {
+		return err
+	}
+	file := filepath.Join(outdir, domain+".zone")
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := writeZonefile(f, domain, t.ToTXT(domain)); err != nil {
+		return err
+	}
+	log.Info("Wrote zone file", "file", file, "seq", t.Seq())
+	return nil
+}
+
+// dnsToRFC2136 performs dnsRFC2136Command.
+func dnsToRFC2136(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("need tree definition directory as argument")
+	}
+	domain, t, err := loadTreeDefinitionForExport(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	client := newRFC2136Client(ctx)
+	return client.deploy(domain, t)
+}
+
 // dnsNukeRoute53 performs dnsRoute53NukeCommand.
 func dnsNukeRoute53(ctx *cli.Context) error {
 	if ctx.NArg() != 1 {
@@ -246,6 +246,9 @@ func ethFilter(args []string) (nodeFilter, error) {
 	case "mintme":
 		gb := core.GenesisToBlock(params.DefaultMintMeGenesisBlock(), nil)
 		filter = forkid.NewStaticFilter(params.MintMeChainConfig, gb)
+	case "ethernova":
+		gb := core.GenesisToBlock(params.DefaultEthernovaGenesisBlock(), nil)
+		filter = forkid.NewStaticFilter(params.EthernovaChainConfig, gb)
 	default:
 		return nil, fmt.Errorf("unknown network %q", args[0])
 	}
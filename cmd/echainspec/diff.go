@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/confp/generic"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	diffHeadFlag = cli.Uint64Flag{
+		Name:  "head",
+		Usage: "Head block number against which differing activations are checked",
+	}
+	diffTimeFlag = cli.Uint64Flag{
+		Name:  "time",
+		Usage: "Head block timestamp against which differing time-based activations are checked",
+	}
+)
+
+var diffCommand = cli.Command{
+	Name:  "diff",
+	Usage: "Show consensus-relevant differences between two chain configurations",
+	Description: `Compares the chain IDs, protocol transitions, ECBP1100 and max reorg depth
+parameters, parameter schedules, base fee vault and genesis block of two chain
+configurations. Each argument is either a path
+to a JSON chain configuration (or genesis) file, or the name of a default
+chain configuration (see ls-defaults).
+
+Differences taking effect at or before the head given by --head and --time
+are marked as passed: nodes running the two configurations cannot agree on
+the chain at that head.
+
+Exits 0 if the configurations are equivalent, 1 if not.`,
+	ArgsUsage: "<a.json> <b.json>",
+	Flags: []cli.Flag{
+		diffHeadFlag,
+		diffTimeFlag,
+	},
+	Action: diffChainspecs,
+}
+
+// loadChainspec reads the chain configuration at path, or returns the default
+// chain configuration of the given name when there is no such file.
+func loadChainspec(path string) (ctypes.ChainConfigurator, error) {
+	if v, ok := defaultChainspecValues[path]; ok {
+		if _, err := os.Stat(path); err != nil {
+			return v, nil
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var probe struct {
+		Config json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(probe.Config) > 0 {
+		gen := new(genesisT.Genesis)
+		if err := json.Unmarshal(data, gen); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if gen.Config == nil {
+			return nil, fmt.Errorf("%s: %v", path, errInvalidChainspecValue)
+		}
+		return gen, nil
+	}
+	conf, err := generic.UnmarshalChainConfigurator(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return conf, nil
+}
+
+// specDiff is a single difference between two chain configurations.
+type specDiff struct {
+	name   string
+	a, b   string
+	passed bool
+}
+
+// specDiffer accumulates the differences between two chain configurations.
+type specDiffer struct {
+	head, time uint64
+	diffs      []specDiff
+}
+
+func (d *specDiffer) add(name, a, b string, passed bool) {
+	if a != b {
+		d.diffs = append(d.diffs, specDiff{name: name, a: a, b: b, passed: passed})
+	}
+}
+
+// activation compares activation points, which have passed if either side
+// activates at or before the head.
+func (d *specDiffer) activation(name string, a, b *uint64, byTime bool) {
+	head := d.head
+	if byTime {
+		head = d.time
+	}
+	passed := (!isUnsetTransition(a) && *a <= head) || (!isUnsetTransition(b) && *b <= head)
+	d.add(name, formatTransition(a), formatTransition(b), passed)
+}
+
+// parameter compares a scalar parameter, which has passed if the transition
+// enabling it activates at or before the head on either side.
+func (d *specDiffer) parameter(name string, a, b, enableA, enableB *uint64) {
+	passed := (!isUnsetTransition(enableA) && *enableA <= d.head) || (!isUnsetTransition(enableB) && *enableB <= d.head)
+	d.add(name, formatTransition(a), formatTransition(b), passed)
+}
+
+func formatTransition(v *uint64) string {
+	if isUnsetTransition(v) {
+		return "-"
+	}
+	return fmt.Sprintf("%d", *v)
+}
+
+func (d *specDiffer) schedule(name string, a, b ctypes.Uint64Uint256MapEncodesHex) {
+	keys := make(map[uint64]struct{})
+	for n := range a {
+		keys[n] = struct{}{}
+	}
+	for n := range b {
+		keys[n] = struct{}{}
+	}
+	sorted := make([]uint64, 0, len(keys))
+	for n := range keys {
+		sorted = append(sorted, n)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	format := func(m ctypes.Uint64Uint256MapEncodesHex, n uint64) string {
+		if v, ok := m[n]; ok && v != nil {
+			return v.Dec()
+		}
+		return "-"
+	}
+	for _, n := range sorted {
+		d.add(fmt.Sprintf("%s[%d]", name, n), format(a, n), format(b, n), n <= d.head)
+	}
+}
+
+func diffChainConfigs(d *specDiffer, a, b ctypes.ChainConfigurator) {
+	d.add("chainId", fmt.Sprint(a.GetChainID()), fmt.Sprint(b.GetChainID()), true)
+	d.add("networkId", formatTransition(a.GetNetworkID()), formatTransition(b.GetNetworkID()), false)
+	d.add("consensusEngine", a.GetConsensusEngineType().String(), b.GetConsensusEngineType().String(), true)
+
+	// Transitions are collected by name, as the method sets of the two
+	// configuration types may differ.
+	values := func(conf ctypes.ChainConfigurator) map[string]*uint64 {
+		m := make(map[string]*uint64)
+		fns, names := confp.Transitions(conf)
+		for i, fn := range fns {
+			m[names[i]] = fn()
+		}
+		return m
+	}
+	va, vb := values(a), values(b)
+	names := make([]string, 0, len(va))
+	for name := range va {
+		names = append(names, name)
+	}
+	for name := range vb {
+		if _, ok := va[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return lessName(names[i], names[j]) })
+	for _, name := range names {
+		d.activation(transitionName(name), va[name], vb[name], strings.HasSuffix(name, "TransitionTime"))
+	}
+	d.add("ecip1017EraRounds", formatTransition(a.GetEthashECIP1017EraRounds()), formatTransition(b.GetEthashECIP1017EraRounds()), false)
+	d.parameter("ecbp1100AntigravityXCap", a.GetECBP1100AntigravityXCap(), b.GetECBP1100AntigravityXCap(), a.GetECBP1100Transition(), b.GetECBP1100Transition())
+	d.parameter("ecbp1100AntigravityAmplitude", a.GetECBP1100AntigravityAmplitude(), b.GetECBP1100AntigravityAmplitude(), a.GetECBP1100Transition(), b.GetECBP1100Transition())
+	d.parameter("ebpMaxReorgDepth", a.GetEBPMaxReorgDepth(), b.GetEBPMaxReorgDepth(), a.GetEBPMaxReorgDepthTransition(), b.GetEBPMaxReorgDepthTransition())
+
+	for _, s := range scheduleGetters {
+		d.schedule(s.name, s.get(a), s.get(b))
+	}
+
+	vault := func(conf ctypes.ChainConfigurator) string {
+		if v := conf.GetBaseFeeVault(); v != nil {
+			return v.Hex()
+		}
+		return "-"
+	}
+	d.add("baseFeeVault", vault(a), vault(b), true)
+	d.activation("baseFeeVaultFromBlock", a.GetBaseFeeVaultFromBlock(), b.GetBaseFeeVaultFromBlock(), false)
+}
+
+func diffChainspecs(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return errors.New("expected two chain configurations to compare")
+	}
+	a, err := loadChainspec(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	b, err := loadChainspec(ctx.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	d := &specDiffer{head: ctx.Uint64(diffHeadFlag.Name), time: ctx.Uint64(diffTimeFlag.Name)}
+	diffChainConfigs(d, a, b)
+
+	// The genesis blocks can only be compared if both configurations carry one.
+	ga, okA := a.(*genesisT.Genesis)
+	gb, okB := b.(*genesisT.Genesis)
+	if okA && okB {
+		d.add("genesisHash", core.GenesisToBlock(ga, nil).Hash().Hex(), core.GenesisToBlock(gb, nil).Hash().Hex(), true)
+	}
+
+	if len(d.diffs) == 0 {
+		fmt.Println("Equivalent")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "NAME\t%s\t%s\t\n", ctx.Args().Get(0), ctx.Args().Get(1))
+	var passed int
+	for _, diff := range d.diffs {
+		note := ""
+		if diff.passed {
+			note = "PASSED"
+			passed++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", diff.name, diff.a, diff.b, note)
+	}
+	w.Flush()
+	return fmt.Errorf("%d differences, %d passed at head %d (time %d)", len(d.diffs), passed, d.head, d.time)
+}
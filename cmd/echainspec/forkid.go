@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var forkidTimeFlag = cli.Uint64Flag{
+	Name:  "time",
+	Usage: "Head block timestamp",
+}
+
+var forkidCommand = cli.Command{
+	Name:  "forkid",
+	Usage: "Compute the EIP-2124 fork identifier",
+	Description: `Prints the fork identifier (hash and next fork) of the configured chain at the
+given head block number and --time. Without a head, the fork identifiers of
+the genesis block and of every fork are listed.
+
+The chain configuration must include a genesis block.`,
+	ArgsUsage: "[|0x042|0x42|42]",
+	Flags: []cli.Flag{
+		forkidTimeFlag,
+	},
+	Action: forkID,
+}
+
+func forkID(ctx *cli.Context) error {
+	gen, ok := globalChainspecValue.(*genesisT.Genesis)
+	if !ok {
+		return errors.New("fork identifiers require a genesis chain configuration")
+	}
+	genesis := core.GenesisToBlock(gen, nil)
+
+	if ctx.Args().Present() {
+		var head math.HexOrDecimal64
+		if err := head.UnmarshalText([]byte(ctx.Args().First())); err != nil {
+			return err
+		}
+		id := forkid.NewID(gen.Config, genesis, uint64(head), ctx.Uint64(forkidTimeFlag.Name))
+		fmt.Printf("%#x %d\n", id.Hash, id.Next)
+		return nil
+	}
+	fmt.Printf("genesis %s\n", genesis.Hash().Hex())
+	print := func(head, time uint64) {
+		id := forkid.NewID(gen.Config, genesis, head, time)
+		fmt.Printf("%d @%d %#x %d\n", head, time, id.Hash, id.Next)
+	}
+	print(0, genesis.Time())
+
+	var last uint64
+	for _, n := range confp.BlockForks(gen.Config) {
+		print(n, genesis.Time())
+		last = n
+	}
+	for _, t := range confp.TimeForks(gen.Config, genesis.Time()) {
+		print(last, t)
+	}
+	return nil
+}
@@ -48,6 +48,8 @@ var defaultChainspecValues = map[string]ctypes.Configurator{
 	"sepolia":    params.DefaultSepoliaGenesisBlock(),
 
 	"mintme": params.DefaultMintMeGenesisBlock(),
+
+	"ethernova": params.DefaultEthernovaGenesisBlock(),
 }
 
 var defaultChainspecNames = func() []string {
@@ -95,6 +97,10 @@ func mustGetChainspecValue(ctx *cli.Context) error {
 		if strings.Contains(ctx.Args().First(), "help") {
 			return nil
 		}
+		// The diff command reads its own chain configurations.
+		if ctx.Args().First() == diffCommand.Name {
+			return nil
+		}
 	}
 	if ctx.GlobalIsSet(defaultValueFlag.Name) {
 		if ctx.GlobalString(defaultValueFlag.Name) == "" {
@@ -217,6 +223,10 @@ GLOBAL OPTIONS:
 		validateCommand,
 		forksCommand,
 		ipsCommand,
+		predeployHistoryCommand,
+		timelineCommand,
+		diffCommand,
+		forkidCommand,
 	}
 	app.Before = mustGetChainspecValue
 	app.Action = convertf
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var errNoGenesisAlloc = errors.New("chainspec value has no genesis allocation")
+
+var predeployHistoryCommand = cli.Command{
+	Name:        "predeploy-history",
+	Description: "Adds the EIP-2935 history storage contract to the genesis allocation and prints the result. If a block number is given, the EIP-2935 transition is scheduled at it.",
+	Usage:       "Predeploy the EIP-2935 block hash history contract",
+	ArgsUsage:   "[|0x042|0x42|42]",
+	Action:      predeployHistory,
+}
+
+func predeployHistory(ctx *cli.Context) error {
+	g, ok := globalChainspecValue.(*genesisT.Genesis)
+	if !ok {
+		return errNoGenesisAlloc
+	}
+	if ctx.Args().Present() {
+		var n math.HexOrDecimal64
+		if err := n.UnmarshalText([]byte(ctx.Args().First())); err != nil {
+			return err
+		}
+		fork := uint64(n)
+		if err := g.SetEIP2935Transition(&fork); err != nil {
+			return err
+		}
+	}
+	if g.Alloc == nil {
+		g.Alloc = make(genesisT.GenesisAlloc)
+	}
+	params.PredeployHistoryStorage(g.Alloc)
+
+	b, err := jsonMarshalPretty(g)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(b))
+	return nil
+}
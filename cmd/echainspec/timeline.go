@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var timelineCommand = cli.Command{
+	Name:   "timeline",
+	Usage:  "List protocol changes grouped by activation block and time",
+	Action: timeline,
+}
+
+// activation groups the protocol changes taking effect at a single block
+// number or timestamp.
+type activation struct {
+	at      uint64
+	byTime  bool
+	changes []string
+}
+
+func (a *activation) String() string {
+	if a.byTime {
+		return fmt.Sprintf("@%d", a.at)
+	}
+	return fmt.Sprintf("%d", a.at)
+}
+
+// transitionName returns the short name of a transition getter, eg. EIP1559
+// for GetEIP1559Transition.
+func transitionName(getter string) string {
+	name := strings.TrimPrefix(getter, "Get")
+	name = strings.TrimSuffix(name, "Time")
+	return strings.TrimSuffix(name, "Transition")
+}
+
+// isUnsetTransition reports whether a transition value means the transition is
+// not configured, see confp.BlockForks.
+func isUnsetTransition(v *uint64) bool {
+	return v == nil || *v == math.MaxUint64 || *v == 0x7fffffffffffff || *v == 0x7FFFFFFFFFFFFFFF
+}
+
+// lessName orders names like EIP2 before EIP145, by comparing their first
+// embedded numbers numerically.
+func lessName(a, b string) bool {
+	split := func(s string) (string, uint64, string) {
+		i := strings.IndexAny(s, "0123456789")
+		if i < 0 {
+			return s, 0, ""
+		}
+		j := i
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		n, _ := strconv.ParseUint(s[i:j], 10, 64)
+		return s[:i], n, s[j:]
+	}
+	pa, na, ra := split(a)
+	pb, nb, rb := split(b)
+	if pa != pb {
+		return pa < pb
+	}
+	if na != nb {
+		return na < nb
+	}
+	return ra < rb
+}
+
+// scheduleGetters are the block-indexed parameter schedules of a chain
+// configuration, keyed by the name used in chain configuration files.
+var scheduleGetters = []struct {
+	name string
+	get  func(c ctypes.ChainConfigurator) ctypes.Uint64Uint256MapEncodesHex
+}{
+	{"blockReward", func(c ctypes.ChainConfigurator) ctypes.Uint64Uint256MapEncodesHex {
+		return c.GetEthashBlockRewardSchedule()
+	}},
+	{"difficultyBombDelays", func(c ctypes.ChainConfigurator) ctypes.Uint64Uint256MapEncodesHex {
+		return c.GetEthashDifficultyBombDelaySchedule()
+	}},
+	{"elasticityMultiplier", func(c ctypes.ChainConfigurator) ctypes.Uint64Uint256MapEncodesHex {
+		return c.GetElasticityMultiplierSchedule()
+	}},
+	{"baseFeeChangeDenominator", func(c ctypes.ChainConfigurator) ctypes.Uint64Uint256MapEncodesHex {
+		return c.GetBaseFeeChangeDenominatorSchedule()
+	}},
+	{"minBaseFee", func(c ctypes.ChainConfigurator) ctypes.Uint64Uint256MapEncodesHex {
+		return c.GetMinBaseFeeSchedule()
+	}},
+}
+
+// chainTimeline returns the protocol changes of a chain configuration, ordered
+// by activation. Block-based activations precede time-based ones.
+func chainTimeline(conf ctypes.ChainConfigurator) []*activation {
+	type key struct {
+		at     uint64
+		byTime bool
+	}
+	index := make(map[key]*activation)
+	add := func(at uint64, byTime bool, change string) {
+		k := key{at, byTime}
+		if index[k] == nil {
+			index[k] = &activation{at: at, byTime: byTime}
+		}
+		index[k].changes = append(index[k].changes, change)
+	}
+
+	fns, names := confp.Transitions(conf)
+	for i, fn := range fns {
+		v := fn()
+		if isUnsetTransition(v) {
+			continue
+		}
+		add(*v, strings.HasSuffix(names[i], "TransitionTime"), transitionName(names[i]))
+	}
+	for _, s := range scheduleGetters {
+		for n, v := range s.get(conf) {
+			add(n, false, fmt.Sprintf("%s=%s", s.name, v.Dec()))
+		}
+	}
+	if vault := conf.GetBaseFeeVault(); vault != nil {
+		var from uint64
+		if n := conf.GetBaseFeeVaultFromBlock(); n != nil {
+			from = *n
+		}
+		add(from, false, fmt.Sprintf("baseFeeVault=%s", vault.Hex()))
+	}
+
+	timeline := make([]*activation, 0, len(index))
+	for _, a := range index {
+		sort.Slice(a.changes, func(i, j int) bool { return lessName(a.changes[i], a.changes[j]) })
+		timeline = append(timeline, a)
+	}
+	sort.Slice(timeline, func(i, j int) bool {
+		if timeline[i].byTime != timeline[j].byTime {
+			return !timeline[i].byTime
+		}
+		return timeline[i].at < timeline[j].at
+	})
+	return timeline
+}
+
+func timeline(ctx *cli.Context) error {
+	for _, a := range chainTimeline(globalChainspecValue) {
+		fmt.Println(a)
+		for _, c := range a.changes {
+			fmt.Printf("\t%s\n", c)
+		}
+	}
+	return nil
+}
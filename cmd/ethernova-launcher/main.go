@@ -105,6 +105,7 @@ func main() {
 	fmt.Printf("Node started (pid=%d). Logs: %s / %s\n", proc.Process.Pid, paths.nodeLog, paths.nodeErr)
 
 	checkRPC(paths.httpPort)
+	go watchReachability(paths.httpPort)
 
 	fmt.Println("Press Enter to stop the node...")
 	_, _ = fmt.Scanln()
@@ -270,6 +271,35 @@ func checkRPC(port int) {
 	}
 }
 
+// watchReachability prints whether other nodes can connect to the node, once
+// the node has made its first check and whenever it changes.
+func watchReachability(port int) {
+	url := fmt.Sprintf("http://127.0.0.1:%d", port)
+	last := "unknown"
+	for {
+		time.Sleep(time.Minute)
+		resp, err := doRPC(url, "net_reachability", nil)
+		if err != nil {
+			continue
+		}
+		var status string
+		if err := json.Unmarshal(resp.Result, &status); err != nil || status == last {
+			continue
+		}
+		last = status
+		switch status {
+		case "reachable":
+			fmt.Println("P2P: node is reachable from the internet.")
+		case "unreachable":
+			fmt.Println("WARN: P2P: no inbound connections from the internet for an hour, and a local loopback dial of the advertised address failed.")
+			fmt.Println("      Forward TCP/UDP port 30303 to this machine and allow it in the firewall.")
+			fmt.Println("      Run admin.reachability in the console for details.")
+		default:
+			fmt.Printf("P2P: reachability %s\n", status)
+		}
+	}
+}
+
 func rpcChainID(url string) (string, error) {
 	resp, err := doRPC(url, "eth_chainId", nil)
 	if err != nil {
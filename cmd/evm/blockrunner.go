@@ -43,7 +43,7 @@ var blockTestCommand = &cli.Command{
 	Name:      "blocktest",
 	Usage:     "Executes the given blockchain tests",
 	ArgsUsage: "<file>",
-	Flags:     []cli.Flag{RunFlag},
+	Flags:     []cli.Flag{RunFlag, chainConfigFlag},
 }
 
 func blockTestCmd(ctx *cli.Context) error {
@@ -70,6 +70,10 @@ func blockTestCmd(ctx *cli.Context) error {
 	if err = json.Unmarshal(src, &tests); err != nil {
 		return err
 	}
+	chainConfig, err := loadChainConfig(ctx)
+	if err != nil {
+		return err
+	}
 	re, err := regexp.Compile(ctx.String(RunFlag.Name))
 	if err != nil {
 		return fmt.Errorf("invalid regex -%s: %v", RunFlag.Name, err)
@@ -85,14 +89,22 @@ func blockTestCmd(ctx *cli.Context) error {
 		if !re.MatchString(name) {
 			continue
 		}
-		test := tests[name]
-		if err := test.Run(false, rawdb.HashScheme, tracer, func(res error, chain *core.BlockChain) {
-			if ctx.Bool(DumpFlag.Name) {
-				if state, _ := chain.State(); state != nil {
-					fmt.Println(string(state.Dump(nil)))
+		var (
+			test      = tests[name]
+			postCheck = func(res error, chain *core.BlockChain) {
+				if ctx.Bool(DumpFlag.Name) {
+					if state, _ := chain.State(); state != nil {
+						fmt.Println(string(state.Dump(nil)))
+					}
 				}
 			}
-		}); err != nil {
+		)
+		if chainConfig != nil {
+			err = test.RunWithConfig(chainConfig, false, rawdb.HashScheme, tracer, postCheck)
+		} else {
+			err = test.Run(false, rawdb.HashScheme, tracer, postCheck)
+		}
+		if err != nil {
 			return fmt.Errorf("test %v: %w", name, err)
 		}
 	}
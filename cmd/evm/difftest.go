@@ -0,0 +1,127 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	diffForkFlag = &cli.StringFlag{
+		Name:     "fork",
+		Usage:    "Fork name, or chain configuration file (*.json), to execute with",
+		Value:    "Istanbul",
+		Category: flags.VMCategory,
+	}
+	diffBlockFlag = &cli.Uint64Flag{
+		Name:     "block",
+		Usage:    "Block number to execute at",
+		Category: flags.VMCategory,
+	}
+	diffTimeFlag = &cli.Uint64Flag{
+		Name:     "time",
+		Usage:    "Block timestamp to execute at",
+		Category: flags.VMCategory,
+	}
+)
+
+var diffTestCommand = &cli.Command{
+	Action:    diffTestCmd,
+	Name:      "difftest",
+	Usage:     "Executes code on the built-in interpreter and an EVMC interpreter, and compares the outcomes",
+	ArgsUsage: "<code>",
+	Description: `The difftest command runs EVM code on both the built-in interpreter and the
+EVMC interpreter given by --vm.evm, and compares the post-state, gas used and
+return data. It also reports the features of the fork definition which the
+EVMC revision cannot represent, as these are a likely cause of divergence.
+The command fails if the outcomes differ.`,
+	Flags: []cli.Flag{
+		CodeFlag,
+		CodeFileFlag,
+		InputFlag,
+		GasFlag,
+		ValueFlag,
+		diffForkFlag,
+		diffBlockFlag,
+		diffTimeFlag,
+		utils.EVMInterpreterFlag,
+	},
+}
+
+func diffTestCmd(ctx *cli.Context) error {
+	evmcConfig := ctx.String(utils.EVMInterpreterFlag.Name)
+	if evmcConfig == "" {
+		return fmt.Errorf("missing --%s value", utils.EVMInterpreterFlag.Name)
+	}
+	var hexcode []byte
+	switch {
+	case ctx.IsSet(CodeFileFlag.Name):
+		var err error
+		if hexcode, err = os.ReadFile(ctx.String(CodeFileFlag.Name)); err != nil {
+			return fmt.Errorf("could not load code from file: %v", err)
+		}
+	case ctx.IsSet(CodeFlag.Name):
+		hexcode = []byte(ctx.String(CodeFlag.Name))
+	case ctx.Args().Len() > 0:
+		hexcode = []byte(ctx.Args().First())
+	default:
+		return errors.New("missing code, use --code, --codefile or an argument")
+	}
+	hexcode = bytes.TrimSpace(hexcode)
+	if len(hexcode)%2 != 0 {
+		return fmt.Errorf("invalid input length for hex data (%d)", len(hexcode))
+	}
+	config, eips, err := tests.GetChainConfig(ctx.String(diffForkFlag.Name))
+	if err != nil {
+		return err
+	}
+	cfg := &runtime.Config{
+		ChainConfig: config,
+		BlockNumber: new(big.Int).SetUint64(ctx.Uint64(diffBlockFlag.Name)),
+		Time:        ctx.Uint64(diffTimeFlag.Name),
+		GasLimit:    ctx.Uint64(GasFlag.Name),
+		Value:       flags.GlobalBig(ctx, ValueFlag.Name),
+	}
+	cfg.EVMConfig.ExtraEips = eips
+
+	result, err := runtime.ExecuteDifferential(common.FromHex(string(hexcode)), common.FromHex(ctx.String(InputFlag.Name)), cfg, evmcConfig)
+	if err != nil {
+		return err
+	}
+	out, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(out))
+
+	if diffs := result.Divergences(); len(diffs) > 0 {
+		for _, diff := range diffs {
+			fmt.Fprintf(os.Stderr, "divergence: %s\n", diff)
+		}
+		return fmt.Errorf("%d divergences between the built-in and EVMC interpreters", len(diffs))
+	}
+	return nil
+}
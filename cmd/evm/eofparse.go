@@ -0,0 +1,155 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	eofHexFlag = &cli.StringFlag{
+		Name:     "hex",
+		Usage:    "Single container data to parse and validate",
+		Category: flags.DevCategory,
+	}
+	eofInitcodeFlag = &cli.BoolFlag{
+		Name:     "initcode",
+		Usage:    "Validate the containers as initcode instead of runtime code",
+		Category: flags.DevCategory,
+	}
+	eofTestFlag = &cli.StringFlag{
+		Name:     "test",
+		Usage:    "Path to an EOF validation test fixture to run",
+		Category: flags.DevCategory,
+	}
+)
+
+var eofParseCommand = &cli.Command{
+	Action: eofParseCmd,
+	Name:   "eofparse",
+	Usage:  "Parses and validates EOF containers. Hex encoded containers can be fed via standard input (one per line) or given with --hex.",
+	Flags: []cli.Flag{
+		eofHexFlag,
+		eofInitcodeFlag,
+		eofTestFlag,
+	},
+	Category: flags.DevCategory,
+}
+
+var eofDumpCommand = &cli.Command{
+	Action: eofDumpCmd,
+	Name:   "eofdump",
+	Usage:  "Parses an EOF container and prints its sections in a human readable form.",
+	Flags: []cli.Flag{
+		eofHexFlag,
+	},
+	Category: flags.DevCategory,
+}
+
+func eofParseCmd(ctx *cli.Context) error {
+	if ctx.IsSet(eofTestFlag.Name) {
+		return runEOFTests(ctx.String(eofTestFlag.Name))
+	}
+	jt, err := vm.LookupEOFInstructionSet(tests.Forks["EOFv1"], new(big.Int), new(uint64))
+	if err != nil {
+		return err
+	}
+	isInitCode := ctx.Bool(eofInitcodeFlag.Name)
+	if ctx.IsSet(eofHexFlag.Name) {
+		if err := parseAndValidate(ctx.String(eofHexFlag.Name), &jt, isInitCode); err != nil {
+			return err
+		}
+		fmt.Println("OK")
+		return nil
+	}
+	// Batch mode: read containers from standard input and report every line.
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := parseAndValidate(line, &jt, isInitCode); err != nil {
+			fmt.Printf("err: %v\n", err)
+		} else {
+			fmt.Println("OK")
+		}
+	}
+	return scanner.Err()
+}
+
+func eofDumpCmd(ctx *cli.Context) error {
+	if !ctx.IsSet(eofHexFlag.Name) {
+		return fmt.Errorf("missing --%s value", eofHexFlag.Name)
+	}
+	var c vm.Container
+	if err := c.UnmarshalBinary(common.FromHex(ctx.String(eofHexFlag.Name))); err != nil {
+		return err
+	}
+	fmt.Print(c.String())
+	return nil
+}
+
+func parseAndValidate(s string, jt *vm.JumpTable, isInitCode bool) error {
+	b, err := tests.FromHex(s)
+	if err != nil {
+		return fmt.Errorf("unable to decode data: %w", err)
+	}
+	var c vm.Container
+	if err := c.UnmarshalBinary(b); err != nil {
+		return err
+	}
+	return c.ValidateCode(jt, isInitCode)
+}
+
+// runEOFTests executes the EOF validation fixtures in the given file and
+// reports the outcome of every test.
+func runEOFTests(path string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fixtures map[string]*tests.EOFTest
+	if err := json.Unmarshal(src, &fixtures); err != nil {
+		return err
+	}
+	var failed int
+	for name, test := range fixtures {
+		if err := test.Run(); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "FAIL %s: %v\n", name, err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "PASS %s\n", name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, len(fixtures))
+	}
+	return nil
+}
@@ -120,7 +120,7 @@ type rejectedTx struct {
 
 // Apply applies a set of transactions to a pre-state
 func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig ctypes.ChainConfigurator,
-	txIt txIterator, miningReward int64,
+	txIt txIterator, miningReward int64, chainRewards bool,
 	getTracerFn func(txIndex int, txHash common.Hash) (vm.EVMLogger, error)) (*state.StateDB, *ExecutionResult, []byte, error) {
 	// Capture errors for BLOCKHASH operation, if we haven't been supplied the
 	// required blockhashes
@@ -195,6 +195,15 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig ctypes.ChainConfigura
 		evm := vm.NewEVM(vmContext, vm.TxContext{}, statedb, chainConfig, vmConfig)
 		core.ProcessBeaconBlockRoot(*beaconRoot, evm, statedb)
 	}
+	if pre.Env.Number > 0 && chainConfig.IsEnabled(chainConfig.GetEIP2935Transition, new(big.Int).SetUint64(pre.Env.Number)) {
+		prevNumber := pre.Env.Number - 1
+		prevHash, ok := pre.Env.BlockHashes[math.HexOrDecimal64(prevNumber)]
+		if !ok {
+			return nil, nil, nil, NewError(ErrorMissingBlockhash, fmt.Errorf("EIP-2935 requires the parent block hash, blockhash for block %d not provided", prevNumber))
+		}
+		evm := vm.NewEVM(vmContext, vm.TxContext{}, statedb, chainConfig, vmConfig)
+		core.ProcessParentBlockHash(prevHash, evm, statedb)
+	}
 
 	for i := 0; txIt.Next(); i++ {
 		tx, err := txIt.Tx()
@@ -301,7 +310,21 @@ func (pre *Prestate) Apply(vmConfig vm.Config, chainConfig ctypes.ChainConfigura
 	}
 	statedb.IntermediateRoot(chainConfig.IsEnabled(chainConfig.GetEIP161dTransition, vmContext.BlockNumber))
 	// Add mining reward? (-1 means rewards are disabled)
-	if miningReward > 0 {
+	if chainRewards {
+		// Credit the rewards as scheduled by the chain configuration, which
+		// covers eg. ECIP-1017 eras and custom block reward schedules.
+		var (
+			header = &types.Header{Number: vmContext.BlockNumber, Coinbase: pre.Env.Coinbase}
+			ommers = make([]*types.Header, 0, len(pre.Env.Ommers))
+		)
+		for _, ommer := range pre.Env.Ommers {
+			ommers = append(ommers, &types.Header{
+				Number:   new(big.Int).Sub(vmContext.BlockNumber, new(big.Int).SetUint64(ommer.Delta)),
+				Coinbase: ommer.Address,
+			})
+		}
+		mutations.AccumulateRewards(chainConfig, statedb, header, ommers)
+	} else if miningReward > 0 {
 		// Add mining reward. The mining reward may be `0`, which only makes a difference in the cases
 		// where
 		// - the coinbase self-destructed, or
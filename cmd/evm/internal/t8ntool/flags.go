@@ -141,6 +141,10 @@ var (
 		Usage: "Mining reward. Set to -1 to disable",
 		Value: 0,
 	}
+	ChainRewardsFlag = &cli.BoolFlag{
+		Name:  "state.reward.chain",
+		Usage: "Credit the block and ommer rewards scheduled by the chain configuration, instead of --state.reward",
+	}
 	ChainIDFlag = &cli.Int64Flag{
 		Name:  "state.chainid",
 		Usage: "ChainID to use",
@@ -153,7 +157,8 @@ var (
 			"\n\t    %v"+
 			"\n\tAvailable extra eips:"+
 			"\n\t    %v"+
-			"\n\tSyntax <forkname>(+ExtraEip)",
+			"\n\tSyntax <forkname>(+ExtraEip)"+
+			"\n\tA path to a chain configuration or genesis file (*.json) may be used in place of <forkname>",
 			strings.Join(tests.AvailableForks(), "\n\t    "),
 			strings.Join(vm.ActivateableEips(), ", ")),
 		Value: "GrayGlacier",
@@ -178,9 +178,11 @@ func Transition(ctx *cli.Context) error {
 		chainConfig = cConf
 		vmConfig.ExtraEips = extraEips
 	}
-	// Set the chain id
-	if err := chainConfig.SetChainID(big.NewInt(ctx.Int64(ChainIDFlag.Name))); err != nil {
-		return err
+	// Set the chain id, unless it is defined by a chain configuration file
+	if ctx.IsSet(ChainIDFlag.Name) || chainConfig.GetChainID() == nil {
+		if err := chainConfig.SetChainID(big.NewInt(ctx.Int64(ChainIDFlag.Name))); err != nil {
+			return err
+		}
 	}
 
 	if txIt, err = loadTransactions(txStr, inputData, prestate.Env, chainConfig); err != nil {
@@ -199,7 +201,7 @@ func Transition(ctx *cli.Context) error {
 		return err
 	}
 	// Run the test and aggregate the result
-	s, result, body, err := prestate.Apply(vmConfig, chainConfig, txIt, ctx.Int64(RewardFlag.Name), getTracer)
+	s, result, body, err := prestate.Apply(vmConfig, chainConfig, txIt, ctx.Int64(RewardFlag.Name), ctx.Bool(ChainRewardsFlag.Name), getTracer)
 	if err != nil {
 		return err
 	}
@@ -163,6 +163,7 @@ var stateTransitionCommand = &cli.Command{
 		t8ntool.ForknameFlag,
 		t8ntool.ChainIDFlag,
 		t8ntool.RewardFlag,
+		t8ntool.ChainRewardsFlag,
 		t8ntool.VerbosityFlag,
 		utils.EVMInterpreterFlag,
 		utils.EWASMInterpreterFlag,
@@ -237,6 +238,9 @@ func init() {
 	app.Commands = []*cli.Command{
 		compileCommand,
 		disasmCommand,
+		eofParseCommand,
+		eofDumpCommand,
+		diffTestCommand,
 		runCommand,
 		blockTestCommand,
 		stateTestCommand,
@@ -30,6 +30,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/internal/flags"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/tests"
 	"github.com/urfave/cli/v2"
 )
@@ -41,6 +42,7 @@ var stateTestCommand = &cli.Command{
 	ArgsUsage: "<file>",
 	Flags: []cli.Flag{
 		stateTestForkFlag,
+		chainConfigFlag,
 		stateTestEVMCEWASMFlag,
 		utils.EVMInterpreterFlag,
 	},
@@ -59,6 +61,21 @@ var stateTestForkFlag = &cli.StringFlag{
 	Category: flags.DevCategory,
 }
 
+var chainConfigFlag = &cli.StringFlag{
+	Name:     "chainconfig",
+	Usage:    "Chain configuration (or genesis) file to use instead of the fork definitions of the tests",
+	Category: flags.DevCategory,
+}
+
+// loadChainConfig returns the chain configuration requested by the
+// --chainconfig flag, or nil if the tests' own fork definitions are to be used.
+func loadChainConfig(ctx *cli.Context) (ctypes.ChainConfigurator, error) {
+	if !ctx.IsSet(chainConfigFlag.Name) {
+		return nil, nil
+	}
+	return tests.LoadChainConfig(ctx.String(chainConfigFlag.Name))
+}
+
 // StatetestResult contains the execution status after running a state test, any
 // error that might have occurred and a dump of the final state if requested.
 type StatetestResult struct {
@@ -99,9 +116,13 @@ func stateTestCmd(ctx *cli.Context) error {
 		vm.InitEVMCEwasm(cfg.EWASMInterpreter)
 	}
 
+	chainConfig, err := loadChainConfig(ctx)
+	if err != nil {
+		return err
+	}
 	// Load the test content from the input file
 	if len(ctx.Args().First()) != 0 {
-		return runStateTest(ctx.Args().First(), cfg, ctx.Bool(MachineFlag.Name), ctx.Bool(DumpFlag.Name), ctx.String(stateTestForkFlag.Name))
+		return runStateTest(ctx.Args().First(), cfg, ctx.Bool(MachineFlag.Name), ctx.Bool(DumpFlag.Name), ctx.String(stateTestForkFlag.Name), chainConfig)
 	}
 	// Read filenames from stdin and execute back-to-back
 	scanner := bufio.NewScanner(os.Stdin)
@@ -110,7 +131,7 @@ func stateTestCmd(ctx *cli.Context) error {
 		if len(fname) == 0 {
 			return nil
 		}
-		if err := runStateTest(fname, cfg, ctx.Bool(MachineFlag.Name), ctx.Bool(DumpFlag.Name), ctx.String(stateTestForkFlag.Name)); err != nil {
+		if err := runStateTest(fname, cfg, ctx.Bool(MachineFlag.Name), ctx.Bool(DumpFlag.Name), ctx.String(stateTestForkFlag.Name), chainConfig); err != nil {
 			return err
 		}
 	}
@@ -118,7 +139,8 @@ func stateTestCmd(ctx *cli.Context) error {
 }
 
 // runStateTest loads the state-test given by fname, and executes the test.
-func runStateTest(fname string, cfg vm.Config, jsonOut, dump bool, testFork string) error {
+// If chainConfig is non-nil, it is used in place of the subtests' fork definitions.
+func runStateTest(fname string, cfg vm.Config, jsonOut, dump bool, testFork string, chainConfig ctypes.ChainConfigurator) error {
 	src, err := os.ReadFile(fname)
 	if err != nil {
 		return err
@@ -135,6 +157,7 @@ func runStateTest(fname string, cfg vm.Config, jsonOut, dump bool, testFork stri
 			if testFork != "" && testFork != st.Fork {
 				continue
 			}
+			st.Config = chainConfig
 			// Run the test and aggregate the result
 			result := &StatetestResult{Name: key, Fork: st.Fork, Pass: true}
 			test.Run(st, cfg, false, rawdb.HashScheme, func(err error, tstate *tests.StateTestState) {
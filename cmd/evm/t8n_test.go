@@ -326,6 +326,50 @@ func TestT8n(t *testing.T) {
 	}
 }
 
+func TestT8nChainConfig(t *testing.T) {
+	t.Parallel()
+	tt := new(testT8n)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+	for i, tc := range []struct {
+		extra  []string
+		expOut string
+	}{
+		{ // A chain configuration file in place of a fork name
+			expOut: "./testdata/1/exp.json",
+		},
+		{ // Block reward as scheduled by the chain configuration
+			extra:  []string{"--state.reward.chain"},
+			expOut: "./testdata/31/exp.json",
+		},
+	} {
+		var (
+			input  = t8nInput{"alloc.json", "txs.json", "env.json", "./testdata/31/chain.json", ""}
+			output = t8nOutput{alloc: true, result: true}
+			args   = []string{"t8n"}
+		)
+		args = append(args, output.get()...)
+		args = append(args, input.get("./testdata/31")...)
+		args = append(args, tc.extra...)
+		tt.Run("evm-test", args...)
+		want, err := os.ReadFile(tc.expOut)
+		if err != nil {
+			t.Fatalf("test %d: could not read expected output: %v", i, err)
+		}
+		have := tt.Output()
+		ok, err := cmpJson(have, want)
+		switch {
+		case err != nil:
+			t.Fatalf("test %d, file %v: json parsing failed: %v", i, tc.expOut, err)
+		case !ok:
+			t.Fatalf("test %d, file %v: output wrong, have \n%v\nwant\n%v\n", i, tc.expOut, string(have), string(want))
+		}
+		tt.WaitExit()
+		if have := tt.ExitStatus(); have != 0 {
+			t.Fatalf("test %d: wrong exit code, have %d, want 0", i, have)
+		}
+	}
+}
+
 type t9nInput struct {
 	inTxs  string
 	stFork string
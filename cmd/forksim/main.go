@@ -0,0 +1,128 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of core-geth.
+//
+// core-geth is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// core-geth is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with core-geth. If not, see <http://www.gnu.org/licenses/>.
+
+// forksim rehearses hard forks on simulated networks of in-process nodes.
+//
+// Run a scenario with:
+//
+//	forksim run scenarios/ethernova-70000.json
+//
+// The command prints the heads of all nodes and the assertion results, and
+// fails if any assertion doesn't hold.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/internal/debug"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/simulations/forksim"
+	"github.com/urfave/cli/v2"
+)
+
+var app = flags.NewApp("hard fork rehearsal simulator")
+
+var (
+	jsonFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the report as JSON",
+	}
+
+	runCommand = &cli.Command{
+		Name:      "run",
+		Usage:     "Runs a simulation scenario",
+		ArgsUsage: "<scenario.json>",
+		Action:    runScenario,
+		Flags:     []cli.Flag{jsonFlag},
+	}
+)
+
+func init() {
+	app.Flags = append(app.Flags, debug.Flags...)
+	app.Before = func(ctx *cli.Context) error {
+		flags.MigrateGlobalFlags(ctx)
+		return debug.Setup(ctx)
+	}
+	app.After = func(ctx *cli.Context) error {
+		debug.Exit()
+		return nil
+	}
+	app.Commands = []*cli.Command{runCommand}
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func runScenario(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("need scenario file as argument")
+	}
+	scenario, err := forksim.LoadScenario(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	sctx, stop := signal.NotifyContext(ctx.Context, os.Interrupt)
+	defer stop()
+
+	report, err := forksim.Run(sctx, scenario, log.Root())
+	if err != nil {
+		return err
+	}
+	if ctx.Bool(jsonFlag.Name) {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printReport(report)
+	}
+	if !report.OK() {
+		return errors.New("assertions failed")
+	}
+	return nil
+}
+
+func printReport(report *forksim.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tGROUP\tHEAD\tHASH\tPEERS")
+	for _, n := range report.Nodes {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%x\t%d\n", n.Name, n.Group, n.Head, n.Hash.Bytes()[:8], n.Peers)
+	}
+	w.Flush()
+	fmt.Println()
+	for _, res := range report.Results {
+		status := "OK  "
+		if !res.OK {
+			status = "FAIL"
+		}
+		if res.Detail != "" {
+			fmt.Printf("%s %s: %s\n", status, res.Assertion, res.Detail)
+		} else {
+			fmt.Printf("%s %s\n", status, res.Assertion)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of core-geth.
+//
+// core-geth is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// core-geth is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with core-geth. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/checkpoint"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/flags"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	cli "github.com/urfave/cli/v2"
+)
+
+var (
+	checkpointKeyFileFlag = &cli.StringFlag{
+		Name:     "keyfile",
+		Usage:    "Keystore file of the checkpoint signer",
+		Required: true,
+	}
+
+	checkpointCommand = &cli.Command{
+		Name:  "checkpoint",
+		Usage: "Sign and verify sync checkpoints",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "sign",
+				Usage:     "Sign a checkpoint of a local canonical block",
+				ArgsUsage: "<number> <file>",
+				Action:    signCheckpoint,
+				Flags:     flags.Merge([]cli.Flag{checkpointKeyFileFlag, utils.PasswordFileFlag}, utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth checkpoint sign <number> <file>
+Signs the local canonical block <number> (hash and total difficulty) with the
+given keystore key and writes the signed checkpoint to <file>. If <file> already
+contains the same checkpoint, the signature is added to the existing ones, so
+signers can take turns signing the same file until the threshold is reached.
+`,
+			},
+			{
+				Name:      "verify",
+				Usage:     "Verify a signed checkpoint against the configured signers",
+				ArgsUsage: "<file>",
+				Action:    verifyCheckpoint,
+				Flags:     flags.Merge(utils.NetworkFlags, utils.DatabaseFlags),
+				Description: `
+geth checkpoint verify <file>
+Verifies the signatures of a signed checkpoint against the checkpoint signers
+and threshold of the chain configuration, and checks it against the local chain
+if it has the checkpointed block.
+`,
+			},
+		},
+	}
+)
+
+// checkpointChainConfig reads the chain configuration stored in the database.
+func checkpointChainConfig(db ethdb.Database) (ctypes.ChainConfigurator, error) {
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		return nil, errors.New("database not initialized")
+	}
+	config := rawdb.ReadChainConfig(db, genesis)
+	if config == nil {
+		return nil, errors.New("chain config not found")
+	}
+	return config, nil
+}
+
+func signCheckpoint(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return errors.New("need block number and checkpoint file as arguments")
+	}
+	number, err := strconv.ParseUint(ctx.Args().Get(0), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid block number: %v", err)
+	}
+	path := ctx.Args().Get(1)
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	config, err := checkpointChainConfig(db)
+	if err != nil {
+		return err
+	}
+	hash := rawdb.ReadCanonicalHash(db, number)
+	if hash == (common.Hash{}) {
+		return fmt.Errorf("block %d not found in local chain", number)
+	}
+	td := rawdb.ReadTd(db, hash, number)
+	if td == nil {
+		return fmt.Errorf("total difficulty of block %d not found", number)
+	}
+	cp := &checkpoint.SignedCheckpoint{Checkpoint: checkpoint.Checkpoint{Number: number, Hash: hash, TD: td}}
+
+	// Add to an existing signed checkpoint if it vouches for the same block
+	if _, err := os.Stat(path); err == nil {
+		known, err := checkpoint.Load(path)
+		if err != nil {
+			return err
+		}
+		if known.Checkpoint.Number != number || known.Checkpoint.Hash != hash || known.Checkpoint.TD.Cmp(td) != 0 {
+			return fmt.Errorf("%s contains a different checkpoint (number %d, hash %x)", path, known.Checkpoint.Number, known.Checkpoint.Hash)
+		}
+		cp = known
+	}
+	keyfile := ctx.String(checkpointKeyFileFlag.Name)
+	keyjson, err := os.ReadFile(keyfile)
+	if err != nil {
+		return fmt.Errorf("failed to read the keyfile at '%s': %v", keyfile, err)
+	}
+	password := utils.GetPassPhraseWithList("Please enter the password for '"+keyfile+"'", false, 0, utils.MakePasswordList(ctx))
+	key, err := keystore.DecryptKey(keyjson, password)
+	if err != nil {
+		return fmt.Errorf("error decrypting key: %v", err)
+	}
+	if err := cp.Sign(config.GetChainID(), key.PrivateKey); err != nil {
+		return err
+	}
+	if err := cp.Save(path); err != nil {
+		return err
+	}
+	log.Info("Signed checkpoint", "number", number, "hash", hash, "td", td, "signer", key.Address, "signatures", len(cp.Signatures))
+	return nil
+}
+
+func verifyCheckpoint(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return errors.New("need checkpoint file as argument")
+	}
+	cp, err := checkpoint.Load(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, true)
+	defer db.Close()
+
+	config, err := checkpointChainConfig(db)
+	if err != nil {
+		return err
+	}
+	signers, err := cp.Signers(config.GetChainID())
+	if err != nil {
+		return err
+	}
+	for _, signer := range signers {
+		log.Info("Checkpoint signature", "signer", signer)
+	}
+	if err := cp.Verify(config.GetChainID(), checkpoint.SignersConfig(config)); err != nil {
+		return err
+	}
+	number, hash := cp.Checkpoint.Number, cp.Checkpoint.Hash
+	if local := rawdb.ReadCanonicalHash(db, number); local == (common.Hash{}) {
+		log.Warn("Checkpointed block not in local chain", "number", number)
+	} else if local != hash {
+		return fmt.Errorf("local chain conflicts with checkpoint %d: have %x, want %x", number, local, hash)
+	}
+	log.Info("Checkpoint verified", "number", number, "hash", hash, "td", cp.Checkpoint.TD)
+	return nil
+}
@@ -88,7 +88,8 @@ var tomlSettings = toml.Config{
 }
 
 type ethstatsConfig struct {
-	URL string `toml:",omitempty"`
+	URL         string `toml:",omitempty"`
+	Propagation bool   `toml:",omitempty"`
 }
 
 type gethConfig struct {
@@ -162,6 +163,9 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	if ctx.IsSet(utils.EthStatsURLFlag.Name) {
 		cfg.Ethstats.URL = ctx.String(utils.EthStatsURLFlag.Name)
 	}
+	if ctx.IsSet(utils.EthStatsPropagationFlag.Name) {
+		cfg.Ethstats.Propagation = ctx.Bool(utils.EthStatsPropagationFlag.Name)
+	}
 	applyMetricConfig(ctx, &cfg)
 
 	return stack, cfg
@@ -222,9 +226,13 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if ctx.IsSet(utils.GraphQLEnabledFlag.Name) {
 		utils.RegisterGraphQLService(stack, backend, filterSystem, &cfg.Node)
 	}
+	// Configure the user operation bundler if requested.
+	if ctx.IsSet(utils.BundlerEnabledFlag.Name) {
+		utils.RegisterBundlerService(ctx, stack, backend)
+	}
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
-		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
+		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL, cfg.Ethstats.Propagation)
 	}
 	// Configure full-sync tester service if requested
 	if ctx.IsSet(utils.SyncTargetFlag.Name) {
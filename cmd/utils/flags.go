@@ -32,6 +32,7 @@ import (
 	"path/filepath"
 	"runtime"
 	godebug "runtime/debug"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -46,15 +47,18 @@ import (
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool/legacypool"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/crypto/kzg4844"
 	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/bundler"
 	"github.com/ethereum/go-ethereum/eth/catalyst"
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/filters"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
+	ethproto "github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethdb/remotedb"
@@ -310,6 +314,12 @@ var (
 		Value:    &defaultSyncMode,
 		Category: flags.StateCategory,
 	}
+	CheckpointFileFlag = &cli.PathFlag{
+		Name:      "checkpoint.file",
+		Usage:     "Signed checkpoint file to enforce during sync (see 'geth checkpoint sign')",
+		TakesFile: true,
+		Category:  flags.StateCategory,
+	}
 	GCModeFlag = &cli.StringFlag{
 		Name:     "gcmode",
 		Usage:    `Blockchain garbage collection mode, only relevant in state.scheme=hash ("full", "archive")`,
@@ -336,7 +346,7 @@ var (
 	// Light server and client settings
 	LightServeFlag = &cli.IntFlag{
 		Name:     "light.serve",
-		Usage:    "Maximum percentage of time allowed for serving LES requests (multi-threaded processing allows values over 100)",
+		Usage:    "Serves headers, CHT and state proofs to light clients over the lhp protocol if above zero",
 		Value:    ethconfig.Defaults.LightServ,
 		Category: flags.LightCategory,
 	}
@@ -354,7 +364,7 @@ var (
 	}
 	LightMaxPeersFlag = &cli.IntFlag{
 		Name:     "light.maxpeers",
-		Usage:    "Maximum number of light clients to serve, or light servers to attach to",
+		Usage:    "Maximum number of light clients to serve",
 		Value:    ethconfig.Defaults.LightPeers,
 		Category: flags.LightCategory,
 	}
@@ -504,6 +514,22 @@ var (
 		Value:    ethconfig.Defaults.TxPool.Lifetime,
 		Category: flags.TxPoolCategory,
 	}
+	TxPoolPrivatePeersFlag = &cli.StringFlag{
+		Name:     "txpool.private.peers",
+		Usage:    "Comma separated enode URLs of the trusted mining peers to relay private transactions to",
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPrivateExpiryFlag = &cli.Uint64Flag{
+		Name:     "txpool.private.expiry",
+		Usage:    "Number of blocks after which pending private transactions are broadcast publicly",
+		Value:    ethconfig.Defaults.PrivateTxExpiry,
+		Category: flags.TxPoolCategory,
+	}
+	TxPoolPrivateTransportsFlag = &cli.StringFlag{
+		Name:     "txpool.private.transports",
+		Usage:    "Comma separated RPC transports (http, ws, ipc) whose submitted transactions are all private",
+		Category: flags.TxPoolCategory,
+	}
 	// Blob transaction pool settings
 	BlobPoolDataDirFlag = &cli.StringFlag{
 		Name:     "blobpool.datadir",
@@ -695,6 +721,16 @@ var (
 		Value:    ethconfig.Defaults.RPCTxFeeCap,
 		Category: flags.APICategory,
 	}
+	TraceIndexFlag = &cli.BoolFlag{
+		Name:     "trace.index",
+		Usage:    "Trace canonical blocks in the background to serve trace_block and trace_filter from an index (requires archive state)",
+		Category: flags.APICategory,
+	}
+	TraceIndexFromFlag = &cli.Uint64Flag{
+		Name:     "trace.index.from",
+		Usage:    "Block number to start the trace index from",
+		Category: flags.APICategory,
+	}
 	// Authenticated RPC HTTP settings
 	AuthListenFlag = &cli.StringFlag{
 		Name:     "authrpc.addr",
@@ -726,6 +762,11 @@ var (
 		Usage:    "Reporting URL of a ethstats service (nodename:secret@host:port)",
 		Category: flags.MetricsCategory,
 	}
+	EthStatsPropagationFlag = &cli.BoolFlag{
+		Name:     "ethstats.propagation",
+		Usage:    "Report the propagation of blocks received from the network to the ethstats service",
+		Category: flags.MetricsCategory,
+	}
 	FakePoWFlag = &cli.BoolFlag{
 		Name:     "fakepow",
 		Usage:    "Disables proof-of-work verification",
@@ -819,6 +860,34 @@ var (
 		Value:    strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
 		Category: flags.APICategory,
 	}
+	BundlerEnabledFlag = &cli.BoolFlag{
+		Name:     "bundler",
+		Usage:    "Enable the ERC-4337 user operation bundler (eth_sendUserOperation and related methods)",
+		Category: flags.APICategory,
+	}
+	BundlerEntryPointsFlag = &cli.StringFlag{
+		Name:     "bundler.entrypoints",
+		Usage:    "Comma separated list of EntryPoint contracts to accept user operations for",
+		Value:    bundler.DefaultEntryPoint.Hex(),
+		Category: flags.APICategory,
+	}
+	BundlerAccountFlag = &cli.StringFlag{
+		Name:     "bundler.account",
+		Usage:    "Unlocked account signing the bundle transactions and receiving their fees",
+		Category: flags.APICategory,
+	}
+	BundlerIntervalFlag = &cli.DurationFlag{
+		Name:     "bundler.interval",
+		Usage:    "Interval between user operation bundles",
+		Value:    bundler.DefaultConfig.Interval,
+		Category: flags.APICategory,
+	}
+	BundlerMaxGasFlag = &cli.Uint64Flag{
+		Name:     "bundler.maxgas",
+		Usage:    "Maximum total gas of the user operations in a bundle",
+		Value:    bundler.DefaultConfig.MaxBundleGas,
+		Category: flags.APICategory,
+	}
 	WSEnabledFlag = &cli.BoolFlag{
 		Name:     "ws",
 		Usage:    "Enable the WS-RPC server",
@@ -928,6 +997,16 @@ var (
 		Value:    "any",
 		Category: flags.NetworkingCategory,
 	}
+	NATPortsFlag = &cli.StringFlag{
+		Name:     "nat.ports",
+		Usage:    "Ports forwarded manually on the router, as comma separated <external>[:<local>] ports or port ranges (e.g. 30303 or 40000-40009:30303-30312)",
+		Category: flags.NetworkingCategory,
+	}
+	NATExtIP6Flag = &cli.StringFlag{
+		Name:     "nat.extip6",
+		Usage:    "IPv6 address to advertise next to the IPv4 one when listening on all interfaces (<IP> or auto for a global address of this machine)",
+		Category: flags.NetworkingCategory,
+	}
 	NoDiscoverFlag = &cli.BoolFlag{
 		Name:     "nodiscover",
 		Usage:    "Disables the peer discovery mechanism (manual peer addition)",
@@ -962,6 +1041,32 @@ var (
 		Value:    30303,
 		Category: flags.NetworkingCategory,
 	}
+	DialStrategyFlag = &cli.StringFlag{
+		Name:     "dialstrategy",
+		Usage:    "Sources of dial candidates: mixed (verified peers of earlier sessions and discovered nodes), verified (verified peers first) or discovery",
+		Value:    p2p.DialMixed,
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthUploadFlag = &cli.IntFlag{
+		Name:     "bandwidth.upload",
+		Usage:    "Maximum upload rate to all peers in KB/s (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthDownloadFlag = &cli.IntFlag{
+		Name:     "bandwidth.download",
+		Usage:    "Maximum download rate from all peers in KB/s (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthPeerUploadFlag = &cli.IntFlag{
+		Name:     "bandwidth.peerupload",
+		Usage:    "Maximum upload rate to each peer in KB/s (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
+	BandwidthPeerDownloadFlag = &cli.IntFlag{
+		Name:     "bandwidth.peerdownload",
+		Usage:    "Maximum download rate from each peer in KB/s (0 = unlimited)",
+		Category: flags.NetworkingCategory,
+	}
 
 	// Console
 	JSpathFlag = &flags.DirectoryFlag{
@@ -1002,6 +1107,11 @@ var (
 		Value:    ethconfig.Defaults.GPO.IgnorePrice.Int64(),
 		Category: flags.GasPriceCategory,
 	}
+	GpoMempoolFlag = &cli.BoolFlag{
+		Name:     "gpo.mempool",
+		Usage:    "Suggest gas prices from the pending transactions, miner tip and projected base fee rather than from recent blocks alone",
+		Category: flags.GasPriceCategory,
+	}
 
 	// Metrics flags
 	MetricsEnabledFlag = &cli.BoolFlag{
@@ -1295,6 +1405,25 @@ func setNAT(ctx *cli.Context, cfg *p2p.Config) {
 		}
 		cfg.NAT = natif
 	}
+	if ctx.IsSet(NATPortsFlag.Name) {
+		ports, err := nat.ParsePortMap(ctx.String(NATPortsFlag.Name))
+		if err != nil {
+			Fatalf("Option %s: %v", NATPortsFlag.Name, err)
+		}
+		cfg.MappedPorts = ports
+	}
+	if ctx.IsSet(NATExtIP6Flag.Name) {
+		switch spec := ctx.String(NATExtIP6Flag.Name); spec {
+		case "auto":
+			cfg.ExtIP6 = net.IPv6unspecified
+		default:
+			ip := net.ParseIP(spec)
+			if ip == nil || ip.To4() != nil {
+				Fatalf("Option %s: invalid IPv6 address %q", NATExtIP6Flag.Name, spec)
+			}
+			cfg.ExtIP6 = ip
+		}
+	}
 }
 
 // SplitAndTrim splits input separated by a comma
@@ -1416,10 +1545,11 @@ func setIPC(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
-// setLes shows the deprecation warnings for LES flags.
+// setLes applies the light serving flags and shows the deprecation warnings for
+// the remaining LES flags.
 func setLes(ctx *cli.Context, cfg *ethconfig.Config) {
 	if ctx.IsSet(LightServeFlag.Name) {
-		log.Warn("The light server has been deprecated, please remove this flag", "flag", LightServeFlag.Name)
+		cfg.LightServ = ctx.Int(LightServeFlag.Name)
 	}
 	if ctx.IsSet(LightIngressFlag.Name) {
 		log.Warn("The light server has been deprecated, please remove this flag", "flag", LightIngressFlag.Name)
@@ -1428,7 +1558,7 @@ func setLes(ctx *cli.Context, cfg *ethconfig.Config) {
 		log.Warn("The light server has been deprecated, please remove this flag", "flag", LightEgressFlag.Name)
 	}
 	if ctx.IsSet(LightMaxPeersFlag.Name) {
-		log.Warn("The light server has been deprecated, please remove this flag", "flag", LightMaxPeersFlag.Name)
+		cfg.LightPeers = ctx.Int(LightMaxPeersFlag.Name)
 	}
 	if ctx.IsSet(UltraLightServersFlag.Name) {
 		cfg.UltraLightServers = strings.Split(ctx.String(UltraLightServersFlag.Name), ",")
@@ -1447,7 +1577,7 @@ func setLes(ctx *cli.Context, cfg *ethconfig.Config) {
 		log.Warn("The light server has been deprecated, please remove this flag", "flag", LightNoPruneFlag.Name)
 	}
 	if ctx.IsSet(LightNoSyncServeFlag.Name) {
-		log.Warn("The light server has been deprecated, please remove this flag", "flag", LightNoSyncServeFlag.Name)
+		cfg.LightNoSyncServe = ctx.Bool(LightNoSyncServeFlag.Name)
 	}
 }
 
@@ -1545,11 +1675,23 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	setBootstrapNodes(ctx, cfg)
 	setBootstrapNodesV5(ctx, cfg)
 
+	// Light clients get their own slots on top of the eth peers, unless both
+	// limits are set explicitly and the total is up to the user.
+	lightServer := ctx.Int(LightServeFlag.Name) != 0
+	lightPeers := ctx.Int(LightMaxPeersFlag.Name)
 	if ctx.IsSet(MaxPeersFlag.Name) {
 		cfg.MaxPeers = ctx.Int(MaxPeersFlag.Name)
+		if lightServer && !ctx.IsSet(LightMaxPeersFlag.Name) {
+			cfg.MaxPeers += lightPeers
+		}
+	} else if lightServer {
+		cfg.MaxPeers += lightPeers
+	}
+	if !lightServer {
+		lightPeers = 0
 	}
-	ethPeers := cfg.MaxPeers
-	log.Info("Maximum peer count", "ETH", ethPeers, "total", cfg.MaxPeers)
+	ethPeers := cfg.MaxPeers - lightPeers
+	log.Info("Maximum peer count", "ETH", ethPeers, "LES", lightPeers, "total", cfg.MaxPeers)
 
 	if ctx.IsSet(MaxPendingPeersFlag.Name) {
 		cfg.MaxPendingPeers = ctx.Int(MaxPendingPeersFlag.Name)
@@ -1571,6 +1713,22 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 		cfg.NetRestrict = list
 	}
 
+	if ctx.IsSet(DialStrategyFlag.Name) {
+		cfg.DialStrategy = ctx.String(DialStrategyFlag.Name)
+	}
+	if ctx.IsSet(BandwidthUploadFlag.Name) {
+		cfg.UploadRate = ctx.Int(BandwidthUploadFlag.Name) * 1024
+	}
+	if ctx.IsSet(BandwidthDownloadFlag.Name) {
+		cfg.DownloadRate = ctx.Int(BandwidthDownloadFlag.Name) * 1024
+	}
+	if ctx.IsSet(BandwidthPeerUploadFlag.Name) {
+		cfg.PeerUploadRate = ctx.Int(BandwidthPeerUploadFlag.Name) * 1024
+	}
+	if ctx.IsSet(BandwidthPeerDownloadFlag.Name) {
+		cfg.PeerDownloadRate = ctx.Int(BandwidthPeerDownloadFlag.Name) * 1024
+	}
+
 	if ctx.Bool(DeveloperFlag.Name) || ctx.Bool(DeveloperPoWFlag.Name) {
 		// --dev mode can't use p2p networking.
 		cfg.MaxPeers = 0
@@ -1737,6 +1895,9 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 	if ctx.IsSet(GpoIgnoreGasPriceFlag.Name) {
 		cfg.IgnorePrice = big.NewInt(ctx.Int64(GpoIgnoreGasPriceFlag.Name))
 	}
+	if ctx.IsSet(GpoMempoolFlag.Name) {
+		cfg.Mempool = ctx.Bool(GpoMempoolFlag.Name)
+	}
 }
 
 func setTxPool(ctx *cli.Context, cfg *legacypool.Config) {
@@ -1792,6 +1953,26 @@ func homeDir() string {
 	return ""
 }
 
+func setPrivateTxs(ctx *cli.Context, cfg *ethconfig.Config) {
+	if ctx.IsSet(TxPoolPrivatePeersFlag.Name) {
+		cfg.PrivateTxPeers = SplitAndTrim(ctx.String(TxPoolPrivatePeersFlag.Name))
+	}
+	if ctx.IsSet(TxPoolPrivateExpiryFlag.Name) {
+		cfg.PrivateTxExpiry = ctx.Uint64(TxPoolPrivateExpiryFlag.Name)
+	}
+	if ctx.IsSet(TxPoolPrivateTransportsFlag.Name) {
+		cfg.PrivateTxTransports = nil
+		for _, transport := range SplitAndTrim(ctx.String(TxPoolPrivateTransportsFlag.Name)) {
+			switch transport {
+			case "http", "ws", "ipc":
+				cfg.PrivateTxTransports = append(cfg.PrivateTxTransports, transport)
+			default:
+				Fatalf("Invalid transport in --%s: %s", TxPoolPrivateTransportsFlag.Name, transport)
+			}
+		}
+	}
+}
+
 func setEthashDatasetDir(ctx *cli.Context, cfg *ethconfig.Config) {
 	switch {
 	case ctx.IsSet(EthashDatasetDirFlag.Name):
@@ -1964,6 +2145,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	setEtherbase(ctx, cfg)
 	setGPO(ctx, &cfg.GPO)
 	setTxPool(ctx, &cfg.TxPool)
+	setPrivateTxs(ctx, cfg)
 	setEthash(ctx, cfg)
 	setMiner(ctx, &cfg.Miner)
 	setRequiredBlocks(ctx, cfg)
@@ -1994,6 +2176,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	} else if ctx.IsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *flags.GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
 	}
+	if ctx.IsSet(CheckpointFileFlag.Name) {
+		cfg.CheckpointFile = ctx.Path(CheckpointFileFlag.Name)
+	}
 
 	if ctx.IsSet(CacheFlag.Name) || ctx.IsSet(CacheDatabaseFlag.Name) {
 		cfg.DatabaseCache = ctx.Int(CacheFlag.Name) * ctx.Int(CacheDatabaseFlag.Name) / 100
@@ -2091,6 +2276,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if ctx.IsSet(RPCGlobalGasCapFlag.Name) {
 		cfg.RPCGasCap = ctx.Uint64(RPCGlobalGasCapFlag.Name)
 	}
+	if ctx.IsSet(TraceIndexFlag.Name) {
+		cfg.TraceIndex = ctx.Bool(TraceIndexFlag.Name)
+	}
+	if ctx.IsSet(TraceIndexFromFlag.Name) {
+		cfg.TraceIndexFrom = ctx.Uint64(TraceIndexFromFlag.Name)
+	}
 	if cfg.RPCGasCap != 0 {
 		log.Info("Set global gas cap", "cap", cfg.RPCGasCap)
 	} else {
@@ -2136,7 +2327,20 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 		supportedProtocolVersions = cfg.Genesis.GetSupportedProtocolVersions()
 	}
 
+	// Unless set on the command line, run the versions of the chain
+	// configuration that are implemented, e.g. eth/69 on Ethernova.
 	configuredProtocolVersions := SplitAndTrim(ctx.String(EthProtocolsFlag.Name))
+	if !ctx.IsSet(EthProtocolsFlag.Name) && cfg.Genesis != nil {
+		var versions []string
+		for _, version := range supportedProtocolVersions {
+			if slices.Contains(ethproto.ProtocolVersions, version) {
+				versions = append(versions, strconv.FormatUint(uint64(version), 10))
+			}
+		}
+		if len(versions) > 0 {
+			configuredProtocolVersions = versions
+		}
+	}
 	if len(configuredProtocolVersions) == 0 {
 		Fatalf("--%s must be comma separated list of %s", EthProtocolsFlag.Name, strings.Join(strings.Fields(fmt.Sprint(supportedProtocolVersions)), ","))
 	}
@@ -2319,13 +2523,24 @@ func RegisterEthService(stack *node.Node, cfg *ethconfig.Config) (ethapi.Backend
 	if err != nil {
 		Fatalf("Failed to register the Ethereum service: %v", err)
 	}
-	stack.RegisterAPIs(tracers.APIs(backend.APIBackend))
+	var indexer *tracers.TraceIndexer
+	if cfg.TraceIndex {
+		db, err := stack.OpenDatabase("traceindex", cfg.DatabaseCache/8, cfg.DatabaseHandles/8, "eth/db/traceindex/", false)
+		if err != nil {
+			Fatalf("Failed to open trace index database: %v", err)
+		}
+		if indexer, err = tracers.NewTraceIndexer(backend.APIBackend, db, cfg.TraceIndexFrom); err != nil {
+			Fatalf("Failed to create trace indexer: %v", err)
+		}
+		stack.RegisterLifecycle(indexer)
+	}
+	stack.RegisterAPIs(tracers.IndexedAPIs(backend.APIBackend, indexer))
 	return backend.APIBackend, backend
 }
 
 // RegisterEthStatsService configures the Ethereum Stats daemon and adds it to the node.
-func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url string) {
-	if err := ethstats.New(stack, backend, backend.Engine(), url); err != nil {
+func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url string, propagation bool) {
+	if err := ethstats.New(stack, backend, backend.Engine(), url, propagation); err != nil {
 		Fatalf("Failed to register the Ethereum Stats service: %v", err)
 	}
 }
@@ -2338,6 +2553,36 @@ func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, filterSyst
 	}
 }
 
+// RegisterBundlerService adds the ERC-4337 user operation bundler to the node.
+func RegisterBundlerService(ctx *cli.Context, stack *node.Node, backend ethapi.Backend) {
+	cfg := bundler.DefaultConfig
+	cfg.EntryPoints = nil
+	for _, entry := range SplitAndTrim(ctx.String(BundlerEntryPointsFlag.Name)) {
+		if !common.IsHexAddress(entry) {
+			Fatalf("Invalid bundler entrypoint %q", entry)
+		}
+		cfg.EntryPoints = append(cfg.EntryPoints, common.HexToAddress(entry))
+	}
+	if !common.IsHexAddress(ctx.String(BundlerAccountFlag.Name)) {
+		Fatalf("The bundler requires a valid --%s", BundlerAccountFlag.Name)
+	}
+	cfg.Account = common.HexToAddress(ctx.String(BundlerAccountFlag.Name))
+	cfg.Interval = ctx.Duration(BundlerIntervalFlag.Name)
+	cfg.MaxBundleGas = ctx.Uint64(BundlerMaxGasFlag.Name)
+
+	account := accounts.Account{Address: cfg.Account}
+	wallet, err := stack.AccountManager().Find(account)
+	if err != nil {
+		Fatalf("Failed to find bundler account %s: %v", cfg.Account, err)
+	}
+	sign := func(tx *types.Transaction) (*types.Transaction, error) {
+		return wallet.SignTx(account, tx, backend.ChainConfig().GetChainID())
+	}
+	b := bundler.New(backend, cfg, sign)
+	stack.RegisterAPIs(b.APIs())
+	stack.RegisterLifecycle(b)
+}
+
 // RegisterFilterAPI adds the eth log filtering RPC API to the node.
 func RegisterFilterAPI(stack *node.Node, backend ethapi.Backend, ethcfg *ethconfig.Config) *filters.FilterSystem {
 	filterSystem := filters.NewFilterSystem(backend, filters.Config{
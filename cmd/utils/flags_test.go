@@ -18,8 +18,12 @@
 package utils
 
 import (
+	"flag"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/urfave/cli/v2"
 )
 
 func Test_SplitTagsFlag(t *testing.T) {
@@ -65,3 +69,34 @@ func Test_SplitTagsFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestSetP2PConfigLightPeers(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		args      []string
+		wantTotal int
+	}{
+		{nil, 50},
+		{[]string{"--light.serve", "1"}, 150},
+		{[]string{"--light.serve", "1", "--maxpeers", "20"}, 120},
+		{[]string{"--light.serve", "1", "--light.maxpeers", "10"}, 60},
+		{[]string{"--light.serve", "1", "--maxpeers", "20", "--light.maxpeers", "10"}, 20},
+		{[]string{"--maxpeers", "20", "--light.maxpeers", "10"}, 20},
+	}
+	for _, tt := range tests {
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		for _, f := range []cli.Flag{MaxPeersFlag, LightServeFlag, LightMaxPeersFlag} {
+			if err := f.Apply(set); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := set.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		cfg := p2p.Config{MaxPeers: 50}
+		SetP2PConfig(cli.NewContext(nil, set, nil), &cfg)
+		if cfg.MaxPeers != tt.wantTotal {
+			t.Errorf("%v: wrong total peer count %d, want %d", tt.args, cfg.MaxPeers, tt.wantTotal)
+		}
+	}
+}
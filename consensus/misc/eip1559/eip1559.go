@@ -36,7 +36,7 @@ func VerifyEIP1559Header(config ctypes.ChainConfigurator, parent, header *types.
 	// Verify that the gas limit remains within allowed bounds
 	parentGasLimit := parent.GasLimit
 	if !config.IsEnabled(config.GetEIP1559Transition, parent.Number) {
-		parentGasLimit = parent.GasLimit * config.GetElasticityMultiplier()
+		parentGasLimit = parent.GasLimit * ctypes.ElasticityMultiplier(config, header.Number)
 	}
 	if err := misc.VerifyGaslimit(parentGasLimit, header.GasLimit); err != nil {
 		return err
@@ -55,21 +55,34 @@ func VerifyEIP1559Header(config ctypes.ChainConfigurator, parent, header *types.
 }
 
 // CalcBaseFee calculates the basefee of the header.
+//
+// The fee market parameters are those scheduled for the block being built on
+// top of parent, and the result never falls below that block's base fee floor.
 func CalcBaseFee(config ctypes.ChainConfigurator, parent *types.Header) *big.Int {
+	number := new(big.Int).Add(parent.Number, common.Big1)
+	baseFee := calcBaseFee(config, parent, number)
+	if floor := ctypes.MinBaseFee(config, number); floor != nil && baseFee.Cmp(floor) < 0 {
+		return floor
+	}
+	return baseFee
+}
+
+func calcBaseFee(config ctypes.ChainConfigurator, parent *types.Header, number *big.Int) *big.Int {
 	// If the current block is the first EIP-1559 block, return the InitialBaseFee.
 	if !config.IsEnabled(config.GetEIP1559Transition, parent.Number) {
 		return new(big.Int).SetUint64(vars.InitialBaseFee)
 	}
 
-	parentGasTarget := parent.GasLimit / config.GetElasticityMultiplier()
+	parentGasTarget := parent.GasLimit / ctypes.ElasticityMultiplier(config, number)
 	// If the parent gasUsed is the same as the target, the baseFee remains unchanged.
 	if parent.GasUsed == parentGasTarget {
 		return new(big.Int).Set(parent.BaseFee)
 	}
 
 	var (
-		num   = new(big.Int)
-		denom = new(big.Int)
+		num         = new(big.Int)
+		denom       = new(big.Int)
+		denominator = ctypes.BaseFeeChangeDenominator(config, number)
 	)
 
 	if parent.GasUsed > parentGasTarget {
@@ -78,7 +91,7 @@ func CalcBaseFee(config ctypes.ChainConfigurator, parent *types.Header) *big.Int
 		num.SetUint64(parent.GasUsed - parentGasTarget)
 		num.Mul(num, parent.BaseFee)
 		num.Div(num, denom.SetUint64(parentGasTarget))
-		num.Div(num, denom.SetUint64(config.GetBaseFeeChangeDenominator()))
+		num.Div(num, denom.SetUint64(denominator))
 		baseFeeDelta := math.BigMax(num, common.Big1)
 
 		return num.Add(parent.BaseFee, baseFeeDelta)
@@ -88,7 +101,7 @@ func CalcBaseFee(config ctypes.ChainConfigurator, parent *types.Header) *big.Int
 		num.SetUint64(parentGasTarget - parent.GasUsed)
 		num.Mul(num, parent.BaseFee)
 		num.Div(num, denom.SetUint64(parentGasTarget))
-		num.Div(num, denom.SetUint64(config.GetBaseFeeChangeDenominator()))
+		num.Div(num, denom.SetUint64(denominator))
 		baseFee := num.Sub(parent.BaseFee, num)
 
 		return math.BigMax(baseFee, common.Big0)
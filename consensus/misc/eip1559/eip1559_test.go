@@ -23,8 +23,10 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/types/goethereum"
 	"github.com/ethereum/go-ethereum/params/vars"
+	"github.com/holiman/uint256"
 )
 
 // copyConfig does a _shallow_ copy of a given config. Safe to set new values, but
@@ -132,3 +134,58 @@ func TestCalcBaseFee(t *testing.T) {
 		}
 	}
 }
+
+// TestCalcBaseFeeSchedules tests that scheduled fee market parameters and the
+// base fee floor take effect at their activation blocks.
+func TestCalcBaseFeeSchedules(t *testing.T) {
+	cfg := config()
+	cfg.ElasticityMultiplierSchedule = ctypes.Uint64Uint256MapEncodesHex{
+		40: uint256.NewInt(4),
+	}
+	cfg.BaseFeeChangeDenominatorSchedule = ctypes.Uint64Uint256MapEncodesHex{
+		40: uint256.NewInt(16),
+		50: uint256.NewInt(0), // restores the default
+	}
+	cfg.MinBaseFeeSchedule = ctypes.Uint64Uint256MapEncodesHex{
+		60: uint256.NewInt(990000000),
+		70: uint256.NewInt(vars.InitialBaseFee + 1),
+	}
+	tests := []struct {
+		parentNum       int64
+		parentGasLimit  uint64
+		parentGasUsed   uint64
+		expectedBaseFee int64
+	}{
+		{32, 20000000, 9000000, 987500000},               // defaults before any schedule entry
+		{39, 20000000, 5000000, vars.InitialBaseFee},     // usage == target under scheduled elasticity
+		{39, 20000000, 0, 937500000},                     // scheduled denominator
+		{49, 20000000, 0, 875000000},                     // zero entry restores the default
+		{59, 20000000, 0, 990000000},                     // floor raises the decayed fee
+		{59, 20000000, 10000000, 1125000000},             // floor below the computed fee
+		{69, 20000000, 5000000, vars.InitialBaseFee + 1}, // raised floor
+	}
+	for i, test := range tests {
+		parent := &types.Header{
+			Number:   big.NewInt(test.parentNum),
+			GasLimit: test.parentGasLimit,
+			GasUsed:  test.parentGasUsed,
+			BaseFee:  big.NewInt(vars.InitialBaseFee),
+		}
+		have, want := CalcBaseFee(cfg, parent), big.NewInt(test.expectedBaseFee)
+		if have.Cmp(want) != 0 {
+			t.Errorf("test %d: have %d  want %d, ", i, have, want)
+		}
+		header := &types.Header{
+			Number:   big.NewInt(test.parentNum + 1),
+			GasLimit: test.parentGasLimit,
+			BaseFee:  have,
+		}
+		if err := VerifyEIP1559Header(cfg, parent, header); err != nil {
+			t.Errorf("test %d: expected valid header: %v", i, err)
+		}
+		header.BaseFee = new(big.Int).Sub(have, common.Big1)
+		if err := VerifyEIP1559Header(cfg, parent, header); err == nil {
+			t.Errorf("test %d: expected invalid base fee to be rejected", i)
+		}
+	}
+}
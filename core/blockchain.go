@@ -262,8 +262,11 @@ type BlockChain struct {
 	forker     *ForkChoice
 	vmConfig   vm.Config
 
-	artificialFinalityNoDisable     *int32 // manual override prevents disabling artificial finality feature activation
-	artificialFinalityEnabledStatus int32  // toggles artificial finality features; will be always 1 if artificialFinalityForce=1
+	artificialFinalityNoDisable     *int32                            // manual override prevents disabling artificial finality feature activation
+	artificialFinalityEnabledStatus int32                             // toggles artificial finality features; will be always 1 if artificialFinalityForce=1
+	lastFinalityRejection           atomic.Pointer[FinalityRejection] // most recent reorg refused by artificial finality
+
+	sideChain *sideChainTracker // Record of seen non-canonical blocks and reorgs
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -308,6 +311,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, genesis *genesis
 		futureBlocks:  lru.NewCache[common.Hash, *types.Block](maxFutureBlocks),
 		engine:        engine,
 		vmConfig:      vmConfig,
+		sideChain:     newSideChainTracker(),
 	}
 	bc.flushInterval.Store(int64(cacheConfig.TrieTimeLimit))
 	bc.forker = NewForkChoice(bc, shouldPreserve)
@@ -953,6 +957,8 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 
 	bc.currentBlock.Store(block.Header())
 	headBlockGauge.Update(int64(block.NumberU64()))
+
+	bc.sideChain.setCanonical(block)
 }
 
 // stopWithoutSaving stops the blockchain service. If any imports are currently in progress
@@ -1493,6 +1499,7 @@ func (bc *BlockChain) writeBlockAndSetHead(block *types.Block, receipts []*types
 			bc.chainHeadFeed.Send(ChainHeadEvent{Block: block})
 		}
 	} else {
+		bc.sideChain.addSide(block, false)
 		bc.chainSideFeed.Send(ChainSideEvent{Block: block})
 	}
 	return status, nil
@@ -1522,6 +1529,19 @@ func (bc *BlockChain) addFutureBlock(block *types.Block) error {
 // the index number of the failing block as well an error describing what went
 // wrong. After insertion is done, all accumulated events will be fired.
 func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
+	return bc.insertBlocks(chain, true)
+}
+
+// InsertChainWithoutSealVerification works exactly the same as InsertChain,
+// except that the seals of the block headers are not verified. It is used for
+// blocks vouched for by a trusted checkpoint they link up to.
+func (bc *BlockChain) InsertChainWithoutSealVerification(chain types.Blocks) (int, error) {
+	return bc.insertBlocks(chain, false)
+}
+
+// insertBlocks checks that the chain is contiguous and imports it, verifying the
+// seals of its headers if requested.
+func (bc *BlockChain) insertBlocks(chain types.Blocks, verifySeals bool) (int, error) {
 	// Sanity check that we have something meaningful to import
 	if len(chain) == 0 {
 		return 0, nil
@@ -1549,7 +1569,7 @@ func (bc *BlockChain) InsertChain(chain types.Blocks) (int, error) {
 		return 0, errChainStopped
 	}
 	defer bc.chainmu.Unlock()
-	return bc.insertChain(chain, true, true)
+	return bc.insertChain(chain, verifySeals, true)
 }
 
 // insertChain is the internal implementation of InsertChain, which assumes that
@@ -1573,7 +1593,8 @@ func (bc *BlockChain) insertChain(chain types.Blocks, verifySeals, setHead bool)
 		stats = insertStats{
 			startTime: mclock.Now(),
 			artificialFinality: bc.IsArtificialFinalityEnabled() &&
-				bc.chainConfig.IsEnabled(bc.chainConfig.GetECBP1100Transition, bc.CurrentBlock().Number),
+				(bc.chainConfig.IsEnabled(bc.chainConfig.GetECBP1100Transition, bc.CurrentBlock().Number) ||
+					ebpMaxReorgDepthActive(bc.chainConfig, bc.CurrentBlock().Number)),
 		}
 		lastCanon *types.Block
 	)
@@ -1963,6 +1984,7 @@ func (bc *BlockChain) insertSideChain(block *types.Block, it *insertIterator) (i
 			if err := bc.writeBlockWithoutState(block, externTd); err != nil {
 				return it.index, err
 			}
+			bc.sideChain.addSide(block, false)
 			log.Debug("Injected sidechain block", "number", block.Number(), "hash", block.Hash(),
 				"diff", block.Difficulty(), "elapsed", common.PrettyDuration(time.Since(start)),
 				"txs", len(block.Transactions()), "gas", block.GasUsed(), "uncles", len(block.Uncles()),
@@ -2222,6 +2244,7 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 		blockReorgAddMeter.Mark(int64(len(newChain)))
 		blockReorgDropMeter.Mark(int64(len(oldChain)))
 		blockReorgMeter.Mark(1)
+		bc.sideChain.addReorg(commonBlock, len(oldChain), len(newChain))
 	} else if len(newChain) > 0 {
 		// Special case happens in the post merge stage that current head is
 		// the ancestor of new head while these two blocks are not consecutive
@@ -2285,6 +2308,7 @@ func (bc *BlockChain) reorg(oldHead *types.Header, newHead *types.Block) error {
 	var deletedLogs []*types.Log
 	for i := len(oldChain) - 1; i >= 0; i-- {
 		// Also send event for blocks removed from the canon chain.
+		bc.sideChain.addSide(oldChain[i], true)
 		bc.chainSideFeed.Send(ChainSideEvent{Block: oldChain[i]})
 
 		// Collect deleted logs for notification
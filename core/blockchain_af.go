@@ -11,11 +11,64 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
 )
 
 // errReorgFinality represents an error caused by artificial finality mechanisms.
 var errReorgFinality = errors.New("finality-enforced invalid new chain")
 
+var (
+	reorgFinalityRejectMeter     = metrics.NewRegisteredMeter("chain/reorg/finality/rejected", nil)
+	reorgFinalityRejectDepthHist = metrics.NewRegisteredHistogram("chain/reorg/finality/depth", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// FinalityRejection describes the most recent reorg refused by artificial finality.
+type FinalityRejection struct {
+	Time         time.Time   `json:"time"`
+	CommonNumber uint64      `json:"commonNumber"`
+	CommonHash   common.Hash `json:"commonHash"`
+	CurrentHash  common.Hash `json:"currentHash"`
+	ProposedHash common.Hash `json:"proposedHash"`
+	Depth        uint64      `json:"depth"`
+	Reason       string      `json:"reason"`
+}
+
+// FinalityStatus describes the artificial finality rules in effect at the
+// current head.
+type FinalityStatus struct {
+	Enabled bool   `json:"enabled"` // Whether the node currently enforces artificial finality (synced, enough peers)
+	Head    uint64 `json:"head"`
+
+	ECBP1100Active               bool   `json:"ecbp1100Active"`
+	ECBP1100AntigravityXCap      uint64 `json:"ecbp1100AntigravityXCap"`
+	ECBP1100AntigravityAmplitude uint64 `json:"ecbp1100AntigravityAmplitude"`
+
+	MaxReorgDepthActive bool    `json:"maxReorgDepthActive"`
+	MaxReorgDepth       *uint64 `json:"maxReorgDepth,omitempty"`
+	FinalizedNumber     *uint64 `json:"finalizedNumber,omitempty"` // Blocks at or below this number cannot be reorged by the depth rule
+
+	LastRejection *FinalityRejection `json:"lastRejection,omitempty"`
+}
+
+// ReorgPenalty describes the requirements a competing chain forking off at a
+// given canonical block would have to satisfy to be accepted.
+type ReorgPenalty struct {
+	CommonNumber    uint64      `json:"commonNumber"`
+	CommonHash      common.Hash `json:"commonHash"`
+	Depth           uint64      `json:"depth"`
+	TimeSpan        uint64      `json:"timeSpan"` // Seconds between the common ancestor and the current head
+	LocalSubchainTD *big.Int    `json:"localSubchainTD"`
+
+	// Antigravity is the multiple of the local subchain TD the proposed subchain
+	// must exceed under ECBP1100, or 1 if ECBP1100 is not active.
+	Antigravity float64  `json:"antigravity"`
+	RequiredTD  *big.Int `json:"requiredSubchainTD"`
+
+	// DepthRejected is set if the max-reorg-depth rule refuses the fork outright.
+	DepthRejected bool `json:"depthRejected"`
+}
+
 // ArtificialFinalityNoDisable overrides toggling of AF features, forcing it on.
 // n  = 1 : ON
 // n != 1 : OFF
@@ -90,6 +143,109 @@ func (bc *BlockChain) IsArtificialFinalityEnabled() bool {
 	return atomic.LoadInt32(&bc.artificialFinalityEnabledStatus) == 1
 }
 
+// FinalityStatus reports the artificial finality rules in effect at the current head.
+func (bc *BlockChain) FinalityStatus() *FinalityStatus {
+	head := bc.CurrentHeader()
+	xcap, ampl := ecbp1100Antigravity(bc.chainConfig)
+	status := &FinalityStatus{
+		Enabled:                      bc.IsArtificialFinalityEnabled(),
+		Head:                         head.Number.Uint64(),
+		ECBP1100Active:               bc.chainConfig.IsEnabled(bc.chainConfig.GetECBP1100Transition, head.Number),
+		ECBP1100AntigravityXCap:      xcap.Uint64(),
+		ECBP1100AntigravityAmplitude: ampl.Uint64(),
+		MaxReorgDepthActive:          ebpMaxReorgDepthActive(bc.chainConfig, head.Number),
+		MaxReorgDepth:                bc.chainConfig.GetEBPMaxReorgDepth(),
+		LastRejection:                bc.lastFinalityRejection.Load(),
+	}
+	if status.MaxReorgDepthActive && status.Head > *status.MaxReorgDepth {
+		finalized := status.Head - *status.MaxReorgDepth
+		status.FinalizedNumber = &finalized
+	}
+	return status
+}
+
+// ReorgPenalty computes the penalty a hypothetical competing chain forking off
+// the given canonical block would face against the current head.
+func (bc *BlockChain) ReorgPenalty(commonAncestor *types.Header) *ReorgPenalty {
+	current := bc.CurrentHeader()
+	localTD := bc.GetTd(current.Hash(), current.Number.Uint64())
+	commonTD := bc.GetTd(commonAncestor.Hash(), commonAncestor.Number.Uint64())
+
+	penalty := &ReorgPenalty{
+		CommonNumber:    commonAncestor.Number.Uint64(),
+		CommonHash:      commonAncestor.Hash(),
+		Depth:           current.Number.Uint64() - commonAncestor.Number.Uint64(),
+		TimeSpan:        current.Time - commonAncestor.Time,
+		LocalSubchainTD: new(big.Int).Sub(localTD, commonTD),
+		Antigravity:     1,
+	}
+	penalty.RequiredTD = new(big.Int).Set(penalty.LocalSubchainTD)
+	if bc.chainConfig.IsEnabled(bc.chainConfig.GetECBP1100Transition, current.Number) {
+		xcap, ampl := ecbp1100Antigravity(bc.chainConfig)
+		numerator := ecbp1100PolynomialVWith(new(big.Int).SetUint64(penalty.TimeSpan), xcap, ampl)
+		penalty.Antigravity, _ = new(big.Float).Quo(
+			new(big.Float).SetInt(numerator),
+			new(big.Float).SetInt(ecbp1100PolynomialVCurveFunctionDenominator),
+		).Float64()
+		penalty.RequiredTD.Mul(penalty.RequiredTD, numerator)
+		penalty.RequiredTD.Div(penalty.RequiredTD, ecbp1100PolynomialVCurveFunctionDenominator)
+	}
+	if ebpMaxReorgDepthActive(bc.chainConfig, current.Number) {
+		penalty.DepthRejected = penalty.Depth > *bc.chainConfig.GetEBPMaxReorgDepth()
+	}
+	return penalty
+}
+
+// recordFinalityRejection logs and accounts a reorg refused by artificial finality.
+func (bc *BlockChain) recordFinalityRejection(commonAncestor, current, proposed *types.Header, err error) {
+	depth := current.Number.Uint64() - commonAncestor.Number.Uint64()
+	reorgFinalityRejectMeter.Mark(1)
+	reorgFinalityRejectDepthHist.Update(int64(depth))
+	bc.lastFinalityRejection.Store(&FinalityRejection{
+		Time:         time.Now(),
+		CommonNumber: commonAncestor.Number.Uint64(),
+		CommonHash:   commonAncestor.Hash(),
+		CurrentHash:  current.Hash(),
+		ProposedHash: proposed.Hash(),
+		Depth:        depth,
+		Reason:       err.Error(),
+	})
+}
+
+// ebpMaxReorgDepthActive returns whether the max-reorg-depth rule is configured
+// and activated at block n.
+func ebpMaxReorgDepthActive(conf ctypes.ChainConfigurator, n *big.Int) bool {
+	return conf.GetEBPMaxReorgDepth() != nil && conf.IsEnabled(conf.GetEBPMaxReorgDepthTransition, n)
+}
+
+// ebpMaxReorgDepth implements the max-reorg-depth artificial finality rule,
+// refusing any reorg which would drop more than maxDepth canonical blocks.
+func ebpMaxReorgDepth(commonAncestor, current, proposed *types.Header, maxDepth uint64) error {
+	if depth := current.Number.Uint64() - commonAncestor.Number.Uint64(); depth > maxDepth {
+		return fmt.Errorf(`%w: max-reorg-depth 🔒 status=rejected depth=%d max=%d common.bno=%d common.hash=%s current.bno=%d current.hash=%s proposed.bno=%d proposed.hash=%s`,
+			errReorgFinality, depth, maxDepth,
+			commonAncestor.Number.Uint64(), commonAncestor.Hash().Hex(),
+			current.Number.Uint64(), current.Hash().Hex(),
+			proposed.Number.Uint64(), proposed.Hash().Hex(),
+		)
+	}
+	return nil
+}
+
+// ecbp1100Antigravity returns the antigravity curve parameters (xcap, amplitude)
+// configured for the chain, falling back to the ECIP-1100 defaults. A zero xcap
+// would divide by zero in the curve, so it falls back too.
+func ecbp1100Antigravity(conf ctypes.ChainConfigurator) (xcap, ampl *big.Int) {
+	xcap, ampl = ecbp1100PolynomialVXCap, ecbp1100PolynomialVAmpl
+	if n := conf.GetECBP1100AntigravityXCap(); n != nil && *n > 0 {
+		xcap = new(big.Int).SetUint64(*n)
+	}
+	if n := conf.GetECBP1100AntigravityAmplitude(); n != nil {
+		ampl = new(big.Int).SetUint64(*n)
+	}
+	return xcap, ampl
+}
+
 // getTDRatio is a helper function returning the total difficulty ratio of
 // proposed over current chain segments.
 // nolint:unused
@@ -112,7 +268,8 @@ func (bc *BlockChain) getTDRatio(commonAncestor, current, proposed *types.Header
 // ecbp1100 implements the "MESS" artificial finality mechanism
 // "Modified Exponential Subjective Scoring" used to prefer known chain segments
 // over later-to-come counterparts, especially proposed segments stretching far into the past.
-func ecbp1100(commonAncestor, current, proposed *types.Header, getTDFunc func(common.Hash, uint64) *big.Int) error {
+// The xcap and ampl arguments parameterize the antigravity curve, see ecbp1100PolynomialVWith.
+func ecbp1100(commonAncestor, current, proposed *types.Header, getTDFunc func(common.Hash, uint64) *big.Int, xcap, ampl *big.Int) error {
 	// Get the total difficulties of the proposed chain segment and the existing one.
 	commonAncestorTD := getTDFunc(commonAncestor.Hash(), commonAncestor.Number.Uint64())
 	proposedParentTD := getTDFunc(proposed.ParentHash, proposed.Number.Uint64()-1)
@@ -124,7 +281,7 @@ func ecbp1100(commonAncestor, current, proposed *types.Header, getTDFunc func(co
 	localSubchainTD := new(big.Int).Sub(localTD, commonAncestorTD)
 
 	xBig := big.NewInt(int64(current.Time - commonAncestor.Time))
-	eq := ecbp1100PolynomialV(xBig)
+	eq := ecbp1100PolynomialVWith(xBig, xcap, ampl)
 	want := eq.Mul(eq, localSubchainTD)
 
 	got := new(big.Int).Mul(proposedSubchainTD, ecbp1100PolynomialVCurveFunctionDenominator)
@@ -171,18 +328,24 @@ if proposed_subchain_td * CURVE_FUNCTION_DENOMINATOR < get_curve_function_numera
 */
 // nolint:goimports
 func ecbp1100PolynomialV(x *big.Int) *big.Int {
+	return ecbp1100PolynomialVWith(x, ecbp1100PolynomialVXCap, ecbp1100PolynomialVAmpl)
+}
+
+// ecbp1100PolynomialVWith is ecbp1100PolynomialV with a configurable xcap and
+// amplitude, allowing networks to tune the antigravity curve.
+func ecbp1100PolynomialVWith(x, xcap, ampl *big.Int) *big.Int {
 	// Make a copy; do not mutate argument value.
 
 	// if x > xcap:
 	//    x = xcap
 	xA := new(big.Int).Set(x)
-	if xA.Cmp(ecbp1100PolynomialVXCap) > 0 {
-		xA.Set(ecbp1100PolynomialVXCap)
+	if xA.Cmp(xcap) > 0 {
+		xA.Set(xcap)
 	}
 
 	xB := new(big.Int).Set(x)
-	if xB.Cmp(ecbp1100PolynomialVXCap) > 0 {
-		xB.Set(ecbp1100PolynomialVXCap)
+	if xB.Cmp(xcap) > 0 {
+		xB.Set(xcap)
 	}
 
 	out := big.NewInt(0)
@@ -194,16 +357,19 @@ func ecbp1100PolynomialV(x *big.Int) *big.Int {
 	// 3 * x**2 // xcap
 	xB.Exp(xB, big3, nil)
 	xB.Mul(xB, big2)
-	xB.Div(xB, ecbp1100PolynomialVXCap)
+	xB.Div(xB, xcap)
 
 	// (3 * x**2 - 2 * x**3 // xcap)
 	out.Sub(xA, xB)
 
+	// height = CURVE_FUNCTION_DENOMINATOR * (ampl * 2)
+	height := new(big.Int).Mul(new(big.Int).Mul(ecbp1100PolynomialVCurveFunctionDenominator, ampl), big2)
+
 	// // (3 * x**2 - 2 * x**3 // xcap) * height
-	out.Mul(out, ecbp1100PolynomialVHeight)
+	out.Mul(out, height)
 
 	// xcap ** 2
-	xcap2 := new(big.Int).Exp(ecbp1100PolynomialVXCap, big2, nil)
+	xcap2 := new(big.Int).Exp(xcap, big2, nil)
 
 	// (3 * x**2 - 2 * x**3 // xcap) * height // xcap ** 2
 	out.Div(out, xcap2)
@@ -228,10 +394,6 @@ var ecbp1100PolynomialVXCap = big.NewInt(25132)
 // ampl = 15
 var ecbp1100PolynomialVAmpl = big.NewInt(15)
 
-// ecbp1100PolynomialVHeight
-// height = CURVE_FUNCTION_DENOMINATOR * (ampl * 2)
-var ecbp1100PolynomialVHeight = new(big.Int).Mul(new(big.Int).Mul(ecbp1100PolynomialVCurveFunctionDenominator, ecbp1100PolynomialVAmpl), big2)
-
 /*
 ecbp1100AGSinusoidalA is a sinusoidal function.
 
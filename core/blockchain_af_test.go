@@ -16,6 +16,9 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
 	"github.com/ethereum/go-ethereum/triedb"
 	"gonum.org/v1/plot"
 	"gonum.org/v1/plot/plotter"
@@ -1077,3 +1080,95 @@ got.tdr=%v got.pen=%v`,
 		}
 	}
 }
+
+func TestBlockChain_AF_MaxReorgDepth(t *testing.T) {
+	config, err := confp.CloneChainConfigurator(params.TestChainConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero, maxDepth := uint64(0), uint64(2)
+	config.SetEBPMaxReorgDepthTransition(&zero)
+	config.SetEBPMaxReorgDepth(&maxDepth)
+
+	engine := ethash.NewFaker()
+	gspec := &genesisT.Genesis{Config: config, BaseFee: big.NewInt(vars.InitialBaseFee)}
+	genDb, canon, _ := GenerateChainWithGenesis(gspec, engine, 5, nil)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer chain.Stop()
+	chain.EnableArtificialFinality(true)
+
+	if _, err := chain.InsertChain(canon); err != nil {
+		t.Fatal(err)
+	}
+	if penalty := chain.ReorgPenalty(canon[1].Header()); !penalty.DepthRejected || penalty.Depth != 3 {
+		t.Errorf("unexpected penalty for depth 3 fork: %+v", penalty)
+	}
+	if penalty := chain.ReorgPenalty(canon[2].Header()); penalty.DepthRejected || penalty.Depth != 2 {
+		t.Errorf("unexpected penalty for depth 2 fork: %+v", penalty)
+	}
+
+	// A heavier fork dropping 3 blocks must be refused.
+	deep, _ := GenerateChain(config, canon[1], engine, genDb, 6, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x01})
+	})
+	if _, err := chain.InsertChain(deep); err != nil {
+		t.Fatal(err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != canon[len(canon)-1].Hash() {
+		t.Fatalf("deep reorg was not refused")
+	}
+	status := chain.FinalityStatus()
+	if status.LastRejection == nil || status.LastRejection.Depth != 3 {
+		t.Fatalf("rejection not recorded: %+v", status.LastRejection)
+	}
+	if status.FinalizedNumber == nil || *status.FinalizedNumber != 3 {
+		t.Errorf("finalized number mismatch: have %v, want %d", status.FinalizedNumber, 3)
+	}
+
+	// A heavier fork dropping 2 blocks is allowed.
+	shallow, _ := GenerateChain(config, canon[2], engine, genDb, 6, func(i int, b *BlockGen) {
+		b.SetCoinbase(common.Address{0x02})
+	})
+	if _, err := chain.InsertChain(shallow); err != nil {
+		t.Fatal(err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != shallow[len(shallow)-1].Hash() {
+		t.Fatalf("shallow reorg was refused")
+	}
+}
+
+func TestEcbp1100PolynomialVWith(t *testing.T) {
+	for _, x := range []int64{0, 100, 1000, 25132, 30000} {
+		n := big.NewInt(x)
+		if have, want := ecbp1100PolynomialVWith(n, ecbp1100PolynomialVXCap, ecbp1100PolynomialVAmpl), ecbp1100PolynomialV(n); have.Cmp(want) != 0 {
+			t.Errorf("x=%d: default parameters mismatch: have %v, want %v", x, have, want)
+		}
+	}
+	// At and beyond the cap the curve yields 1 + 2*ampl times the denominator.
+	xcap, ampl := big.NewInt(3600), big.NewInt(3)
+	for _, x := range []int64{3600, 100000} {
+		if have, want := ecbp1100PolynomialVWith(big.NewInt(x), xcap, ampl), big.NewInt(128*7); have.Cmp(want) != 0 {
+			t.Errorf("x=%d: ceiling mismatch: have %v, want %v", x, have, want)
+		}
+	}
+}
+
+func TestEcbp1100AntigravityZeroXCap(t *testing.T) {
+	config := *params.MessNetConfig
+	zero, ampl := uint64(0), uint64(3)
+	config.ECBP1100AntigravityXCap, config.ECBP1100AntigravityAmplitude = &zero, &ampl
+
+	xcap, amplitude := ecbp1100Antigravity(&config)
+	if xcap.Cmp(ecbp1100PolynomialVXCap) != 0 {
+		t.Errorf("zero xcap not defaulted: have %v, want %v", xcap, ecbp1100PolynomialVXCap)
+	}
+	if amplitude.Uint64() != ampl {
+		t.Errorf("amplitude mismatch: have %v, want %v", amplitude, ampl)
+	}
+	// The curve is computable with the parameters in effect.
+	ecbp1100PolynomialVWith(big.NewInt(1000), xcap, amplitude)
+}
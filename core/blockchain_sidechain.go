@@ -0,0 +1,212 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+const (
+	// sideChainTrackerLimit is the maximum number of non-canonical blocks the
+	// tracker remembers. Oldest (lowest numbered) entries are evicted first.
+	sideChainTrackerLimit = 8192
+
+	// sideChainReorgLimit is the maximum number of reorg events the tracker
+	// remembers.
+	sideChainReorgLimit = 1024
+)
+
+var (
+	sideBlockMeter      = metrics.NewRegisteredMeter("chain/side/blocks", nil)
+	sideBlockUncleMeter = metrics.NewRegisteredMeter("chain/side/uncles", nil)
+	sideBlockReorgMeter = metrics.NewRegisteredMeter("chain/side/reorged", nil)
+	reorgDepthHistogram = metrics.NewRegisteredHistogram("chain/reorg/depth", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// SideBlock is a record of a block the node has seen but which is not (or no
+// longer) part of the canonical chain.
+type SideBlock struct {
+	Hash       common.Hash    `json:"hash"`
+	Number     uint64         `json:"number"`
+	ParentHash common.Hash    `json:"parentHash"`
+	Miner      common.Address `json:"miner"`
+	Difficulty *big.Int       `json:"difficulty"`
+	Arrival    time.Time      `json:"arrival"`
+
+	// Reorged is set if the block was canonical at some point and was
+	// removed from the canonical chain by a reorg.
+	Reorged bool `json:"reorged"`
+
+	// UncleOf is the hash of the canonical block which included this block as
+	// an uncle, if any.
+	UncleOf *common.Hash `json:"uncleOf,omitempty"`
+}
+
+// ReorgEvent is a record of a single chain reorganisation.
+type ReorgEvent struct {
+	Time         time.Time   `json:"time"`
+	CommonNumber uint64      `json:"commonNumber"`
+	CommonHash   common.Hash `json:"commonHash"`
+	Dropped      int         `json:"dropped"`
+	Added        int         `json:"added"`
+}
+
+// sideChainTracker keeps a bounded, in-memory record of non-canonical blocks
+// and reorgs seen by the blockchain, to be able to report orphan and uncle
+// rates. It is safe for concurrent use.
+type sideChainTracker struct {
+	blocks map[common.Hash]*SideBlock
+	reorgs []ReorgEvent
+	lock   sync.RWMutex
+}
+
+func newSideChainTracker() *sideChainTracker {
+	return &sideChainTracker{
+		blocks: make(map[common.Hash]*SideBlock),
+	}
+}
+
+// addSide records a non-canonical block. Recording an already known block only
+// updates its reorged flag.
+func (t *sideChainTracker) addSide(block *types.Block, reorged bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if known, ok := t.blocks[block.Hash()]; ok {
+		known.Reorged = known.Reorged || reorged
+		return
+	}
+	t.blocks[block.Hash()] = &SideBlock{
+		Hash:       block.Hash(),
+		Number:     block.NumberU64(),
+		ParentHash: block.ParentHash(),
+		Miner:      block.Coinbase(),
+		Difficulty: new(big.Int).Set(block.Difficulty()),
+		Arrival:    time.Now(),
+		Reorged:    reorged,
+	}
+	sideBlockMeter.Mark(1)
+	if reorged {
+		sideBlockReorgMeter.Mark(1)
+	}
+	if len(t.blocks) > sideChainTrackerLimit {
+		t.evict()
+	}
+}
+
+// evict drops the lowest numbered entries until the tracker is back under its
+// limit. The caller must hold the write lock.
+func (t *sideChainTracker) evict() {
+	records := make([]*SideBlock, 0, len(t.blocks))
+	for _, b := range t.blocks {
+		records = append(records, b)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Number < records[j].Number })
+	for _, b := range records[:len(records)-sideChainTrackerLimit] {
+		delete(t.blocks, b.Hash)
+	}
+}
+
+// setCanonical is invoked for every block becoming the canonical head. The
+// block itself is forgotten if known, and its uncles are marked as included.
+func (t *sideChainTracker) setCanonical(block *types.Block) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.blocks, block.Hash())
+	for _, uncle := range block.Uncles() {
+		if known, ok := t.blocks[uncle.Hash()]; ok && known.UncleOf == nil {
+			hash := block.Hash()
+			known.UncleOf = &hash
+			sideBlockUncleMeter.Mark(1)
+		}
+	}
+}
+
+// addReorg records a reorg which dropped and added the given number of blocks
+// on top of the common ancestor.
+func (t *sideChainTracker) addReorg(common *types.Block, dropped, added int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.reorgs = append(t.reorgs, ReorgEvent{
+		Time:         time.Now(),
+		CommonNumber: common.NumberU64(),
+		CommonHash:   common.Hash(),
+		Dropped:      dropped,
+		Added:        added,
+	})
+	if len(t.reorgs) > sideChainReorgLimit {
+		t.reorgs = t.reorgs[len(t.reorgs)-sideChainReorgLimit:]
+	}
+	reorgDepthHistogram.Update(int64(dropped))
+}
+
+// sideBlocks returns copies of the tracked side blocks within [from, to],
+// sorted by number.
+func (t *sideChainTracker) sideBlocks(from, to uint64) []SideBlock {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	var blocks []SideBlock
+	for _, b := range t.blocks {
+		if b.Number >= from && b.Number <= to {
+			blocks = append(blocks, *b)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Number != blocks[j].Number {
+			return blocks[i].Number < blocks[j].Number
+		}
+		return blocks[i].Arrival.Before(blocks[j].Arrival)
+	})
+	return blocks
+}
+
+// reorgEvents returns the reorgs whose first replaced block falls within
+// [from, to].
+func (t *sideChainTracker) reorgEvents(from, to uint64) []ReorgEvent {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	var events []ReorgEvent
+	for _, ev := range t.reorgs {
+		if n := ev.CommonNumber + 1; n >= from && n <= to {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+// SideBlocks returns the non-canonical blocks seen by this node since it was
+// started, whose numbers are within [from, to].
+func (bc *BlockChain) SideBlocks(from, to uint64) []SideBlock {
+	return bc.sideChain.sideBlocks(from, to)
+}
+
+// Reorgs returns the reorganisations performed by this node since it was
+// started, whose first replaced block is within [from, to].
+func (bc *BlockChain) Reorgs(from, to uint64) []ReorgEvent {
+	return bc.sideChain.reorgEvents(from, to)
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// Tests that side blocks and reorged canonical blocks are recorded by the side
+// chain tracker.
+func TestSideChainTracker(t *testing.T) {
+	var (
+		engine    = ethash.NewFaker()
+		sideMiner = common.HexToAddress("0x5151")
+		gspec     = &genesisT.Genesis{
+			Config:  params.TestChainConfig,
+			BaseFee: big.NewInt(vars.InitialBaseFee),
+		}
+	)
+	genDb, canon, _ := GenerateChainWithGenesis(gspec, engine, 4, nil)
+
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(canon); err != nil {
+		t.Fatalf("failed to insert canonical chain: %v", err)
+	}
+	// Import a competing, lighter block at height 3.
+	side, _ := GenerateChain(gspec.Config, canon[1], engine, genDb, 1, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(sideMiner)
+	})
+	if _, err := chain.InsertChain(side); err != nil {
+		t.Fatalf("failed to insert side block: %v", err)
+	}
+	blocks := chain.SideBlocks(0, 10)
+	if len(blocks) != 1 {
+		t.Fatalf("side block count mismatch: have %d, want %d", len(blocks), 1)
+	}
+	if have := blocks[0]; have.Hash != side[0].Hash() || have.Miner != sideMiner || have.Reorged || have.UncleOf != nil {
+		t.Fatalf("side block record mismatch: %+v", have)
+	}
+	// Reorg the chain from block 1 with a longer fork, dropping blocks 2-4.
+	fork, _ := GenerateChain(gspec.Config, canon[0], engine, genDb, 6, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(common.Address{0x01})
+	})
+	if _, err := chain.InsertChain(fork); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	if head := chain.CurrentBlock().Hash(); head != fork[len(fork)-1].Hash() {
+		t.Fatalf("fork did not become canonical")
+	}
+	var reorged int
+	for _, b := range chain.SideBlocks(0, 10) {
+		if b.Reorged {
+			reorged++
+		}
+	}
+	if reorged != 3 {
+		t.Fatalf("reorged block count mismatch: have %d, want %d", reorged, 3)
+	}
+	reorgs := chain.Reorgs(0, 10)
+	if len(reorgs) != 1 {
+		t.Fatalf("reorg count mismatch: have %d, want %d", len(reorgs), 1)
+	}
+	if reorgs[0].CommonNumber != 1 || reorgs[0].Dropped != 3 || reorgs[0].Added < 3 {
+		t.Fatalf("reorg record mismatch: %+v", reorgs[0])
+	}
+}
+
+// Tests that tracked side blocks are marked once included as uncles, and are
+// forgotten once they become canonical.
+func TestSideChainTrackerUncles(t *testing.T) {
+	tracker := newSideChainTracker()
+
+	uncle := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3), Difficulty: big.NewInt(1), Extra: []byte("uncle")})
+	other := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3), Difficulty: big.NewInt(1), Extra: []byte("other")})
+	tracker.addSide(uncle, false)
+	tracker.addSide(other, false)
+
+	nephew := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(5), Difficulty: big.NewInt(1)}).WithBody(nil, []*types.Header{uncle.Header()})
+	tracker.setCanonical(nephew)
+
+	blocks := tracker.sideBlocks(0, 10)
+	if len(blocks) != 2 {
+		t.Fatalf("side block count mismatch: have %d, want %d", len(blocks), 2)
+	}
+	for _, b := range blocks {
+		switch b.Hash {
+		case uncle.Hash():
+			if b.UncleOf == nil || *b.UncleOf != nephew.Hash() {
+				t.Errorf("uncle inclusion not recorded: %+v", b)
+			}
+		case other.Hash():
+			if b.UncleOf != nil {
+				t.Errorf("unexpected uncle inclusion: %+v", b)
+			}
+		}
+	}
+	tracker.setCanonical(other)
+	if blocks := tracker.sideBlocks(0, 10); len(blocks) != 1 || blocks[0].Hash != uncle.Hash() {
+		t.Fatalf("canonical block not forgotten: %+v", blocks)
+	}
+}
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
@@ -39,6 +40,7 @@ import (
 	"github.com/ethereum/go-ethereum/eth/tracers/logger"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/types/genesisT"
 	"github.com/ethereum/go-ethereum/params/types/goethereum"
@@ -4393,3 +4395,225 @@ func TestEIP3651(t *testing.T) {
 		t.Fatalf("sender balance incorrect: expected %d, got %d", expected, actual)
 	}
 }
+
+// TestEIP7702 deploys two delegation designations and calls them on a
+// proof-of-work chain. It writes one value to storage which is verified after.
+func TestEIP7702(t *testing.T) {
+	var (
+		aa     = common.HexToAddress("0x000000000000000000000000000000000000aaaa")
+		bb     = common.HexToAddress("0x000000000000000000000000000000000000bbbb")
+		engine = ethash.NewFaker()
+
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		funds   = new(big.Int).Mul(common.Big1, big.NewInt(vars.Ether))
+		config  = *params.AllEthashProtocolChanges
+	)
+	config.SetCodeBlock = common.Big0
+
+	// The address 0xAAAA calls into addr2, then stores 0x42 at slot 0x42 and
+	// the code size of its own address at slot 0x01.
+	aaCode := []byte{
+		byte(vm.PUSH1), 0, // out size
+		byte(vm.PUSH1), 0, // out offset
+		byte(vm.PUSH1), 0, // in size
+		byte(vm.PUSH1), 0, // in offset
+		byte(vm.PUSH1), 0, // value
+		byte(vm.PUSH20),
+	}
+	aaCode = append(aaCode, addr2.Bytes()...)
+	aaCode = append(aaCode,
+		byte(vm.GAS),
+		byte(vm.CALL),
+		byte(vm.POP),
+		byte(vm.PUSH1), 0x42,
+		byte(vm.PUSH1), 0x42,
+		byte(vm.SSTORE),
+		byte(vm.ADDRESS),
+		byte(vm.EXTCODESIZE),
+		byte(vm.PUSH1), 0x01,
+		byte(vm.SSTORE),
+	)
+	// The address 0xBBBB stores 0x69 at slot 0x69.
+	bbCode := []byte{
+		byte(vm.PUSH1), 0x69,
+		byte(vm.PUSH1), 0x69,
+		byte(vm.SSTORE),
+	}
+	gspec := &genesisT.Genesis{
+		Config: &config,
+		Alloc: genesisT.GenesisAlloc{
+			addr1: {Balance: funds},
+			addr2: {Balance: funds},
+			aa:    {Code: aaCode, Balance: big.NewInt(0)},
+			bb:    {Code: bbCode, Balance: big.NewInt(0)},
+		},
+	}
+	// Sign authorizations: the sender's own authorization must account for the
+	// nonce bump of the transaction itself.
+	chainID := uint256.MustFromBig(gspec.Config.GetChainID())
+	auth1, _ := types.SignSetCode(key1, types.SetCodeAuthorization{
+		ChainID: *chainID,
+		Address: aa,
+		Nonce:   1,
+	})
+	auth2, _ := types.SignSetCode(key2, types.SetCodeAuthorization{
+		ChainID: *chainID,
+		Address: bb,
+		Nonce:   0,
+	})
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 1, func(i int, b *BlockGen) {
+		b.SetCoinbase(aa)
+		txdata := &types.SetCodeTx{
+			ChainID:   chainID,
+			Nonce:     0,
+			To:        addr1,
+			Gas:       500000,
+			GasFeeCap: uint256.MustFromBig(newGwei(5)),
+			GasTipCap: uint256.NewInt(2),
+			AuthList:  []types.SetCodeAuthorization{auth1, auth2},
+		}
+		tx := types.MustSignNewTx(key1, types.LatestSigner(gspec.Config), txdata)
+		b.AddTx(tx)
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	receipts := chain.GetReceiptsByHash(blocks[0].Hash())
+	if len(receipts) != 1 || receipts[0].Status != types.ReceiptStatusSuccessful || receipts[0].Type != types.SetCodeTxType {
+		t.Fatalf("unexpected receipts: %v", receipts)
+	}
+	// Verify delegation designations were deployed.
+	state, _ := chain.State()
+	code, want := state.GetCode(addr1), types.AddressToDelegation(aa)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("addr1 code incorrect: got %s, want %s", common.Bytes2Hex(code), common.Bytes2Hex(want))
+	}
+	code, want = state.GetCode(addr2), types.AddressToDelegation(bb)
+	if !bytes.Equal(code, want) {
+		t.Fatalf("addr2 code incorrect: got %s, want %s", common.Bytes2Hex(code), common.Bytes2Hex(want))
+	}
+	if nonce := state.GetNonce(addr1); nonce != 2 {
+		t.Fatalf("addr1 nonce incorrect: got %d, want %d", nonce, 2)
+	}
+	// Verify delegation executed the correct code.
+	var (
+		fortyTwo = common.BytesToHash([]byte{0x42})
+		actual   = state.GetState(addr1, fortyTwo)
+	)
+	if actual.Cmp(fortyTwo) != 0 {
+		t.Fatalf("addr1 storage wrong: expected %d, got %d", fortyTwo, actual)
+	}
+	sixtyNine := common.BytesToHash([]byte{0x69})
+	if actual = state.GetState(addr2, sixtyNine); actual.Cmp(sixtyNine) != 0 {
+		t.Fatalf("addr2 storage wrong: expected %d, got %d", sixtyNine, actual)
+	}
+	// EXTCODESIZE reads the delegation designator itself, not the target code.
+	if size := state.GetState(addr1, common.BytesToHash([]byte{0x01})); size.Big().Uint64() != uint64(len(types.AddressToDelegation(aa))) {
+		t.Fatalf("addr1 code size wrong: expected %d, got %d", len(types.AddressToDelegation(aa)), size.Big())
+	}
+}
+
+// TestEIP2935 tests that parent block hashes are written to the history storage
+// contract starting at the EIP-2935 transition block, and that contracts can read
+// them back through the contract's query interface.
+func TestEIP2935(t *testing.T) {
+	var (
+		cc     = common.HexToAddress("0x000000000000000000000000000000000000cccc")
+		engine = ethash.NewFaker()
+
+		key, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		funds  = new(big.Int).Mul(common.Big1, big.NewInt(vars.Ether))
+		fork   = uint64(3)
+		config = &coregeth.CoreGethChainConfig{
+			NetworkID:     1,
+			ChainID:       big.NewInt(1),
+			Ethash:        new(ctypes.EthashConfig),
+			EIP150Block:   big.NewInt(0),
+			EIP155Block:   big.NewInt(0),
+			EIP214FBlock:  big.NewInt(0),
+			EIP3855FBlock: big.NewInt(0),
+			EIP2935FBlock: new(big.Int).SetUint64(fork),
+		}
+	)
+	// The address 0xCCCC queries the history contract for the hash of the
+	// grandparent block and stores the result at slot 0.
+	ccCode := []byte{
+		byte(vm.PUSH1), 2,
+		byte(vm.NUMBER),
+		byte(vm.SUB),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32, // out size
+		byte(vm.PUSH1), 0, // out offset
+		byte(vm.PUSH1), 32, // in size
+		byte(vm.PUSH1), 0, // in offset
+		byte(vm.PUSH20),
+	}
+	ccCode = append(ccCode, vars.HistoryStorageAddress.Bytes()...)
+	ccCode = append(ccCode,
+		byte(vm.GAS),
+		byte(vm.STATICCALL),
+		byte(vm.POP),
+		byte(vm.PUSH1), 0,
+		byte(vm.MLOAD),
+		byte(vm.PUSH1), 0,
+		byte(vm.SSTORE),
+	)
+	gspec := &genesisT.Genesis{
+		Config: config,
+		Alloc: genesisT.GenesisAlloc{
+			addr: {Balance: funds},
+			cc:   {Code: ccCode, Balance: big.NewInt(0)},
+		},
+	}
+	params.PredeployHistoryStorage(gspec.Alloc)
+
+	_, blocks, _ := GenerateChainWithGenesis(gspec, engine, 6, func(i int, b *BlockGen) {
+		if i == 5 {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(addr), cc, common.Big0, 100000, b.header.BaseFee, nil), types.HomesteadSigner{}, key)
+			b.AddTx(tx)
+		}
+	})
+	chain, err := NewBlockChain(rawdb.NewMemoryDatabase(), nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("block %d: failed to insert into chain: %v", n, err)
+	}
+	for _, block := range blocks {
+		var (
+			number = block.NumberU64()
+			slot   = common.BigToHash(new(big.Int).SetUint64((number - 1) % vars.HistoryServeWindow))
+		)
+		state, err := chain.StateAt(block.Root())
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve state: %v", number, err)
+		}
+		have := state.GetState(vars.HistoryStorageAddress, slot)
+		if number < fork {
+			if have != (common.Hash{}) {
+				t.Errorf("block %d: parent hash stored before activation: %x", number, have)
+			}
+			continue
+		}
+		if want := block.ParentHash(); have != want {
+			t.Errorf("block %d: parent hash mismatch: have %x, want %x", number, have, want)
+		}
+	}
+	// The last block queried the hash of block 4 through the contract interface.
+	state, _ := chain.State()
+	if have, want := state.GetState(cc, common.Hash{}), blocks[3].Hash(); have != want {
+		t.Fatalf("queried hash mismatch: have %x, want %x", have, want)
+	}
+}
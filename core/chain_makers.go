@@ -236,7 +236,7 @@ func (b *BlockGen) AddUncle(h *types.Header) {
 	if b.cm.config.IsEnabled(b.cm.config.GetEIP1559Transition, h.Number) {
 		h.BaseFee = eip1559.CalcBaseFee(b.cm.config, parent)
 		if !b.cm.config.IsEnabled(b.cm.config.GetEIP1559Transition, parent.Number) {
-			parentGasLimit := parent.GasLimit * b.cm.config.GetElasticityMultiplier()
+			parentGasLimit := parent.GasLimit * ctypes.ElasticityMultiplier(b.cm.config, h.Number)
 			h.GasLimit = CalcGasLimit(parentGasLimit, parentGasLimit)
 		}
 	}
@@ -344,6 +344,11 @@ func GenerateChain(config ctypes.ChainConfigurator, parent *types.Block, engine
 		if generic.AsGenericCC(config).DAOSupport() && config.GetEthashEIP779Transition() != nil && *config.GetEthashEIP779Transition() == b.header.Number.Uint64() {
 			mutations.ApplyDAOHardFork(statedb)
 		}
+		if config.IsEnabled(config.GetEIP2935Transition, b.header.Number) {
+			blockContext := NewEVMBlockContext(b.header, cm, &b.header.Coinbase)
+			vmenv := vm.NewEVM(blockContext, vm.TxContext{}, statedb, config, vm.Config{})
+			ProcessParentBlockHash(b.header.ParentHash, vmenv, statedb)
+		}
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
@@ -440,7 +445,7 @@ func (cm *chainMaker) makeHeader(parent *types.Block, state *state.StateDB, engi
 	if cm.Config().IsEnabled(cm.Config().GetEIP1559Transition, header.Number) {
 		header.BaseFee = eip1559.CalcBaseFee(cm.Config(), parent.Header())
 		if !cm.Config().IsEnabled(cm.Config().GetEIP1559Transition, parent.Number()) {
-			parentGasLimit := parent.GasLimit() * cm.Config().GetElasticityMultiplier()
+			parentGasLimit := parent.GasLimit() * ctypes.ElasticityMultiplier(cm.Config(), header.Number)
 			header.GasLimit = CalcGasLimit(parentGasLimit, parentGasLimit)
 		}
 	}
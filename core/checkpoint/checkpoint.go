@@ -0,0 +1,219 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package checkpoint implements block checkpoints signed by a configured set of
+// keys. A checkpoint pins the hash and total difficulty of a block, allowing a
+// syncing node to reject chains which do not contain it and to skip proof-of-work
+// verification of the headers below it.
+package checkpoint
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/types/goethereum"
+)
+
+// signingPrefix domain-separates checkpoint signatures from any other message
+// signed with the same keys.
+var signingPrefix = []byte("\x19core-geth signed checkpoint:\n")
+
+var (
+	errNoSigners        = errors.New("no checkpoint signers configured")
+	errInvalidThreshold = errors.New("invalid checkpoint signer threshold")
+	errMissingTD        = errors.New("checkpoint total difficulty missing")
+)
+
+// Checkpoint is a canonical block, identified by its number and hash, together
+// with the total difficulty of the chain up to and including it.
+type Checkpoint struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+	TD     *big.Int    `json:"td"`
+}
+
+type checkpointJSON struct {
+	Number hexutil.Uint64 `json:"number"`
+	Hash   common.Hash    `json:"hash"`
+	TD     *hexutil.Big   `json:"td"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding numbers as hex.
+func (c Checkpoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(checkpointJSON{
+		Number: hexutil.Uint64(c.Number),
+		Hash:   c.Hash,
+		TD:     (*hexutil.Big)(c.TD),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Checkpoint) UnmarshalJSON(input []byte) error {
+	var dec checkpointJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.TD == nil {
+		return errMissingTD
+	}
+	c.Number, c.Hash, c.TD = uint64(dec.Number), dec.Hash, (*big.Int)(dec.TD)
+	return nil
+}
+
+// SigningHash returns the hash signed by checkpoint signers. The chain ID is
+// included so that checkpoints can't be replayed across networks.
+func (c *Checkpoint) SigningHash(chainID *big.Int) common.Hash {
+	var num [8]byte
+	binary.BigEndian.PutUint64(num[:], c.Number)
+
+	id, td := new(big.Int), new(big.Int)
+	if chainID != nil {
+		id.Set(chainID)
+	}
+	if c.TD != nil {
+		td.Set(c.TD)
+	}
+	return crypto.Keccak256Hash(
+		signingPrefix,
+		common.BigToHash(id).Bytes(),
+		num[:],
+		c.Hash.Bytes(),
+		common.BigToHash(td).Bytes(),
+	)
+}
+
+// SignedCheckpoint is a checkpoint along with the signatures vouching for it.
+type SignedCheckpoint struct {
+	Checkpoint Checkpoint      `json:"checkpoint"`
+	Signatures []hexutil.Bytes `json:"signatures"`
+}
+
+// Sign signs the checkpoint with the given key and appends the signature. Signing
+// again with a key which already signed is a noop.
+func (s *SignedCheckpoint) Sign(chainID *big.Int, key *ecdsa.PrivateKey) error {
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+	signers, err := s.Signers(chainID)
+	if err != nil {
+		return err
+	}
+	for _, known := range signers {
+		if known == signer {
+			return nil
+		}
+	}
+	hash := s.Checkpoint.SigningHash(chainID)
+	sig, err := crypto.Sign(hash[:], key)
+	if err != nil {
+		return err
+	}
+	s.Signatures = append(s.Signatures, sig)
+	return nil
+}
+
+// Signers recovers the addresses of all signatures attached to the checkpoint,
+// in the order the signatures are listed.
+func (s *SignedCheckpoint) Signers(chainID *big.Int) ([]common.Address, error) {
+	hash := s.Checkpoint.SigningHash(chainID)
+
+	signers := make([]common.Address, 0, len(s.Signatures))
+	for i, sig := range s.Signatures {
+		if len(sig) != crypto.SignatureLength {
+			return nil, fmt.Errorf("signature %d: invalid length %d", i, len(sig))
+		}
+		pubkey, err := crypto.SigToPub(hash[:], sig)
+		if err != nil {
+			return nil, fmt.Errorf("signature %d: %v", i, err)
+		}
+		signers = append(signers, crypto.PubkeyToAddress(*pubkey))
+	}
+	return signers, nil
+}
+
+// Verify checks that the checkpoint is signed by at least the threshold of
+// distinct configured signers. Signatures by unknown keys are ignored.
+func (s *SignedCheckpoint) Verify(chainID *big.Int, config *ctypes.CheckpointSignersConfig) error {
+	if config == nil || len(config.Signers) == 0 {
+		return errNoSigners
+	}
+	if config.Threshold == 0 || config.Threshold > uint64(len(config.Signers)) {
+		return fmt.Errorf("%w: %d of %d", errInvalidThreshold, config.Threshold, len(config.Signers))
+	}
+	if s.Checkpoint.TD == nil {
+		return errMissingTD
+	}
+	signers, err := s.Signers(chainID)
+	if err != nil {
+		return err
+	}
+	allowed := make(map[common.Address]bool, len(config.Signers))
+	for _, signer := range config.Signers {
+		allowed[signer] = true
+	}
+	approved := make(map[common.Address]bool)
+	for _, signer := range signers {
+		if allowed[signer] {
+			approved[signer] = true
+		}
+	}
+	if uint64(len(approved)) < config.Threshold {
+		return fmt.Errorf("insufficient checkpoint signatures: have %d, want %d", len(approved), config.Threshold)
+	}
+	return nil
+}
+
+// Load reads a signed checkpoint from a JSON file.
+func Load(path string) (*SignedCheckpoint, error) {
+	blob, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := new(SignedCheckpoint)
+	if err := json.Unmarshal(blob, s); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the signed checkpoint to a JSON file.
+func (s *SignedCheckpoint) Save(path string) error {
+	blob, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(blob, '\n'), 0644)
+}
+
+// SignersConfig returns the checkpoint signer set configured for the chain, or
+// nil if there is none.
+func SignersConfig(config ctypes.ChainConfigurator) *ctypes.CheckpointSignersConfig {
+	switch c := config.(type) {
+	case *coregeth.CoreGethChainConfig:
+		return c.CheckpointSigners
+	case *goethereum.ChainConfig:
+		return c.CheckpointSigners
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpoint
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+)
+
+func TestSignVerify(t *testing.T) {
+	var (
+		chainID = big.NewInt(77777)
+		keys    = make([]*ecdsa.PrivateKey, 3)
+		addrs   = make([]common.Address, 3)
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	config := &ctypes.CheckpointSignersConfig{Signers: addrs[:2], Threshold: 2}
+
+	cp := &SignedCheckpoint{Checkpoint: Checkpoint{Number: 100, Hash: common.Hash{0x01}, TD: big.NewInt(123456)}}
+	if err := cp.Verify(chainID, config); err == nil {
+		t.Fatal("unsigned checkpoint verified")
+	}
+	// A signature by an unknown key must not count towards the threshold, and
+	// duplicate signatures by the same key must be ignored.
+	for _, key := range []*ecdsa.PrivateKey{keys[0], keys[0], keys[2]} {
+		if err := cp.Sign(chainID, key); err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+	}
+	if len(cp.Signatures) != 2 {
+		t.Fatalf("signature count mismatch: have %d, want %d", len(cp.Signatures), 2)
+	}
+	if err := cp.Verify(chainID, config); err == nil {
+		t.Fatal("checkpoint verified below threshold")
+	}
+	if err := cp.Sign(chainID, keys[1]); err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if err := cp.Verify(chainID, config); err != nil {
+		t.Fatalf("failed to verify checkpoint: %v", err)
+	}
+	// Signatures must not be valid on other networks or for other checkpoints.
+	if err := cp.Verify(big.NewInt(1), config); err == nil {
+		t.Fatal("checkpoint verified on a different chain")
+	}
+	tampered := *cp
+	tampered.Checkpoint.TD = big.NewInt(1)
+	if err := tampered.Verify(chainID, config); err == nil {
+		t.Fatal("tampered checkpoint verified")
+	}
+	// Misconfigured signer sets must be rejected.
+	if err := cp.Verify(chainID, nil); !errors.Is(err, errNoSigners) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errNoSigners)
+	}
+	if err := cp.Verify(chainID, &ctypes.CheckpointSignersConfig{Signers: addrs, Threshold: 4}); !errors.Is(err, errInvalidThreshold) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errInvalidThreshold)
+	}
+	// Round-trip the checkpoint through a file.
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	if err := cp.Save(path); err != nil {
+		t.Fatalf("failed to save checkpoint: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("failed to load checkpoint: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, cp) {
+		t.Fatalf("checkpoint mismatch after reload: have %+v, want %+v", loaded, cp)
+	}
+	if err := loaded.Verify(chainID, config); err != nil {
+		t.Fatalf("failed to verify reloaded checkpoint: %v", err)
+	}
+}
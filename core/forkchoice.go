@@ -156,13 +156,19 @@ func (f *ForkChoice) ReorgNeeded(current *types.Header, extern *types.Header) (b
 		return reorg, nil
 	}
 
-	if bc, ok := f.chain.(*BlockChain); ok {
+	bc, isBlockChain := f.chain.(*BlockChain)
+	if isBlockChain {
 		// Short circuit if not configured for Artificial Finality.
 		if !bc.IsArtificialFinalityEnabled() {
 			return reorg, nil
 		}
 	}
-	if !f.chain.Config().IsEnabled(f.chain.Config().GetECBP1100Transition, current.Number) {
+	var (
+		config   = f.chain.Config()
+		mess     = config.IsEnabled(config.GetECBP1100Transition, current.Number)
+		maxDepth = ebpMaxReorgDepthActive(config, current.Number)
+	)
+	if !mess && !maxDepth {
 		return reorg, nil
 	}
 
@@ -171,10 +177,21 @@ func (f *ForkChoice) ReorgNeeded(current *types.Header, extern *types.Header) (b
 		return reorg, err
 	}
 
-	if err := ecbp1100(commonHeader, current, extern, f.chain.GetTd); err != nil {
+	var finalityErr error
+	if maxDepth {
+		finalityErr = ebpMaxReorgDepth(commonHeader, current, extern, *config.GetEBPMaxReorgDepth())
+	}
+	if finalityErr == nil && mess {
+		xcap, ampl := ecbp1100Antigravity(config)
+		finalityErr = ecbp1100(commonHeader, current, extern, f.chain.GetTd, xcap, ampl)
+	}
+	if finalityErr != nil {
 		reorg = false
-		log.Warn("Reorg disallowed", "error", err)
-	} else if current.Number.Uint64()-commonHeader.Number.Uint64() > 2 {
+		log.Warn("Reorg disallowed", "error", finalityErr)
+		if isBlockChain {
+			bc.recordFinalityRejection(commonHeader, current, extern, finalityErr)
+		}
+	} else if mess && current.Number.Uint64()-commonHeader.Number.Uint64() > 2 {
 		// Reorg is allowed, only log the MESS line if old chain is longer than normal.
 		log.Info("ECBP1100-MESS 🔓",
 			"status", "accepted",
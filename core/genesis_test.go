@@ -224,6 +224,7 @@ func TestGenesisHashes(t *testing.T) {
 		{params.DefaultGenesisBlock(), params.MainnetGenesisHash},
 		{params.DefaultGoerliGenesisBlock(), params.GoerliGenesisHash},
 		{params.DefaultSepoliaGenesisBlock(), params.SepoliaGenesisHash},
+		{params.DefaultEthernovaGenesisBlock(), params.EthernovaGenesisHash},
 	} {
 		// Test via MustCommit
 		db := rawdb.NewMemoryDatabase()
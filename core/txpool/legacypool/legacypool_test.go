@@ -132,6 +132,29 @@ func dynamicFeeTx(nonce uint64, gaslimit uint64, gasFee *big.Int, tip *big.Int,
 	return tx
 }
 
+func setCodeTx(nonce uint64, key *ecdsa.PrivateKey, auths []types.SetCodeAuthorization) *types.Transaction {
+	tx, _ := types.SignNewTx(key, types.LatestSignerForChainID(params.TestChainConfig.ChainID), &types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(params.TestChainConfig.ChainID),
+		Nonce:     nonce,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(1),
+		Gas:       100000,
+		To:        common.Address{},
+		Value:     uint256.NewInt(100),
+		AuthList:  auths,
+	})
+	return tx
+}
+
+func setCodeAuth(nonce uint64, target common.Address, key *ecdsa.PrivateKey) types.SetCodeAuthorization {
+	auth, _ := types.SignSetCode(key, types.SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(params.TestChainConfig.ChainID),
+		Address: target,
+		Nonce:   nonce,
+	})
+	return auth
+}
+
 func makeAddressReserver() txpool.AddressReserver {
 	var (
 		reserved = make(map[common.Address]struct{})
@@ -442,6 +465,120 @@ func TestTipAboveFeeCap(t *testing.T) {
 	}
 }
 
+// Tests that transactions whose fee cap can never cover the scheduled base fee
+// floor are rejected, local or not.
+func TestMinBaseFee(t *testing.T) {
+	t.Parallel()
+
+	cpy := *params.TestChainConfig
+	config := &cpy
+	zero := uint64(0)
+	config.SetEIP2718Transition(&zero)
+	config.SetEIP1559Transition(&zero)
+	config.SetMinBaseFeeSchedule(ctypes.Uint64Uint256MapEncodesHex{1: uint256.NewInt(100)})
+
+	pool, key := setupPoolWithConfig(config)
+	defer pool.Close()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(dynamicFeeTx(0, 100000, big.NewInt(99), big.NewInt(1), key)); !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Fatalf("dynamic fee tx below floor error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+	if err := pool.addLocal(pricedTransaction(0, 100000, big.NewInt(99), key)); !errors.Is(err, txpool.ErrUnderpriced) {
+		t.Fatalf("local legacy tx below floor error mismatch: have %v, want %v", err, txpool.ErrUnderpriced)
+	}
+	if err := pool.addRemoteSync(dynamicFeeTx(0, 100000, big.NewInt(100), big.NewInt(1), key)); err != nil {
+		t.Fatalf("failed to add tx at floor: %v", err)
+	}
+}
+
+// Tests that set code transactions are only accepted once EIP-7702 is enabled,
+// and that accounts with delegations, pending or in state, are limited to a
+// single in-flight transaction.
+func TestSetCodeTransactions(t *testing.T) {
+	t.Parallel()
+
+	// Set code transactions are rejected before the fork
+	pool, key := setupPoolWithConfig(eip1559Config)
+	defer pool.Close()
+
+	auth := setCodeAuth(0, common.Address{0xaa}, key)
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	if err := pool.addRemoteSync(setCodeTx(0, key, []types.SetCodeAuthorization{auth})); !errors.Is(err, core.ErrTxTypeNotSupported) {
+		t.Fatalf("pre-fork set code tx error mismatch: have %v, want %v", err, core.ErrTxTypeNotSupported)
+	}
+	// Enable EIP-7702 and retry with a few different accounts
+	cpy := *params.TestChainConfig
+	config := &cpy
+	zero := uint64(0)
+	config.SetEIP2718Transition(&zero)
+	config.SetEIP1559Transition(&zero)
+	config.SetEIP7702Transition(&zero)
+
+	pool, _ = setupPoolWithConfig(config)
+	defer pool.Close()
+
+	var (
+		keyA, _ = crypto.GenerateKey()
+		keyB, _ = crypto.GenerateKey()
+		keyC, _ = crypto.GenerateKey()
+		keyD, _ = crypto.GenerateKey()
+	)
+	for _, k := range []*ecdsa.PrivateKey{keyA, keyB, keyC, keyD} {
+		testAddBalance(pool, crypto.PubkeyToAddress(k.PublicKey), big.NewInt(1000000000))
+	}
+	if err := pool.addRemoteSync(setCodeTx(0, keyA, []types.SetCodeAuthorization{})); !errors.Is(err, core.ErrEmptyAuthList) {
+		t.Fatalf("empty auth list error mismatch: have %v, want %v", err, core.ErrEmptyAuthList)
+	}
+	// A pending authorization limits the authority to one in-flight transaction
+	if err := pool.addRemoteSync(setCodeTx(0, keyA, []types.SetCodeAuthorization{setCodeAuth(0, common.Address{0xaa}, keyB)})); err != nil {
+		t.Fatalf("failed to add set code tx: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), keyB)); err != nil {
+		t.Fatalf("failed to add first tx of authority: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(1), keyB)); !errors.Is(err, ErrInflightTxLimitReached) {
+		t.Fatalf("second tx of authority error mismatch: have %v, want %v", err, ErrInflightTxLimitReached)
+	}
+	// Replacing the in-flight transaction is still allowed
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(2), keyB)); err != nil {
+		t.Fatalf("failed to replace tx of authority: %v", err)
+	}
+	// A delegation in state limits the account to one in-flight transaction
+	addrC := crypto.PubkeyToAddress(keyC.PublicKey)
+	pool.mu.Lock()
+	pool.currentState.SetCode(addrC, types.AddressToDelegation(common.Address{0xaa}))
+	pool.mu.Unlock()
+
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), keyC)); err != nil {
+		t.Fatalf("failed to add first tx of delegated account: %v", err)
+	}
+	if err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(1), keyC)); !errors.Is(err, ErrInflightTxLimitReached) {
+		t.Fatalf("second tx of delegated account error mismatch: have %v, want %v", err, ErrInflightTxLimitReached)
+	}
+	// Authorizations of accounts with several in-flight transactions are rejected
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := pool.addRemoteSync(pricedTransaction(nonce, 100000, big.NewInt(1), keyD)); err != nil {
+			t.Fatalf("failed to add tx %d: %v", nonce, err)
+		}
+	}
+	if err := pool.addRemoteSync(setCodeTx(1, keyA, []types.SetCodeAuthorization{setCodeAuth(2, common.Address{0xaa}, keyD)})); !errors.Is(err, ErrAuthorityReserved) {
+		t.Fatalf("reserved authority error mismatch: have %v, want %v", err, ErrAuthorityReserved)
+	}
+	// Dropping the set code transaction releases the authority
+	pool.mu.Lock()
+	for _, tx := range pool.pending[crypto.PubkeyToAddress(keyA.PublicKey)].Flatten() {
+		pool.removeTx(tx.Hash(), true, true)
+	}
+	pool.mu.Unlock()
+	if pool.all.hasAuth(crypto.PubkeyToAddress(keyB.PublicKey)) {
+		t.Fatal("authority still tracked after set code tx removal")
+	}
+	if err := validatePoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 func TestVeryHighValues(t *testing.T) {
 	t.Parallel()
 
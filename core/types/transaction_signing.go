@@ -40,6 +40,12 @@ type sigCache struct {
 func MakeSigner(config ctypes.ChainConfigurator, blockNumber *big.Int, blockTime uint64) Signer {
 	var signer Signer
 	switch {
+	case config.IsEnabled(config.GetEIP7702Transition, blockNumber):
+		if config.IsEnabledByTime(config.GetEIP4844TransitionTime, &blockTime) || config.IsEnabled(config.GetEIP4844Transition, blockNumber) {
+			signer = NewPragueSigner(config.GetChainID())
+		} else {
+			signer = NewEIP7702Signer(config.GetChainID())
+		}
 	case config.IsEnabledByTime(config.GetEIP4844TransitionTime, &blockTime), config.IsEnabled(config.GetEIP4844Transition, blockNumber):
 		signer = NewCancunSigner(config.GetChainID())
 	case config.IsEnabled(config.GetEIP1559Transition, blockNumber):
@@ -65,7 +71,14 @@ func MakeSigner(config ctypes.ChainConfigurator, blockNumber *big.Int, blockTime
 // have the current block number available, use MakeSigner instead.
 func LatestSigner(config ctypes.ChainConfigurator) Signer {
 	if chainID := config.GetChainID(); chainID != nil {
-		if config.GetEIP4844TransitionTime() != nil || config.GetEIP4844Transition() != nil {
+		blobs := config.GetEIP4844TransitionTime() != nil || config.GetEIP4844Transition() != nil
+		if config.GetEIP7702Transition() != nil {
+			if blobs {
+				return NewPragueSigner(chainID)
+			}
+			return NewEIP7702Signer(chainID)
+		}
+		if blobs {
 			return NewCancunSigner(chainID)
 		}
 		if config.GetEIP1559Transition() != nil {
@@ -92,8 +105,8 @@ func LatestSignerForChainID(chainID *big.Int) Signer {
 	if chainID == nil {
 		return HomesteadSigner{}
 	}
-	// EIP4844Signer == CancunSigner
-	return NewCancunSigner(chainID)
+	// EIP7702Signer == PragueSigner
+	return NewPragueSigner(chainID)
 }
 
 // SignTx signs the transaction using the given signer and private key.
@@ -176,6 +189,99 @@ type Signer interface {
 	Equal(Signer) bool
 }
 
+// NewPragueSigner returns a signer that accepts
+// - EIP-7702 set code transactions
+// - EIP-4844 blob transactions
+// - EIP-1559 dynamic fee transactions
+// - EIP-2930 access list transactions,
+// - EIP-155 replay protected transactions, and
+// - legacy Homestead transactions.
+type eip7702Signer struct {
+	eip4844Signer
+	blobs bool // Whether EIP-4844 blob transactions are accepted too
+}
+
+func NewPragueSigner(chainId *big.Int) Signer {
+	return eip7702Signer{eip4844Signer{eip1559Signer{eip2930Signer{NewEIP155Signer(chainId)}}}, true}
+}
+
+// NewEIP7702Signer returns a signer that accepts
+// - EIP-7702 set code transactions
+// - EIP-1559 dynamic fee transactions
+// - EIP-2930 access list transactions,
+// - EIP-155 replay protected transactions, and
+// - legacy Homestead transactions.
+//
+// It is meant for chains scheduling EIP-7702 without EIP-4844.
+func NewEIP7702Signer(chainId *big.Int) Signer {
+	return eip7702Signer{eip4844Signer{eip1559Signer{eip2930Signer{NewEIP155Signer(chainId)}}}, false}
+}
+
+func (s eip7702Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() == BlobTxType && !s.blobs {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
+	if tx.Type() != SetCodeTxType {
+		return s.eip4844Signer.Sender(tx)
+	}
+	V, R, S := tx.RawSignatureValues()
+	// Set code txs are defined to use 0 and 1 as their recovery
+	// id, add 27 to become equivalent to unprotected Homestead signatures.
+	V = new(big.Int).Add(V, big.NewInt(27))
+	if tx.ChainId().Cmp(s.chainId) != 0 {
+		return common.Address{}, fmt.Errorf("%w: have %d want %d", ErrInvalidChainId, tx.ChainId(), s.chainId)
+	}
+	return recoverPlain(s.Hash(tx), R, S, V, true)
+}
+
+func (s eip7702Signer) Equal(s2 Signer) bool {
+	x, ok := s2.(eip7702Signer)
+	return ok && x.chainId.Cmp(s.chainId) == 0 && x.blobs == s.blobs
+}
+
+func (s eip7702Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() == BlobTxType && !s.blobs {
+		return nil, nil, nil, ErrTxTypeNotSupported
+	}
+	txdata, ok := tx.inner.(*SetCodeTx)
+	if !ok {
+		return s.eip4844Signer.SignatureValues(tx, sig)
+	}
+	// Check that chain ID of tx matches the signer. We also accept ID zero here,
+	// because it indicates that the chain ID was not specified in the tx.
+	if txdata.ChainID.Sign() != 0 && txdata.ChainID.ToBig().Cmp(s.chainId) != 0 {
+		return nil, nil, nil, fmt.Errorf("%w: have %d want %d", ErrInvalidChainId, txdata.ChainID, s.chainId)
+	}
+	R, S, _ = decodeSignature(sig)
+	V = big.NewInt(int64(sig[64]))
+	return R, S, V, nil
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s eip7702Signer) Hash(tx *Transaction) common.Hash {
+	if tx.Type() == BlobTxType && !s.blobs {
+		return common.Hash{}
+	}
+	if tx.Type() != SetCodeTxType {
+		return s.eip4844Signer.Hash(tx)
+	}
+	return prefixedRlpHash(
+		tx.Type(),
+		[]interface{}{
+			s.chainId,
+			tx.Nonce(),
+			tx.GasTipCap(),
+			tx.GasFeeCap(),
+			tx.Gas(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+			tx.AccessList(),
+			tx.SetCodeAuthorizations(),
+		})
+}
+
 // NewEIP4844Signer returns a signer that accepts
 // - EIP-4844 blob transactions
 // - EIP-1559 dynamic fee transactions
@@ -24,8 +24,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
 	"github.com/ethereum/go-ethereum/params/vars"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/holiman/uint256"
 )
 
 func TestEIP155Signing(t *testing.T) {
@@ -178,6 +180,47 @@ func TestNilSigner(t *testing.T) {
 	}
 }
 
+// Tests that a chain scheduling EIP-7702 without EIP-4844 gets a signer that
+// accepts set code transactions but rejects blob transactions.
+func TestMakeSignerEIP7702WithoutEIP4844(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	config := &coregeth.CoreGethChainConfig{
+		ChainID:       big.NewInt(1),
+		EIP155Block:   big.NewInt(0),
+		EIP2718FBlock: big.NewInt(0),
+		EIP2930FBlock: big.NewInt(0),
+		EIP1559FBlock: big.NewInt(0),
+		EIP7702FBlock: big.NewInt(0),
+	}
+	signer := MakeSigner(config, big.NewInt(1), 0)
+
+	setcode, err := SignNewTx(key, signer, &SetCodeTx{
+		ChainID:   uint256.NewInt(1),
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(10),
+		Gas:       100000,
+		Value:     uint256.NewInt(0),
+	})
+	if err != nil {
+		t.Fatalf("failed to sign set code transaction: %v", err)
+	}
+	if _, err := Sender(signer, setcode); err != nil {
+		t.Errorf("set code transaction rejected: %v", err)
+	}
+	blobtx, err := SignNewTx(key, NewCancunSigner(big.NewInt(1)), createEmptyBlobTxInner(false))
+	if err != nil {
+		t.Fatalf("failed to sign blob transaction: %v", err)
+	}
+	if _, err := Sender(signer, blobtx); !errors.Is(err, ErrTxTypeNotSupported) {
+		t.Errorf("blob transaction error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	// Once EIP-4844 is active too, blob transactions are accepted
+	config.EIP4844FBlock = big.NewInt(0)
+	if _, err := Sender(MakeSigner(config, big.NewInt(1), 0), blobtx); err != nil {
+		t.Errorf("blob transaction rejected after EIP-4844: %v", err)
+	}
+}
+
 func createTestLegacyTxInner() *LegacyTx {
 	return &LegacyTx{
 		Nonce:    uint64(0),
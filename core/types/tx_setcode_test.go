@@ -0,0 +1,147 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// TestParseDelegation tests a few possible delegation designator values and
+// ensures they are parsed correctly.
+func TestParseDelegation(t *testing.T) {
+	addr := common.Address{0x42}
+	for _, tt := range []struct {
+		val  []byte
+		want *common.Address
+	}{
+		{ // simple correct delegation
+			val:  append(DelegationPrefix, addr.Bytes()...),
+			want: &addr,
+		},
+		{ // wrong address size
+			val: append(DelegationPrefix, addr.Bytes()[0:19]...),
+		},
+		{ // short address
+			val: append(DelegationPrefix, 0x42),
+		},
+		{ // long address
+			val: append(append(DelegationPrefix, addr.Bytes()...), 0x42),
+		},
+		{ // wrong prefix size
+			val: append(DelegationPrefix[:2], addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x01, 0x01}, addr.Bytes()...),
+		},
+		{ // wrong prefix
+			val: append([]byte{0xef, 0x00, 0x00}, addr.Bytes()...),
+		},
+		{ // no prefix
+			val: addr.Bytes(),
+		},
+		{ // no address
+			val: DelegationPrefix,
+		},
+	} {
+		got, ok := ParseDelegation(tt.val)
+		if ok && tt.want == nil {
+			t.Fatalf("expected fail, got %s", got.Hex())
+		}
+		if !ok && tt.want != nil {
+			t.Fatalf("failed to parse, want %s", tt.want.Hex())
+		}
+	}
+	if have := AddressToDelegation(addr); !reflect.DeepEqual(have, append(DelegationPrefix, addr.Bytes()...)) {
+		t.Fatalf("delegation mismatch: have %x", have)
+	}
+}
+
+// Tests that set code transactions survive the binary and JSON encodings, and
+// that both the sender and the authorities can be recovered afterwards.
+func TestSetCodeTxEncoding(t *testing.T) {
+	var (
+		key, _     = crypto.GenerateKey()
+		authKey, _ = crypto.GenerateKey()
+		chainID    = big.NewInt(77777)
+		signer     = NewPragueSigner(chainID)
+		sender     = crypto.PubkeyToAddress(key.PublicKey)
+		authority  = crypto.PubkeyToAddress(authKey.PublicKey)
+	)
+	auth, err := SignSetCode(authKey, SetCodeAuthorization{
+		ChainID: *uint256.MustFromBig(chainID),
+		Address: common.Address{0xaa},
+		Nonce:   1,
+	})
+	if err != nil {
+		t.Fatalf("failed to sign authorization: %v", err)
+	}
+	tx := MustSignNewTx(key, signer, &SetCodeTx{
+		ChainID:   uint256.MustFromBig(chainID),
+		Nonce:     0,
+		GasTipCap: uint256.NewInt(1),
+		GasFeeCap: uint256.NewInt(10),
+		Gas:       100000,
+		To:        sender,
+		Value:     uint256.NewInt(0),
+		AuthList:  []SetCodeAuthorization{auth, auth},
+	})
+	bin, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	if bin[0] != SetCodeTxType {
+		t.Fatalf("wrong transaction type prefix: have %d, want %d", bin[0], SetCodeTxType)
+	}
+	js, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("failed to JSON encode transaction: %v", err)
+	}
+	for _, enc := range []func(*Transaction) error{
+		func(dec *Transaction) error { return dec.UnmarshalBinary(bin) },
+		func(dec *Transaction) error { return json.Unmarshal(js, dec) },
+	} {
+		dec := new(Transaction)
+		if err := enc(dec); err != nil {
+			t.Fatalf("failed to decode transaction: %v", err)
+		}
+		if dec.Hash() != tx.Hash() {
+			t.Fatalf("hash mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+		}
+		if from, err := Sender(signer, dec); err != nil || from != sender {
+			t.Fatalf("sender mismatch: have %x, want %x, err %v", from, sender, err)
+		}
+		if have := dec.SetCodeAuthorities(); !reflect.DeepEqual(have, []common.Address{authority}) {
+			t.Fatalf("authorities mismatch: have %v, want %v", have, []common.Address{authority})
+		}
+	}
+	// Set code transactions are rejected by signers predating EIP-7702
+	if _, err := Sender(NewCancunSigner(chainID), tx); err != ErrTxTypeNotSupported {
+		t.Fatalf("unexpected error from old signer: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	// Tampering with the authorization changes the recovered authority
+	auth.Nonce++
+	if have, err := auth.Authority(); err == nil && have == authority {
+		t.Fatal("authority recovered from tampered authorization")
+	}
+}
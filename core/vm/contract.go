@@ -51,10 +51,11 @@ type Contract struct {
 	jumpdests map[common.Hash]bitvec // Aggregated result of JUMPDEST analysis.
 	analysis  bitvec                 // Locally cached result of JUMPDEST analysis
 
-	Code     []byte
-	CodeHash common.Hash
-	CodeAddr *common.Address
-	Input    []byte
+	Code      []byte
+	CodeHash  common.Hash
+	CodeAddr  *common.Address
+	Input     []byte
+	Container *Container // Decoded EOF container, nil for legacy code
 
 	Gas   uint64
 	value *uint256.Int
@@ -139,10 +140,26 @@ func (c *Contract) AsDelegate() *Contract {
 	return c
 }
 
-// GetOp returns the n'th element in the contract's byte array
-func (c *Contract) GetOp(n uint64) OpCode {
-	if n < uint64(len(c.Code)) {
-		return OpCode(c.Code[n])
+// IsEOF returns whether the contract's code is an EOF container.
+func (c *Contract) IsEOF() bool {
+	return c.Container != nil
+}
+
+// CodeAt returns the code of the given code section for EOF contracts, or the
+// full contract code for legacy contracts.
+func (c *Contract) CodeAt(section uint64) []byte {
+	if c.Container == nil {
+		return c.Code
+	}
+	return c.Container.codeSections[section]
+}
+
+// GetOp returns the n'th element in the contract's byte array, or the n'th
+// element of the given code section for EOF contracts.
+func (c *Contract) GetOp(n uint64, section uint64) OpCode {
+	code := c.CodeAt(section)
+	if n < uint64(len(code)) {
+		return OpCode(code[n])
 	}
 
 	return STOP
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -94,6 +95,7 @@ var allPrecompiles = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{0x0f, 0x10}): &bls12381Pairing{},
 	common.BytesToAddress([]byte{0x0f, 0x11}): &bls12381MapG1{},
 	common.BytesToAddress([]byte{0x0f, 0x12}): &bls12381MapG2{},
+	common.BytesToAddress([]byte{0x01, 0x00}): &p256Verify{},
 }
 
 func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
@@ -413,6 +415,28 @@ func TestPrecompiledBLS12381MapG2(t *testing.T)      { testJson("blsMapG2", "f12
 
 func TestPrecompiledPointEvaluation(t *testing.T) { testJson("pointEvaluation", "0a", t) }
 
+func TestPrecompiledP256Verify(t *testing.T)      { testJson("p256Verify", "100", t) }
+func BenchmarkPrecompiledP256Verify(b *testing.B) { benchJson("p256Verify", "100", b) }
+
+// Tests that the P256VERIFY precompile is only active from its configured
+// transition onwards.
+func TestP256VerifyActivation(t *testing.T) {
+	var (
+		addr   = common.BytesToAddress([]byte{0x01, 0x00})
+		config = &coregeth.CoreGethChainConfig{EIP7212FBlock: big.NewInt(10)}
+		zero   = uint64(0)
+	)
+	if PrecompiledContractsForConfig(config, big.NewInt(9), &zero)[addr] != nil {
+		t.Fatal("P256VERIFY active before its transition")
+	}
+	if PrecompiledContractsForConfig(config, big.NewInt(10), &zero)[addr] == nil {
+		t.Fatal("P256VERIFY not active at its transition")
+	}
+	if PrecompiledContractsForConfig(params.AllEthashProtocolChanges, big.NewInt(0), &zero)[addr] != nil {
+		t.Fatal("P256VERIFY active without configured transition")
+	}
+}
+
 func BenchmarkPrecompiledBLS12381G1Add(b *testing.B)      { benchJson("blsG1Add", "f0a", b) }
 func BenchmarkPrecompiledBLS12381G1Mul(b *testing.B)      { benchJson("blsG1Mul", "f0b", b) }
 func BenchmarkPrecompiledBLS12381G1MultiExp(b *testing.B) { benchJson("blsG1MultiExp", "f0c", b) }
@@ -0,0 +1,485 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+const (
+	offsetVersion   = 2
+	offsetTypesKind = 3
+	offsetCodeKind  = 6
+
+	kindTypes     = 1
+	kindCode      = 2
+	kindContainer = 3
+	kindData      = 0xff
+
+	eofFormatByte = 0xef
+	eof1Version   = 1
+
+	maxInputItems        = 127
+	maxOutputItems       = 128
+	maxStackIncrease     = 1023
+	maxCodeSections      = 1024
+	maxContainerSections = 256
+
+	// nonReturningFunction is the outputs value marking a code section that
+	// never returns to its caller.
+	nonReturningFunction = 0x80
+)
+
+var eofMagic = []byte{0xef, 0x00}
+
+// HasEOFByte returns true if code starts with 0xEF byte
+func HasEOFByte(code []byte) bool {
+	return len(code) != 0 && code[0] == eofFormatByte
+}
+
+// hasEOFMagic returns true if code starts with magic defined by EIP-3540
+func hasEOFMagic(code []byte) bool {
+	return len(eofMagic) <= len(code) && bytes.Equal(eofMagic, code[0:len(eofMagic)])
+}
+
+// isEOFVersion1 returns true if the code's version byte equals eof1Version. It
+// does not verify the EOF magic is valid.
+func isEOFVersion1(code []byte) bool {
+	return 2 < len(code) && code[2] == byte(eof1Version)
+}
+
+// Container is an EOF container object.
+type Container struct {
+	types             []*functionMetadata
+	codeSections      [][]byte
+	subContainers     []*Container
+	subContainerCodes [][]byte
+	data              []byte
+	dataSize          int // might be more than len(data)
+}
+
+// functionMetadata is an EOF function signature.
+type functionMetadata struct {
+	inputs           uint8
+	outputs          uint8
+	maxStackIncrease uint16
+}
+
+// returning reports whether the function returns control to its caller.
+func (meta *functionMetadata) returning() bool {
+	return meta.outputs != nonReturningFunction
+}
+
+// checkInputs checks the current minimum stack (stackMin) against the required inputs
+// of the metadata, and returns an error if the stack is too shallow.
+func (meta *functionMetadata) checkInputs(stackMin int) error {
+	if int(meta.inputs) > stackMin {
+		return errStackUnderflow
+	}
+	return nil
+}
+
+// checkStackMax checks if the current maximum stack combined with the
+// function's max stack increase will result in a stack overflow, and if so
+// returns an error.
+func (meta *functionMetadata) checkStackMax(stackMax int) error {
+	if stackMax+int(meta.maxStackIncrease) > int(vars.StackLimit) {
+		return errStackOverflow
+	}
+	return nil
+}
+
+// CodeSectionCount returns the number of code sections in the container.
+func (c *Container) CodeSectionCount() int {
+	return len(c.codeSections)
+}
+
+// SubContainerCount returns the number of nested containers.
+func (c *Container) SubContainerCount() int {
+	return len(c.subContainers)
+}
+
+// Data returns the (possibly truncated) data section of the container.
+func (c *Container) Data() []byte {
+	return c.data
+}
+
+// MarshalBinary encodes an EOF container into binary format.
+func (c *Container) MarshalBinary() []byte {
+	// Build EOF prefix.
+	b := make([]byte, 2)
+	copy(b, eofMagic)
+	b = append(b, eof1Version)
+
+	// Write section headers.
+	b = append(b, kindTypes)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.types)*4))
+	b = append(b, kindCode)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.codeSections)))
+	for _, codeSection := range c.codeSections {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(codeSection)))
+	}
+	if len(c.subContainers) != 0 {
+		b = append(b, kindContainer)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(c.subContainers)))
+		for _, section := range c.subContainerCodes {
+			b = binary.BigEndian.AppendUint32(b, uint32(len(section)))
+		}
+	}
+	b = append(b, kindData)
+	b = binary.BigEndian.AppendUint16(b, uint16(c.dataSize))
+	b = append(b, 0) // terminator
+
+	// Write section contents.
+	for _, ty := range c.types {
+		b = append(b, []byte{ty.inputs, ty.outputs, byte(ty.maxStackIncrease >> 8), byte(ty.maxStackIncrease & 0x00ff)}...)
+	}
+	for _, code := range c.codeSections {
+		b = append(b, code...)
+	}
+	for _, section := range c.subContainerCodes {
+		b = append(b, section...)
+	}
+	b = append(b, c.data...)
+
+	return b
+}
+
+// UnmarshalBinary decodes an EOF container. The data section of the
+// container must not be truncated.
+func (c *Container) UnmarshalBinary(b []byte) error {
+	_, err := c.unmarshalContainer(b, true, false)
+	return err
+}
+
+// UnmarshalInitcode decodes an EOF container found at the start of b, such as
+// the data of a creation transaction, and returns the bytes following it.
+func (c *Container) UnmarshalInitcode(b []byte) ([]byte, error) {
+	size, err := c.unmarshalContainer(b, true, true)
+	if err != nil {
+		return nil, err
+	}
+	return b[size:], nil
+}
+
+// unmarshalContainer decodes the container at the start of b and returns its
+// encoded length. Only non top-level containers may have truncated data, and
+// only when trailing is disallowed must the container span all of b.
+func (c *Container) unmarshalContainer(b []byte, topLevel bool, trailing bool) (int, error) {
+	if !hasEOFMagic(b) {
+		return 0, fmt.Errorf("%w: want %x", errInvalidMagic, eofMagic)
+	}
+	if len(b) < 14 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if !isEOFVersion1(b) {
+		return 0, fmt.Errorf("%w: have %d, want %d", errInvalidVersion, b[offsetVersion], eof1Version)
+	}
+
+	var (
+		kind, typesSize, dataSize int
+		codeSizes                 []int
+		containerSizes            []int
+		err                       error
+	)
+
+	// Parse type section header.
+	kind, typesSize, err = parseSection(b, offsetTypesKind)
+	if err != nil {
+		return 0, err
+	}
+	if kind != kindTypes {
+		return 0, fmt.Errorf("%w: found section kind %x instead", errMissingTypeHeader, kind)
+	}
+	if typesSize < 4 || typesSize%4 != 0 {
+		return 0, fmt.Errorf("%w: type section size must be divisible by 4, have %d", errInvalidTypeSize, typesSize)
+	}
+	if typesSize/4 > maxCodeSections {
+		return 0, fmt.Errorf("%w: type section must not exceed 4*1024, have %d", errInvalidTypeSize, typesSize)
+	}
+
+	// Parse code section header.
+	kind, codeSizes, err = parseSectionList(b, offsetCodeKind, 2)
+	if err != nil {
+		return 0, err
+	}
+	if kind != kindCode {
+		return 0, fmt.Errorf("%w: found section kind %x instead", errMissingCodeHeader, kind)
+	}
+	if len(codeSizes) != typesSize/4 {
+		return 0, fmt.Errorf("%w: mismatch of code sections found and type signatures, types %d, code %d", errInvalidCodeSize, typesSize/4, len(codeSizes))
+	}
+	offset := offsetCodeKind + 2 + 1 + 2*len(codeSizes)
+
+	// Parse optional container section header.
+	if offset < len(b) && b[offset] == kindContainer {
+		kind, containerSizes, err = parseSectionList(b, offset, 4)
+		if err != nil {
+			return 0, err
+		}
+		if len(containerSizes) > maxContainerSections {
+			return 0, fmt.Errorf("%w: number of container sections may not exceed %d, have %d", errInvalidContainerSectionSize, maxContainerSections, len(containerSizes))
+		}
+		offset += 2 + 1 + 4*len(containerSizes)
+	}
+
+	// Parse data section header.
+	kind, dataSize, err = parseSection(b, offset)
+	if err != nil {
+		return 0, err
+	}
+	if kind != kindData {
+		return 0, fmt.Errorf("%w: found section %x instead", errMissingDataHeader, kind)
+	}
+	offset += 3
+
+	// Check for terminator.
+	if offset >= len(b) {
+		return 0, fmt.Errorf("%w: invalid offset terminator", io.ErrUnexpectedEOF)
+	}
+	if b[offset] != 0 {
+		return 0, fmt.Errorf("%w: have %x", errMissingTerminator, b[offset])
+	}
+	offset++
+
+	// Verify body size.
+	bodySize := typesSize
+	for _, size := range codeSizes {
+		bodySize += size
+	}
+	for _, size := range containerSizes {
+		bodySize += size
+	}
+	if offset+bodySize > len(b) {
+		return 0, fmt.Errorf("%w: container size less than minimum valid size", errInvalidContainerSize)
+	}
+
+	// Parse types section.
+	types := make([]*functionMetadata, 0, typesSize/4)
+	for i := 0; i < typesSize/4; i++ {
+		sig := &functionMetadata{
+			inputs:           b[offset+i*4],
+			outputs:          b[offset+i*4+1],
+			maxStackIncrease: binary.BigEndian.Uint16(b[offset+i*4+2:]),
+		}
+		if sig.inputs > maxInputItems {
+			return 0, fmt.Errorf("%w for section %d: have %d", errTooManyInputs, i, sig.inputs)
+		}
+		if sig.outputs > maxOutputItems {
+			return 0, fmt.Errorf("%w for section %d: have %d", errTooManyOutputs, i, sig.outputs)
+		}
+		if sig.maxStackIncrease > maxStackIncrease {
+			return 0, fmt.Errorf("%w for section %d: have %d", errTooLargeMaxStackHeight, i, sig.maxStackIncrease)
+		}
+		types = append(types, sig)
+	}
+	if types[0].inputs != 0 || types[0].outputs != nonReturningFunction {
+		return 0, fmt.Errorf("%w: have %d, %d", errInvalidSection0Type, types[0].inputs, types[0].outputs)
+	}
+	c.types = types
+	offset += typesSize
+
+	// Parse code sections.
+	codeSections := make([][]byte, len(codeSizes))
+	for i, size := range codeSizes {
+		codeSections[i] = b[offset : offset+size]
+		offset += size
+	}
+	c.codeSections = codeSections
+
+	// Parse the optional container sections.
+	if len(containerSizes) != 0 {
+		subContainerCodes := make([][]byte, 0, len(containerSizes))
+		subContainers := make([]*Container, 0, len(containerSizes))
+		for i, size := range containerSizes {
+			subContainer := new(Container)
+			end := offset + size
+			if _, err := subContainer.unmarshalContainer(b[offset:end], false, false); err != nil {
+				return 0, fmt.Errorf("%w: container section %d", err, i)
+			}
+			subContainers = append(subContainers, subContainer)
+			subContainerCodes = append(subContainerCodes, b[offset:end])
+			offset = end
+		}
+		c.subContainerCodes = subContainerCodes
+		c.subContainers = subContainers
+	}
+
+	// Parse data section.
+	end := offset + dataSize
+	if end > len(b) {
+		if topLevel {
+			return 0, fmt.Errorf("%w: have %d, want %d", errTruncatedTopLevelContainer, len(b), end)
+		}
+		end = len(b)
+	} else if !trailing && end < len(b) {
+		return 0, fmt.Errorf("%w: have %d, want %d", errInvalidContainerSize, len(b), end)
+	}
+	c.data = b[offset:end]
+	c.dataSize = dataSize
+
+	return end, nil
+}
+
+// withAuxData returns the binary encoding of the container after appending
+// aux to its data section, as deployed by RETURNCONTRACT.
+func (c *Container) withAuxData(aux []byte) ([]byte, error) {
+	dataSize := len(c.data) + len(aux)
+	if dataSize < c.dataSize {
+		return nil, fmt.Errorf("%w: have %d, want %d", errTruncatedData, dataSize, c.dataSize)
+	}
+	if dataSize > 0xffff {
+		return nil, fmt.Errorf("%w: have %d", errInvalidDataSize, dataSize)
+	}
+	deployed := *c
+	deployed.data = append(append(make([]byte, 0, dataSize), c.data...), aux...)
+	deployed.dataSize = dataSize
+	return deployed.MarshalBinary(), nil
+}
+
+// parseSection decodes a (kind, size) pair from an EOF header.
+func parseSection(b []byte, idx int) (kind, size int, err error) {
+	if idx+3 > len(b) {
+		return 0, 0, io.ErrUnexpectedEOF
+	}
+	kind = int(b[idx])
+	size = int(binary.BigEndian.Uint16(b[idx+1 : idx+3]))
+	return kind, size, nil
+}
+
+// parseSectionList decodes a (kind, len, []sizes) section list from an EOF
+// header, where each size is encoded in width bytes.
+func parseSectionList(b []byte, idx int, width int) (kind int, list []int, err error) {
+	if idx >= len(b) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	kind = int(b[idx])
+	if idx+3 > len(b) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	count := int(binary.BigEndian.Uint16(b[idx+1:]))
+	if count == 0 {
+		return 0, nil, fmt.Errorf("%w: section kind %x", errEmptySectionList, kind)
+	}
+	if kind == kindCode && count > maxCodeSections {
+		return 0, nil, fmt.Errorf("%w: have %d", errInvalidCodeSize, count)
+	}
+	idx += 3
+	if idx+width*count > len(b) {
+		return 0, nil, io.ErrUnexpectedEOF
+	}
+	list = make([]int, count)
+	for i := 0; i < count; i++ {
+		var size int
+		if width == 2 {
+			size = int(binary.BigEndian.Uint16(b[idx+width*i:]))
+		} else {
+			size = int(binary.BigEndian.Uint32(b[idx+width*i:]))
+		}
+		if size == 0 {
+			return 0, nil, fmt.Errorf("%w: section kind %x, entry %d", errEmptySectionList, kind, i)
+		}
+		list[i] = size
+	}
+	return kind, list, nil
+}
+
+// String returns a human readable dump of the container.
+func (c *Container) String() string {
+	var result strings.Builder
+	result.WriteString("Header\n")
+	result.WriteString(fmt.Sprintf("  - EOFMagic: %02x\n", eofMagic))
+	result.WriteString(fmt.Sprintf("  - EOFVersion: %02x\n", eof1Version))
+	result.WriteString(fmt.Sprintf("  - KindType: %02x\n", kindTypes))
+	result.WriteString(fmt.Sprintf("  - TypesSize: %04x\n", len(c.types)*4))
+	result.WriteString(fmt.Sprintf("  - KindCode: %02x\n", kindCode))
+	result.WriteString(fmt.Sprintf("  - CodeSections: %04x\n", len(c.codeSections)))
+	for i, code := range c.codeSections {
+		result.WriteString(fmt.Sprintf("    - Code section %d length: %04x\n", i, len(code)))
+	}
+	if len(c.subContainers) > 0 {
+		result.WriteString(fmt.Sprintf("  - KindContainer: %02x\n", kindContainer))
+		result.WriteString(fmt.Sprintf("  - ContainerSections: %04x\n", len(c.subContainers)))
+		for i, section := range c.subContainerCodes {
+			result.WriteString(fmt.Sprintf("    - Container section %d length: %08x\n", i, len(section)))
+		}
+	}
+	result.WriteString(fmt.Sprintf("  - KindData: %02x\n", kindData))
+	result.WriteString(fmt.Sprintf("  - DataSize: %04x\n", c.dataSize))
+	result.WriteString("  - Terminator: 0x00\n")
+	result.WriteString("Body\n")
+	for i, typ := range c.types {
+		result.WriteString(fmt.Sprintf("  - Type %v: %x\n", i, []byte{typ.inputs, typ.outputs, byte(typ.maxStackIncrease >> 8), byte(typ.maxStackIncrease & 0x00ff)}))
+	}
+	for i, code := range c.codeSections {
+		result.WriteString(fmt.Sprintf("  - Code section %d: %#x\n", i, code))
+	}
+	for i, section := range c.subContainerCodes {
+		result.WriteString(fmt.Sprintf("  - Container section %d: %#x\n", i, section))
+	}
+	result.WriteString(fmt.Sprintf("  - Data: %#x\n", c.data))
+	return result.String()
+}
+
+var (
+	errInvalidMagic                  = errors.New("invalid magic")
+	errUndefinedInstruction          = errors.New("undefined instruction")
+	errTruncatedImmediate            = errors.New("truncated immediate")
+	errInvalidSectionArgument        = errors.New("invalid section argument")
+	errInvalidCallArgument           = errors.New("callf into non-returning section")
+	errInvalidDataloadNArgument      = errors.New("invalid dataloadN argument")
+	errInvalidJumpDest               = errors.New("invalid jump destination")
+	errInvalidBackwardJump           = errors.New("invalid backward jump")
+	errInvalidOutputs                = errors.New("invalid number of outputs")
+	errInvalidMaxStackHeight         = errors.New("invalid max stack height")
+	errInvalidCodeTermination        = errors.New("invalid code termination")
+	errEOFCreateWithTruncatedSection = errors.New("eofcreate with truncated section")
+	errOrphanedSubcontainer          = errors.New("subcontainer not referenced at all")
+	errIncompatibleContainerKind     = errors.New("incompatible container kind")
+	errAmbiguousContainer            = errors.New("cannot reference subcontainer from both eofcreate and returncontract")
+	errStackOverflow                 = errors.New("stack overflow")
+	errStackUnderflow                = errors.New("stack underflow")
+	errUnreachableCode               = errors.New("unreachable code")
+	errInvalidNonReturning           = errors.New("invalid non-returning flag")
+	errInvalidVersion                = errors.New("invalid version")
+	errMissingTypeHeader             = errors.New("missing type header")
+	errInvalidTypeSize               = errors.New("invalid type section size")
+	errMissingCodeHeader             = errors.New("missing code header")
+	errInvalidCodeSize               = errors.New("invalid code size")
+	errInvalidContainerArgument      = errors.New("invalid container argument")
+	errInvalidContainerSectionSize   = errors.New("invalid container section size")
+	errInvalidContainerSize          = errors.New("invalid container size")
+	errMissingDataHeader             = errors.New("missing data header")
+	errMissingTerminator             = errors.New("missing header terminator")
+	errTooManyInputs                 = errors.New("invalid type content, too many inputs")
+	errTooManyOutputs                = errors.New("invalid type content, too many outputs")
+	errInvalidSection0Type           = errors.New("invalid section 0 type, input and output should be zero and non-returning (0x80)")
+	errTooLargeMaxStackHeight        = errors.New("invalid type content, max stack height exceeds limit")
+	errEmptySectionList              = errors.New("empty section list")
+	errTruncatedTopLevelContainer    = errors.New("truncated top level container")
+	errUnreachableCodeSections       = errors.New("unreachable code sections")
+	errJUMPFOutputs                  = errors.New("jumpf to section with more outputs than the current section")
+	errTruncatedData                 = errors.New("deployed container data shorter than declared")
+	errInvalidDataSize               = errors.New("deployed container data size exceeds limit")
+)
@@ -0,0 +1,462 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params/vars"
+	"github.com/holiman/uint256"
+)
+
+// returnStackLimit is the maximum depth of nested EOF function calls.
+const returnStackLimit = 1024
+
+// opRjump implements the RJUMP opcode.
+func opRjump(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code   = scope.Contract.CodeAt(scope.CodeSection)
+		offset = parseInt16(code[*pc+1:])
+	)
+	// move pc past op and operand (+3), add relative offset, subtract 1 to
+	// account for interpreter loop.
+	*pc = uint64(int64(*pc+3) + int64(offset) - 1)
+	return nil, nil
+}
+
+// opRjumpi implements the RJUMPI opcode
+func opRjumpi(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	condition := scope.Stack.pop()
+	if condition.BitLen() == 0 {
+		// Not branching, just skip over immediate argument.
+		*pc += 2
+		return nil, nil
+	}
+	return opRjump(pc, interpreter, scope)
+}
+
+// opRjumpv implements the RJUMPV opcode
+func opRjumpv(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code     = scope.Contract.CodeAt(scope.CodeSection)
+		maxIndex = uint64(code[*pc+1]) + 1
+		index    = scope.Stack.pop()
+	)
+	idx, overflow := index.Uint64WithOverflow()
+	if overflow || idx >= maxIndex {
+		// Index out-of-bounds, don't branch, just skip over immediate
+		// argument.
+		*pc += 1 + maxIndex*2
+		return nil, nil
+	}
+	offset := parseInt16(code[*pc+2+2*idx:])
+	// move pc past op and count byte (2), move past count number of 16bit offsets (count*2), add relative offset, subtract 1 to
+	// account for interpreter loop.
+	*pc = uint64(int64(*pc+2+maxIndex*2) + int64(offset) - 1)
+	return nil, nil
+}
+
+// opCallf implements the CALLF opcode
+func opCallf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code = scope.Contract.CodeAt(scope.CodeSection)
+		idx  = binary.BigEndian.Uint16(code[*pc+1:])
+		typ  = scope.Contract.Container.types[idx]
+	)
+	if scope.Stack.len()+int(typ.maxStackIncrease) > int(vars.StackLimit) {
+		return nil, &ErrStackOverflow{stackLen: scope.Stack.len(), limit: int(vars.StackLimit) - int(typ.maxStackIncrease)}
+	}
+	if len(scope.ReturnStack) >= returnStackLimit {
+		return nil, ErrReturnStackExceeded
+	}
+	scope.ReturnStack = append(scope.ReturnStack, &ReturnContext{
+		Section:     scope.CodeSection,
+		Pc:          *pc + 3,
+		StackHeight: scope.Stack.len() - int(typ.inputs),
+	})
+	scope.CodeSection = uint64(idx)
+	// The interpreter loop advances pc after every instruction, leave it one
+	// position before the first instruction of the callee.
+	*pc = math.MaxUint64
+	return nil, nil
+}
+
+// opRetf implements the RETF opcode
+func opRetf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	retCtx := scope.ReturnStack[len(scope.ReturnStack)-1]
+	scope.ReturnStack = scope.ReturnStack[:len(scope.ReturnStack)-1]
+	scope.CodeSection = retCtx.Section
+	*pc = retCtx.Pc - 1
+	return nil, nil
+}
+
+// opJumpf implements the JUMPF opcode
+func opJumpf(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code = scope.Contract.CodeAt(scope.CodeSection)
+		idx  = binary.BigEndian.Uint16(code[*pc+1:])
+		typ  = scope.Contract.Container.types[idx]
+	)
+	if scope.Stack.len()+int(typ.maxStackIncrease) > int(vars.StackLimit) {
+		return nil, &ErrStackOverflow{stackLen: scope.Stack.len(), limit: int(vars.StackLimit) - int(typ.maxStackIncrease)}
+	}
+	scope.CodeSection = uint64(idx)
+	// See opCallf.
+	*pc = math.MaxUint64
+	return nil, nil
+}
+
+// opDupN implements the DUPN opcode
+func opDupN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code  = scope.Contract.CodeAt(scope.CodeSection)
+		index = int(code[*pc+1]) + 1
+	)
+	if scope.Stack.len() < index {
+		return nil, &ErrStackUnderflow{stackLen: scope.Stack.len(), required: index}
+	}
+	scope.Stack.dup(index)
+	*pc += 1 // move past immediate
+	return nil, nil
+}
+
+// opSwapN implements the SWAPN opcode
+func opSwapN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code  = scope.Contract.CodeAt(scope.CodeSection)
+		index = int(code[*pc+1]) + 2
+	)
+	if scope.Stack.len() < index {
+		return nil, &ErrStackUnderflow{stackLen: scope.Stack.len(), required: index}
+	}
+	scope.Stack.swap(index)
+	*pc += 1 // move past immediate
+	return nil, nil
+}
+
+// opExchange implements the EXCHANGE opcode
+func opExchange(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code  = scope.Contract.CodeAt(scope.CodeSection)
+		index = int(code[*pc+1])
+		n     = (index >> 4) + 1
+		m     = (index & 0x0F) + 1
+	)
+	if scope.Stack.len() < n+m+1 {
+		return nil, &ErrStackUnderflow{stackLen: scope.Stack.len(), required: n + m + 1}
+	}
+	data := scope.Stack.Data()
+	top := len(data) - 1
+	data[top-n], data[top-n-m] = data[top-n-m], data[top-n]
+	*pc += 1 // move past immediate
+	return nil, nil
+}
+
+// opDataLoad implements the DATALOAD opcode
+func opDataLoad(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		stackItem        = scope.Stack.peek()
+		offset, overflow = stackItem.Uint64WithOverflow()
+	)
+	if overflow {
+		stackItem.Clear()
+	} else {
+		data := getData(scope.Contract.Container.data, offset, 32)
+		stackItem.SetBytes(data)
+	}
+	return nil, nil
+}
+
+// opDataLoadN implements the DATALOADN opcode
+func opDataLoadN(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code   = scope.Contract.CodeAt(scope.CodeSection)
+		offset = uint64(binary.BigEndian.Uint16(code[*pc+1:]))
+	)
+	data := getData(scope.Contract.Container.data, offset, 32)
+	scope.Stack.push(new(uint256.Int).SetBytes(data))
+	*pc += 2 // move past immediates
+	return nil, nil
+}
+
+// opDataSize implements the DATASIZE opcode
+func opDataSize(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	length := len(scope.Contract.Container.data)
+	scope.Stack.push(new(uint256.Int).SetUint64(uint64(length)))
+	return nil, nil
+}
+
+// opDataCopy implements the DATACOPY opcode
+func opDataCopy(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		memOffset = scope.Stack.pop()
+		offset    = scope.Stack.pop()
+		size      = scope.Stack.pop()
+	)
+	// These values are checked for overflow during memory expansion calculation
+	// (the memorySize function on the opcode).
+	offset64, overflow := offset.Uint64WithOverflow()
+	if overflow {
+		offset64 = math.MaxUint64
+	}
+	data := getData(scope.Contract.Container.data, offset64, size.Uint64())
+	scope.Memory.Set(memOffset.Uint64(), size.Uint64(), data)
+	return nil, nil
+}
+
+// opEOFCreate implements the EOFCREATE opcode
+func opEOFCreate(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	if interpreter.readOnly {
+		return nil, ErrWriteProtection
+	}
+	var (
+		code         = scope.Contract.CodeAt(scope.CodeSection)
+		idx          = code[*pc+1]
+		value        = scope.Stack.pop()
+		salt         = scope.Stack.pop()
+		offset, size = scope.Stack.pop(), scope.Stack.pop()
+		input        = scope.Memory.GetCopy(int64(offset.Uint64()), int64(size.Uint64()))
+		initcode     = scope.Contract.Container.subContainerCodes[idx]
+	)
+	*pc += 1 // move past immediate
+
+	// The initcontainer is hashed to derive the new contract address.
+	hashingCost := toWordSize(uint64(len(initcode))) * vars.Keccak256WordGas
+	if !scope.Contract.UseGas(hashingCost) {
+		return nil, ErrOutOfGas
+	}
+	gas := scope.Contract.Gas
+	gas -= gas / 64
+	scope.Contract.UseGas(gas)
+
+	// reuse size int for stackvalue
+	stackvalue := size
+	res, addr, returnGas, suberr := interpreter.evm.EOFCreate(scope.Contract, scope.Contract.Container.subContainers[idx], initcode, input, gas, &value, &salt)
+	// Push item on the stack based on the returned error.
+	if suberr != nil {
+		stackvalue.Clear()
+		interpreter.evm.CallErrorTemp = suberr // temp storage, for debug tracing
+	} else {
+		stackvalue.SetBytes(addr.Bytes())
+	}
+	scope.Stack.push(&stackvalue)
+	scope.Contract.Gas += returnGas
+
+	if suberr == ErrExecutionReverted {
+		interpreter.returnData = res // set REVERT data to return data buffer
+		return res, nil
+	}
+	interpreter.returnData = nil // clear dirty return data buffer
+	return nil, nil
+}
+
+// opReturnContract implements the RETURNCONTRACT opcode
+func opReturnContract(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		code         = scope.Contract.CodeAt(scope.CodeSection)
+		idx          = code[*pc+1]
+		offset, size = scope.Stack.pop(), scope.Stack.pop()
+		aux          = scope.Memory.GetPtr(int64(offset.Uint64()), int64(size.Uint64()))
+	)
+	ret, err := scope.Contract.Container.subContainers[idx].withAuxData(aux)
+	if err != nil {
+		return nil, err
+	}
+	return ret, errStopToken
+}
+
+// opReturnDataLoad implements the RETURNDATALOAD opcode
+func opReturnDataLoad(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		stackItem        = scope.Stack.peek()
+		offset, overflow = stackItem.Uint64WithOverflow()
+	)
+	if overflow {
+		offset = math.MaxUint64
+	}
+	stackItem.SetBytes(getData(interpreter.returnData, offset, 32))
+	return nil, nil
+}
+
+// opReturnDataCopyEOF implements RETURNDATACOPY for EOF code, where reading
+// past the end of the return data is padded with zeroes instead of failing.
+func opReturnDataCopyEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		memOffset  = scope.Stack.pop()
+		dataOffset = scope.Stack.pop()
+		length     = scope.Stack.pop()
+	)
+	offset64, overflow := dataOffset.Uint64WithOverflow()
+	if overflow {
+		offset64 = math.MaxUint64
+	}
+	scope.Memory.Set(memOffset.Uint64(), length.Uint64(), getData(interpreter.returnData, offset64, length.Uint64()))
+	return nil, nil
+}
+
+// opExtCall implements the EXTCALL opcode
+func opExtCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		stack                  = scope.Stack
+		addr, inOffset, inSize = stack.pop(), stack.pop(), stack.pop()
+		value                  = stack.pop()
+		toAddr                 = common.Address(addr.Bytes20())
+		args                   = scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+	)
+	if interpreter.readOnly && !value.IsZero() {
+		return nil, ErrWriteProtection
+	}
+	gas, ok := extCallGas(interpreter, scope)
+	if !ok || !value.IsZero() && !interpreter.evm.Context.CanTransfer(interpreter.evm.StateDB, scope.Contract.Address(), &value) {
+		return extCallLightFailure(interpreter, scope)
+	}
+	ret, returnGas, err := interpreter.evm.Call(scope.Contract, toAddr, args, gas, &value)
+	return extCallResult(interpreter, scope, ret, returnGas, err)
+}
+
+// opExtDelegateCall implements the EXTDELEGATECALL opcode
+func opExtDelegateCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		stack                  = scope.Stack
+		addr, inOffset, inSize = stack.pop(), stack.pop(), stack.pop()
+		toAddr                 = common.Address(addr.Bytes20())
+		args                   = scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+	)
+	gas, ok := extCallGas(interpreter, scope)
+	// Delegating to legacy code is not allowed.
+	if !ok || !hasEOFMagic(interpreter.evm.resolveCode(toAddr)) {
+		return extCallLightFailure(interpreter, scope)
+	}
+	ret, returnGas, err := interpreter.evm.DelegateCall(scope.Contract, toAddr, args, gas)
+	return extCallResult(interpreter, scope, ret, returnGas, err)
+}
+
+// opExtStaticCall implements the EXTSTATICCALL opcode
+func opExtStaticCall(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		stack                  = scope.Stack
+		addr, inOffset, inSize = stack.pop(), stack.pop(), stack.pop()
+		toAddr                 = common.Address(addr.Bytes20())
+		args                   = scope.Memory.GetPtr(int64(inOffset.Uint64()), int64(inSize.Uint64()))
+	)
+	gas, ok := extCallGas(interpreter, scope)
+	if !ok {
+		return extCallLightFailure(interpreter, scope)
+	}
+	ret, returnGas, err := interpreter.evm.StaticCall(scope.Contract, toAddr, args, gas)
+	return extCallResult(interpreter, scope, ret, returnGas, err)
+}
+
+// extCallGas returns the gas passed to the callee of an EXT*CALL, and whether
+// the call may proceed. The caller retains 1/64th of its gas, and at least
+// ExtCallMinRetainedGas, while the callee must receive ExtCallMinCalleeGas.
+func extCallGas(interpreter *EVMInterpreter, scope *ScopeContext) (uint64, bool) {
+	available := scope.Contract.Gas
+	retained := max(available/64, vars.ExtCallMinRetainedGas)
+	if available < retained+vars.ExtCallMinCalleeGas || interpreter.evm.depth > int(vars.CallCreateDepth) {
+		return 0, false
+	}
+	gas := available - retained
+	scope.Contract.UseGas(gas)
+	return gas, true
+}
+
+// extCallLightFailure pushes the status of an EXT*CALL which failed before
+// the callee was entered. No gas is consumed beyond the instruction cost.
+func extCallLightFailure(interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	interpreter.returnData = nil
+	scope.Stack.push(new(uint256.Int).SetOne())
+	return nil, nil
+}
+
+// extCallResult pushes the status of a completed EXT*CALL: 0 on success, 1 on
+// revert and 2 on failure.
+func extCallResult(interpreter *EVMInterpreter, scope *ScopeContext, ret []byte, returnGas uint64, err error) ([]byte, error) {
+	status := new(uint256.Int)
+	switch err {
+	case nil:
+	case ErrExecutionReverted, ErrDepth, ErrInsufficientBalance:
+		status.SetOne()
+		interpreter.evm.CallErrorTemp = err // temp storage, for debug tracing
+	default:
+		status.SetUint64(2)
+		interpreter.evm.CallErrorTemp = err // temp storage, for debug tracing
+	}
+	scope.Stack.push(status)
+	scope.Contract.Gas += returnGas
+
+	interpreter.returnData = ret
+	return nil, nil
+}
+
+// opExtCodeSizeEOF implements EXTCODESIZE for legacy code, where EOF
+// accounts are reported as having the 2 byte code 0xEF00.
+func opExtCodeSizeEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	slot := scope.Stack.peek()
+	code := interpreter.evm.StateDB.GetCode(slot.Bytes20())
+	if hasEOFMagic(code) {
+		slot.SetUint64(uint64(len(eofMagic)))
+	} else {
+		slot.SetUint64(uint64(len(code)))
+	}
+	return nil, nil
+}
+
+// opExtCodeCopyEOF implements EXTCODECOPY for legacy code, where the code of
+// EOF accounts is presented as 0xEF00.
+func opExtCodeCopyEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	var (
+		stack      = scope.Stack
+		a          = stack.pop()
+		memOffset  = stack.pop()
+		codeOffset = stack.pop()
+		length     = stack.pop()
+	)
+	uint64CodeOffset, overflow := codeOffset.Uint64WithOverflow()
+	if overflow {
+		uint64CodeOffset = math.MaxUint64
+	}
+	code := interpreter.evm.StateDB.GetCode(a.Bytes20())
+	if hasEOFMagic(code) {
+		code = eofMagic
+	}
+	codeCopy := getData(code, uint64CodeOffset, length.Uint64())
+	scope.Memory.Set(memOffset.Uint64(), length.Uint64(), codeCopy)
+	return nil, nil
+}
+
+// opExtCodeHashEOF implements EXTCODEHASH for legacy code, where EOF accounts
+// are reported with the hash of 0xEF00.
+func opExtCodeHashEOF(pc *uint64, interpreter *EVMInterpreter, scope *ScopeContext) ([]byte, error) {
+	slot := scope.Stack.peek()
+	address := common.Address(slot.Bytes20())
+	if interpreter.evm.StateDB.Empty(address) {
+		slot.Clear()
+	} else if code := interpreter.evm.StateDB.GetCode(address); hasEOFMagic(code) {
+		slot.SetBytes(crypto.Keccak256(eofMagic))
+	} else {
+		slot.SetBytes(interpreter.evm.StateDB.GetCodeHash(address).Bytes())
+	}
+	return nil, nil
+}
+
+func parseInt16(b []byte) int16 {
+	return int16(b[1]) | int16(b[0])<<8
+}
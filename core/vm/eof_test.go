@@ -0,0 +1,459 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func newEOFInstructionSetForTesting(t *testing.T) *JumpTable {
+	t.Helper()
+	config := *params.AllDevChainProtocolChanges
+	config.EOFBlock = big.NewInt(0)
+	jt, err := LookupEOFInstructionSet(&config, big.NewInt(0), new(uint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &jt
+}
+
+func TestEOFMarshaling(t *testing.T) {
+	runtime := &Container{
+		types:        []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 0}},
+		codeSections: [][]byte{common.Hex2Bytes("00")},
+		data:         []byte{0x01, 0x02},
+		dataSize:     4,
+	}
+	runtimeCode := runtime.MarshalBinary()
+	for i, test := range []struct {
+		want Container
+		err  error
+	}{
+		{
+			want: Container{
+				types:        []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+				codeSections: [][]byte{common.Hex2Bytes("604200")},
+				data:         []byte{0x01, 0x02, 0x03},
+				dataSize:     3,
+			},
+		},
+		{
+			want: Container{
+				types: []*functionMetadata{
+					{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1},
+					{inputs: 2, outputs: 3, maxStackIncrease: 4},
+					{inputs: 1, outputs: 1, maxStackIncrease: 1},
+				},
+				codeSections: [][]byte{
+					common.Hex2Bytes("604200"),
+					common.Hex2Bytes("6042604200"),
+					common.Hex2Bytes("00"),
+				},
+				data: []byte{},
+			},
+		},
+		{
+			want: Container{
+				types:             []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 2}},
+				codeSections:      [][]byte{common.Hex2Bytes("5f5fee00")},
+				subContainers:     []*Container{runtime},
+				subContainerCodes: [][]byte{runtimeCode},
+				data:              []byte{},
+			},
+		},
+	} {
+		var (
+			b   = test.want.MarshalBinary()
+			got Container
+		)
+		if err := got.UnmarshalBinary(b); err != nil && err != test.err {
+			t.Fatalf("test %d: got error \"%v\", want \"%v\"", i, err, test.err)
+		}
+		if !bytes.Equal(got.MarshalBinary(), b) {
+			t.Fatalf("test %d: round trip mismatch: have %x, want %x", i, got.MarshalBinary(), b)
+		}
+		if len(got.subContainers) != len(test.want.subContainers) {
+			t.Fatalf("test %d: have %d subcontainers, want %d", i, len(got.subContainers), len(test.want.subContainers))
+		}
+	}
+}
+
+func TestEOFUnmarshalErrors(t *testing.T) {
+	for i, test := range []struct {
+		code string
+		err  error
+	}{
+		{"ef0001010004020001", io.ErrUnexpectedEOF},
+		{"ef01010100040200010001ff00000000800000fe", errInvalidMagic},
+		{"ef00020100040200010001ff00000000800000fe", errInvalidVersion},
+		{"ef00010200040200010001ff00000000800000fe", errMissingTypeHeader},
+		{"ef00010100030200010001ff00000000800000fe", errInvalidTypeSize},
+		{"ef00010100040300010001ff00000000800000fe", errMissingCodeHeader},
+		{"ef00010100040200010001fe00000000800000fe", errMissingDataHeader},
+		{"ef00010100040200010001ff00000100800000fe", errMissingTerminator},
+		{"ef00010100040200010001ff00000000000000fe", errInvalidSection0Type},
+		{"ef00010100040200010001ff00000000800400fe", errTooLargeMaxStackHeight},
+		{"ef00010100040200010001ff00020000800000fe", errTruncatedTopLevelContainer},
+		{"ef00010100040200010001ff00000000800000fefe", errInvalidContainerSize},
+		{"ef0001010004020000ff00000000800000", errEmptySectionList},
+	} {
+		var c Container
+		if err := c.UnmarshalBinary(common.FromHex(test.code)); !errors.Is(err, test.err) {
+			t.Errorf("test %d: have error %v, want %v", i, err, test.err)
+		}
+	}
+}
+
+func TestEOFUnmarshalInitcode(t *testing.T) {
+	code := common.FromHex("ef00010100040200010001ff00000000800000fe")
+	var c Container
+	input, err := c.UnmarshalInitcode(append(code, 0xaa, 0xbb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(input, []byte{0xaa, 0xbb}) {
+		t.Fatalf("have calldata %x, want aabb", input)
+	}
+}
+
+func TestValidateCode(t *testing.T) {
+	jt := newEOFInstructionSetForTesting(t)
+	for i, test := range []struct {
+		code     []byte
+		metadata []*functionMetadata
+		dataSize int
+		err      error
+	}{
+		{
+			code:     []byte{byte(CALLER), byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+		},
+		{
+			code:     []byte{byte(CALLF), 0x00, 0x00, byte(RETF)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0, maxStackIncrease: 0}},
+		},
+		{
+			code:     []byte{byte(ADDRESS), byte(CALLF), 0x00, 0x00, byte(POP), byte(RETF)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0, maxStackIncrease: 1}},
+		},
+		{
+			code:     []byte{byte(CALLER), byte(POP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			err:      errInvalidCodeTermination,
+		},
+		{
+			code: []byte{
+				byte(RJUMP),
+				byte(0x00),
+				byte(0x01),
+				byte(CALLER),
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 0}},
+			err:      errUnreachableCode,
+		},
+		{
+			code: []byte{
+				byte(PUSH1),
+				byte(0x42),
+				byte(ADD),
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			err:      errStackUnderflow,
+		},
+		{
+			code: []byte{
+				byte(PUSH1),
+				byte(0x42),
+				byte(POP),
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 2}},
+			err:      errInvalidMaxStackHeight,
+		},
+		{
+			code: []byte{
+				byte(PUSH0),
+				byte(RJUMPI),
+				byte(0x00),
+				byte(0x01),
+				byte(PUSH1),
+				byte(0x42), // jumps to here
+				byte(POP),
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			err:      errInvalidJumpDest,
+		},
+		{
+			code: []byte{
+				byte(PUSH0),
+				byte(RJUMPV),
+				byte(0x01),
+				byte(0x00),
+				byte(0x01),
+				byte(0x00),
+				byte(0x02),
+				byte(PUSH1),
+				byte(0x42), // jumps to here
+				byte(POP),  // and here
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			err:      errInvalidJumpDest,
+		},
+		{
+			code: []byte{
+				byte(PUSH0),
+				byte(RJUMPV),
+				byte(0x00),
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			err:      errTruncatedImmediate,
+		},
+		{
+			code: []byte{
+				byte(RJUMP), 0x00, 0x03,
+				byte(JUMPDEST), // this code is unreachable to forward jumps alone
+				byte(JUMPDEST),
+				byte(RETURN),
+				byte(PUSH1), 20,
+				byte(PUSH1), 39,
+				byte(PUSH1), 0x00,
+				byte(DATACOPY),
+				byte(PUSH1), 20,
+				byte(PUSH1), 0x00,
+				byte(RJUMP), 0xff, 0xef,
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 3}},
+			err:      errUnreachableCode,
+		},
+		{
+			code: []byte{
+				byte(PUSH1), 1,
+				byte(RJUMPI), 0x00, 0x03,
+				byte(JUMPDEST),
+				byte(JUMPDEST),
+				byte(STOP),
+				byte(PUSH1), 20,
+				byte(PUSH1), 39,
+				byte(PUSH1), 0x00,
+				byte(DATACOPY),
+				byte(PUSH1), 20,
+				byte(PUSH1), 0x00,
+				byte(RETURN),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 3}},
+		},
+		{
+			code: []byte{
+				byte(PUSH0),
+				byte(PUSH0),
+				byte(RJUMPV), 0x01, 0x00, 0x03, 0xff, 0xf8, // jumps back to the start with a different stack height
+				byte(JUMPDEST),
+				byte(JUMPDEST),
+				byte(STOP),
+				byte(PUSH1), 20,
+				byte(PUSH1), 0x00,
+				byte(RETURN),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 3}},
+			err:      errInvalidBackwardJump,
+		},
+		{
+			code: []byte{
+				byte(STOP),
+				byte(STOP),
+				byte(INVALID),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 0}},
+			err:      errUnreachableCode,
+		},
+		{
+			code: []byte{
+				byte(RETF),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 1, maxStackIncrease: 0}},
+			err:      errInvalidOutputs,
+		},
+		{
+			code: []byte{
+				byte(RETF),
+			},
+			metadata: []*functionMetadata{{inputs: 3, outputs: 3, maxStackIncrease: 0}},
+		},
+		{
+			code: []byte{
+				byte(CALLF), 0x00, 0x01,
+				byte(POP),
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}, {inputs: 0, outputs: 1, maxStackIncrease: 0}},
+		},
+		{
+			code: []byte{
+				byte(ORIGIN),
+				byte(ORIGIN),
+				byte(CALLF), 0x00, 0x01,
+				byte(POP),
+				byte(RETF),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: 0, maxStackIncrease: 2}, {inputs: 2, outputs: 1, maxStackIncrease: 0}},
+		},
+		{
+			code: []byte{
+				byte(PUSH0),
+				byte(DUPN), 0x00,
+				byte(SWAPN), 0x00,
+				byte(EXCHANGE), 0x00,
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 2}},
+			err:      errStackUnderflow,
+		},
+		{
+			code: []byte{
+				byte(PUSH0),
+				byte(PUSH0),
+				byte(PUSH0),
+				byte(DUPN), 0x02,
+				byte(SWAPN), 0x01,
+				byte(EXCHANGE), 0x00,
+				byte(STOP),
+			},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 4}},
+		},
+		{
+			code:     []byte{byte(DATALOADN), 0x00, 0x01, byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			dataSize: 32,
+			err:      errInvalidDataloadNArgument,
+		},
+		{
+			code:     []byte{byte(DATALOADN), 0x00, 0x00, byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			dataSize: 32,
+		},
+		{
+			code:     []byte{byte(GAS), byte(POP), byte(STOP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			err:      errUndefinedInstruction,
+		},
+		{
+			code:     []byte{byte(PUSH1), 0x00, byte(JUMP)},
+			metadata: []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 1}},
+			err:      errUndefinedInstruction,
+		},
+	} {
+		container := &Container{
+			types:        test.metadata,
+			data:         make([]byte, test.dataSize),
+			dataSize:     test.dataSize,
+			codeSections: make([][]byte, len(test.metadata)),
+		}
+		for j := range container.codeSections {
+			container.codeSections[j] = test.code
+		}
+		_, err := validateCode(test.code, 0, container, jt, false)
+		if !errors.Is(err, test.err) {
+			t.Errorf("test %d (%s): unexpected error (want: %v, got: %v)", i, common.Bytes2Hex(test.code), test.err, err)
+		}
+	}
+}
+
+func TestValidateContainer(t *testing.T) {
+	jt := newEOFInstructionSetForTesting(t)
+	var (
+		runtime = &Container{
+			types:        []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 0}},
+			codeSections: [][]byte{{byte(INVALID)}},
+			data:         []byte{},
+		}
+		truncated = &Container{
+			types:        []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 0}},
+			codeSections: [][]byte{{byte(INVALID)}},
+			data:         []byte{},
+			dataSize:     2,
+		}
+	)
+	initcode := func(height uint16, code []byte, subs ...*Container) *Container {
+		c := &Container{
+			types:         []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: height}},
+			codeSections:  [][]byte{code},
+			subContainers: subs,
+			data:          []byte{},
+		}
+		for _, sub := range subs {
+			c.subContainerCodes = append(c.subContainerCodes, sub.MarshalBinary())
+		}
+		return c
+	}
+	for i, test := range []struct {
+		container  *Container
+		isInitCode bool
+		err        error
+	}{
+		// Deploying a (truncated) container from initcode.
+		{initcode(2, []byte{byte(PUSH0), byte(PUSH0), byte(RETURNCONTRACT), 0x00}, truncated), true, nil},
+		// RETURNCONTRACT is not allowed in runtime code.
+		{initcode(2, []byte{byte(PUSH0), byte(PUSH0), byte(RETURNCONTRACT), 0x00}, runtime), false, errIncompatibleContainerKind},
+		// STOP is not allowed in initcode.
+		{initcode(0, []byte{byte(STOP)}), true, errIncompatibleContainerKind},
+		// Every subcontainer must be referenced.
+		{initcode(2, []byte{byte(PUSH0), byte(PUSH0), byte(RETURNCONTRACT), 0x00}, runtime, runtime), true, errOrphanedSubcontainer},
+		// EOFCREATE of a truncated container.
+		{initcode(4, []byte{byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(EOFCREATE), 0x00, byte(POP), byte(STOP)}, truncated), false, errEOFCreateWithTruncatedSection},
+		// A subcontainer referenced as both initcode and runtime code.
+		{initcode(4, []byte{byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(PUSH0), byte(EOFCREATE), 0x00, byte(POP), byte(PUSH0), byte(PUSH0), byte(RETURNCONTRACT), 0x00}, runtime), true, errAmbiguousContainer},
+	} {
+		if err := test.container.ValidateCode(jt, test.isInitCode); !errors.Is(err, test.err) {
+			t.Errorf("test %d: unexpected error (want: %v, got: %v)", i, test.err, err)
+		}
+	}
+}
+
+func TestWithAuxData(t *testing.T) {
+	c := &Container{
+		types:        []*functionMetadata{{inputs: 0, outputs: nonReturningFunction, maxStackIncrease: 0}},
+		codeSections: [][]byte{{byte(INVALID)}},
+		data:         []byte{0x01},
+		dataSize:     3,
+	}
+	if _, err := c.withAuxData([]byte{0x02}); !errors.Is(err, errTruncatedData) {
+		t.Fatalf("have error %v, want %v", err, errTruncatedData)
+	}
+	deployed, err := c.withAuxData([]byte{0x02, 0x03, 0x04})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Container
+	if err := got.UnmarshalBinary(deployed); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.data, []byte{0x01, 0x02, 0x03, 0x04}) || got.dataSize != 4 {
+		t.Fatalf("have data %x (size %d), want 01020304 (size 4)", got.data, got.dataSize)
+	}
+}
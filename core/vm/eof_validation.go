@@ -0,0 +1,350 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// Below are the different ways a subcontainer can be referenced from the code
+// of its parent container.
+const (
+	notRefByEither = iota
+	refByReturnContract
+	refByEOFCreate
+)
+
+// validationResult collects the code sections and subcontainers referenced
+// from a single code section.
+type validationResult struct {
+	visitedCode          map[int]struct{}
+	visitedSubContainers map[int]int
+}
+
+// ValidateCode validates each code section of the container against the EOF
+// v1 rules, recursing into its subcontainers. Initcode containers, executed by
+// creation transactions and EOFCREATE, may not contain RETURN or STOP, and
+// runtime containers may not contain RETURNCONTRACT.
+func (c *Container) ValidateCode(jt *JumpTable, isInitCode bool) error {
+	refBy := refByReturnContract
+	if isInitCode {
+		refBy = refByEOFCreate
+	}
+	return c.validateSubContainer(jt, refBy)
+}
+
+func (c *Container) validateSubContainer(jt *JumpTable, refBy int) error {
+	var (
+		visited    = make(map[int]struct{})
+		subRefs    = make(map[int]int)
+		toVisit    = []int{0}
+		isInitCode = refBy == refByEOFCreate
+	)
+	for len(toVisit) > 0 {
+		// Pop the next section to validate.
+		index := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+		if _, ok := visited[index]; ok {
+			continue
+		}
+		res, err := validateCode(c.codeSections[index], index, c, jt, isInitCode)
+		if err != nil {
+			return err
+		}
+		visited[index] = struct{}{}
+		// Queue the code sections referenced from this one.
+		for idx := range res.visitedCode {
+			if _, ok := visited[idx]; !ok {
+				toVisit = append(toVisit, idx)
+			}
+		}
+		// Record how the subcontainers are referenced.
+		for idx, reference := range res.visitedSubContainers {
+			if existing, ok := subRefs[idx]; ok && existing != reference {
+				return fmt.Errorf("%w: subcontainer %d", errAmbiguousContainer, idx)
+			}
+			subRefs[idx] = reference
+		}
+	}
+	if len(visited) != len(c.codeSections) {
+		return fmt.Errorf("%w: reached %d of %d sections", errUnreachableCodeSections, len(visited), len(c.codeSections))
+	}
+	// Validate all subcontainers in the mode they are referenced with.
+	for idx, sub := range c.subContainers {
+		reference, ok := subRefs[idx]
+		if !ok {
+			return fmt.Errorf("%w: subcontainer %d", errOrphanedSubcontainer, idx)
+		}
+		if reference == refByEOFCreate && len(sub.data) != sub.dataSize {
+			return fmt.Errorf("%w: subcontainer %d", errEOFCreateWithTruncatedSection, idx)
+		}
+		if err := sub.validateSubContainer(jt, reference); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateCode validates the code of a single code section against the EOF
+// v1 rules, and returns the sections and subcontainers referenced from it.
+func validateCode(code []byte, section int, container *Container, jt *JumpTable, isInitCode bool) (*validationResult, error) {
+	var (
+		i           = 0
+		op          OpCode
+		analysis    = make(bitvec, len(code)/8+1+4)
+		jumpTargets []int
+		hasReturn   bool
+		result      = &validationResult{
+			visitedCode:          make(map[int]struct{}),
+			visitedSubContainers: make(map[int]int),
+		}
+	)
+	// Walk the instructions, checking opcodes, immediates and arguments, and
+	// collecting the relative jump targets.
+	for i < len(code) {
+		op = OpCode(code[i])
+		if jt[op].undefined {
+			return nil, fmt.Errorf("%w: op %s, pos %d", errUndefinedInstruction, op, i)
+		}
+		size := int(immediates[op])
+		if size != 0 && len(code) <= i+size {
+			return nil, fmt.Errorf("%w: op %s, pos %d", errTruncatedImmediate, op, i)
+		}
+		switch op {
+		case RJUMP, RJUMPI:
+			jumpTargets = append(jumpTargets, i+3+int(int16(binary.BigEndian.Uint16(code[i+1:]))))
+		case RJUMPV:
+			maxIndex := int(code[i+1])
+			size = 1 + 2*(maxIndex+1)
+			if len(code) <= i+size {
+				return nil, fmt.Errorf("%w: jump table truncated, op %s, pos %d", errTruncatedImmediate, op, i)
+			}
+			for j := 0; j <= maxIndex; j++ {
+				offset := int(int16(binary.BigEndian.Uint16(code[i+2+2*j:])))
+				jumpTargets = append(jumpTargets, i+1+size+offset)
+			}
+		case CALLF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(container.types) {
+				return nil, fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(container.types), i)
+			}
+			if !container.types[arg].returning() {
+				return nil, fmt.Errorf("%w: section %v", errInvalidCallArgument, arg)
+			}
+			result.visitedCode[arg] = struct{}{}
+		case RETF:
+			hasReturn = true
+		case JUMPF:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg >= len(container.types) {
+				return nil, fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidSectionArgument, arg, len(container.types), i)
+			}
+			if container.types[arg].returning() {
+				if container.types[section].outputs < container.types[arg].outputs {
+					return nil, fmt.Errorf("%w: section %v", errJUMPFOutputs, arg)
+				}
+				hasReturn = true
+			}
+			result.visitedCode[arg] = struct{}{}
+		case DATALOADN:
+			arg := int(binary.BigEndian.Uint16(code[i+1:]))
+			if arg+32 > container.dataSize {
+				return nil, fmt.Errorf("%w: arg %d, data size %d, pos %d", errInvalidDataloadNArgument, arg, container.dataSize, i)
+			}
+		case RETURNCONTRACT, EOFCREATE:
+			arg := int(code[i+1])
+			if arg >= len(container.subContainers) {
+				return nil, fmt.Errorf("%w: arg %d, last %d, pos %d", errInvalidContainerArgument, arg, len(container.subContainers), i)
+			}
+			reference := refByEOFCreate
+			if op == RETURNCONTRACT {
+				if !isInitCode {
+					return nil, fmt.Errorf("%w: %s in runtime code, pos %d", errIncompatibleContainerKind, op, i)
+				}
+				reference = refByReturnContract
+			}
+			if existing, ok := result.visitedSubContainers[arg]; ok && existing != reference {
+				return nil, fmt.Errorf("%w: subcontainer %d", errAmbiguousContainer, arg)
+			}
+			result.visitedSubContainers[arg] = reference
+		case RETURN, STOP:
+			if isInitCode {
+				return nil, fmt.Errorf("%w: %s in initcode, pos %d", errIncompatibleContainerKind, op, i)
+			}
+		}
+		// Mark the immediate bytes as data.
+		for j := 1; j <= size; j++ {
+			analysis.set1(uint64(i + j))
+		}
+		i += size + 1
+	}
+	// Relative jumps must land on an instruction within the section.
+	for _, target := range jumpTargets {
+		if target < 0 || target >= len(code) || !analysis.codeSegment(uint64(target)) {
+			return nil, fmt.Errorf("%w: target %d, section %d", errInvalidJumpDest, target, section)
+		}
+	}
+	// A section returns control to its caller if and only if it is declared
+	// returning.
+	if hasReturn != container.types[section].returning() {
+		return nil, fmt.Errorf("%w: section %d", errInvalidNonReturning, section)
+	}
+	maxStackHeight, err := validateControlFlow(code, section, container.types, jt)
+	if err != nil {
+		return nil, err
+	}
+	if want := int(container.types[section].inputs) + int(container.types[section].maxStackIncrease); maxStackHeight != want {
+		return nil, fmt.Errorf("%w in code section %d: have %d, want %d", errInvalidMaxStackHeight, section, maxStackHeight, want)
+	}
+	return result, nil
+}
+
+// validateControlFlow iterates through all possible branches of the provided
+// code section and ensures that each instruction is reachable, that the stack
+// height is consistent across the branches leading to it, and that the stack
+// never underflows nor overflows. It returns the maximum stack height reached.
+func validateControlFlow(code []byte, section int, metadata []*functionMetadata, jt *JumpTable) (int, error) {
+	var (
+		maxStackHeight = int(metadata[section].inputs)
+		stackBounds    = make(map[int]*bounds, len(code))
+	)
+	stackBounds[0] = &bounds{maxStackHeight, maxStackHeight}
+
+	// visit records the stack bounds at a successor of the instruction at pos.
+	visit := func(pos, target, lo, hi int) error {
+		if target > pos {
+			// Forward jump or fallthrough, merge the bounds.
+			if b, ok := stackBounds[target]; ok {
+				b.min = min(b.min, lo)
+				b.max = max(b.max, hi)
+			} else {
+				stackBounds[target] = &bounds{lo, hi}
+			}
+			return nil
+		}
+		// Backward jumps must match the bounds recorded at the target exactly.
+		if b, ok := stackBounds[target]; !ok || b.min != lo || b.max != hi {
+			return fmt.Errorf("%w from %d to %d", errInvalidBackwardJump, pos, target)
+		}
+		return nil
+	}
+	for pos := 0; pos < len(code); {
+		b, ok := stackBounds[pos]
+		if !ok {
+			return 0, fmt.Errorf("%w: section %d, pos %d", errUnreachableCode, section, pos)
+		}
+		var (
+			op          = OpCode(code[pos])
+			currentMin  = b.min
+			currentMax  = b.max
+			inputs      = jt[op].minStack
+			outputs     = int(vars.StackLimit) + jt[op].minStack - jt[op].maxStack
+			size        = int(immediates[op])
+			isTerminal  = terminals[op]
+			isUncondJmp = op == RJUMP
+		)
+		switch op {
+		case CALLF:
+			arg := int(binary.BigEndian.Uint16(code[pos+1:]))
+			if err := metadata[arg].checkInputs(currentMin); err != nil {
+				return 0, fmt.Errorf("%w: at pos %d", err, pos)
+			}
+			if err := metadata[arg].checkStackMax(currentMax); err != nil {
+				return 0, fmt.Errorf("%w: at pos %d", err, pos)
+			}
+			inputs, outputs = int(metadata[arg].inputs), int(metadata[arg].outputs)
+		case RETF:
+			if currentMin != currentMax || currentMax != int(metadata[section].outputs) {
+				return 0, fmt.Errorf("%w: have %d-%d, want %d, at pos %d", errInvalidOutputs, currentMin, currentMax, metadata[section].outputs, pos)
+			}
+			inputs, outputs = 0, 0
+		case JUMPF:
+			arg := int(binary.BigEndian.Uint16(code[pos+1:]))
+			if err := metadata[arg].checkStackMax(currentMax); err != nil {
+				return 0, fmt.Errorf("%w: at pos %d", err, pos)
+			}
+			if metadata[arg].returning() {
+				want := int(metadata[section].outputs) + int(metadata[arg].inputs) - int(metadata[arg].outputs)
+				if currentMin != currentMax || currentMax != want {
+					return 0, fmt.Errorf("%w: have %d-%d, want %d, at pos %d", errInvalidOutputs, currentMin, currentMax, want, pos)
+				}
+			} else if err := metadata[arg].checkInputs(currentMin); err != nil {
+				return 0, fmt.Errorf("%w: at pos %d", err, pos)
+			}
+			inputs, outputs = 0, 0
+		case DUPN:
+			arg := int(code[pos+1]) + 1
+			inputs, outputs = arg, arg+1
+		case SWAPN:
+			arg := int(code[pos+1]) + 1
+			inputs, outputs = arg+1, arg+1
+		case EXCHANGE:
+			n, m := int(code[pos+1]>>4)+1, int(code[pos+1]&0x0f)+1
+			inputs, outputs = n+m+1, n+m+1
+		case RJUMPV:
+			size = 1 + 2*(int(code[pos+1])+1)
+		}
+		if currentMin < inputs {
+			return 0, fmt.Errorf("%w: at pos %d", errStackUnderflow, pos)
+		}
+		newMin, newMax := currentMin-inputs+outputs, currentMax-inputs+outputs
+		if newMax > int(vars.StackLimit) {
+			return 0, fmt.Errorf("%w: at pos %d", errStackOverflow, pos)
+		}
+		maxStackHeight = max(maxStackHeight, newMax)
+
+		next := pos + size + 1
+		switch op {
+		case RJUMP:
+			target := next + int(int16(binary.BigEndian.Uint16(code[pos+1:])))
+			if err := visit(pos, target, newMin, newMax); err != nil {
+				return 0, err
+			}
+		case RJUMPI:
+			target := next + int(int16(binary.BigEndian.Uint16(code[pos+1:])))
+			if err := visit(pos, target, newMin, newMax); err != nil {
+				return 0, err
+			}
+		case RJUMPV:
+			for j := 0; j <= int(code[pos+1]); j++ {
+				target := next + int(int16(binary.BigEndian.Uint16(code[pos+2+2*j:])))
+				if err := visit(pos, target, newMin, newMax); err != nil {
+					return 0, err
+				}
+			}
+		}
+		if !isTerminal && !isUncondJmp {
+			if next >= len(code) {
+				return 0, fmt.Errorf("%w: section %d", errInvalidCodeTermination, section)
+			}
+			if err := visit(pos, next, newMin, newMax); err != nil {
+				return 0, err
+			}
+		}
+		pos = next
+	}
+	return maxStackHeight, nil
+}
+
+// bounds is the range of stack heights an instruction may be reached with.
+type bounds struct {
+	min int
+	max int
+}
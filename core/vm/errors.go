@@ -37,6 +37,10 @@ var (
 	ErrGasUintOverflow          = errors.New("gas uint64 overflow")
 	ErrInvalidCode              = errors.New("invalid code: must not begin with 0xef")
 	ErrNonceUintOverflow        = errors.New("nonce uint64 overflow")
+	ErrInvalidEOFInitcode       = errors.New("invalid eof initcode")
+	ErrInvalidEOFCode           = errors.New("invalid eof code")
+	ErrReturnStackExceeded      = errors.New("return stack limit reached")
+	ErrInvalidAddress           = errors.New("invalid address: high bytes must be zero")
 
 	// errStopToken is an internal token indicating interpreter loop termination,
 	// never returned to outside callers.
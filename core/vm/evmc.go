@@ -342,33 +342,18 @@ func (host *hostContext) Call(kind evmc.CallKind,
 }
 
 // getRevision translates ChainConfig's HF block information into EVMC revision.
+// Precompiles are not part of the revision: calls to them are made through the
+// host (see hostContext.Call), so features adding precompiles only, like the
+// RIP-7212 P256VERIFY precompile, apply to EVMC interpreters as configured.
+//
+// The mapping is lossy for chains activating features out of the canonical
+// Ethereum order; see EVMCRevisionGaps for the features it cannot represent.
 func getRevision(env *EVM) evmc.Revision {
-	n := env.Context.BlockNumber
-	conf := env.ChainConfig()
-	switch {
-	// This is an example of choosing to use an "abstracted" idea
-	// about chain config, where I'm choosing to prioritize "indicative" features
-	// as identifiers for Fork-Feature-Groups. Note that this is very different
-	// than using Feature-complete sets to assert "did Forkage."
-	case conf.IsEnabled(conf.GetEIP2565Transition, n):
+	rev := evmcRevision(env.ChainConfig(), env.Context.BlockNumber)
+	if rev > evmcMaxRevision {
 		panic("berlin is unsupported by EVMCv7")
-	case conf.IsEnabled(conf.GetEIP1884Transition, n):
-		return evmc.Istanbul
-	case conf.IsEnabled(conf.GetEIP1283DisableTransition, n):
-		return evmc.Petersburg
-	case conf.IsEnabled(conf.GetEIP145Transition, n):
-		return evmc.Constantinople
-	case conf.IsEnabled(conf.GetEIP198Transition, n):
-		return evmc.Byzantium
-	case conf.IsEnabled(conf.GetEIP155Transition, n):
-		return evmc.SpuriousDragon
-	case conf.IsEnabled(conf.GetEIP150Transition, n):
-		return evmc.TangerineWhistle
-	case conf.IsEnabled(conf.GetEIP7Transition, n):
-		return evmc.Homestead
-	default:
-		return evmc.Frontier
 	}
+	return evmc.Revision(rev)
 }
 
 // Run implements Interpreter.Run().
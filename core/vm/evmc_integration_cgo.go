@@ -13,3 +13,8 @@ func appendExternalInterpreters(evm *EVM) {
 		evm.interpreters = append(evm.interpreters, &EVMC{evmModule, evm, evmc.CapabilityEVM1, false})
 	}
 }
+
+// EVMCSupported reports whether external EVMC interpreters can be loaded.
+func EVMCSupported() bool {
+	return true
+}
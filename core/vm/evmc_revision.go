@@ -0,0 +1,174 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+)
+
+// evmcRevisionNames are the names of the EVMC revisions, indexed by their
+// numeric value (evmc.Revision).
+var evmcRevisionNames = []string{
+	"Frontier",
+	"Homestead",
+	"TangerineWhistle",
+	"SpuriousDragon",
+	"Byzantium",
+	"Constantinople",
+	"Petersburg",
+	"Istanbul",
+	"Berlin",
+}
+
+const (
+	// evmcMaxRevision is the latest revision supported by the EVMC bindings.
+	evmcMaxRevision = 7 // Istanbul
+
+	// evmcNoRevision marks features which are not part of any supported revision.
+	evmcNoRevision = -1
+)
+
+// evmcFeature is a granular, interpreter-relevant chain feature, along with the
+// EVMC revision introducing it. Features which are implemented outside the
+// interpreter (precompiles, transaction validation, state clearing, contract
+// creation checks) are handled by the host and thus not listed.
+type evmcFeature struct {
+	name     string
+	revision int
+	enabled  func(c ctypes.ChainConfigurator, n *big.Int, time *uint64) bool
+}
+
+func byBlock(get func(c ctypes.ChainConfigurator) func() *uint64) func(ctypes.ChainConfigurator, *big.Int, *uint64) bool {
+	return func(c ctypes.ChainConfigurator, n *big.Int, _ *uint64) bool {
+		return c.IsEnabled(get(c), n)
+	}
+}
+
+func byBlockOrTime(get func(c ctypes.ChainConfigurator) func() *uint64, getTime func(c ctypes.ChainConfigurator) func() *uint64) func(ctypes.ChainConfigurator, *big.Int, *uint64) bool {
+	return func(c ctypes.ChainConfigurator, n *big.Int, time *uint64) bool {
+		return c.IsEnabled(get(c), n) || (time != nil && c.IsEnabledByTime(getTime(c), time))
+	}
+}
+
+var evmcFeatures = []evmcFeature{
+	{"EIP7", 1, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP7Transition })},
+	{"EIP150", 2, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP150Transition })},
+	{"EIP160", 3, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP160Transition })},
+	{"EIP140", 4, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP140Transition })},
+	{"EIP211", 4, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP211Transition })},
+	{"EIP214", 4, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP214Transition })},
+	{"EIP145", 5, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP145Transition })},
+	{"EIP1014", 5, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP1014Transition })},
+	{"EIP1052", 5, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP1052Transition })},
+	{"EIP1344", 7, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP1344Transition })},
+	{"EIP1884", 7, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP1884Transition })},
+	{"EIP2200", 7, func(c ctypes.ChainConfigurator, n *big.Int, _ *uint64) bool {
+		return c.IsEnabled(c.GetEIP2200Transition, n) && !c.IsEnabled(c.GetEIP2200DisableTransition, n)
+	}},
+	{"EIP2929", evmcNoRevision, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP2929Transition })},
+	{"EIP3198", evmcNoRevision, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP3198Transition })},
+	{"EIP3529", evmcNoRevision, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP3529Transition })},
+	{"EIP4399", evmcNoRevision, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP4399Transition })},
+	{"EIP3855", evmcNoRevision, byBlockOrTime(
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP3855Transition },
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP3855TransitionTime })},
+	{"EIP3860", evmcNoRevision, byBlockOrTime(
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP3860Transition },
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP3860TransitionTime })},
+	{"EIP1153", evmcNoRevision, byBlockOrTime(
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP1153Transition },
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP1153TransitionTime })},
+	{"EIP5656", evmcNoRevision, byBlockOrTime(
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP5656Transition },
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP5656TransitionTime })},
+	{"EIP6780", evmcNoRevision, byBlockOrTime(
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP6780Transition },
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP6780TransitionTime })},
+	{"EIP4844", evmcNoRevision, byBlockOrTime(
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP4844Transition },
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP4844TransitionTime })},
+	{"EIP7516", evmcNoRevision, byBlockOrTime(
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP7516Transition },
+		func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP7516TransitionTime })},
+	{"EIP7692", evmcNoRevision, byBlock(func(c ctypes.ChainConfigurator) func() *uint64 { return c.GetEIP7692Transition })},
+}
+
+// evmcRevision returns the EVMC revision used to execute code at the given
+// block, as chosen by the indicative feature of each revision.
+func evmcRevision(conf ctypes.ChainConfigurator, n *big.Int) int {
+	// This is an example of choosing to use an "abstracted" idea
+	// about chain config, where I'm choosing to prioritize "indicative" features
+	// as identifiers for Fork-Feature-Groups. Note that this is very different
+	// than using Feature-complete sets to assert "did Forkage."
+	switch {
+	case conf.IsEnabled(conf.GetEIP2565Transition, n):
+		return 8 // Berlin
+	case conf.IsEnabled(conf.GetEIP1884Transition, n):
+		return 7 // Istanbul
+	case conf.IsEnabled(conf.GetEIP1283DisableTransition, n):
+		return 6 // Petersburg
+	case conf.IsEnabled(conf.GetEIP145Transition, n):
+		return 5 // Constantinople
+	case conf.IsEnabled(conf.GetEIP198Transition, n):
+		return 4 // Byzantium
+	case conf.IsEnabled(conf.GetEIP155Transition, n):
+		return 3 // SpuriousDragon
+	case conf.IsEnabled(conf.GetEIP150Transition, n):
+		return 2 // TangerineWhistle
+	case conf.IsEnabled(conf.GetEIP7Transition, n):
+		return 1 // Homestead
+	default:
+		return 0 // Frontier
+	}
+}
+
+// EVMCRevisionGaps returns the name of the EVMC revision an external EVMC
+// interpreter would execute with at the given block, and the features whose
+// activation state that revision cannot represent. A feature is reported if it
+// is enabled but not part of the revision, or if it is part of the revision but
+// disabled by the chain configuration. Execution on an EVMC interpreter can
+// silently diverge from the built-in one whenever the list is non-empty.
+func EVMCRevisionGaps(conf ctypes.ChainConfigurator, n *big.Int, time *uint64) (revision string, gaps []string) {
+	rev := evmcRevision(conf, n)
+	revision = evmcRevisionNames[rev]
+	if rev > evmcMaxRevision {
+		gaps = append(gaps, fmt.Sprintf("revision %s is unsupported by the EVMC bindings", revision))
+	}
+	for _, f := range evmcFeatures {
+		enabled := f.enabled(conf, n, time)
+		implied := f.revision != evmcNoRevision && f.revision <= rev
+		switch {
+		case enabled && !implied:
+			gaps = append(gaps, fmt.Sprintf("%s enabled, but not part of revision %s", f.name, revision))
+		case !enabled && implied:
+			gaps = append(gaps, fmt.Sprintf("%s disabled, but part of revision %s", f.name, revision))
+		}
+	}
+	// EIP-1283 net gas metering was part of Constantinople only.
+	eip1283 := conf.IsEnabled(conf.GetEIP1283Transition, n) && !conf.IsEnabled(conf.GetEIP1283DisableTransition, n)
+	if eip1283 != (rev == 5) {
+		state := "disabled"
+		if eip1283 {
+			state = "enabled"
+		}
+		gaps = append(gaps, fmt.Sprintf("EIP1283 %s, inconsistent with revision %s", state, revision))
+	}
+	return revision, gaps
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
+)
+
+func TestEVMCRevisionGaps(t *testing.T) {
+	// A granular schedule, with the Istanbul opcode repricing and CHAINID
+	// activated before the Byzantium feature set.
+	mixed := &coregeth.CoreGethChainConfig{
+		EIP7FBlock:    big.NewInt(0),
+		EIP150Block:   big.NewInt(0),
+		EIP155Block:   big.NewInt(0),
+		EIP160FBlock:  big.NewInt(0),
+		EIP1344FBlock: big.NewInt(60_000),
+		EIP1884FBlock: big.NewInt(60_000),
+		EIP2200FBlock: big.NewInt(60_000),
+		EIP198FBlock:  big.NewInt(70_000),
+		EIP140FBlock:  big.NewInt(70_000),
+		EIP211FBlock:  big.NewInt(70_000),
+		EIP214FBlock:  big.NewInt(70_000),
+	}
+	for i, test := range []struct {
+		number   int64
+		mainnet  bool
+		revision string
+		gaps     int
+	}{
+		{number: 9_069_000, mainnet: true, revision: "Istanbul", gaps: 0},
+		{number: 7_280_000, mainnet: true, revision: "Petersburg", gaps: 0},
+		{number: 12_244_000, mainnet: true, revision: "Berlin"},
+		{number: 1, revision: "SpuriousDragon", gaps: 0},
+		{number: 60_000, revision: "Istanbul", gaps: 6}, // Byzantium and Constantinople features are missing
+		{number: 70_000, revision: "Istanbul", gaps: 3}, // Constantinople features are missing
+	} {
+		var (
+			revision string
+			gaps     []string
+		)
+		if test.mainnet {
+			revision, gaps = EVMCRevisionGaps(params.MainnetChainConfig, big.NewInt(test.number), nil)
+		} else {
+			revision, gaps = EVMCRevisionGaps(mixed, big.NewInt(test.number), nil)
+		}
+		if revision != test.revision {
+			t.Errorf("test %d: have revision %s, want %s", i, revision, test.revision)
+		}
+		if test.revision == "Berlin" {
+			if len(gaps) == 0 {
+				t.Errorf("test %d: expected gaps for an unsupported revision", i)
+			}
+			continue
+		}
+		if len(gaps) != test.gaps {
+			t.Errorf("test %d: have %d gaps, want %d: %v", i, len(gaps), test.gaps, gaps)
+		}
+	}
+}
@@ -22,3 +22,8 @@ func InitEVMCEwasm(config string) {
 		log.Warn("EVMC support disabled (built without cgo); --vm.ewasm ignored", "path", config)
 	}
 }
+
+// EVMCSupported reports whether external EVMC interpreters can be loaded.
+func EVMCSupported() bool {
+	return false
+}
@@ -93,6 +93,7 @@ var (
 	gasMcopy          = memoryCopierGas(2)
 	gasExtCodeCopy    = memoryCopierGas(3)
 	gasReturnDataCopy = memoryCopierGas(2)
+	gasDataCopy       = memoryCopierGas(2)
 )
 
 func gasSStore(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
@@ -291,6 +292,9 @@ var (
 	gasMStore8 = pureMemoryGascost
 	gasMStore  = pureMemoryGascost
 	gasCreate  = pureMemoryGascost
+
+	gasEOFCreate      = pureMemoryGascost
+	gasReturnContract = pureMemoryGascost
 )
 
 func gasCreate2(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
@@ -485,3 +489,48 @@ func gasSelfdestruct(evm *EVM, contract *Contract, stack *Stack, mem *Memory, me
 	}
 	return gas, nil
 }
+
+// makeExtCallGas creates the gas function of the EXT*CALL instructions, which
+// charge for memory expansion and cold account access, and, if value may be
+// transferred, for the value transfer and creation of the callee account.
+// Unlike the legacy calls, the gas passed to the callee is not part of the
+// dynamic cost, but is determined when executing the instruction.
+func makeExtCallGas(transfersValue bool) gasFunc {
+	return func(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+		// Addresses wider than 20 bytes are invalid.
+		if stack.Back(0).ByteLen() > common.AddressLength {
+			return 0, ErrInvalidAddress
+		}
+		gas, err := memoryGasCost(mem, memorySize)
+		if err != nil {
+			return 0, err
+		}
+		var (
+			address  = common.Address(stack.Back(0).Bytes20())
+			overflow bool
+		)
+		// The warm storage read cost is already charged as constantGas
+		if !evm.StateDB.AddressInAccessList(address) {
+			evm.StateDB.AddAddressToAccessList(address)
+			if gas, overflow = math.SafeAdd(gas, vars.ColdAccountAccessCostEIP2929-vars.WarmStorageReadCostEIP2929); overflow {
+				return 0, ErrGasUintOverflow
+			}
+		}
+		if transfersValue && !stack.Back(3).IsZero() {
+			extra := vars.CallValueTransferGas
+			if evm.StateDB.Empty(address) {
+				extra += vars.CallNewAccountGas
+			}
+			if gas, overflow = math.SafeAdd(gas, extra); overflow {
+				return 0, ErrGasUintOverflow
+			}
+		}
+		return gas, nil
+	}
+}
+
+var (
+	gasExtCall         = makeExtCallGas(true)
+	gasExtDelegateCall = makeExtCallGas(false)
+	gasExtStaticCall   = makeExtCallGas(false)
+)
@@ -17,6 +17,8 @@
 package vm
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -61,12 +63,24 @@ type ScopeContext struct {
 	Memory   *Memory
 	Stack    *Stack
 	Contract *Contract
+
+	CodeSection uint64           // Code section being executed, always 0 for legacy code
+	ReturnStack []*ReturnContext // Callers of the executing EOF function
+}
+
+// ReturnContext is the location at which execution resumes after returning
+// from an EOF function.
+type ReturnContext struct {
+	Section     uint64
+	Pc          uint64
+	StackHeight int
 }
 
 // EVMInterpreter represents an EVM interpreter
 type EVMInterpreter struct {
-	evm   *EVM
-	table *JumpTable
+	evm      *EVM
+	table    *JumpTable
+	eofTable *JumpTable // Instruction set for EOF containers, nil before EIP-7692
 
 	hasher    crypto.KeccakState // Keccak256 hasher instance shared across opcodes
 	hasherBuf common.Hash        // Keccak256 hasher result array shared across opcodes
@@ -94,7 +108,11 @@ func NewEVMInterpreter(evm *EVM) *EVMInterpreter {
 		}
 	}
 	evm.Config.ExtraEips = extraEips
-	return &EVMInterpreter{evm: evm, table: table}
+	var eofTable *JumpTable
+	if evm.chainConfig.IsEnabled(evm.chainConfig.GetEIP7692Transition, evm.Context.BlockNumber) {
+		eofTable = newEOFInstructionSet(table)
+	}
+	return &EVMInterpreter{evm: evm, table: table, eofTable: eofTable}
 }
 
 // Run loops and evaluates the contract's code with the given input data and returns
@@ -124,6 +142,22 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 		return nil, nil
 	}
 
+	// Select the instruction set for the code, decoding EOF containers. Code
+	// in state was validated upon deployment, so it only needs to be parsed.
+	table := in.table
+	if in.eofTable != nil {
+		if contract.Container == nil && hasEOFMagic(contract.Code) {
+			container := new(Container)
+			if err := container.UnmarshalBinary(contract.Code); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidEOFCode, err)
+			}
+			contract.Container = container
+		}
+		if contract.IsEOF() {
+			table = in.eofTable
+		}
+	}
+
 	var (
 		op          OpCode        // current opcode
 		mem         = NewMemory() // bound memory
@@ -179,8 +213,8 @@ func (in *EVMInterpreter) Run(contract *Contract, input []byte, readOnly bool) (
 
 		// Get the operation from the jump table and validate the stack to ensure there are
 		// enough stack items available to perform the operation.
-		op = contract.GetOp(pc)
-		operation := in.table[op]
+		op = contract.GetOp(pc, callContext.CodeSection)
+		operation := table[op]
 		cost = operation.constantGas // For tracing
 		// Validate stack
 		if sLen := stack.len(); sLen < operation.minStack {
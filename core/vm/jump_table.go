@@ -44,6 +44,9 @@ type operation struct {
 
 	// memorySize returns the memory size required for the operation
 	memorySize memorySizeFunc
+
+	// undefined denotes if the instruction is not officially defined in the jump table
+	undefined bool
 }
 
 // JumpTable contains the EVM opcodes supported at a given fork.
@@ -237,10 +240,172 @@ func instructionSetForConfig(config ctypes.ChainConfigurator, isPostMerge bool,
 	if config.IsEnabledByTime(config.GetEIP6780TransitionTime, bt) || config.IsEnabled(config.GetEIP6780Transition, bn) {
 		enable6780(instructionSet) // EIP-6780 SELFDESTRUCT only in same transaction
 	}
+	if config.IsEnabled(config.GetEIP7702Transition, bn) {
+		enable7702(instructionSet) // EIP-7702 Delegation designator resolution on calls
+	}
+	if config.IsEnabled(config.GetEIP7692Transition, bn) {
+		enableEOF(instructionSet) // EIP-7692 Legacy code introspection of EOF accounts
+	}
 
 	return validate(instructionSet)
 }
 
+// newEOFInstructionSet returns the instruction set used by EOF containers. It
+// is derived from the legacy instruction set of the same fork, adding the EOF
+// instructions and removing those superseded by EOF.
+func newEOFInstructionSet(legacy *JumpTable) *JumpTable {
+	jt := copyJumpTable(legacy)
+
+	// Control flow
+	jt[RJUMP] = &operation{
+		execute:     opRjump,
+		constantGas: GasQuickStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+	jt[RJUMPI] = &operation{
+		execute:     opRjumpi,
+		constantGas: GasFastishStep,
+		minStack:    minStack(1, 0),
+		maxStack:    maxStack(1, 0),
+	}
+	jt[RJUMPV] = &operation{
+		execute:     opRjumpv,
+		constantGas: GasFastishStep,
+		minStack:    minStack(1, 0),
+		maxStack:    maxStack(1, 0),
+	}
+	jt[CALLF] = &operation{
+		execute:     opCallf,
+		constantGas: GasFastStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+	jt[RETF] = &operation{
+		execute:     opRetf,
+		constantGas: GasFastestStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+	jt[JUMPF] = &operation{
+		execute:     opJumpf,
+		constantGas: GasFastStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+
+	// Stack manipulation
+	jt[DUPN] = &operation{
+		execute:     opDupN,
+		constantGas: GasFastestStep,
+		minStack:    minStack(0, 1),
+		maxStack:    maxStack(0, 1),
+	}
+	jt[SWAPN] = &operation{
+		execute:     opSwapN,
+		constantGas: GasFastestStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+	jt[EXCHANGE] = &operation{
+		execute:     opExchange,
+		constantGas: GasFastestStep,
+		minStack:    minStack(0, 0),
+		maxStack:    maxStack(0, 0),
+	}
+
+	// Data section access
+	jt[DATALOAD] = &operation{
+		execute:     opDataLoad,
+		constantGas: GasFastishStep,
+		minStack:    minStack(1, 1),
+		maxStack:    maxStack(1, 1),
+	}
+	jt[DATALOADN] = &operation{
+		execute:     opDataLoadN,
+		constantGas: GasFastestStep,
+		minStack:    minStack(0, 1),
+		maxStack:    maxStack(0, 1),
+	}
+	jt[DATASIZE] = &operation{
+		execute:     opDataSize,
+		constantGas: GasQuickStep,
+		minStack:    minStack(0, 1),
+		maxStack:    maxStack(0, 1),
+	}
+	jt[DATACOPY] = &operation{
+		execute:     opDataCopy,
+		constantGas: GasFastestStep,
+		dynamicGas:  gasDataCopy,
+		minStack:    minStack(3, 0),
+		maxStack:    maxStack(3, 0),
+		memorySize:  memoryDataCopy,
+	}
+
+	// Contract creation
+	jt[EOFCREATE] = &operation{
+		execute:     opEOFCreate,
+		constantGas: vars.CreateGas,
+		dynamicGas:  gasEOFCreate,
+		minStack:    minStack(4, 1),
+		maxStack:    maxStack(4, 1),
+		memorySize:  memoryEOFCreate,
+	}
+	jt[RETURNCONTRACT] = &operation{
+		execute:    opReturnContract,
+		dynamicGas: gasReturnContract,
+		minStack:   minStack(2, 0),
+		maxStack:   maxStack(2, 0),
+		memorySize: memoryReturnContract,
+	}
+
+	// Calls and return data
+	jt[RETURNDATALOAD] = &operation{
+		execute:     opReturnDataLoad,
+		constantGas: GasFastestStep,
+		minStack:    minStack(1, 1),
+		maxStack:    maxStack(1, 1),
+	}
+	jt[RETURNDATACOPY].execute = opReturnDataCopyEOF
+	jt[EXTCALL] = &operation{
+		execute:     opExtCall,
+		constantGas: vars.WarmStorageReadCostEIP2929,
+		dynamicGas:  gasExtCall,
+		minStack:    minStack(4, 1),
+		maxStack:    maxStack(4, 1),
+		memorySize:  memoryExtCall,
+	}
+	jt[EXTDELEGATECALL] = &operation{
+		execute:     opExtDelegateCall,
+		constantGas: vars.WarmStorageReadCostEIP2929,
+		dynamicGas:  gasExtDelegateCall,
+		minStack:    minStack(3, 1),
+		maxStack:    maxStack(3, 1),
+		memorySize:  memoryExtCall,
+	}
+	jt[EXTSTATICCALL] = &operation{
+		execute:     opExtStaticCall,
+		constantGas: vars.WarmStorageReadCostEIP2929,
+		dynamicGas:  gasExtStaticCall,
+		minStack:    minStack(3, 1),
+		maxStack:    maxStack(3, 1),
+		memorySize:  memoryExtCall,
+	}
+
+	// Instructions which observe gas or code, or which are replaced by the
+	// instructions above, are not available in EOF code.
+	for _, op := range []OpCode{
+		JUMP, JUMPI, PC, GAS, CODESIZE, CODECOPY, EXTCODESIZE, EXTCODECOPY, EXTCODEHASH,
+		CREATE, CREATE2, CALL, CALLCODE, DELEGATECALL, STATICCALL, SELFDESTRUCT,
+	} {
+		jt[op] = &operation{execute: opUndefined, maxStack: maxStack(0, 0), undefined: true}
+	}
+	// INVALID is a valid terminating instruction in EOF code, even though it
+	// still aborts execution when reached.
+	jt[INVALID] = &operation{execute: opUndefined, maxStack: maxStack(0, 0)}
+	return validate(jt)
+}
+
 // newBaseInstructionSet returns Frontier instructions
 func newBaseInstructionSet() *JumpTable {
 	tbl := &JumpTable{
@@ -1049,7 +1214,7 @@ func newBaseInstructionSet() *JumpTable {
 	// Fill all unassigned slots with opUndefined.
 	for i, entry := range tbl {
 		if entry == nil {
-			tbl[i] = &operation{execute: opUndefined, maxStack: maxStack(0, 0)}
+			tbl[i] = &operation{execute: opUndefined, maxStack: maxStack(0, 0), undefined: true}
 		}
 	}
 
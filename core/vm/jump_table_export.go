@@ -34,6 +34,16 @@ func LookupInstructionSet(config ctypes.ChainConfigurator, blockN *big.Int, bloc
 	return JumpTable{}, errors.New("no instruction set available")
 }
 
+// LookupEOFInstructionSet returns the instruction set EOF containers are
+// validated against and executed with at the given block.
+func LookupEOFInstructionSet(config ctypes.ChainConfigurator, blockN *big.Int, blockTime *uint64) (JumpTable, error) {
+	if !config.IsEnabled(config.GetEIP7692Transition, blockN) {
+		return JumpTable{}, errors.New("eof is not enabled")
+	}
+	is := instructionSetForConfig(config, config.GetEthashTerminalTotalDifficultyPassed(), blockN, blockTime)
+	return *newEOFInstructionSet(is), nil
+}
+
 // Stack returns the minimum and maximum stack requirements.
 func (op *operation) Stack() (int, int) {
 	return op.minStack, op.maxStack
@@ -0,0 +1,131 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Outcome is the result of executing code on a single interpreter.
+type Outcome struct {
+	Status  string        `json:"status"` // "success", "revert" or "error"
+	Error   string        `json:"error,omitempty"`
+	Return  hexutil.Bytes `json:"return"`
+	GasUsed uint64        `json:"gasUsed"`
+	Root    common.Hash   `json:"stateRoot"`
+}
+
+// DifferentialResult holds the outcomes of executing the same code on the
+// built-in interpreter and on an external EVMC interpreter.
+type DifferentialResult struct {
+	Native   Outcome `json:"native"`
+	External Outcome `json:"external"`
+
+	// Revision is the EVMC revision the external interpreter executed with,
+	// and Gaps the features whose activation state it cannot represent (see
+	// vm.EVMCRevisionGaps). Gaps explain most divergences.
+	Revision string   `json:"revision"`
+	Gaps     []string `json:"gaps,omitempty"`
+}
+
+// Divergences lists the aspects in which the two outcomes differ. Errors are
+// compared by status only, as interpreters report them differently.
+func (r *DifferentialResult) Divergences() []string {
+	var diffs []string
+	if r.Native.Status != r.External.Status {
+		diffs = append(diffs, fmt.Sprintf("status: native %s (%s), external %s (%s)", r.Native.Status, r.Native.Error, r.External.Status, r.External.Error))
+	}
+	if r.Native.GasUsed != r.External.GasUsed {
+		diffs = append(diffs, fmt.Sprintf("gas used: native %d, external %d", r.Native.GasUsed, r.External.GasUsed))
+	}
+	if !bytes.Equal(r.Native.Return, r.External.Return) {
+		diffs = append(diffs, fmt.Sprintf("return data: native %x, external %x", []byte(r.Native.Return), []byte(r.External.Return)))
+	}
+	if r.Native.Root != r.External.Root {
+		diffs = append(diffs, fmt.Sprintf("state root: native %x, external %x", r.Native.Root, r.External.Root))
+	}
+	return diffs
+}
+
+// ExecuteDifferential executes the given code, like Execute, once on the
+// built-in interpreter and once on the EVMC interpreter described by
+// evmcConfig (a library path, optionally followed by comma separated options).
+// Both executions start from copies of the configured state, which is left
+// untouched.
+func ExecuteDifferential(code, input []byte, cfg *Config, evmcConfig string) (*DifferentialResult, error) {
+	if !vm.EVMCSupported() {
+		return nil, errors.New("EVMC support disabled (built without cgo)")
+	}
+	if evmcConfig == "" {
+		return nil, errors.New("no EVMC interpreter configured")
+	}
+	if cfg == nil {
+		cfg = new(Config)
+	}
+	setDefaults(cfg)
+
+	if cfg.State == nil {
+		cfg.State, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	}
+	result := new(DifferentialResult)
+	result.Revision, result.Gaps = vm.EVMCRevisionGaps(cfg.ChainConfig, cfg.BlockNumber, &cfg.Time)
+
+	native := *cfg
+	native.State = cfg.State.Copy()
+	native.EVMConfig.EVMInterpreter = ""
+	result.Native = executeOutcome(code, input, &native)
+
+	vm.InitEVMCEVM(evmcConfig)
+	external := *cfg
+	external.State = cfg.State.Copy()
+	external.EVMConfig.EVMInterpreter = evmcConfig
+	result.External = executeOutcome(code, input, &external)
+
+	return result, nil
+}
+
+func executeOutcome(code, input []byte, cfg *Config) (outcome Outcome) {
+	defer func() {
+		// EVMC interpreters panic on internal errors and unsupported revisions.
+		if r := recover(); r != nil {
+			outcome = Outcome{Status: "error", Error: fmt.Sprintf("panic: %v", r)}
+		}
+	}()
+	ret, leftOverGas, err := execute(code, input, cfg)
+	outcome = Outcome{
+		Status:  "success",
+		Return:  ret,
+		GasUsed: cfg.GasLimit - leftOverGas,
+		Root:    cfg.State.IntermediateRoot(true),
+	}
+	switch {
+	case errors.Is(err, vm.ErrExecutionReverted):
+		outcome.Status, outcome.Error = "revert", err.Error()
+	case err != nil:
+		outcome.Status, outcome.Error = "error", err.Error()
+	}
+	return outcome
+}
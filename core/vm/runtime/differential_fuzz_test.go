@@ -0,0 +1,77 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package runtime
+
+import (
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// FuzzEVMCDifferential executes random code against random pre-states on both
+// the built-in interpreter and the EVMC interpreter named by the EVMC_DIFF_VM
+// environment variable, and fails on any divergence. A library can be built
+// with build/evmc-example_vm.so.sh or fetched with build/evmone.sh, eg.
+//
+//	EVMC_DIFF_VM=build/_workspace/evmone/lib/libevmone.so go test -run - -fuzz FuzzEVMCDifferential ./core/vm/runtime
+func FuzzEVMCDifferential(f *testing.F) {
+	evmcConfig := os.Getenv("EVMC_DIFF_VM")
+	if evmcConfig == "" {
+		f.Skip("EVMC_DIFF_VM not set")
+	}
+	if !vm.EVMCSupported() {
+		f.Skip("EVMC support disabled (built without cgo)")
+	}
+	f.Add([]byte{byte(vm.PUSH1), 0x01, byte(vm.PUSH1), 0x02, byte(vm.ADD), byte(vm.STOP)}, []byte{}, []byte{0x01})
+	f.Add([]byte{byte(vm.PUSH1), 0x2a, byte(vm.PUSH1), 0x00, byte(vm.SSTORE), byte(vm.STOP)}, []byte{}, []byte{})
+	f.Add([]byte{byte(vm.PUSH1), 0x01, byte(vm.BALANCE), byte(vm.PUSH1), 0x00, byte(vm.MSTORE), byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x00, byte(vm.RETURN)}, []byte{}, []byte{0xff, 0x01})
+	f.Add([]byte{byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0x00, byte(vm.PUSH1), 0x00, byte(vm.CALLDATACOPY), byte(vm.CALLDATASIZE), byte(vm.PUSH1), 0x00, byte(vm.REVERT)}, []byte{0xde, 0xad}, []byte{})
+
+	f.Fuzz(func(t *testing.T, code, input, balances []byte) {
+		// Fund a few accounts from the balances seed, so that the pre-state
+		// is not empty.
+		statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		for i, b := range balances {
+			if i >= 16 {
+				break
+			}
+			statedb.AddBalance(common.BytesToAddress([]byte{0xff, byte(i)}), uint256.NewInt(uint64(b)))
+		}
+		cfg := &Config{
+			ChainConfig: params.MainnetChainConfig,
+			BlockNumber: big.NewInt(9_069_000), // Istanbul
+			GasLimit:    1_000_000,
+			State:       statedb,
+		}
+		result, err := ExecuteDifferential(code, input, cfg, evmcConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diffs := result.Divergences(); len(diffs) > 0 {
+			t.Fatalf("code %x, input %x: %s (revision %s, gaps: %v)", code, input, strings.Join(diffs, "; "), result.Revision, result.Gaps)
+		}
+	})
+}
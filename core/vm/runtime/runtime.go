@@ -119,6 +119,13 @@ func Execute(code, input []byte, cfg *Config) ([]byte, *state.StateDB, error) {
 	if cfg.State == nil {
 		cfg.State, _ = state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
 	}
+	ret, _, err := execute(code, input, cfg)
+	return ret, cfg.State, err
+}
+
+// execute runs the given code against the (defaulted) configuration, returning
+// the gas left over in addition to the results of Execute.
+func execute(code, input []byte, cfg *Config) ([]byte, uint64, error) {
 	var (
 		address = common.BytesToAddress([]byte("contract"))
 		vmenv   = NewEnv(cfg)
@@ -142,14 +149,13 @@ func Execute(code, input []byte, cfg *Config) ([]byte, *state.StateDB, error) {
 	// set the receiver's (the executing contract) code for execution.
 	cfg.State.SetCode(address, code)
 	// Call the code with the given configuration.
-	ret, _, err := vmenv.Call(
+	return vmenv.Call(
 		sender,
 		common.BytesToAddress([]byte("contract")),
 		input,
 		cfg.GasLimit,
 		uint256.MustFromBig(cfg.Value),
 	)
-	return ret, cfg.State, err
 }
 
 // Create executes the code using the EVM create method
@@ -17,6 +17,8 @@
 package runtime
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
 	"os"
@@ -913,3 +915,69 @@ func BenchmarkTracerStepVsCallFrame(b *testing.B) {
 	benchmarkNonModifyingCode(10000000, code, "tracer-step-10M", stepTracer, b)
 	benchmarkNonModifyingCode(10000000, code, "tracer-call-frame-10M", callFrameTracer, b)
 }
+
+func TestEOFExecution(t *testing.T) {
+	cfg := &Config{
+		ChainConfig: &goethereum.ChainConfig{
+			ChainID:             big.NewInt(1),
+			HomesteadBlock:      new(big.Int),
+			EIP150Block:         new(big.Int),
+			EIP155Block:         new(big.Int),
+			EIP158Block:         new(big.Int),
+			ByzantiumBlock:      new(big.Int),
+			ConstantinopleBlock: new(big.Int),
+			PetersburgBlock:     new(big.Int),
+			IstanbulBlock:       new(big.Int),
+			BerlinBlock:         new(big.Int),
+			LondonBlock:         new(big.Int),
+			ShanghaiTime:        new(uint64),
+			EOFBlock:            new(big.Int),
+		},
+	}
+	// The runtime container calls into a second code section which adds the
+	// first word of the data section to its input, and returns the result.
+	runtime := common.FromHex(
+		"ef0001" + "010008" + "020002000b0005" + "ff0020" + "00" +
+			"00800002" + "01010001" +
+			"6005" + "e30001" + "5f" + "52" + "6020" + "5f" + "f3" +
+			"d10000" + "01" + "e4" +
+			"0000000000000000000000000000000000000000000000000000000000000007")
+
+	ret, _, err := Execute(runtime, nil, cfg)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if num := new(big.Int).SetBytes(ret); num.Cmp(big.NewInt(12)) != 0 {
+		t.Errorf("expected 12, got %v", num)
+	}
+
+	// Deploy the same runtime container from an initcontainer, and call it.
+	initcode := common.FromHex(
+		"ef0001" + "010004" + "0200010004" + fmt.Sprintf("030001%08x", len(runtime)) + "ff0000" + "00" +
+			"00800002" +
+			"5f5fee00")
+	initcode = append(initcode, runtime...)
+
+	cfg.State = nil
+	code, address, _, err := Create(initcode, cfg)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if !bytes.Equal(code, runtime) {
+		t.Fatalf("deployed code mismatch: have %x, want %x", code, runtime)
+	}
+	ret, _, err = Call(address, nil, cfg)
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if num := new(big.Int).SetBytes(ret); num.Cmp(big.NewInt(12)) != 0 {
+		t.Errorf("expected 12, got %v", num)
+	}
+
+	// Invalid initcontainers are rejected before execution.
+	invalid := common.CopyBytes(initcode)
+	invalid[len("ef0001010004020001000403000100000000ff000000")/2+4] = byte(vm.STOP)
+	if _, _, _, err := Create(invalid, cfg); !errors.Is(err, vm.ErrInvalidEOFInitcode) {
+		t.Errorf("expected %v, got %v", vm.ErrInvalidEOFInitcode, err)
+	}
+}
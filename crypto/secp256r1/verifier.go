@@ -0,0 +1,46 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package secp256r1 implements signature verification on the secp256r1 (NIST
+// P-256) curve, as used by the RIP-7212 precompile.
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+)
+
+// Verify checks the signature (r, s) of the given message hash against the
+// public key (x, y). It returns false for public keys not on the curve, which
+// includes the point at infinity, and for r or s outside of [1, n-1].
+func Verify(hash []byte, r, s, x, y *big.Int) bool {
+	key := newPublicKey(x, y)
+	if key == nil {
+		return false
+	}
+	return ecdsa.Verify(key, hash, r, s)
+}
+
+// newPublicKey creates an ECDSA public key from the given coordinates, or
+// returns nil if the point is not on the curve.
+func newPublicKey(x, y *big.Int) *ecdsa.PublicKey {
+	curve := elliptic.P256()
+	if x == nil || y == nil || !curve.IsOnCurve(x, y) {
+		return nil
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+}
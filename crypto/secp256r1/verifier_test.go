@@ -0,0 +1,87 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package secp256r1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	hash := sha256.Sum256([]byte("core-geth"))
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	if !Verify(hash[:], r, s, key.X, key.Y) {
+		t.Fatal("valid signature rejected")
+	}
+	// P-256 signatures are malleable, (r, n-s) is valid as well
+	if !Verify(hash[:], r, new(big.Int).Sub(elliptic.P256().Params().N, s), key.X, key.Y) {
+		t.Fatal("malleated signature rejected")
+	}
+	other := sha256.Sum256([]byte("go-ethereum"))
+	if Verify(other[:], r, s, key.X, key.Y) {
+		t.Fatal("signature accepted for different hash")
+	}
+	if Verify(hash[:], r, s, new(big.Int), new(big.Int)) {
+		t.Fatal("signature accepted for point at infinity")
+	}
+	if Verify(hash[:], r, s, key.X, new(big.Int).Add(key.Y, big.NewInt(1))) {
+		t.Fatal("signature accepted for key not on curve")
+	}
+	if Verify(hash[:], r, s, nil, nil) {
+		t.Fatal("signature accepted for missing key")
+	}
+}
+
+func FuzzVerify(f *testing.F) {
+	f.Fuzz(func(t *testing.T, seed, hash []byte) {
+		// Arbitrary input must never crash the verifier
+		part := func(i int) *big.Int {
+			if len(seed) < (i+1)*32 {
+				return new(big.Int).SetBytes(seed)
+			}
+			return new(big.Int).SetBytes(seed[i*32 : (i+1)*32])
+		}
+		Verify(hash, part(0), part(1), part(2), part(3))
+
+		// Signatures over arbitrary hashes by valid keys must always verify
+		if len(hash) == 0 {
+			return
+		}
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, key, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !Verify(hash, r, s, key.X, key.Y) {
+			t.Fatalf("valid signature rejected for hash %x", hash)
+		}
+	})
+}
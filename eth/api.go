@@ -17,8 +17,12 @@
 package eth
 
 import (
+	"context"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
 )
 
 // EthereumAPI provides an API to access Ethereum full node-related information.
@@ -50,3 +54,19 @@ func (api *EthereumAPI) Hashrate() hexutil.Uint64 {
 func (api *EthereumAPI) Mining() bool {
 	return api.e.IsMining()
 }
+
+// SendPrivateRawTransaction adds the signed transaction to the transaction pool
+// without broadcasting it, and relays it to the trusted private transaction
+// peers only. If it's still pending after expiry blocks (default if nil), the
+// transaction is broadcast publicly.
+func (api *EthereumAPI) SendPrivateRawTransaction(ctx context.Context, input hexutil.Bytes, expiry *hexutil.Uint64) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	var blocks uint64
+	if expiry != nil {
+		blocks = uint64(*expiry)
+	}
+	return ethapi.SubmitTransaction(withPrivateTx(ctx, blocks), api.e.APIBackend, tx)
+}
@@ -26,6 +26,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 )
@@ -166,3 +167,9 @@ func (api *AdminAPI) MaxPeers(n int) (bool, error) {
 	}
 	return true, nil
 }
+
+// ForkFilterStats returns the number of dial candidates accepted and rejected
+// by their advertised fork ID, the latter by reason.
+func (api *AdminAPI) ForkFilterStats() eth.NodeFilterStats {
+	return api.eth.handler.nodeFilter.Stats()
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/fetcher"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/tracers"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -279,7 +280,16 @@ func (b *EthAPIBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscri
 	return b.eth.BlockChain().SubscribeLogsEvent(ch)
 }
 
+// SubscribeBlockPropagation subscribes to the propagation history of blocks
+// received from the network.
+func (b *EthAPIBackend) SubscribeBlockPropagation(ch chan<- fetcher.BlockPropagation) event.Subscription {
+	return b.eth.handler.blockFetcher.SubscribePropagation(ch)
+}
+
 func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction) error {
+	if expiry, ok := privateTxExpiry(ctx, b.eth.config.PrivateTxTransports); ok {
+		return b.eth.handler.privateTxs.submit(signedTx, expiry)
+	}
 	return b.eth.txPool.Add([]*types.Transaction{signedTx}, true, false)[0]
 }
 
@@ -362,6 +372,14 @@ func (b *EthAPIBackend) FeeHistory(ctx context.Context, blockCount uint64, lastB
 	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
 }
 
+func (b *EthAPIBackend) FeeEstimate(ctx context.Context, targetBlocks []uint64, confidence []float64) (*gasprice.FeeEstimates, error) {
+	return b.gpo.FeeEstimate(ctx, targetBlocks, confidence)
+}
+
+func (b *EthAPIBackend) MinerGasTip() *big.Int {
+	return b.eth.miner.GasTip()
+}
+
 func (b *EthAPIBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
@@ -24,10 +24,12 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/fetcher"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -440,3 +442,125 @@ func (api *DebugAPI) GetTrieFlushInterval() (string, error) {
 	}
 	return api.eth.blockchain.GetTrieFlushInterval().String(), nil
 }
+
+// maxOrphanStatsRange is the maximum number of blocks debug_orphanStats will
+// scan in a single request.
+const maxOrphanStatsRange = 65536
+
+// OrphanStats is the result of a debug_orphanStats call.
+type OrphanStats struct {
+	From            uint64                    `json:"from"`
+	To              uint64                    `json:"to"`
+	CanonicalBlocks uint64                    `json:"canonicalBlocks"`
+	SideBlocks      uint64                    `json:"sideBlocks"`
+	Orphans         uint64                    `json:"orphans"`
+	Uncles          uint64                    `json:"uncles"`
+	OrphanRate      float64                   `json:"orphanRate"`
+	UncleRate       float64                   `json:"uncleRate"`
+	Reorgs          []core.ReorgEvent         `json:"reorgs"`
+	ReorgDepths     map[int]int               `json:"reorgDepths"`
+	Miners          map[common.Address]uint64 `json:"miners"`
+	Blocks          []core.SideBlock          `json:"blocks"`
+}
+
+// OrphanStats reports the non-canonical blocks this node has seen within the
+// given (inclusive) block range, along with the uncle and orphan rates and the
+// distribution of reorg depths. Side blocks are only tracked in memory since
+// the node was started, while uncle counts are read from the canonical chain.
+func (api *DebugAPI) OrphanStats(from, to rpc.BlockNumber) (*OrphanStats, error) {
+	head := api.eth.blockchain.CurrentBlock().Number.Uint64()
+	resolveNum := func(num rpc.BlockNumber) uint64 {
+		if num.Int64() < 0 || uint64(num.Int64()) > head {
+			return head
+		}
+		return uint64(num.Int64())
+	}
+	start, end := resolveNum(from), resolveNum(to)
+	if start > end {
+		return nil, fmt.Errorf("invalid range: from %d > to %d", start, end)
+	}
+	if end-start >= maxOrphanStatsRange {
+		return nil, fmt.Errorf("range too large: %d blocks, max %d", end-start+1, maxOrphanStatsRange)
+	}
+	stats := &OrphanStats{
+		From:            start,
+		To:              end,
+		CanonicalBlocks: end - start + 1,
+		Reorgs:          api.eth.blockchain.Reorgs(start, end),
+		ReorgDepths:     make(map[int]int),
+		Miners:          make(map[common.Address]uint64),
+		Blocks:          api.eth.blockchain.SideBlocks(start, end),
+	}
+	for n := start; n <= end; n++ {
+		header := api.eth.blockchain.GetHeaderByNumber(n)
+		if header == nil {
+			return nil, fmt.Errorf("missing header %d", n)
+		}
+		if header.UncleHash == types.EmptyUncleHash {
+			continue
+		}
+		block := api.eth.blockchain.GetBlock(header.Hash(), n)
+		if block == nil {
+			return nil, fmt.Errorf("missing block %d", n)
+		}
+		stats.Uncles += uint64(len(block.Uncles()))
+	}
+	for _, b := range stats.Blocks {
+		stats.SideBlocks++
+		stats.Miners[b.Miner]++
+		if b.UncleOf == nil {
+			stats.Orphans++
+		}
+	}
+	for _, ev := range stats.Reorgs {
+		stats.ReorgDepths[ev.Dropped]++
+	}
+	stats.OrphanRate = float64(stats.Orphans) / float64(stats.CanonicalBlocks+stats.SideBlocks)
+	stats.UncleRate = float64(stats.Uncles) / float64(stats.CanonicalBlocks)
+	return stats, nil
+}
+
+// FinalityWindow reports the artificial finality (ECBP1100 MESS and
+// max-reorg-depth) rules in effect at the current head, together with the
+// most recent reorg they refused.
+func (api *DebugAPI) FinalityWindow() *core.FinalityStatus {
+	return api.eth.blockchain.FinalityStatus()
+}
+
+// ReorgPenalty reports the total difficulty a hypothetical competing chain
+// forking off the given canonical block would need to replace the current head,
+// taking artificial finality into account.
+func (api *DebugAPI) ReorgPenalty(commonAncestor rpc.BlockNumber) (*core.ReorgPenalty, error) {
+	var header *types.Header
+	if commonAncestor.Int64() < 0 {
+		header = api.eth.blockchain.CurrentHeader()
+	} else {
+		header = api.eth.blockchain.GetHeaderByNumber(uint64(commonAncestor.Int64()))
+	}
+	if header == nil {
+		return nil, fmt.Errorf("block #%d not found", commonAncestor)
+	}
+	return api.eth.blockchain.ReorgPenalty(header), nil
+}
+
+// BlockPropagation reports when and from which peers a recently received block
+// was first announced and received, and how long its import took.
+func (api *DebugAPI) BlockPropagation(hash common.Hash) (*fetcher.BlockPropagation, error) {
+	if p := api.eth.handler.blockFetcher.Propagation(hash); p != nil {
+		return p, nil
+	}
+	return nil, errors.New("block not received from the network recently")
+}
+
+// ChtRoot returns the root of the canonical hash trie covering the blocks up to
+// the end of a section, which light clients can trust to start from.
+func (api *DebugAPI) ChtRoot(section hexutil.Uint64) (common.Hash, error) {
+	cht := api.eth.cht
+	if cht == nil {
+		return common.Hash{}, errors.New("light serving is disabled")
+	}
+	if uint64(section) >= cht.Sections() {
+		return common.Hash{}, fmt.Errorf("section %d not indexed yet, %d sections available", section, cht.Sections())
+	}
+	return cht.Root(uint64(section)), nil
+}
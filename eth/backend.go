@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/lyra2"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
+	"github.com/ethereum/go-ethereum/core/checkpoint"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/pruner"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -44,6 +45,8 @@ import (
 	"github.com/ethereum/go-ethereum/eth/ethconfig"
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/eth/protocols/lhp"
+	"github.com/ethereum/go-ethereum/eth/protocols/ptx"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
@@ -79,6 +82,7 @@ type Ethereum struct {
 	handler            *handler
 	ethDialCandidates  enode.Iterator
 	snapDialCandidates enode.Iterator
+	privateTxPeers     []*enode.Node // Trusted peers to relay private transactions to
 	merger             *consensus.Merger
 
 	// DB interfaces
@@ -90,6 +94,7 @@ type Ethereum struct {
 
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
+	cht               *lhp.CHT                       // Canonical hash tries served to light clients
 	closeBloomHandler chan struct{}
 
 	APIBackend *EthAPIBackend
@@ -242,6 +247,10 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, err
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	if config.LightServ > 0 {
+		eth.cht = lhp.NewCHT(chainDb, lhp.CHTSectionSize, lhp.CHTConfirmations)
+		eth.cht.Start(eth.blockchain)
+	}
 	// Handle artificial finality config override cases.
 	if n := config.OverrideECBP1100; n != nil {
 		if err := eth.blockchain.Config().SetECBP1100Transition(n); err != nil {
@@ -276,6 +285,12 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	}
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
+	var signedCheckpoint *checkpoint.Checkpoint
+	if config.CheckpointFile != "" {
+		if signedCheckpoint, err = loadSignedCheckpoint(config.CheckpointFile, eth.blockchain); err != nil {
+			return nil, err
+		}
+	}
 	checkpoint := config.Checkpoint
 	if checkpoint == nil {
 		if p, ok := eth.blockchain.Config().(*coregeth.CoreGethChainConfig); ok {
@@ -284,17 +299,30 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 			checkpoint = p.TrustedCheckpoint
 		}
 	}
+	for _, url := range config.PrivateTxPeers {
+		node, err := enode.Parse(enode.ValidSchemes, url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid private transaction relay peer %q: %v", url, err)
+		}
+		eth.privateTxPeers = append(eth.privateTxPeers, node)
+	}
 	if eth.handler, err = newHandler(&handlerConfig{
-		Database:       chainDb,
-		Chain:          eth.blockchain,
-		TxPool:         eth.txPool,
-		Merger:         eth.merger,
-		Network:        networkID,
-		Sync:           config.SyncMode,
-		BloomCache:     uint64(cacheLimit),
-		EventMux:       eth.eventMux,
-		Checkpoint:     checkpoint,
-		RequiredBlocks: config.RequiredBlocks,
+		Database:         chainDb,
+		Chain:            eth.blockchain,
+		TxPool:           eth.txPool,
+		Merger:           eth.merger,
+		Network:          networkID,
+		Sync:             config.SyncMode,
+		BloomCache:       uint64(cacheLimit),
+		EventMux:         eth.eventMux,
+		Checkpoint:       checkpoint,
+		SignedCheckpoint: signedCheckpoint,
+		RequiredBlocks:   config.RequiredBlocks,
+		PrivateTxPeers:   eth.privateTxPeers,
+		PrivateTxExpiry:  config.PrivateTxExpiry,
+		CHT:              eth.cht,
+		LightPeers:       config.LightPeers,
+		LightNoSyncServe: config.LightNoSyncServe,
 	}); err != nil {
 		return nil, err
 	}
@@ -313,15 +341,19 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 	eth.APIBackend.gpo = gasprice.NewOracle(eth.APIBackend, gpoParams)
 
 	// Setup DNS discovery iterators.
+	// Nodes of other chains sharing the discovery DHT are skipped by their fork
+	// ID before they are dialed.
 	dnsclient := dnsdisc.NewClient(dnsdisc.Config{})
 	eth.ethDialCandidates, err = dnsclient.NewIterator(eth.config.EthDiscoveryURLs...)
 	if err != nil {
 		return nil, err
 	}
+	eth.ethDialCandidates = enode.Filter(eth.ethDialCandidates, eth.handler.nodeFilter.Check)
 	eth.snapDialCandidates, err = dnsclient.NewIterator(eth.config.SnapDiscoveryURLs...)
 	if err != nil {
 		return nil, err
 	}
+	eth.snapDialCandidates = enode.Filter(eth.snapDialCandidates, eth.handler.nodeFilter.Check)
 
 	// Start the RPC service
 	eth.netRPCService = ethapi.NewNetAPI(eth.p2pServer, networkID)
@@ -354,6 +386,24 @@ func makeExtraData(extra []byte) []byte {
 	return extra
 }
 
+// loadSignedCheckpoint loads a signed checkpoint from disk and verifies it against
+// the chain's configured checkpoint signers and the local chain.
+func loadSignedCheckpoint(path string, chain *core.BlockChain) (*checkpoint.Checkpoint, error) {
+	cp, err := checkpoint.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load signed checkpoint: %v", err)
+	}
+	if err := cp.Verify(chain.Config().GetChainID(), checkpoint.SignersConfig(chain.Config())); err != nil {
+		return nil, fmt.Errorf("failed to verify signed checkpoint: %v", err)
+	}
+	number, hash := cp.Checkpoint.Number, cp.Checkpoint.Hash
+	if local := chain.GetHeaderByNumber(number); local != nil && local.Hash() != hash {
+		return nil, fmt.Errorf("local chain conflicts with signed checkpoint %d: have %x, want %x", number, local.Hash(), hash)
+	}
+	log.Info("Loaded signed checkpoint", "number", number, "hash", hash, "td", cp.Checkpoint.TD, "signatures", len(cp.Signatures))
+	return &cp.Checkpoint, nil
+}
+
 // APIs return the collection of RPC services the ethereum package offers.
 // NOTE, some of these services probably need to be moved to somewhere else.
 func (s *Ethereum) APIs() []rpc.API {
@@ -543,7 +593,7 @@ func (s *Ethereum) Engine() consensus.Engine          { return s.engine }
 func (s *Ethereum) ChainDb() ethdb.Database           { return s.chainDb }
 func (s *Ethereum) IsListening() bool                 { return true } // Always listening
 func (s *Ethereum) EthVersion() int {
-	return int(eth.MakeProtocols((*ethHandler)(s.handler), s.networkID, s.config.ProtocolVersions, s.ethDialCandidates)[0].Version)
+	return int(eth.MakeProtocols((*ethHandler)(s.handler), s.networkID, s.config.ProtocolVersions, s.ethDialCandidates, s.handler.nodeFilter)[0].Version)
 }
 func (s *Ethereum) NetVersion() uint64                 { return s.networkID }
 func (s *Ethereum) Downloader() *downloader.Downloader { return s.handler.downloader }
@@ -560,10 +610,14 @@ func (s *Ethereum) SyncMode() downloader.SyncMode {
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
-	protos := eth.MakeProtocols((*ethHandler)(s.handler), s.networkID, s.config.ProtocolVersions, s.ethDialCandidates)
+	protos := eth.MakeProtocols((*ethHandler)(s.handler), s.networkID, s.config.ProtocolVersions, s.ethDialCandidates, s.handler.nodeFilter)
 	if s.config.SnapshotCache > 0 {
 		protos = append(protos, snap.MakeProtocols((*snapHandler)(s.handler), s.snapDialCandidates)...)
 	}
+	protos = append(protos, ptx.MakeProtocols((*ptxHandler)(s.handler))...)
+	if s.cht != nil {
+		protos = append(protos, lhp.MakeProtocols((*lhpHandler)(s.handler))...)
+	}
 	return protos
 }
 
@@ -588,6 +642,13 @@ func (s *Ethereum) Start() error {
 	}
 	// Start the networking layer and the light server if requested
 	s.handler.Start(maxPeers)
+
+	// Keep connected to the private transaction relay peers, which are the only
+	// ones allowed to relay private transactions to us.
+	for _, node := range s.privateTxPeers {
+		s.p2pServer.AddTrustedPeer(node)
+		s.p2pServer.AddPeer(node)
+	}
 	return nil
 }
 
@@ -601,6 +662,9 @@ func (s *Ethereum) Stop() error {
 
 	// Then stop everything else.
 	s.bloomIndexer.Close()
+	if s.cht != nil {
+		s.cht.Close()
+	}
 	close(s.closeBloomHandler)
 	s.txPool.Close()
 	s.miner.Close()
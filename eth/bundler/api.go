@@ -0,0 +1,161 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// API exposes the bundler over the ERC-4337 RPC methods in the eth namespace.
+type API struct {
+	b *Bundler
+}
+
+// SendUserOperation validates a user operation and adds it to the mempool,
+// returning its hash.
+func (api *API) SendUserOperation(ctx context.Context, op UserOperation, entryPoint common.Address) (common.Hash, error) {
+	return api.b.Add(ctx, &op, entryPoint)
+}
+
+// EstimateUserOperationGas estimates the gas limits of a user operation.
+func (api *API) EstimateUserOperationGas(ctx context.Context, op UserOperation, entryPoint common.Address) (map[string]hexutil.Uint64, error) {
+	estimate, err := api.b.Estimate(ctx, &op, entryPoint)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]hexutil.Uint64{
+		"preVerificationGas":   hexutil.Uint64(estimate.PreVerificationGas),
+		"verificationGasLimit": hexutil.Uint64(estimate.VerificationGasLimit),
+		"callGasLimit":         hexutil.Uint64(estimate.CallGasLimit),
+	}, nil
+}
+
+// GetUserOperationReceipt returns the receipt of an included user operation,
+// or nil if it is unknown or pending.
+func (api *API) GetUserOperationReceipt(ctx context.Context, hash common.Hash) (*UserOperationReceipt, error) {
+	return api.b.Receipt(ctx, hash)
+}
+
+// SupportedEntryPoints returns the EntryPoint contracts the bundler accepts
+// user operations for.
+func (api *API) SupportedEntryPoints() []common.Address {
+	return api.b.config.EntryPoints
+}
+
+// UserOperationReceipt is the outcome of an included user operation.
+type UserOperationReceipt struct {
+	UserOpHash    common.Hash    `json:"userOpHash"`
+	EntryPoint    common.Address `json:"entryPoint"`
+	Sender        common.Address `json:"sender"`
+	Nonce         *hexutil.Big   `json:"nonce"`
+	Paymaster     common.Address `json:"paymaster"`
+	ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed *hexutil.Big   `json:"actualGasUsed"`
+	Success       bool           `json:"success"`
+	Reason        hexutil.Bytes  `json:"reason,omitempty"`
+	Logs          []*types.Log   `json:"logs"`
+	Receipt       *types.Receipt `json:"receipt"`
+}
+
+// Receipt looks up the receipt of a user operation, first among the bundles
+// submitted by this node, then in the logs of recent blocks.
+func (b *Bundler) Receipt(ctx context.Context, hash common.Hash) (*UserOperationReceipt, error) {
+	if txHash, ok := b.submitted.Get(hash); ok {
+		found, _, blockHash, _, index, err := b.backend.GetTransaction(ctx, txHash)
+		if err != nil || !found {
+			return nil, err
+		}
+		receipts, err := b.backend.GetReceipts(ctx, blockHash)
+		if err != nil || uint64(len(receipts)) <= index {
+			return nil, err
+		}
+		return b.receiptFrom(hash, receipts[index]), nil
+	}
+	head := b.backend.CurrentHeader().Number.Uint64()
+	for n := head; n+receiptLookback > head; n-- {
+		header, err := b.backend.HeaderByNumber(ctx, rpc.BlockNumber(n))
+		if err != nil || header == nil {
+			return nil, err
+		}
+		if types.BloomLookup(header.Bloom, hash) {
+			receipts, err := b.backend.GetReceipts(ctx, header.Hash())
+			if err != nil {
+				return nil, err
+			}
+			for _, receipt := range receipts {
+				if r := b.receiptFrom(hash, receipt); r != nil {
+					return r, nil
+				}
+			}
+		}
+		if n == 0 {
+			break
+		}
+	}
+	return nil, nil
+}
+
+// receiptFrom extracts the outcome of a user operation from the receipt of
+// the bundle transaction including it.
+func (b *Bundler) receiptFrom(hash common.Hash, receipt *types.Receipt) *UserOperationReceipt {
+	var (
+		event  = entryPointABI.Events["UserOperationEvent"]
+		revert = entryPointABI.Events["UserOperationRevertReason"]
+		start  int // index of the first log of the operation
+		result *UserOperationReceipt
+	)
+	for i, l := range receipt.Logs {
+		if len(l.Topics) < 2 || b.supported(l.Address) != nil {
+			continue
+		}
+		switch {
+		case l.Topics[0] == revert.ID && l.Topics[1] == hash:
+			values, err := revert.Inputs.NonIndexed().Unpack(l.Data)
+			if err == nil && result == nil {
+				result = &UserOperationReceipt{Reason: values[1].([]byte)}
+			}
+		case l.Topics[0] == event.ID && l.Topics[1] != hash:
+			start = i + 1
+		case l.Topics[0] == event.ID && len(l.Topics) == 4:
+			values, err := event.Inputs.NonIndexed().Unpack(l.Data)
+			if err != nil {
+				return nil
+			}
+			if result == nil {
+				result = new(UserOperationReceipt)
+			}
+			result.UserOpHash = hash
+			result.EntryPoint = l.Address
+			result.Sender = common.BytesToAddress(l.Topics[2].Bytes())
+			result.Paymaster = common.BytesToAddress(l.Topics[3].Bytes())
+			result.Nonce = (*hexutil.Big)(values[0].(*big.Int))
+			result.Success = values[1].(bool)
+			result.ActualGasCost = (*hexutil.Big)(values[2].(*big.Int))
+			result.ActualGasUsed = (*hexutil.Big)(values[3].(*big.Int))
+			result.Logs = receipt.Logs[start:i]
+			result.Receipt = receipt
+			return result
+		}
+	}
+	return nil
+}
@@ -0,0 +1,503 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package bundler implements an ERC-4337 user operation bundler running inside
+// the node. User operations are validated by simulation against the EntryPoint
+// under the ERC-7562 rules, pooled, and periodically bundled into handleOps
+// transactions submitted to the local transaction pool.
+package bundler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native" // registers the erc7562Tracer
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Config are the configuration parameters of the bundler.
+type Config struct {
+	EntryPoints     []common.Address // Supported EntryPoint contracts
+	Account         common.Address   // Account signing the bundles and receiving their fees
+	Interval        time.Duration    // Interval between bundles
+	MaxBundleGas    uint64           // Maximum total gas of the operations in a bundle
+	PoolSize        int              // Maximum number of pooled user operations
+	MaxOpsPerSender int              // Maximum number of pooled operations per unstaked sender
+	MinStake        *big.Int         // Minimum stake for an entity to be considered staked
+	MinUnstakeDelay uint64           // Minimum unstake delay, in seconds, for an entity to be considered staked
+}
+
+// DefaultConfig contains the default bundler settings.
+var DefaultConfig = Config{
+	EntryPoints:     []common.Address{DefaultEntryPoint},
+	Interval:        5 * time.Second,
+	MaxBundleGas:    5_000_000,
+	PoolSize:        4096,
+	MaxOpsPerSender: 4,
+	MinStake:        big.NewInt(1e18),
+	MinUnstakeDelay: 86400,
+}
+
+const (
+	// receiptLookback is the number of recent blocks searched for the receipt
+	// of a user operation not bundled by this node.
+	receiptLookback = 1024
+
+	// submittedCacheSize is the number of bundled user operations whose bundle
+	// transaction is remembered.
+	submittedCacheSize = 16384
+
+	// expiryMargin is the minimum remaining validity of a user operation, so
+	// that it can be included before it expires.
+	expiryMargin = 30 // seconds
+)
+
+// SignerFn signs a bundle transaction.
+type SignerFn func(tx *types.Transaction) (*types.Transaction, error)
+
+// Bundler pools ERC-4337 user operations and submits them in bundles.
+type Bundler struct {
+	backend ethapi.Backend
+	config  Config
+	sign    SignerFn
+
+	pool      *mempool
+	submitted *lru.Cache[common.Hash, common.Hash] // user operation hash -> bundle transaction hash
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// New creates a bundler submitting transactions through the given backend.
+func New(backend ethapi.Backend, config Config, sign SignerFn) *Bundler {
+	if len(config.EntryPoints) == 0 {
+		config.EntryPoints = DefaultConfig.EntryPoints
+	}
+	if config.Interval <= 0 {
+		config.Interval = DefaultConfig.Interval
+	}
+	if config.MaxBundleGas == 0 {
+		config.MaxBundleGas = DefaultConfig.MaxBundleGas
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = DefaultConfig.PoolSize
+	}
+	if config.MaxOpsPerSender <= 0 {
+		config.MaxOpsPerSender = DefaultConfig.MaxOpsPerSender
+	}
+	if config.MinStake == nil {
+		config.MinStake = DefaultConfig.MinStake
+	}
+	return &Bundler{
+		backend:   backend,
+		config:    config,
+		sign:      sign,
+		pool:      newMempool(config.PoolSize, config.MaxOpsPerSender),
+		submitted: lru.NewCache[common.Hash, common.Hash](submittedCacheSize),
+		quit:      make(chan struct{}),
+	}
+}
+
+// APIs returns the RPC services of the bundler.
+func (b *Bundler) APIs() []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "eth",
+			Service:   &API{b},
+		},
+	}
+}
+
+// Start implements node.Lifecycle, starting the bundling loop.
+func (b *Bundler) Start() error {
+	b.wg.Add(1)
+	go b.loop()
+	log.Info("Started user operation bundler", "entrypoints", b.config.EntryPoints, "account", b.config.Account)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the bundling loop.
+func (b *Bundler) Stop() error {
+	close(b.quit)
+	b.wg.Wait()
+	return nil
+}
+
+func (b *Bundler) loop() {
+	defer b.wg.Done()
+
+	ticker := time.NewTicker(b.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.track(context.Background())
+			for _, entryPoint := range b.config.EntryPoints {
+				if err := b.bundle(context.Background(), entryPoint); err != nil {
+					log.Warn("Failed to submit user operation bundle", "entrypoint", entryPoint, "err", err)
+				}
+			}
+		case <-b.quit:
+			return
+		}
+	}
+}
+
+func (b *Bundler) supported(entryPoint common.Address) error {
+	for _, addr := range b.config.EntryPoints {
+		if addr == entryPoint {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported entrypoint %s", entryPoint)
+}
+
+func (b *Bundler) staked(info stakeInfo) bool {
+	return info.Stake != nil && info.Stake.Cmp(b.config.MinStake) >= 0 &&
+		info.UnstakeDelaySec != nil && info.UnstakeDelaySec.Cmp(new(big.Int).SetUint64(b.config.MinUnstakeDelay)) >= 0
+}
+
+// Add validates a user operation and adds it to the pool, returning its hash.
+func (b *Bundler) Add(ctx context.Context, op *UserOperation, entryPoint common.Address) (common.Hash, error) {
+	if err := b.supported(entryPoint); err != nil {
+		return common.Hash{}, err
+	}
+	if err := op.sanityCheck(); err != nil {
+		return common.Hash{}, err
+	}
+	res, err := b.validate(ctx, op, entryPoint)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := op.Hash(entryPoint, b.backend.ChainConfig().GetChainID())
+	err = b.pool.add(&pooledOp{
+		op:           op.Copy(),
+		hash:         hash,
+		entryPoint:   entryPoint,
+		senderStaked: b.staked(res.SenderInfo),
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	log.Debug("Pooled user operation", "hash", hash, "sender", op.Sender, "nonce", op.Nonce)
+	return hash, nil
+}
+
+// simulate executes a call to the EntryPoint on top of the latest state,
+// returning the revert data the simulation methods end with.
+func (b *Bundler) simulate(ctx context.Context, entryPoint common.Address, data []byte, tracer vm.EVMLogger) ([]byte, error) {
+	state, header, err := b.backend.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	gas := header.GasLimit
+	if gasCap := b.backend.RPCGasCap(); gasCap != 0 && gasCap < gas {
+		gas = gasCap
+	}
+	msg := &core.Message{
+		From:              b.config.Account,
+		To:                &entryPoint,
+		Value:             new(big.Int),
+		GasLimit:          gas,
+		GasPrice:          new(big.Int),
+		GasFeeCap:         new(big.Int),
+		GasTipCap:         new(big.Int),
+		Data:              data,
+		SkipAccountChecks: true,
+	}
+	vmConfig := &vm.Config{NoBaseFee: true}
+	if tracer != nil {
+		vmConfig.Tracer = tracer
+	}
+	evm := b.backend.GetEVM(ctx, msg, state, header, vmConfig, nil)
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(gas))
+	if err != nil {
+		return nil, err
+	}
+	if !errors.Is(result.Err, vm.ErrExecutionReverted) {
+		return nil, fmt.Errorf("simulation did not revert: %v", result.Err)
+	}
+	return result.Revert(), nil
+}
+
+// erc7562Report is the result of the erc7562Tracer.
+type erc7562Report struct {
+	Violations []struct {
+		Entity   string         `json:"entity"`
+		Address  common.Address `json:"address"`
+		Rule     string         `json:"rule"`
+		Reason   string         `json:"reason"`
+		StakedOK bool           `json:"stakedOk"`
+	} `json:"violations"`
+}
+
+// validate simulates the validation of the user operation, enforcing the
+// ERC-7562 rules on the entities involved.
+func (b *Bundler) validate(ctx context.Context, op *UserOperation, entryPoint common.Address) (*validationResult, error) {
+	tracerConfig, _ := json.Marshal(map[string]interface{}{
+		"entryPoint": entryPoint,
+		"sender":     op.Sender,
+		"factory":    op.Factory(),
+		"paymaster":  op.Paymaster(),
+	})
+	tracer, err := tracers.DefaultDirectory.New("erc7562Tracer", new(tracers.Context), tracerConfig)
+	if err != nil {
+		return nil, err
+	}
+	data, err := entryPointABI.Pack("simulateValidation", op.abi())
+	if err != nil {
+		return nil, err
+	}
+	revert, err := b.simulate(ctx, entryPoint, data, tracer)
+	if err != nil {
+		return nil, err
+	}
+	res := new(validationResult)
+	if err := unpackEntryPointError("ValidationResult", revert, res); err != nil {
+		return nil, err
+	}
+	if res.ReturnInfo.SigFailed {
+		return nil, &revertError{reason: "invalid user operation signature"}
+	}
+	if until := res.ReturnInfo.ValidUntil; until != nil && until.Sign() != 0 && until.Uint64() < uint64(time.Now().Unix())+expiryMargin {
+		return nil, &revertError{reason: "user operation expires too soon"}
+	}
+
+	raw, err := tracer.GetResult()
+	if err != nil {
+		return nil, err
+	}
+	var report erc7562Report
+	if err := json.Unmarshal(raw, &report); err != nil {
+		return nil, err
+	}
+	stakes := map[string]bool{
+		"factory":   b.staked(res.FactoryInfo),
+		"account":   b.staked(res.SenderInfo),
+		"paymaster": b.staked(res.PaymasterInfo),
+	}
+	for _, v := range report.Violations {
+		if v.StakedOK && stakes[v.Entity] {
+			continue
+		}
+		return nil, &revertError{reason: fmt.Sprintf("%s %s: %s (%s)", v.Entity, v.Address, v.Reason, v.Rule)}
+	}
+	return res, nil
+}
+
+// GasEstimate holds the estimated gas limits of a user operation.
+type GasEstimate struct {
+	PreVerificationGas   uint64 `json:"preVerificationGas"`
+	VerificationGasLimit uint64 `json:"verificationGasLimit"`
+	CallGasLimit         uint64 `json:"callGasLimit"`
+}
+
+// callGasMeter records the gas used by the call into the sender's callData.
+type callGasMeter struct {
+	sender   common.Address
+	callData []byte
+	stack    []bool // whether each open frame is the metered call
+	used     uint64
+	found    bool
+}
+
+func (m *callGasMeter) CaptureTxStart(gasLimit uint64) {}
+func (m *callGasMeter) CaptureTxEnd(restGas uint64)    {}
+func (m *callGasMeter) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+}
+func (m *callGasMeter) CaptureEnd(output []byte, gasUsed uint64, err error) {}
+func (m *callGasMeter) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	m.stack = append(m.stack, typ == vm.CALL && to == m.sender && string(input) == string(m.callData))
+}
+func (m *callGasMeter) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(m.stack) == 0 {
+		return
+	}
+	if m.stack[len(m.stack)-1] {
+		m.used, m.found = gasUsed, true
+	}
+	m.stack = m.stack[:len(m.stack)-1]
+}
+func (m *callGasMeter) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+func (m *callGasMeter) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// Estimate estimates the gas limits of a user operation by simulating its
+// execution with generous limits and no fees. The signature may be a dummy.
+func (b *Bundler) Estimate(ctx context.Context, op *UserOperation, entryPoint common.Address) (*GasEstimate, error) {
+	if err := b.supported(entryPoint); err != nil {
+		return nil, err
+	}
+	_, header, err := b.backend.StateAndHeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil || err != nil {
+		return nil, err
+	}
+	sim := op.Copy()
+	sim.MaxFeePerGas, sim.MaxPriorityFeePerGas = new(hexutil.Big), new(hexutil.Big)
+	sim.VerificationGasLimit = uint64Big(header.GasLimit / 4)
+	sim.CallGasLimit = uint64Big(header.GasLimit / 2)
+	sim.PreVerificationGas = uint64Big(0)
+	estimate := &GasEstimate{PreVerificationGas: sim.calldataGas()}
+	sim.PreVerificationGas = uint64Big(estimate.PreVerificationGas)
+
+	data, err := entryPointABI.Pack("simulateHandleOp", sim.abi(), common.Address{}, []byte{})
+	if err != nil {
+		return nil, err
+	}
+	meter := &callGasMeter{sender: op.Sender, callData: op.CallData}
+	revert, err := b.simulate(ctx, entryPoint, data, meter)
+	if err != nil {
+		return nil, err
+	}
+	res := new(executionResult)
+	if err := unpackEntryPointError("ExecutionResult", revert, res); err != nil {
+		return nil, err
+	}
+	verification := new(big.Int).Sub(res.PreOpGas, new(big.Int).SetUint64(estimate.PreVerificationGas))
+	if verification.Sign() < 0 || !verification.IsUint64() {
+		return nil, fmt.Errorf("invalid pre-operation gas %v", res.PreOpGas)
+	}
+	// Add a margin of 10%, as the gas used may depend on the signature.
+	estimate.VerificationGasLimit = verification.Uint64() + verification.Uint64()/10
+	if len(op.CallData) > 0 && !meter.found {
+		return nil, errors.New("user operation call not executed")
+	}
+	// The EntryPoint forwards at most 63/64 of its remaining gas to the call.
+	call := meter.used * 64 / 63
+	estimate.CallGasLimit = call + call/10
+	return estimate, nil
+}
+
+// bundle revalidates the pending user operations of an EntryPoint and submits
+// as many as fit into a single handleOps transaction.
+func (b *Bundler) bundle(ctx context.Context, entryPoint common.Address) error {
+	var (
+		ops         []abiUserOperation
+		hashes      []common.Hash
+		gas         uint64
+		tip, feeCap *big.Int
+	)
+	for _, pop := range b.pool.pending(entryPoint) {
+		total := pop.op.TotalGas()
+		if gas+total > b.config.MaxBundleGas {
+			continue
+		}
+		// The state may have changed since the operation was pooled.
+		if _, err := b.validate(ctx, pop.op, entryPoint); err != nil {
+			log.Debug("Dropped invalid user operation", "hash", pop.hash, "err", err)
+			b.pool.remove(pop.hash)
+			continue
+		}
+		ops = append(ops, pop.op.abi())
+		hashes = append(hashes, pop.hash)
+		gas += total
+		if tip == nil || pop.op.MaxPriorityFeePerGas.ToInt().Cmp(tip) < 0 {
+			tip = pop.op.MaxPriorityFeePerGas.ToInt()
+		}
+		if feeCap == nil || pop.op.MaxFeePerGas.ToInt().Cmp(feeCap) < 0 {
+			feeCap = pop.op.MaxFeePerGas.ToInt()
+		}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	data, err := entryPointABI.Pack("handleOps", ops, b.config.Account)
+	if err != nil {
+		return err
+	}
+	nonce, err := b.backend.GetPoolNonce(ctx, b.config.Account)
+	if err != nil {
+		return err
+	}
+	var (
+		config = b.backend.ChainConfig()
+		head   = b.backend.CurrentHeader()
+		next   = new(big.Int).Add(head.Number, common.Big1)
+		txdata types.TxData
+	)
+	gas += bundleOverheadGas
+	if config.IsEnabled(config.GetEIP1559Transition, next) {
+		txdata = &types.DynamicFeeTx{
+			ChainID:   config.GetChainID(),
+			Nonce:     nonce,
+			GasTipCap: tip,
+			GasFeeCap: feeCap,
+			Gas:       gas,
+			To:        &entryPoint,
+			Value:     new(big.Int),
+			Data:      data,
+		}
+	} else {
+		txdata = &types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: feeCap,
+			Gas:      gas,
+			To:       &entryPoint,
+			Value:    new(big.Int),
+			Data:     data,
+		}
+	}
+	tx, err := b.sign(types.NewTx(txdata))
+	if err != nil {
+		return err
+	}
+	if err := b.backend.SendTx(ctx, tx); err != nil {
+		return err
+	}
+	b.pool.bundled(hashes, tx.Hash())
+	for _, hash := range hashes {
+		b.submitted.Add(hash, tx.Hash())
+	}
+	log.Info("Submitted user operation bundle", "entrypoint", entryPoint, "ops", len(hashes), "tx", tx.Hash())
+	return nil
+}
+
+// track resolves the submitted bundles. The user operations of the included
+// ones are dropped from the pool, while those of the bundles which left the
+// transaction pool without being included are bundled again.
+func (b *Bundler) track(ctx context.Context) {
+	for tx, hashes := range b.pool.bundles() {
+		found, _, _, _, _, err := b.backend.GetTransaction(ctx, tx)
+		if err != nil {
+			log.Debug("Failed to look up user operation bundle", "tx", tx, "err", err)
+			continue
+		}
+		switch {
+		case found:
+			for _, hash := range hashes {
+				b.pool.remove(hash)
+			}
+		case b.backend.GetPoolTransaction(tx) == nil:
+			log.Debug("Rebundling user operations of dropped bundle", "tx", tx, "ops", len(hashes))
+			b.pool.unbundle(tx)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultEntryPoint is the address of the ERC-4337 EntryPoint v0.6 contract,
+// which is deployed at the same address on every chain.
+var DefaultEntryPoint = common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+
+const userOpTuple = `{"name":"userOp","type":"tuple","components":[
+	{"name":"sender","type":"address"},
+	{"name":"nonce","type":"uint256"},
+	{"name":"initCode","type":"bytes"},
+	{"name":"callData","type":"bytes"},
+	{"name":"callGasLimit","type":"uint256"},
+	{"name":"verificationGasLimit","type":"uint256"},
+	{"name":"preVerificationGas","type":"uint256"},
+	{"name":"maxFeePerGas","type":"uint256"},
+	{"name":"maxPriorityFeePerGas","type":"uint256"},
+	{"name":"paymasterAndData","type":"bytes"},
+	{"name":"signature","type":"bytes"}]}`
+
+const stakeInfoComponents = `[{"name":"stake","type":"uint256"},{"name":"unstakeDelaySec","type":"uint256"}]`
+
+// entryPointABI is the subset of the EntryPoint v0.6 interface used by the
+// bundler.
+var entryPointABI = mustParseABI(`[
+	{"type":"function","name":"simulateValidation","inputs":[` + userOpTuple + `],"outputs":[]},
+	{"type":"function","name":"simulateHandleOp","inputs":[` + userOpTuple + `,
+		{"name":"target","type":"address"},{"name":"targetCallData","type":"bytes"}],"outputs":[]},
+	{"type":"function","name":"handleOps","inputs":[` + strings.Replace(strings.Replace(userOpTuple, `"userOp"`, `"ops"`, 1), `"tuple"`, `"tuple[]"`, 1) + `,
+		{"name":"beneficiary","type":"address"}],"outputs":[]},
+	{"type":"error","name":"FailedOp","inputs":[{"name":"opIndex","type":"uint256"},{"name":"reason","type":"string"}]},
+	{"type":"error","name":"ValidationResult","inputs":[
+		{"name":"returnInfo","type":"tuple","components":[
+			{"name":"preOpGas","type":"uint256"},
+			{"name":"prefund","type":"uint256"},
+			{"name":"sigFailed","type":"bool"},
+			{"name":"validAfter","type":"uint48"},
+			{"name":"validUntil","type":"uint48"},
+			{"name":"paymasterContext","type":"bytes"}]},
+		{"name":"senderInfo","type":"tuple","components":` + stakeInfoComponents + `},
+		{"name":"factoryInfo","type":"tuple","components":` + stakeInfoComponents + `},
+		{"name":"paymasterInfo","type":"tuple","components":` + stakeInfoComponents + `}]},
+	{"type":"error","name":"ValidationResultWithAggregation","inputs":[
+		{"name":"returnInfo","type":"tuple","components":[
+			{"name":"preOpGas","type":"uint256"},
+			{"name":"prefund","type":"uint256"},
+			{"name":"sigFailed","type":"bool"},
+			{"name":"validAfter","type":"uint48"},
+			{"name":"validUntil","type":"uint48"},
+			{"name":"paymasterContext","type":"bytes"}]},
+		{"name":"senderInfo","type":"tuple","components":` + stakeInfoComponents + `},
+		{"name":"factoryInfo","type":"tuple","components":` + stakeInfoComponents + `},
+		{"name":"paymasterInfo","type":"tuple","components":` + stakeInfoComponents + `},
+		{"name":"aggregatorInfo","type":"tuple","components":[
+			{"name":"aggregator","type":"address"},
+			{"name":"stakeInfo","type":"tuple","components":` + stakeInfoComponents + `}]}]},
+	{"type":"error","name":"ExecutionResult","inputs":[
+		{"name":"preOpGas","type":"uint256"},
+		{"name":"paid","type":"uint256"},
+		{"name":"validAfter","type":"uint48"},
+		{"name":"validUntil","type":"uint48"},
+		{"name":"targetSuccess","type":"bool"},
+		{"name":"targetResult","type":"bytes"}]},
+	{"type":"event","name":"UserOperationEvent","anonymous":false,"inputs":[
+		{"name":"userOpHash","type":"bytes32","indexed":true},
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"paymaster","type":"address","indexed":true},
+		{"name":"nonce","type":"uint256","indexed":false},
+		{"name":"success","type":"bool","indexed":false},
+		{"name":"actualGasCost","type":"uint256","indexed":false},
+		{"name":"actualGasUsed","type":"uint256","indexed":false}]},
+	{"type":"event","name":"UserOperationRevertReason","anonymous":false,"inputs":[
+		{"name":"userOpHash","type":"bytes32","indexed":true},
+		{"name":"sender","type":"address","indexed":true},
+		{"name":"nonce","type":"uint256","indexed":false},
+		{"name":"revertReason","type":"bytes","indexed":false}]}
+]`)
+
+func mustParseABI(def string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(def))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func mustNewType(t string, components ...abi.ArgumentMarshaling) abi.Type {
+	typ, err := abi.NewType(t, "", components)
+	if err != nil {
+		panic(err)
+	}
+	return typ
+}
+
+var (
+	uint256Type = mustNewType("uint256")
+	bytes32Type = mustNewType("bytes32")
+	addressType = mustNewType("address")
+
+	// userOpArgs encodes a single user operation.
+	userOpArgs = entryPointABI.Methods["simulateValidation"].Inputs
+
+	// userOpPackArgs encodes the fields of a user operation hashed by
+	// EntryPoint.getUserOpHash, with the dynamic fields replaced by their hashes.
+	userOpPackArgs = abi.Arguments{
+		{Type: addressType}, {Type: uint256Type},
+		{Type: bytes32Type}, {Type: bytes32Type},
+		{Type: uint256Type}, {Type: uint256Type}, {Type: uint256Type},
+		{Type: uint256Type}, {Type: uint256Type},
+		{Type: bytes32Type},
+	}
+	// userOpHashArgs encodes the packed user operation hash along with the
+	// EntryPoint and chain.
+	userOpHashArgs = abi.Arguments{{Type: bytes32Type}, {Type: addressType}, {Type: uint256Type}}
+)
+
+// stakeInfo is the stake of an entity in the EntryPoint.
+type stakeInfo struct {
+	Stake           *big.Int
+	UnstakeDelaySec *big.Int
+}
+
+// validationResult is the outcome of EntryPoint.simulateValidation.
+type validationResult struct {
+	ReturnInfo struct {
+		PreOpGas         *big.Int
+		Prefund          *big.Int
+		SigFailed        bool
+		ValidAfter       *big.Int
+		ValidUntil       *big.Int
+		PaymasterContext []byte
+	}
+	SenderInfo    stakeInfo
+	FactoryInfo   stakeInfo
+	PaymasterInfo stakeInfo
+}
+
+// executionResult is the outcome of EntryPoint.simulateHandleOp.
+type executionResult struct {
+	PreOpGas      *big.Int
+	Paid          *big.Int
+	ValidAfter    *big.Int
+	ValidUntil    *big.Int
+	TargetSuccess bool
+	TargetResult  []byte
+}
+
+// revertError is the error reported for user operations rejected by the
+// EntryPoint.
+type revertError struct {
+	reason string
+}
+
+func (e *revertError) Error() string {
+	return e.reason
+}
+
+// unpackEntryPointError decodes a custom error raised by the EntryPoint into
+// out, which must match the named error. FailedOp and other reverts are
+// returned as errors.
+func unpackEntryPointError(name string, data []byte, out interface{}) error {
+	if len(data) < 4 {
+		return &revertError{reason: "execution reverted without reason"}
+	}
+	abiErr := entryPointABI.Errors[name]
+	if bytes.Equal(data[:4], abiErr.ID[:4]) {
+		values, err := abiErr.Inputs.Unpack(data[4:])
+		if err != nil {
+			return err
+		}
+		return abiErr.Inputs.Copy(out, values)
+	}
+	if failed := entryPointABI.Errors["FailedOp"]; bytes.Equal(data[:4], failed.ID[:4]) {
+		values, err := failed.Inputs.Unpack(data[4:])
+		if err != nil {
+			return err
+		}
+		return &revertError{reason: values[1].(string)}
+	}
+	if aggregated := entryPointABI.Errors["ValidationResultWithAggregation"]; bytes.Equal(data[:4], aggregated.ID[:4]) {
+		return &revertError{reason: "signature aggregators are not supported"}
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return &revertError{reason: reason}
+	}
+	return &revertError{reason: fmt.Sprintf("unexpected revert data %#x", data)}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func packEntryPointError(t *testing.T, name string, args ...interface{}) []byte {
+	t.Helper()
+	abiErr := entryPointABI.Errors[name]
+	enc, err := abiErr.Inputs.Pack(args...)
+	if err != nil {
+		t.Fatalf("failed to pack %s: %v", name, err)
+	}
+	return append(abiErr.ID[:4:4], enc...)
+}
+
+func TestUnpackEntryPointError(t *testing.T) {
+	var want validationResult
+	want.ReturnInfo.PreOpGas = big.NewInt(50000)
+	want.ReturnInfo.Prefund = big.NewInt(1000)
+	want.ReturnInfo.ValidAfter = big.NewInt(10)
+	want.ReturnInfo.ValidUntil = big.NewInt(20)
+	want.ReturnInfo.PaymasterContext = []byte{0x01}
+	want.SenderInfo = stakeInfo{big.NewInt(0), big.NewInt(0)}
+	want.FactoryInfo = stakeInfo{big.NewInt(1), big.NewInt(2)}
+	want.PaymasterInfo = stakeInfo{big.NewInt(3), big.NewInt(4)}
+
+	data := packEntryPointError(t, "ValidationResult", want.ReturnInfo, want.SenderInfo, want.FactoryInfo, want.PaymasterInfo)
+	var have validationResult
+	if err := unpackEntryPointError("ValidationResult", data, &have); err != nil {
+		t.Fatalf("failed to unpack validation result: %v", err)
+	}
+	if have.ReturnInfo.PreOpGas.Cmp(want.ReturnInfo.PreOpGas) != 0 || have.ReturnInfo.ValidUntil.Cmp(want.ReturnInfo.ValidUntil) != 0 ||
+		have.PaymasterInfo.UnstakeDelaySec.Cmp(want.PaymasterInfo.UnstakeDelaySec) != 0 {
+		t.Fatalf("validation result mismatch: have %+v, want %+v", have, want)
+	}
+
+	data = packEntryPointError(t, "FailedOp", big.NewInt(0), "AA21 didn't pay prefund")
+	err := unpackEntryPointError("ValidationResult", data, &have)
+	var revert *revertError
+	if !errors.As(err, &revert) || revert.reason != "AA21 didn't pay prefund" {
+		t.Fatalf("failed op error mismatch: have %v", err)
+	}
+	if err := unpackEntryPointError("ValidationResult", nil, &have); err == nil {
+		t.Fatal("empty revert data accepted")
+	}
+}
+
+func TestUserOperationHash(t *testing.T) {
+	op := newTestOp([20]byte{0x01}, 0, 100, 10).op
+	hash := op.Hash(DefaultEntryPoint, big.NewInt(1))
+	if hash != op.Copy().Hash(DefaultEntryPoint, big.NewInt(1)) {
+		t.Fatal("copied operation hash mismatch")
+	}
+	if hash == op.Hash(DefaultEntryPoint, big.NewInt(2)) {
+		t.Fatal("operation hash does not commit to the chain")
+	}
+	op.Signature = []byte{0x01}
+	if hash != op.Hash(DefaultEntryPoint, big.NewInt(1)) {
+		t.Fatal("operation hash commits to the signature")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrPoolFull is returned if the user operation mempool is full.
+	ErrPoolFull = errors.New("user operation pool full")
+
+	// ErrAlreadyKnown is returned if the user operation is already pooled.
+	ErrAlreadyKnown = errors.New("user operation already known")
+
+	// ErrReplaceUnderpriced is returned if a user operation replacing another
+	// one with the same sender and nonce does not bump both fees enough.
+	ErrReplaceUnderpriced = errors.New("replacement user operation underpriced")
+
+	// ErrSenderLimit is returned if an unstaked sender already has the maximum
+	// number of user operations pooled.
+	ErrSenderLimit = errors.New("too many user operations from sender")
+
+	// ErrAlreadyBundled is returned if a user operation replacing another one
+	// with the same sender and nonce arrives after that one was bundled.
+	ErrAlreadyBundled = errors.New("user operation already bundled")
+)
+
+// replaceBump is the minimum fee increase, in percent, for replacing a pooled
+// user operation.
+const replaceBump = 10
+
+// pooledOp is a validated user operation waiting to be bundled.
+type pooledOp struct {
+	op           *UserOperation
+	hash         common.Hash
+	entryPoint   common.Address
+	senderStaked bool
+	bundle       common.Hash // Transaction the operation was bundled in, zero if pending
+}
+
+// mempool keeps the validated user operations, indexed by hash and by sender.
+type mempool struct {
+	mu        sync.Mutex
+	size      int
+	perSender int
+	ops       map[common.Hash]*pooledOp
+	senders   map[common.Address][]*pooledOp // sorted by nonce
+}
+
+func newMempool(size, perSender int) *mempool {
+	return &mempool{
+		size:      size,
+		perSender: perSender,
+		ops:       make(map[common.Hash]*pooledOp),
+		senders:   make(map[common.Address][]*pooledOp),
+	}
+}
+
+// bumped reports whether fee is at least replaceBump percent above old.
+func bumped(fee, old *big.Int) bool {
+	min := new(big.Int).Mul(old, big.NewInt(100+replaceBump))
+	min.Div(min, big.NewInt(100))
+	return fee.Cmp(min) >= 0
+}
+
+// add inserts a validated user operation, replacing a pooled one with the same
+// sender and nonce if it pays sufficiently higher fees.
+func (p *mempool) add(pop *pooledOp) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.ops[pop.hash]; ok {
+		return ErrAlreadyKnown
+	}
+	queue := p.senders[pop.op.Sender]
+	for i, old := range queue {
+		if old.op.Nonce.ToInt().Cmp(pop.op.Nonce.ToInt()) != 0 {
+			continue
+		}
+		if old.bundle != (common.Hash{}) {
+			return ErrAlreadyBundled
+		}
+		if !bumped(pop.op.MaxFeePerGas.ToInt(), old.op.MaxFeePerGas.ToInt()) ||
+			!bumped(pop.op.MaxPriorityFeePerGas.ToInt(), old.op.MaxPriorityFeePerGas.ToInt()) {
+			return ErrReplaceUnderpriced
+		}
+		delete(p.ops, old.hash)
+		queue[i] = pop
+		p.ops[pop.hash] = pop
+		return nil
+	}
+	if len(queue) >= p.perSender && !pop.senderStaked {
+		return ErrSenderLimit
+	}
+	if len(p.ops) >= p.size {
+		return ErrPoolFull
+	}
+	queue = append(queue, pop)
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].op.Nonce.ToInt().Cmp(queue[j].op.Nonce.ToInt()) < 0
+	})
+	p.senders[pop.op.Sender] = queue
+	p.ops[pop.hash] = pop
+	return nil
+}
+
+// get returns the pooled user operation with the given hash.
+func (p *mempool) get(hash common.Hash) *pooledOp {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.ops[hash]
+}
+
+// remove drops the user operation with the given hash.
+func (p *mempool) remove(hash common.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pop, ok := p.ops[hash]
+	if !ok {
+		return
+	}
+	delete(p.ops, hash)
+	queue := p.senders[pop.op.Sender]
+	for i, op := range queue {
+		if op.hash == hash {
+			queue = append(queue[:i:i], queue[i+1:]...)
+			break
+		}
+	}
+	if len(queue) == 0 {
+		delete(p.senders, pop.op.Sender)
+	} else {
+		p.senders[pop.op.Sender] = queue
+	}
+}
+
+// bundled marks the user operations with the given hashes as submitted in a
+// bundle transaction. They are kept until the bundle is resolved.
+func (p *mempool) bundled(hashes []common.Hash, tx common.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, hash := range hashes {
+		if pop, ok := p.ops[hash]; ok {
+			pop.bundle = tx
+		}
+	}
+}
+
+// bundles returns the hashes of the bundled user operations, grouped by the
+// bundle transaction they were submitted in.
+func (p *mempool) bundles() map[common.Hash][]common.Hash {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	bundles := make(map[common.Hash][]common.Hash)
+	for hash, pop := range p.ops {
+		if pop.bundle != (common.Hash{}) {
+			bundles[pop.bundle] = append(bundles[pop.bundle], hash)
+		}
+	}
+	return bundles
+}
+
+// unbundle returns the user operations submitted in the given bundle
+// transaction to the pending ones.
+func (p *mempool) unbundle(tx common.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, pop := range p.ops {
+		if pop.bundle == tx {
+			pop.bundle = common.Hash{}
+		}
+	}
+}
+
+// pending returns the lowest-nonce user operation of each sender for the given
+// EntryPoint, ordered by decreasing priority fee. Senders whose lowest-nonce
+// operation awaits the inclusion of its bundle are skipped.
+func (p *mempool) pending(entryPoint common.Address) []*pooledOp {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var ops []*pooledOp
+	for _, queue := range p.senders {
+		for _, pop := range queue {
+			if pop.entryPoint == entryPoint {
+				if pop.bundle == (common.Hash{}) {
+					ops = append(ops, pop)
+				}
+				break
+			}
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		if c := ops[i].op.MaxPriorityFeePerGas.ToInt().Cmp(ops[j].op.MaxPriorityFeePerGas.ToInt()); c != 0 {
+			return c > 0
+		}
+		return ops[i].hash.Cmp(ops[j].hash) < 0
+	})
+	return ops
+}
+
+// len returns the number of pooled user operations.
+func (p *mempool) len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.ops)
+}
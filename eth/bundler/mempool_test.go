@@ -0,0 +1,154 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func newTestOp(sender common.Address, nonce, maxFee, tip uint64) *pooledOp {
+	op := &UserOperation{
+		Sender:               sender,
+		Nonce:                uint64Big(nonce),
+		CallGasLimit:         uint64Big(100000),
+		VerificationGasLimit: uint64Big(100000),
+		PreVerificationGas:   uint64Big(50000),
+		MaxFeePerGas:         uint64Big(maxFee),
+		MaxPriorityFeePerGas: uint64Big(tip),
+	}
+	return &pooledOp{op: op, hash: op.Hash(DefaultEntryPoint, big.NewInt(1)), entryPoint: DefaultEntryPoint}
+}
+
+func TestMempoolReplacement(t *testing.T) {
+	pool := newMempool(16, 4)
+	sender := common.HexToAddress("0x01")
+
+	if err := pool.add(newTestOp(sender, 0, 100, 10)); err != nil {
+		t.Fatalf("failed to add operation: %v", err)
+	}
+	if err := pool.add(newTestOp(sender, 0, 100, 10)); !errors.Is(err, ErrAlreadyKnown) {
+		t.Fatalf("duplicate operation: have %v, want %v", err, ErrAlreadyKnown)
+	}
+	if err := pool.add(newTestOp(sender, 0, 109, 11)); !errors.Is(err, ErrReplaceUnderpriced) {
+		t.Fatalf("underpriced replacement: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	replacement := newTestOp(sender, 0, 110, 11)
+	if err := pool.add(replacement); err != nil {
+		t.Fatalf("failed to replace operation: %v", err)
+	}
+	if pool.len() != 1 {
+		t.Fatalf("pool size mismatch: have %d, want 1", pool.len())
+	}
+	if pool.get(replacement.hash) == nil {
+		t.Fatal("replacement operation not pooled")
+	}
+}
+
+func TestMempoolSenderLimit(t *testing.T) {
+	pool := newMempool(16, 2)
+	sender := common.HexToAddress("0x01")
+
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := pool.add(newTestOp(sender, nonce, 100, 10)); err != nil {
+			t.Fatalf("failed to add operation %d: %v", nonce, err)
+		}
+	}
+	if err := pool.add(newTestOp(sender, 2, 100, 10)); !errors.Is(err, ErrSenderLimit) {
+		t.Fatalf("unstaked sender over limit: have %v, want %v", err, ErrSenderLimit)
+	}
+	staked := newTestOp(sender, 2, 100, 10)
+	staked.senderStaked = true
+	if err := pool.add(staked); err != nil {
+		t.Fatalf("staked sender over limit rejected: %v", err)
+	}
+}
+
+func TestMempoolPending(t *testing.T) {
+	pool := newMempool(16, 4)
+	a, b := common.HexToAddress("0x0a"), common.HexToAddress("0x0b")
+
+	ops := []*pooledOp{
+		newTestOp(a, 1, 100, 50),
+		newTestOp(a, 0, 100, 1),
+		newTestOp(b, 0, 100, 20),
+	}
+	for _, op := range ops {
+		if err := pool.add(op); err != nil {
+			t.Fatalf("failed to add operation: %v", err)
+		}
+	}
+	// Only the lowest nonce of each sender is pending, ordered by tip.
+	pending := pool.pending(DefaultEntryPoint)
+	if len(pending) != 2 {
+		t.Fatalf("pending count mismatch: have %d, want 2", len(pending))
+	}
+	if pending[0].hash != ops[2].hash || pending[1].hash != ops[1].hash {
+		t.Fatalf("pending order mismatch")
+	}
+	pool.remove(ops[1].hash)
+	pending = pool.pending(DefaultEntryPoint)
+	if len(pending) != 2 || pending[0].hash != ops[0].hash {
+		t.Fatalf("next nonce not pending after removal")
+	}
+	if len(pool.pending(common.HexToAddress("0xee"))) != 0 {
+		t.Fatal("operations pending for unrelated entrypoint")
+	}
+}
+
+func TestMempoolBundled(t *testing.T) {
+	pool := newMempool(16, 4)
+	a, b := common.HexToAddress("0x0a"), common.HexToAddress("0x0b")
+
+	ops := []*pooledOp{
+		newTestOp(a, 0, 100, 10),
+		newTestOp(a, 1, 100, 10),
+		newTestOp(b, 0, 100, 10),
+	}
+	for _, op := range ops {
+		if err := pool.add(op); err != nil {
+			t.Fatalf("failed to add operation: %v", err)
+		}
+	}
+	// Bundled operations stay pooled, but hold back their senders
+	tx := common.HexToHash("0x01")
+	pool.bundled([]common.Hash{ops[0].hash}, tx)
+
+	if pool.len() != 3 {
+		t.Fatalf("pool size mismatch: have %d, want 3", pool.len())
+	}
+	if pending := pool.pending(DefaultEntryPoint); len(pending) != 1 || pending[0].hash != ops[2].hash {
+		t.Fatalf("bundled sender still pending")
+	}
+	if bundles := pool.bundles(); len(bundles) != 1 || len(bundles[tx]) != 1 || bundles[tx][0] != ops[0].hash {
+		t.Fatalf("bundles mismatch: %v", bundles)
+	}
+	if err := pool.add(newTestOp(a, 0, 200, 20)); !errors.Is(err, ErrAlreadyBundled) {
+		t.Fatalf("bundled replacement: have %v, want %v", err, ErrAlreadyBundled)
+	}
+	// A dropped bundle returns its operations to pending
+	pool.unbundle(tx)
+	if pending := pool.pending(DefaultEntryPoint); len(pending) != 2 {
+		t.Fatalf("pending count mismatch after unbundling: have %d, want 2", len(pending))
+	}
+	if len(pool.bundles()) != 0 {
+		t.Fatal("bundle still tracked after unbundling")
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// UserOperation is an ERC-4337 user operation, in the format of EntryPoint
+// v0.6.
+type UserOperation struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+// abiUserOperation is the ABI representation of a UserOperation.
+type abiUserOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+func bigOrZero(b *hexutil.Big) *big.Int {
+	if b == nil {
+		return new(big.Int)
+	}
+	return (*big.Int)(b)
+}
+
+func uint64Big(n uint64) *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).SetUint64(n))
+}
+
+func (op *UserOperation) abi() abiUserOperation {
+	return abiUserOperation{
+		Sender:               op.Sender,
+		Nonce:                bigOrZero(op.Nonce),
+		InitCode:             op.InitCode,
+		CallData:             op.CallData,
+		CallGasLimit:         bigOrZero(op.CallGasLimit),
+		VerificationGasLimit: bigOrZero(op.VerificationGasLimit),
+		PreVerificationGas:   bigOrZero(op.PreVerificationGas),
+		MaxFeePerGas:         bigOrZero(op.MaxFeePerGas),
+		MaxPriorityFeePerGas: bigOrZero(op.MaxPriorityFeePerGas),
+		PaymasterAndData:     op.PaymasterAndData,
+		Signature:            op.Signature,
+	}
+}
+
+// Copy returns a deep copy of the user operation.
+func (op *UserOperation) Copy() *UserOperation {
+	cpy := &UserOperation{
+		Sender:           op.Sender,
+		InitCode:         common.CopyBytes(op.InitCode),
+		CallData:         common.CopyBytes(op.CallData),
+		PaymasterAndData: common.CopyBytes(op.PaymasterAndData),
+		Signature:        common.CopyBytes(op.Signature),
+	}
+	for _, f := range []struct {
+		dst **hexutil.Big
+		src *hexutil.Big
+	}{
+		{&cpy.Nonce, op.Nonce},
+		{&cpy.CallGasLimit, op.CallGasLimit},
+		{&cpy.VerificationGasLimit, op.VerificationGasLimit},
+		{&cpy.PreVerificationGas, op.PreVerificationGas},
+		{&cpy.MaxFeePerGas, op.MaxFeePerGas},
+		{&cpy.MaxPriorityFeePerGas, op.MaxPriorityFeePerGas},
+	} {
+		*f.dst = (*hexutil.Big)(new(big.Int).Set(bigOrZero(f.src)))
+	}
+	return cpy
+}
+
+// Factory returns the factory deploying the sender, if any.
+func (op *UserOperation) Factory() *common.Address {
+	if len(op.InitCode) < common.AddressLength {
+		return nil
+	}
+	addr := common.BytesToAddress(op.InitCode[:common.AddressLength])
+	return &addr
+}
+
+// Paymaster returns the paymaster sponsoring the operation, if any.
+func (op *UserOperation) Paymaster() *common.Address {
+	if len(op.PaymasterAndData) < common.AddressLength {
+		return nil
+	}
+	addr := common.BytesToAddress(op.PaymasterAndData[:common.AddressLength])
+	return &addr
+}
+
+// TotalGas returns the total gas the operation may consume.
+func (op *UserOperation) TotalGas() uint64 {
+	gas := new(big.Int).Add(bigOrZero(op.CallGasLimit), bigOrZero(op.VerificationGasLimit))
+	gas.Add(gas, bigOrZero(op.PreVerificationGas))
+	if op.Paymaster() != nil {
+		// The paymaster's postOp may use up to the verification gas limit again.
+		gas.Add(gas, bigOrZero(op.VerificationGasLimit))
+	}
+	if !gas.IsUint64() {
+		return ^uint64(0)
+	}
+	return gas.Uint64()
+}
+
+// sanityCheck verifies the static validity of the operation.
+func (op *UserOperation) sanityCheck() error {
+	switch {
+	case op.Nonce == nil:
+		return errors.New("missing nonce")
+	case op.CallGasLimit == nil, op.VerificationGasLimit == nil, op.PreVerificationGas == nil:
+		return errors.New("missing gas limits")
+	case op.MaxFeePerGas == nil, op.MaxPriorityFeePerGas == nil:
+		return errors.New("missing fees")
+	case op.MaxPriorityFeePerGas.ToInt().Cmp(op.MaxFeePerGas.ToInt()) > 0:
+		return errors.New("maxPriorityFeePerGas higher than maxFeePerGas")
+	case len(op.InitCode) > 0 && len(op.InitCode) < common.AddressLength:
+		return errors.New("initCode too short")
+	case len(op.PaymasterAndData) > 0 && len(op.PaymasterAndData) < common.AddressLength:
+		return errors.New("paymasterAndData too short")
+	case op.PreVerificationGas.ToInt().Cmp(new(big.Int).SetUint64(op.calldataGas())) < 0:
+		return errors.New("preVerificationGas too low")
+	}
+	return nil
+}
+
+// Hash returns the hash identifying the operation at the given EntryPoint and
+// chain, as computed by EntryPoint.getUserOpHash.
+func (op *UserOperation) Hash(entryPoint common.Address, chainID *big.Int) common.Hash {
+	o := op.abi()
+	packed, err := userOpPackArgs.Pack(
+		o.Sender, o.Nonce,
+		crypto.Keccak256Hash(o.InitCode), crypto.Keccak256Hash(o.CallData),
+		o.CallGasLimit, o.VerificationGasLimit, o.PreVerificationGas,
+		o.MaxFeePerGas, o.MaxPriorityFeePerGas,
+		crypto.Keccak256Hash(o.PaymasterAndData),
+	)
+	if err != nil {
+		panic(err)
+	}
+	enc, err := userOpHashArgs.Pack(crypto.Keccak256Hash(packed), entryPoint, chainID)
+	if err != nil {
+		panic(err)
+	}
+	return crypto.Keccak256Hash(enc)
+}
+
+// Gas overheads of the handleOps call charged to each operation through its
+// preVerificationGas.
+const (
+	bundleOverheadGas = 21000 // Intrinsic gas of the bundle transaction
+	perUserOpGas      = 18300 // EntryPoint bookkeeping per operation
+	perUserOpWordGas  = 4     // ABI encoding overhead per calldata word
+	zeroByteGas       = 4
+	nonZeroByteGas    = 16
+	expectedBundleLen = 1
+)
+
+// calldataGas returns the preVerificationGas the operation must pay for its
+// share of the bundle transaction, following the estimation of the reference
+// bundler.
+func (op *UserOperation) calldataGas() uint64 {
+	o := op.abi()
+	packed, err := userOpArgs.Pack(o)
+	if err != nil {
+		panic(err)
+	}
+	// Strip the tuple offset, as the operation is encoded inline in the bundle.
+	packed = packed[32:]
+	var gas uint64
+	for _, b := range packed {
+		if b == 0 {
+			gas += zeroByteGas
+		} else {
+			gas += nonZeroByteGas
+		}
+	}
+	words := uint64(len(packed)+31) / 32
+	return gas + bundleOverheadGas/expectedBundleLen + perUserOpGas + words*perUserOpWordGas
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package bundler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+)
+
+// callCode returns code performing a zero-value CALL to addr with all the
+// remaining gas.
+func callCode(addr common.Address) []byte {
+	code := []byte{
+		byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0, byte(vm.PUSH1), 0,
+		byte(vm.PUSH20),
+	}
+	code = append(code, addr.Bytes()...)
+	return append(code, byte(vm.GAS), byte(vm.CALL), byte(vm.POP))
+}
+
+// Tests that the validation tracer attributes the opcodes and storage accesses
+// to the entity being validated, as delimited by the EntryPoint with NUMBER.
+func TestValidationTracer(t *testing.T) {
+	var (
+		entryPoint = common.HexToAddress("0xee")
+		sender     = common.HexToAddress("0xaa")
+		paymaster  = common.HexToAddress("0xbb")
+	)
+	// The EntryPoint validates the account, then the paymaster.
+	var epCode []byte
+	epCode = append(epCode, byte(vm.NUMBER), byte(vm.POP))
+	epCode = append(epCode, callCode(sender)...)
+	epCode = append(epCode, byte(vm.NUMBER), byte(vm.POP))
+	epCode = append(epCode, callCode(paymaster)...)
+	epCode = append(epCode, byte(vm.STOP))
+
+	statedb, _ := state.New(types.EmptyRootHash, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetCode(entryPoint, epCode)
+	// The account reads its own storage, which is allowed, but also uses
+	// TIMESTAMP and GAS without a call.
+	statedb.SetCode(sender, []byte{
+		byte(vm.TIMESTAMP), byte(vm.POP),
+		byte(vm.GAS), byte(vm.POP),
+		byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP),
+		byte(vm.STOP),
+	})
+	// The paymaster reads its own storage, which requires it to be staked.
+	statedb.SetCode(paymaster, []byte{byte(vm.PUSH1), 0, byte(vm.SLOAD), byte(vm.POP), byte(vm.STOP)})
+
+	tracerConfig, _ := json.Marshal(map[string]interface{}{
+		"entryPoint": entryPoint,
+		"sender":     sender,
+		"paymaster":  paymaster,
+	})
+	tracer, err := tracers.DefaultDirectory.New("erc7562Tracer", new(tracers.Context), tracerConfig)
+	if err != nil {
+		t.Fatalf("failed to create tracer: %v", err)
+	}
+	cfg := &runtime.Config{State: statedb, EVMConfig: vm.Config{Tracer: tracer}}
+	if _, _, err := runtime.Call(entryPoint, nil, cfg); err != nil {
+		t.Fatalf("failed to execute: %v", err)
+	}
+	raw, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to get tracer result: %v", err)
+	}
+	var report erc7562Report
+	if err := json.Unmarshal(raw, &report); err != nil {
+		t.Fatalf("failed to decode tracer result: %v", err)
+	}
+	want := []struct {
+		entity   string
+		addr     common.Address
+		rule     string
+		stakedOK bool
+	}{
+		{"account", sender, "OP-011", false},
+		{"account", sender, "OP-012", false},
+		{"paymaster", paymaster, "STO-031", true},
+	}
+	if len(report.Violations) != len(want) {
+		t.Fatalf("violation count mismatch: have %d, want %d: %s", len(report.Violations), len(want), raw)
+	}
+	for i, v := range report.Violations {
+		if v.Entity != want[i].entity || v.Address != want[i].addr || v.Rule != want[i].rule || v.StakedOK != want[i].stakedOK {
+			t.Errorf("violation %d mismatch: have %+v, want %+v", i, v, want[i])
+		}
+	}
+}
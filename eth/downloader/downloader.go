@@ -27,6 +27,7 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/checkpoint"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -34,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params/vars"
 	"github.com/ethereum/go-ethereum/triedb"
 )
@@ -86,6 +88,24 @@ var (
 	ErrMergeTransition         = errors.New("legacy sync reached the merge")
 )
 
+// PeerOffense returns the offense committed by the peer a synchronisation
+// failed with the given error, if the failure is attributable to it.
+func PeerOffense(err error) (p2p.Offense, bool) {
+	switch {
+	case errors.Is(err, errInvalidChain), errors.Is(err, errInvalidBody), errors.Is(err, errInvalidReceipt):
+		return p2p.OffenseInvalidBlock, true
+	case errors.Is(err, errInvalidAncestor):
+		return p2p.OffenseInvalidHeader, true
+	case errors.Is(err, errBadPeer):
+		return p2p.OffenseProtocolViolation, true
+	case errors.Is(err, errEmptyHeaderSet):
+		return p2p.OffenseUselessResponse, true
+	case errors.Is(err, errTimeout), errors.Is(err, errStallingPeer):
+		return p2p.OffenseTimeout, true
+	}
+	return 0, false
+}
+
 // peerDropFn is a callback type for dropping a peer detected as malicious.
 type peerDropFn func(id string)
 
@@ -104,10 +124,13 @@ type Downloader struct {
 	mode atomic.Uint32  // Synchronisation mode defining the strategy used (per sync cycle), use d.getMode() to get the SyncMode
 	mux  *event.TypeMux // Event multiplexer to announce sync operation events
 
-	checkpoint uint64   // Checkpoint block number to enforce head against (e.g. snap sync)
-	genesis    uint64   // Genesis block number to limit sync to (e.g. light client CHT)
-	queue      *queue   // Scheduler for selecting the hashes to download
-	peers      *peerSet // Set of active peers from which download can proceed
+	checkpoint     uint64      // Checkpoint block number to enforce head against (e.g. snap sync)
+	checkpointHash common.Hash // Signed checkpoint hash to enforce on remote chains (zero if none)
+	checkpointTD   *big.Int    // Signed checkpoint total difficulty to enforce on remote peers
+	checkpointLink atomic.Bool // Whether the synced chain is proven to link up to the signed checkpoint
+	genesis        uint64      // Genesis block number to limit sync to (e.g. light client CHT)
+	queue          *queue      // Scheduler for selecting the hashes to download
+	peers          *peerSet    // Set of active peers from which download can proceed
 
 	stateDB ethdb.Database // Database to state sync into (and deduplicate via)
 
@@ -214,6 +237,10 @@ type BlockChain interface {
 	// InsertChain inserts a batch of blocks into the local chain.
 	InsertChain(types.Blocks) (int, error)
 
+	// InsertChainWithoutSealVerification inserts a batch of blocks into the
+	// local chain without verifying the seals of their headers.
+	InsertChainWithoutSealVerification(types.Blocks) (int, error)
+
 	// InsertReceiptChain inserts a batch of receipts into the local chain.
 	InsertReceiptChain(types.Blocks, []types.Receipts, uint64) (int, error)
 
@@ -253,6 +280,16 @@ func New(checkpoint uint64, stateDb ethdb.Database, mux *event.TypeMux, chain Bl
 	return dl
 }
 
+// SetSignedCheckpoint configures a verified signed checkpoint to enforce during
+// sync. Remote chains not containing it are rejected, and headers and blocks
+// below it are imported without seal verification. It must be called before any
+// synchronisation is started.
+func (d *Downloader) SetSignedCheckpoint(cp *checkpoint.Checkpoint) {
+	d.checkpoint = cp.Number
+	d.checkpointHash = cp.Hash
+	d.checkpointTD = new(big.Int).Set(cp.TD)
+}
+
 // Progress retrieves the synchronisation boundaries, specifically the origin
 // block where synchronisation started at (may have failed/suspended); the block
 // or header sync is currently at; and the latest known block which the sync targets.
@@ -482,6 +519,7 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td, ttd *
 		}
 	}()
 	mode := d.getMode()
+	d.checkpointLink.Store(false)
 
 	if !beaconMode {
 		log.Debug("Synchronising with the network", "peer", p.id, "eth", p.version, "head", hash, "td", td, "mode", mode)
@@ -769,6 +807,11 @@ func (d *Downloader) fetchHead(p *peerConnection) (head *types.Header, pivot *ty
 	if (mode == SnapSync || mode == LightSync) && head.Number.Uint64() < d.checkpoint {
 		return nil, nil, fmt.Errorf("%w: remote head %d below checkpoint %d", errUnsyncedPeer, head.Number, d.checkpoint)
 	}
+	if d.checkpointHash != (common.Hash{}) {
+		if err := d.verifySignedCheckpoint(p, head, peerTd); err != nil {
+			return nil, nil, err
+		}
+	}
 	if len(headers) == 1 {
 		if mode == SnapSync && head.Number.Uint64() > uint64(fsMinFullBlocks) {
 			return nil, nil, fmt.Errorf("%w: no pivot included along head header", errBadPeer)
@@ -785,6 +828,51 @@ func (d *Downloader) fetchHead(p *peerConnection) (head *types.Header, pivot *ty
 	return head, pivot, nil
 }
 
+// verifySignedCheckpoint ensures that the remote chain contains the signed
+// checkpoint, so that an eclipsing peer can't feed a chain forking off below it.
+func (d *Downloader) verifySignedCheckpoint(p *peerConnection, head *types.Header, td *big.Int) error {
+	if head.Number.Uint64() < d.checkpoint {
+		return fmt.Errorf("%w: remote head %d below signed checkpoint %d", errUnsyncedPeer, head.Number, d.checkpoint)
+	}
+	if td != nil && td.Cmp(d.checkpointTD) < 0 {
+		return fmt.Errorf("%w: remote td %v below signed checkpoint td %v", errInvalidChain, td, d.checkpointTD)
+	}
+	headers, hashes, err := d.fetchHeadersByNumber(p, d.checkpoint, 1, 0, false)
+	if err != nil {
+		return err
+	}
+	if len(headers) != 1 {
+		return fmt.Errorf("%w: returned headers %d != requested %d", errBadPeer, len(headers), 1)
+	}
+	if hashes[0] != d.checkpointHash {
+		return fmt.Errorf("%w: signed checkpoint %d mismatch: have %x, want %x", errInvalidChain, d.checkpoint, hashes[0], d.checkpointHash)
+	}
+	p.log.Debug("Remote chain contains signed checkpoint", "number", d.checkpoint, "hash", d.checkpointHash)
+	return nil
+}
+
+// checkSignedCheckpoint ensures that a contiguous chunk of headers doesn't
+// contradict the signed checkpoint, if any.
+func (d *Downloader) checkSignedCheckpoint(headers []*types.Header, hashes []common.Hash) error {
+	if !d.containsSignedCheckpoint(headers) {
+		return nil
+	}
+	if hash := hashes[d.checkpoint-headers[0].Number.Uint64()]; hash != d.checkpointHash {
+		return fmt.Errorf("%w: signed checkpoint %d mismatch: have %x, want %x", errInvalidChain, d.checkpoint, hash, d.checkpointHash)
+	}
+	return nil
+}
+
+// containsSignedCheckpoint reports whether a contiguous chunk of headers spans
+// the signed checkpoint, if any.
+func (d *Downloader) containsSignedCheckpoint(headers []*types.Header) bool {
+	if d.checkpointHash == (common.Hash{}) || len(headers) == 0 {
+		return false
+	}
+	first := headers[0].Number.Uint64()
+	return d.checkpoint >= first && d.checkpoint < first+uint64(len(headers))
+}
+
 // calculateRequestSpan calculates what headers to request from a peer when trying to determine the
 // common ancestor.
 // It returns parameters to be used for peer.RequestHeadersByNumber:
@@ -1368,6 +1456,7 @@ func (d *Downloader) processHeaders(origin uint64, td, ttd *big.Int, beaconMode
 		rollback    uint64 // Zero means no rollback (fine as you can't unroll the genesis)
 		rollbackErr error
 		mode        = d.getMode()
+		unproven    bool // Whether unsealed headers were imported without linking up to the signed checkpoint yet
 	)
 	defer func() {
 		if rollback > 0 {
@@ -1450,6 +1539,12 @@ func (d *Downloader) processHeaders(origin uint64, td, ttd *big.Int, beaconMode
 						}
 					}
 				}
+				// Headers imported unsealed are only vouched for by the signed
+				// checkpoint, don't keep them if the chain never reached it
+				if unproven {
+					rollbackErr = fmt.Errorf("%w: chain ended below signed checkpoint %d", errStallingPeer, d.checkpoint)
+					return rollbackErr
+				}
 				// Disable any rollback and return
 				rollback = 0
 				return nil
@@ -1474,6 +1569,12 @@ func (d *Downloader) processHeaders(origin uint64, td, ttd *big.Int, beaconMode
 				chunkHeaders := headers[:limit]
 				chunkHashes := hashes[:limit]
 
+				// Reject the chunk outright if it contradicts the signed checkpoint
+				if err := d.checkSignedCheckpoint(chunkHeaders, chunkHashes); err != nil {
+					rollbackErr = err
+					return err
+				}
+
 				// In case of header only syncing, validate the chunk immediately
 				if mode == SnapSync || mode == LightSync {
 					// If we're importing pure headers, verify based on their recentness
@@ -1489,6 +1590,12 @@ func (d *Downloader) processHeaders(origin uint64, td, ttd *big.Int, beaconMode
 					if chunkHeaders[len(chunkHeaders)-1].Number.Uint64()+uint64(fsHeaderForceVerify) > pivot {
 						frequency = 1
 					}
+					// Headers below a signed checkpoint are vouched for by the checkpoint
+					// hash they link up to, skip their seal verification altogether. They
+					// are kept uncertain until the chain is proven to reach the checkpoint.
+					if d.checkpointHash != (common.Hash{}) && chunkHeaders[len(chunkHeaders)-1].Number.Uint64() < d.checkpoint {
+						frequency = 0
+					}
 					// Although the received headers might be all valid, a legacy
 					// PoW/PoA sync must not accept post-merge headers. Make sure
 					// that any transition is rejected at this point.
@@ -1531,8 +1638,17 @@ func (d *Downloader) processHeaders(origin uint64, td, ttd *big.Int, beaconMode
 							log.Warn("Invalid header encountered", "number", chunkHeaders[n].Number, "hash", chunkHashes[n], "parent", chunkHeaders[n].ParentHash, "err", err)
 							return fmt.Errorf("%w: %v", errInvalidChain, err)
 						}
+						// Keep unsealed headers uncertain until they link up to the checkpoint
+						if frequency == 0 {
+							if rollback == 0 {
+								rollback = chunkHeaders[0].Number.Uint64()
+							}
+							unproven = true
+						} else if unproven && d.containsSignedCheckpoint(chunkHeaders) {
+							rollback, unproven = 0, false
+						}
 						// All verifications passed, track all headers within the allowed limits
-						if mode == SnapSync {
+						if mode == SnapSync && !unproven {
 							head := chunkHeaders[len(chunkHeaders)-1].Number.Uint64()
 							if head-rollback > uint64(fsHeaderSafetyNet) {
 								rollback = head - uint64(fsHeaderSafetyNet)
@@ -1567,6 +1683,11 @@ func (d *Downloader) processHeaders(origin uint64, td, ttd *big.Int, beaconMode
 						return fmt.Errorf("%w: stale headers", errBadPeer)
 					}
 				}
+				// The chunk linked the chain up to the signed checkpoint, vouching for
+				// everything below it
+				if d.containsSignedCheckpoint(chunkHeaders) {
+					d.checkpointLink.Store(true)
+				}
 				headers = headers[limit:]
 				hashes = hashes[limit:]
 				origin += uint64(limit)
@@ -1660,10 +1781,22 @@ func (d *Downloader) importBlockResults(results []*fetchResult) error {
 	for i, result := range results {
 		blocks[i] = types.NewBlockWithHeader(result.Header).WithBody(result.Transactions, result.Uncles).WithWithdrawals(result.Withdrawals)
 	}
+	// Blocks below a signed checkpoint are vouched for by the checkpoint hash
+	// they link up to, skip their seal verification altogether. Until the header
+	// chain is proven to reach the checkpoint, verify them like any other block.
+	var unsealed int
+	if d.checkpointLink.Load() && first.Number.Uint64() < d.checkpoint {
+		unsealed = min(len(blocks), int(d.checkpoint-first.Number.Uint64()))
+	}
 	// Downloaded blocks are always regarded as trusted after the
 	// transition. Because the downloaded chain is guided by the
 	// consensus-layer.
-	if index, err := d.blockchain.InsertChain(blocks); err != nil {
+	index, err := d.blockchain.InsertChainWithoutSealVerification(blocks[:unsealed])
+	if err == nil {
+		index, err = d.blockchain.InsertChain(blocks[unsealed:])
+		index += unsealed
+	}
+	if err != nil {
 		if index < len(results) {
 			log.Debug("Downloaded item processing failed", "number", results[index].Header.Number, "hash", results[index].Header.Hash(), "err", err)
 
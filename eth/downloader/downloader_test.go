@@ -29,8 +29,10 @@ import (
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/checkpoint"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -62,6 +64,12 @@ func newTester(t *testing.T) *downloadTester {
 
 // newTester creates a new downloader test mocker.
 func newTesterWithNotification(t *testing.T, success func()) *downloadTester {
+	return newTesterWithEngine(t, ethash.NewFaker(), success)
+}
+
+// newTesterWithEngine creates a new downloader test mocker verifying the chain
+// with the given consensus engine.
+func newTesterWithEngine(t *testing.T, engine consensus.Engine, success func()) *downloadTester {
 	freezer := t.TempDir()
 	db, err := rawdb.NewDatabaseWithFreezer(rawdb.NewMemoryDatabase(), freezer, "", false)
 	if err != nil {
@@ -75,7 +83,7 @@ func newTesterWithNotification(t *testing.T, success func()) *downloadTester {
 		Alloc:   genesisT.GenesisAlloc{testAddress: {Balance: big.NewInt(1000000000000000)}},
 		BaseFee: big.NewInt(vars.InitialBaseFee),
 	}
-	chain, err := core.NewBlockChain(db, nil, gspec, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
 	if err != nil {
 		panic(err)
 	}
@@ -154,7 +162,33 @@ type downloadTesterPeer struct {
 	chain *core.BlockChain
 
 	withholdHeaders map[common.Hash]struct{}
+	fakeHeaders     map[uint64]*types.Header // Foreign headers to serve in place of the chain's own
 	fakeTD          *big.Int
+
+	earliest      uint64       // First block whose body and receipts are served
+	prunedQueries atomic.Int32 // Number of bodies and receipts requested below earliest
+}
+
+// ServesBlock reports whether the peer serves the body and receipts of a block.
+func (dlp *downloadTesterPeer) ServesBlock(number uint64) bool {
+	return number >= dlp.earliest
+}
+
+// pruned filters out the hashes of the blocks below earliest, counting them as
+// queries the peer can't serve.
+func (dlp *downloadTesterPeer) pruned(hashes []common.Hash) []common.Hash {
+	if dlp.earliest == 0 {
+		return hashes
+	}
+	served := make([]common.Hash, 0, len(hashes))
+	for _, hash := range hashes {
+		if header := dlp.chain.GetHeaderByHash(hash); header != nil && header.Number.Uint64() < dlp.earliest {
+			dlp.prunedQueries.Add(1)
+			continue
+		}
+		served = append(served, hash)
+	}
+	return served
 }
 
 // Head constructs a function to retrieve a peer's current head hash
@@ -209,6 +243,12 @@ func (dlp *downloadTesterPeer) RequestHeadersByHash(origin common.Hash, amount i
 			}
 		}
 	}
+	// If a malicious peer is simulated splicing in foreign headers, swap them
+	for i, header := range headers {
+		if fake, ok := dlp.fakeHeaders[header.Number.Uint64()]; ok {
+			headers[i] = fake
+		}
+	}
 	hashes := make([]common.Hash, len(headers))
 	for i, header := range headers {
 		hashes[i] = header.Hash()
@@ -253,6 +293,12 @@ func (dlp *downloadTesterPeer) RequestHeadersByNumber(origin uint64, amount int,
 			}
 		}
 	}
+	// If a malicious peer is simulated splicing in foreign headers, swap them
+	for i, header := range headers {
+		if fake, ok := dlp.fakeHeaders[header.Number.Uint64()]; ok {
+			headers[i] = fake
+		}
+	}
 	hashes := make([]common.Hash, len(headers))
 	for i, header := range headers {
 		hashes[i] = header.Hash()
@@ -278,7 +324,7 @@ func (dlp *downloadTesterPeer) RequestHeadersByNumber(origin uint64, amount int,
 // peer in the download tester. The returned function can be used to retrieve
 // batches of block bodies from the particularly requested peer.
 func (dlp *downloadTesterPeer) RequestBodies(hashes []common.Hash, sink chan *eth.Response) (*eth.Request, error) {
-	blobs := eth.ServiceGetBlockBodiesQuery(dlp.chain, hashes)
+	blobs := eth.ServiceGetBlockBodiesQuery(dlp.chain, dlp.pruned(hashes))
 
 	bodies := make([]*eth.BlockBody, len(blobs))
 	for i, blob := range blobs {
@@ -315,7 +361,7 @@ func (dlp *downloadTesterPeer) RequestBodies(hashes []common.Hash, sink chan *et
 // peer in the download tester. The returned function can be used to retrieve
 // batches of block receipts from the particularly requested peer.
 func (dlp *downloadTesterPeer) RequestReceipts(hashes []common.Hash, sink chan *eth.Response) (*eth.Request, error) {
-	blobs := eth.ServiceGetReceiptsQuery(dlp.chain, hashes)
+	blobs := eth.ServiceGetReceiptsQuery(dlp.chain, dlp.pruned(hashes))
 
 	receipts := make([][]*types.Receipt, len(blobs))
 	for i, blob := range blobs {
@@ -470,6 +516,29 @@ func testCanonSync(t *testing.T, protocol uint, mode SyncMode) {
 	assertOwnChain(t, tester, len(chain.blocks))
 }
 
+// Tests that bodies and receipts are not requested from peers which advertised
+// to have pruned them.
+func TestPrunedHistory69Full(t *testing.T) { testPrunedHistory(t, eth.ETH69, FullSync) }
+func TestPrunedHistory69Snap(t *testing.T) { testPrunedHistory(t, eth.ETH69, SnapSync) }
+
+func testPrunedHistory(t *testing.T, protocol uint, mode SyncMode) {
+	tester := newTester(t)
+	defer tester.terminate()
+
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	tester.newPeer("full", protocol, chain.blocks[1:])
+	pruned := tester.newPeer("pruned", protocol, chain.blocks[1:])
+	pruned.earliest = uint64(len(chain.blocks) / 2)
+
+	if err := tester.sync("pruned", nil, mode); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, len(chain.blocks))
+	if n := pruned.prunedQueries.Load(); n != 0 {
+		t.Errorf("pruned peer queried for %d blocks below its history range", n)
+	}
+}
+
 // Tests that if a large batch of blocks are being downloaded, it is throttled
 // until the cached blocks are retrieved.
 func TestThrottling68Full(t *testing.T) { testThrottling(t, eth.ETH68, FullSync) }
@@ -1398,6 +1467,158 @@ func testCheckpointEnforcement(t *testing.T, protocol uint, mode SyncMode) {
 	}
 }
 
+// Tests that chains not containing a signed checkpoint are rejected, even if
+// they are heavier than the checkpointed one.
+func TestSignedCheckpointEnforcement68Full(t *testing.T) {
+	testSignedCheckpointEnforcement(t, eth.ETH68, FullSync)
+}
+func TestSignedCheckpointEnforcement68Snap(t *testing.T) {
+	testSignedCheckpointEnforcement(t, eth.ETH68, SnapSync)
+}
+func TestSignedCheckpointEnforcement68Light(t *testing.T) {
+	testSignedCheckpointEnforcement(t, eth.ETH68, LightSync)
+}
+
+func testSignedCheckpointEnforcement(t *testing.T, protocol uint, mode SyncMode) {
+	tester := newTester(t)
+	defer tester.terminate()
+
+	chainA := testChainForkLightA.shorten(len(testChainBase.blocks) + 80)
+	chainB := testChainForkHeavy.shorten(len(testChainBase.blocks) + 79)
+	light := tester.newPeer("light", protocol, chainA.blocks[1:])
+	tester.newPeer("heavy", protocol, chainB.blocks[1:])
+
+	// Checkpoint a block on the light fork, past the fork point
+	cp := chainA.blocks[len(testChainBase.blocks)+10]
+	tester.downloader.SetSignedCheckpoint(&checkpoint.Checkpoint{
+		Number: cp.NumberU64(),
+		Hash:   cp.Hash(),
+		TD:     light.chain.GetTd(cp.Hash(), cp.NumberU64()),
+	})
+	if err := tester.sync("heavy", nil, mode); !errors.Is(err, errInvalidChain) {
+		t.Fatalf("heavy fork sync error mismatch: have %v, want %v", err, errInvalidChain)
+	}
+	assertOwnChain(t, tester, 1)
+
+	if err := tester.sync("light", nil, mode); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, len(chainA.blocks))
+}
+
+// Tests that blocks below a signed checkpoint are imported in full sync without
+// verifying their seals once they link up to it, and that the blocks above it
+// still are.
+func TestSignedCheckpointSeals68Full(t *testing.T) {
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	cp := chain.blocks[len(chain.blocks)/2]
+
+	// A seal failing below the checkpoint is not noticed
+	tester := newTesterWithEngine(t, ethash.NewFakeFailer(cp.NumberU64()-1), nil)
+	defer tester.terminate()
+
+	// Hold block imports until the header chain reached the checkpoint
+	tester.downloader.chainInsertHook = func(results []*fetchResult) {
+		for deadline := time.Now().Add(5 * time.Second); !tester.downloader.checkpointLink.Load(); {
+			if time.Now().After(deadline) {
+				t.Errorf("header chain never linked up to the checkpoint")
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	peer := tester.newPeer("peer", eth.ETH68, chain.blocks[1:])
+	tester.downloader.SetSignedCheckpoint(&checkpoint.Checkpoint{
+		Number: cp.NumberU64(),
+		Hash:   cp.Hash(),
+		TD:     peer.chain.GetTd(cp.Hash(), cp.NumberU64()),
+	})
+	if err := tester.sync("peer", nil, FullSync); err != nil {
+		t.Fatalf("failed to synchronise blocks: %v", err)
+	}
+	assertOwnChain(t, tester, len(chain.blocks))
+
+	// A seal failing above the checkpoint is
+	tester = newTesterWithEngine(t, ethash.NewFakeFailer(cp.NumberU64()+1), nil)
+	defer tester.terminate()
+
+	peer = tester.newPeer("peer", eth.ETH68, chain.blocks[1:])
+	tester.downloader.SetSignedCheckpoint(&checkpoint.Checkpoint{
+		Number: cp.NumberU64(),
+		Hash:   cp.Hash(),
+		TD:     peer.chain.GetTd(cp.Hash(), cp.NumberU64()),
+	})
+	if err := tester.sync("peer", nil, FullSync); err == nil {
+		t.Fatalf("invalid seal above the checkpoint accepted")
+	}
+}
+
+// Tests that blocks below a signed checkpoint only skip seal verification in
+// full sync once the header chain is proven to link up to the checkpoint.
+func TestSignedCheckpointUnlinkedSeals(t *testing.T) {
+	chain := testChainBase.shorten(blockCacheMaxItems - 15)
+	cp := chain.blocks[len(chain.blocks)/2]
+
+	tester := newTesterWithEngine(t, ethash.NewFakeFailer(1), nil)
+	defer tester.terminate()
+
+	tester.downloader.SetSignedCheckpoint(&checkpoint.Checkpoint{
+		Number: cp.NumberU64(),
+		Hash:   cp.Hash(),
+		TD:     new(big.Int),
+	})
+	results := make([]*fetchResult, 10)
+	for i, block := range chain.blocks[1:11] {
+		results[i] = &fetchResult{
+			Header:       block.Header(),
+			Uncles:       block.Uncles(),
+			Transactions: block.Transactions(),
+			Withdrawals:  block.Withdrawals(),
+		}
+	}
+	if err := tester.downloader.importBlockResults(results); !errors.Is(err, errInvalidChain) {
+		t.Fatalf("unlinked import error mismatch: have %v, want %v", err, errInvalidChain)
+	}
+	tester.downloader.checkpointLink.Store(true)
+	if err := tester.downloader.importBlockResults(results); err != nil {
+		t.Fatalf("failed to import linked blocks: %v", err)
+	}
+	assertOwnChain(t, tester, len(results)+1)
+}
+
+// Tests that a peer serving the signed checkpoint header on top of a foreign
+// ancestry can't get unsealed headers kept below the checkpoint.
+func TestSignedCheckpointFakeAncestry68Snap(t *testing.T) {
+	testSignedCheckpointFakeAncestry(t, eth.ETH68, SnapSync)
+}
+func TestSignedCheckpointFakeAncestry68Light(t *testing.T) {
+	testSignedCheckpointFakeAncestry(t, eth.ETH68, LightSync)
+}
+
+func testSignedCheckpointFakeAncestry(t *testing.T, protocol uint, mode SyncMode) {
+	cp := testChainForkLightA.blocks[len(testChainBase.blocks)+500]
+
+	// Fail the seal of a foreign block well below the checkpoint
+	failed := uint64(len(testChainBase.blocks) + 50)
+	tester := newTesterWithEngine(t, ethash.NewFakeFailer(failed), nil)
+	defer tester.terminate()
+
+	peer := tester.newPeer("fake", protocol, testChainForkLightB.blocks[1:])
+	peer.fakeHeaders = map[uint64]*types.Header{cp.NumberU64(): cp.Header()}
+
+	tester.downloader.SetSignedCheckpoint(&checkpoint.Checkpoint{
+		Number: cp.NumberU64(),
+		Hash:   cp.Hash(),
+		TD:     peer.chain.GetTd(testChainForkLightB.blocks[cp.NumberU64()].Hash(), cp.NumberU64()),
+	})
+	if err := tester.sync("fake", nil, mode); err == nil {
+		t.Fatalf("fake ancestry below the checkpoint accepted")
+	}
+	if head := tester.chain.CurrentHeader().Number.Uint64(); head >= failed {
+		t.Fatalf("unsealed fake ancestry kept: head %d, failing seal at %d", head, failed)
+	}
+}
+
 // Tests that peers below a pre-configured checkpoint block are prevented from
 // being fast-synced from, avoiding potential cheap eclipse attacks.
 func TestBeaconSync68Full(t *testing.T) { testBeaconSync(t, eth.ETH68, FullSync) }
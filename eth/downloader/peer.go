@@ -66,6 +66,12 @@ type Peer interface {
 	RequestReceipts([]common.Hash, chan *eth.Response) (*eth.Request, error)
 }
 
+// historyPeer is implemented by peers advertising the range of blocks they serve
+// bodies and receipts for.
+type historyPeer interface {
+	ServesBlock(number uint64) bool
+}
+
 // newPeerConnection creates a new downloader peer.
 func newPeerConnection(id string, version uint, peer Peer, logger log.Logger) *peerConnection {
 	return &peerConnection{
@@ -159,6 +165,16 @@ func (p *peerConnection) Lacks(hash common.Hash) bool {
 	return ok
 }
 
+// Serves retrieves whether the peer serves the body and receipts of a block,
+// according to the range of blocks it advertised. Pruned nodes are not asked
+// for history they don't have.
+func (p *peerConnection) Serves(number uint64) bool {
+	if peer, ok := p.peer.(historyPeer); ok {
+		return peer.ServesBlock(number)
+	}
+	return true
+}
+
 // peeringEvent is sent on the peer event feed when a remote peer connects or
 // disconnects.
 type peeringEvent struct {
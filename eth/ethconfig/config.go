@@ -81,6 +81,7 @@ var Defaults = Config{
 	Miner:              miner.DefaultConfig,
 	TxPool:             legacypool.DefaultConfig,
 	BlobPool:           blobpool.DefaultConfig,
+	PrivateTxExpiry:    25,
 	RPCGasCap:          50000000,
 	RPCEVMTimeout:      5 * time.Second,
 	GPO:                FullNodeGPO,
@@ -145,10 +146,10 @@ type Config struct {
 	RequiredBlocks map[uint64]common.Hash `toml:"-"`
 
 	// Light client options
-	LightServ          int  `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
+	LightServ          int  `toml:",omitempty"` // Serve light clients over lhp if above zero
 	LightIngress       int  `toml:",omitempty"` // Incoming bandwidth limit for light servers
 	LightEgress        int  `toml:",omitempty"` // Outgoing bandwidth limit for light servers
-	LightPeers         int  `toml:",omitempty"` // Maximum number of LES client peers
+	LightPeers         int  `toml:",omitempty"` // Maximum number of light clients to serve
 	LightNoPrune       bool `toml:",omitempty"` // Whether to disable light chain pruning
 	LightNoSyncServe   bool `toml:",omitempty"` // Whether to serve light clients before syncing
 	SyncFromCheckpoint bool `toml:",omitempty"` // Whether to sync the header chain from the configured checkpoint
@@ -184,6 +185,14 @@ type Config struct {
 	TxPool   legacypool.Config
 	BlobPool blobpool.Config
 
+	// Private transaction relay options. Private transactions are kept out of
+	// the public gossip and relayed to the trusted PrivateTxPeers only, until
+	// they expire after PrivateTxExpiry blocks. Transactions submitted over the
+	// PrivateTxTransports RPC transports (http, ws, ipc) are all private.
+	PrivateTxPeers      []string `toml:",omitempty"`
+	PrivateTxExpiry     uint64   `toml:",omitempty"`
+	PrivateTxTransports []string `toml:",omitempty"`
+
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
@@ -209,12 +218,21 @@ type Config struct {
 	// send-transaction variants. The unit is ether.
 	RPCTxFeeCap float64
 
+	// TraceIndex enables the background trace indexer serving trace_block and
+	// trace_filter, indexing the canonical chain from TraceIndexFrom onwards.
+	TraceIndex     bool   `toml:",omitempty"`
+	TraceIndexFrom uint64 `toml:",omitempty"`
+
 	// Checkpoint is a hardcoded checkpoint which can be nil.
 	Checkpoint *ctypes.TrustedCheckpoint `toml:",omitempty"`
 
 	// CheckpointOracle is the configuration for checkpoint oracle.
 	CheckpointOracle *ctypes.CheckpointOracleConfig `toml:",omitempty"`
 
+	// CheckpointFile is the path of a signed checkpoint to enforce during sync,
+	// verified against the chain's configured checkpoint signers.
+	CheckpointFile string `toml:",omitempty"`
+
 	// Manual configuration field for ECBP1100 activation number. Used for modifying genesis config via CLI flag.
 	OverrideECBP1100 *uint64 `toml:",omitempty"`
 	// Manual configuration field for ECBP1100's disablement block number. Used for modifying genesis config via CLI flag.
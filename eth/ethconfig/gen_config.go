@@ -57,6 +57,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Ethash                     ethash.Config
 		TxPool                     legacypool.Config
 		BlobPool                   blobpool.Config
+		PrivateTxPeers             []string `toml:",omitempty"`
+		PrivateTxExpiry            uint64   `toml:",omitempty"`
+		PrivateTxTransports        []string `toml:",omitempty"`
 		GPO                        gasprice.Config
 		EnablePreimageRecording    bool
 		DocRoot                    string `toml:"-"`
@@ -65,8 +68,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		RPCGasCap                  uint64
 		RPCEVMTimeout              time.Duration
 		RPCTxFeeCap                float64
+		TraceIndex                 bool                           `toml:",omitempty"`
+		TraceIndexFrom             uint64                         `toml:",omitempty"`
 		Checkpoint                 *ctypes.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle           *ctypes.CheckpointOracleConfig `toml:",omitempty"`
+		CheckpointFile             string                         `toml:",omitempty"`
 		OverrideECBP1100           *uint64                        `toml:",omitempty"`
 		OverrideECBP1100Deactivate *uint64                        `toml:",omitempty"`
 		ECBP1100NoDisable          *bool                          `toml:",omitempty"`
@@ -113,6 +119,9 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Ethash = c.Ethash
 	enc.TxPool = c.TxPool
 	enc.BlobPool = c.BlobPool
+	enc.PrivateTxPeers = c.PrivateTxPeers
+	enc.PrivateTxExpiry = c.PrivateTxExpiry
+	enc.PrivateTxTransports = c.PrivateTxTransports
 	enc.GPO = c.GPO
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
@@ -121,8 +130,11 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.RPCGasCap = c.RPCGasCap
 	enc.RPCEVMTimeout = c.RPCEVMTimeout
 	enc.RPCTxFeeCap = c.RPCTxFeeCap
+	enc.TraceIndex = c.TraceIndex
+	enc.TraceIndexFrom = c.TraceIndexFrom
 	enc.Checkpoint = c.Checkpoint
 	enc.CheckpointOracle = c.CheckpointOracle
+	enc.CheckpointFile = c.CheckpointFile
 	enc.OverrideECBP1100 = c.OverrideECBP1100
 	enc.OverrideECBP1100Deactivate = c.OverrideECBP1100Deactivate
 	enc.ECBP1100NoDisable = c.ECBP1100NoDisable
@@ -173,6 +185,9 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Ethash                     *ethash.Config
 		TxPool                     *legacypool.Config
 		BlobPool                   *blobpool.Config
+		PrivateTxPeers             []string `toml:",omitempty"`
+		PrivateTxExpiry            *uint64  `toml:",omitempty"`
+		PrivateTxTransports        []string `toml:",omitempty"`
 		GPO                        *gasprice.Config
 		EnablePreimageRecording    *bool
 		DocRoot                    *string `toml:"-"`
@@ -181,8 +196,11 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		RPCGasCap                  *uint64
 		RPCEVMTimeout              *time.Duration
 		RPCTxFeeCap                *float64
+		TraceIndex                 *bool                          `toml:",omitempty"`
+		TraceIndexFrom             *uint64                        `toml:",omitempty"`
 		Checkpoint                 *ctypes.TrustedCheckpoint      `toml:",omitempty"`
 		CheckpointOracle           *ctypes.CheckpointOracleConfig `toml:",omitempty"`
+		CheckpointFile             *string                        `toml:",omitempty"`
 		OverrideECBP1100           *uint64                        `toml:",omitempty"`
 		OverrideECBP1100Deactivate *uint64                        `toml:",omitempty"`
 		ECBP1100NoDisable          *bool                          `toml:",omitempty"`
@@ -308,6 +326,15 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.BlobPool != nil {
 		c.BlobPool = *dec.BlobPool
 	}
+	if dec.PrivateTxPeers != nil {
+		c.PrivateTxPeers = dec.PrivateTxPeers
+	}
+	if dec.PrivateTxExpiry != nil {
+		c.PrivateTxExpiry = *dec.PrivateTxExpiry
+	}
+	if dec.PrivateTxTransports != nil {
+		c.PrivateTxTransports = dec.PrivateTxTransports
+	}
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
@@ -332,12 +359,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.RPCTxFeeCap != nil {
 		c.RPCTxFeeCap = *dec.RPCTxFeeCap
 	}
+	if dec.TraceIndex != nil {
+		c.TraceIndex = *dec.TraceIndex
+	}
+	if dec.TraceIndexFrom != nil {
+		c.TraceIndexFrom = *dec.TraceIndexFrom
+	}
 	if dec.Checkpoint != nil {
 		c.Checkpoint = dec.Checkpoint
 	}
 	if dec.CheckpointOracle != nil {
 		c.CheckpointOracle = dec.CheckpointOracle
 	}
+	if dec.CheckpointFile != nil {
+		c.CheckpointFile = *dec.CheckpointFile
+	}
 	if dec.OverrideECBP1100 != nil {
 		c.OverrideECBP1100 = dec.OverrideECBP1100
 	}
@@ -27,8 +27,10 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/trie"
 )
 
@@ -73,7 +75,10 @@ var (
 	bodyFilterOutMeter   = metrics.NewRegisteredMeter("eth/fetcher/block/filter/bodies/out", nil)
 )
 
-var errTerminated = errors.New("terminated")
+var (
+	errTerminated    = errors.New("terminated")
+	errUnknownParent = errors.New("unknown parent")
+)
 
 // HeaderRetrievalFn is a callback type for retrieving a header from the local chain.
 type HeaderRetrievalFn func(common.Hash) *types.Header
@@ -102,8 +107,9 @@ type headersInsertFn func(headers []*types.Header) (int, error)
 // chainInsertFn is a callback type to insert a batch of blocks into the local chain.
 type chainInsertFn func(types.Blocks) (int, error)
 
-// peerDropFn is a callback type for dropping a peer detected as malicious.
-type peerDropFn func(id string)
+// peerDropFn is a callback type for dropping a peer detected as malicious,
+// along with the offense it committed.
+type peerDropFn func(id string, offense p2p.Offense)
 
 // blockAnnounce is the hash notification of the availability of a new block in the
 // network.
@@ -196,6 +202,8 @@ type BlockFetcher struct {
 	insertChain    chainInsertFn      // Injects a batch of blocks into the chain
 	dropPeer       peerDropFn         // Drops a peer for misbehaving
 
+	propagation *propagationTracker // Propagation history of recently received blocks
+
 	// Testing hooks
 	announceChangeHook func(common.Hash, bool)           // Method to call upon adding or deleting a hash from the blockAnnounce list
 	queueChangeHook    func(common.Hash, bool)           // Method to call upon adding or deleting a block from the import queue
@@ -230,6 +238,7 @@ func NewBlockFetcher(light bool, getHeader HeaderRetrievalFn, getBlock blockRetr
 		insertHeaders:  insertHeaders,
 		insertChain:    insertChain,
 		dropPeer:       dropPeer,
+		propagation:    newPropagationTracker(propagationLimit),
 	}
 }
 
@@ -249,6 +258,8 @@ func (f *BlockFetcher) Stop() {
 // the network.
 func (f *BlockFetcher) Notify(peer string, hash common.Hash, number uint64, time time.Time,
 	headerFetcher headerRequesterFn, bodyFetcher bodyRequesterFn) error {
+	f.propagation.announced(peer, hash, number, time)
+
 	block := &blockAnnounce{
 		hash:        hash,
 		number:      number,
@@ -265,8 +276,23 @@ func (f *BlockFetcher) Notify(peer string, hash common.Hash, number uint64, time
 	}
 }
 
+// NotifyKnown records an announcement of a block which is already known locally.
+// It's only used to track the propagation of blocks.
+func (f *BlockFetcher) NotifyKnown(peer string, hash common.Hash) {
+	f.propagation.known(hash, false)
+}
+
 // Enqueue tries to fill gaps the fetcher's future import queue.
 func (f *BlockFetcher) Enqueue(peer string, block *types.Block) error {
+	if !f.light && f.getBlock(block.Hash()) != nil {
+		f.propagation.known(block.Hash(), true)
+	} else {
+		receivedAt := block.ReceivedAt
+		if receivedAt.IsZero() {
+			receivedAt = time.Now()
+		}
+		f.propagation.arrived(peer, block, receivedAt, true)
+	}
 	op := &blockOrHeaderInject{
 		origin: peer,
 		block:  block,
@@ -279,6 +305,18 @@ func (f *BlockFetcher) Enqueue(peer string, block *types.Block) error {
 	}
 }
 
+// Propagation returns the propagation history of a recently received block, or
+// nil if the block is unknown.
+func (f *BlockFetcher) Propagation(hash common.Hash) *BlockPropagation {
+	return f.propagation.get(hash)
+}
+
+// SubscribePropagation subscribes to the propagation history of received blocks,
+// which is sent once their import completed or failed.
+func (f *BlockFetcher) SubscribePropagation(ch chan<- BlockPropagation) event.Subscription {
+	return f.propagation.feed.Subscribe(ch)
+}
+
 // FilterHeaders extracts all the headers that were explicitly requested by the fetcher,
 // returning those that should be handled differently.
 func (f *BlockFetcher) FilterHeaders(peer string, headers []*types.Header, time time.Time) []*types.Header {
@@ -497,7 +535,7 @@ func (f *BlockFetcher) loop() {
 								// was already rescheduled at this point, we were
 								// waiting for a catchup. With an unresponsive
 								// peer however, it's a protocol violation.
-								f.dropPeer(peer)
+								f.dropPeer(peer, p2p.OffenseTimeout)
 							}
 						}(hash)
 					}
@@ -556,7 +594,7 @@ func (f *BlockFetcher) loop() {
 						// was already rescheduled at this point, we were
 						// waiting for a catchup. With an unresponsive
 						// peer however, it's a protocol violation.
-						f.dropPeer(peer)
+						f.dropPeer(peer, p2p.OffenseTimeout)
 					}
 				}(peer, hashes)
 			}
@@ -586,7 +624,7 @@ func (f *BlockFetcher) loop() {
 					// If the delivered header does not match the promised number, drop the announcer
 					if header.Number.Uint64() != announce.number {
 						log.Trace("Invalid block number fetched", "peer", announce.origin, "hash", header.Hash(), "announced", announce.number, "provided", header.Number)
-						f.dropPeer(announce.origin)
+						f.dropPeer(announce.origin, p2p.OffenseProtocolViolation)
 						f.forgetHash(hash)
 						continue
 					}
@@ -611,6 +649,7 @@ func (f *BlockFetcher) loop() {
 
 							block := types.NewBlockWithHeader(header)
 							block.ReceivedAt = task.time
+							f.propagation.arrived(task.peer, block, task.time, false)
 
 							complete = append(complete, block)
 							f.completing[hash] = announce
@@ -695,6 +734,7 @@ func (f *BlockFetcher) loop() {
 						if f.getBlock(hash) == nil {
 							block := types.NewBlockWithHeader(announce.header).WithBody(task.transactions[i], task.uncles[i])
 							block.ReceivedAt = task.time
+							f.propagation.arrived(task.peer, block, task.time, false)
 							blocks = append(blocks, block)
 						} else {
 							f.forgetHash(hash)
@@ -825,7 +865,7 @@ func (f *BlockFetcher) importHeaders(peer string, header *types.Header) {
 		// Validate the header and if something went wrong, drop the peer
 		if err := f.verifyHeader(header); err != nil && err != consensus.ErrFutureBlock {
 			log.Debug("Propagated header verification failed", "peer", peer, "number", header.Number, "hash", hash, "err", err)
-			f.dropPeer(peer)
+			f.dropPeer(peer, p2p.OffenseInvalidHeader)
 			return
 		}
 		// Run the actual import and log any issues
@@ -855,6 +895,7 @@ func (f *BlockFetcher) importBlocks(peer string, block *types.Block) {
 		parent := f.getBlock(block.ParentHash())
 		if parent == nil {
 			log.Debug("Unknown parent of propagated block", "peer", peer, "number", block.Number(), "hash", hash, "parent", block.ParentHash())
+			f.propagation.imported(hash, time.Now(), errUnknownParent)
 			return
 		}
 		// Quickly validate the header and propagate the block if it passes
@@ -870,14 +911,17 @@ func (f *BlockFetcher) importBlocks(peer string, block *types.Block) {
 		default:
 			// Something went very wrong, drop the peer
 			log.Debug("Propagated block verification failed", "peer", peer, "number", block.Number(), "hash", hash, "err", err)
-			f.dropPeer(peer)
+			f.propagation.imported(hash, time.Now(), err)
+			f.dropPeer(peer, p2p.OffenseInvalidBlock)
 			return
 		}
 		// Run the actual import and log any issues
 		if _, err := f.insertChain(types.Blocks{block}); err != nil {
 			log.Debug("Propagated block import failed", "peer", peer, "number", block.Number(), "hash", hash, "err", err)
+			f.propagation.imported(hash, time.Now(), err)
 			return
 		}
+		f.propagation.imported(hash, time.Now(), nil)
 		// If import succeeded, broadcast the block
 		blockAnnounceOutTimer.UpdateSince(block.ReceivedAt)
 		go f.broadcastBlock(block, false)
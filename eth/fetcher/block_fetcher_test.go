@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/params/types/genesisT"
 	"github.com/ethereum/go-ethereum/params/vars"
@@ -190,7 +191,7 @@ func (f *fetcherTester) insertChain(blocks types.Blocks) (int, error) {
 
 // dropPeer is an emulator for the peer removal, simply accumulating the various
 // peers dropped by the fetcher.
-func (f *fetcherTester) dropPeer(peer string) {
+func (f *fetcherTester) dropPeer(peer string, offense p2p.Offense) {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 
@@ -596,6 +597,52 @@ func testRandomArrivalImport(t *testing.T, light bool) {
 	verifyChainHeight(t, tester, uint64(len(hashes)-1))
 }
 
+// Tests that the propagation of announced blocks is recorded from the first
+// announcement until the import.
+func TestBlockPropagation(t *testing.T) {
+	hashes, blocks := makeChain(1, 0, genesis)
+
+	tester := newTester(false)
+	defer tester.fetcher.Stop()
+	headerFetcher := tester.makeHeaderFetcher("first", blocks, -gatherSlack)
+	bodyFetcher := tester.makeBodyFetcher("first", blocks, 0)
+
+	events := make(chan BlockPropagation, 1)
+	sub := tester.fetcher.SubscribePropagation(events)
+	defer sub.Unsubscribe()
+
+	announced := time.Now().Add(-arriveTimeout)
+	tester.fetcher.Notify("first", hashes[0], 1, announced, headerFetcher, bodyFetcher)
+	tester.fetcher.Notify("second", hashes[0], 1, time.Now(), headerFetcher, bodyFetcher)
+
+	var event BlockPropagation
+	select {
+	case event = <-events:
+	case <-time.After(time.Second):
+		t.Fatal("no propagation event")
+	}
+	if event.Hash != hashes[0] || event.Number != 1 {
+		t.Fatalf("wrong block: %x #%d", event.Hash, event.Number)
+	}
+	if event.FirstPeer != "first" || event.Source != SourceAnnounce || event.Announces != 2 {
+		t.Errorf("wrong first sighting: peer %s, source %s, %d announces", event.FirstPeer, event.Source, event.Announces)
+	}
+	if event.Arrived == nil || event.ArrivalPeer != "first" || event.ArrivalGap < arriveTimeout-gatherSlack {
+		t.Errorf("wrong arrival: %v from %s, gap %v", event.Arrived, event.ArrivalPeer, event.ArrivalGap)
+	}
+	if event.Imported == nil || event.ImportError != "" || event.ImportDelay < event.ArrivalGap {
+		t.Errorf("wrong import: %v, delay %v, error %q", event.Imported, event.ImportDelay, event.ImportError)
+	}
+	// Broadcasts of the imported block are only counted.
+	tester.fetcher.Enqueue("third", blocks[hashes[0]])
+	if p := tester.fetcher.Propagation(hashes[0]); p == nil || p.Broadcasts != 1 || p.FirstPeer != "first" {
+		t.Errorf("broadcast of known block not counted: %+v", p)
+	}
+	if p := tester.fetcher.Propagation(genesis.Hash()); p != nil {
+		t.Errorf("propagation of local block recorded: %+v", p)
+	}
+}
+
 // Tests that direct block enqueues (due to block propagation vs. hash announce)
 // are correctly schedule, filling and import queue gaps.
 func TestQueueGapFill(t *testing.T) {
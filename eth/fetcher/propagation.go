@@ -0,0 +1,195 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package fetcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// propagationLimit is the number of recent blocks whose propagation is tracked.
+const propagationLimit = 1024
+
+// Ways a block can be first seen.
+const (
+	SourceAnnounce  = "announce"
+	SourceBroadcast = "broadcast"
+)
+
+var (
+	// propagationAgeHist tracks how long after their timestamp blocks are first seen.
+	propagationAgeHist = metrics.NewRegisteredHistogram("eth/fetcher/block/propagation/age", nil, metrics.NewExpDecaySample(1028, 0.015))
+	// propagationGapHist tracks the time from the first announcement of a block
+	// until its body arrived.
+	propagationGapHist = metrics.NewRegisteredHistogram("eth/fetcher/block/propagation/gap", nil, metrics.NewExpDecaySample(1028, 0.015))
+	// propagationImportHist tracks the time from first seeing a block until it's imported.
+	propagationImportHist = metrics.NewRegisteredHistogram("eth/fetcher/block/propagation/import", nil, metrics.NewExpDecaySample(1028, 0.015))
+)
+
+// BlockPropagation is the propagation history of a block received from the
+// network. Durations are in nanoseconds.
+type BlockPropagation struct {
+	Hash   common.Hash `json:"hash"`
+	Number uint64      `json:"number"`
+
+	FirstSeen time.Time `json:"firstSeen"`
+	FirstPeer string    `json:"firstPeer"`
+	Source    string    `json:"source"` // SourceAnnounce or SourceBroadcast
+
+	// Announces and Broadcasts count the announcements and full block
+	// broadcasts received, including those after the block was imported.
+	Announces  int `json:"announces"`
+	Broadcasts int `json:"broadcasts"`
+
+	// Arrived is when the full block was first received, from ArrivalPeer.
+	// ArrivalGap is the time since the first announcement, zero if the block
+	// was broadcast before being announced.
+	Arrived     *time.Time    `json:"arrived,omitempty"`
+	ArrivalPeer string        `json:"arrivalPeer,omitempty"`
+	ArrivalGap  time.Duration `json:"arrivalGap"`
+
+	// Imported is when the import of the block completed, ImportDelay the
+	// time since the block was first seen.
+	Imported    *time.Time    `json:"imported,omitempty"`
+	ImportDelay time.Duration `json:"importDelay"`
+	ImportError string        `json:"importError,omitempty"`
+
+	announced time.Time // First announcement
+}
+
+// propagationTracker records the propagation of recently received blocks.
+type propagationTracker struct {
+	lock   sync.Mutex
+	blocks lru.BasicLRU[common.Hash, *BlockPropagation]
+	feed   event.Feed
+}
+
+func newPropagationTracker(limit int) *propagationTracker {
+	return &propagationTracker{blocks: lru.NewBasicLRU[common.Hash, *BlockPropagation](limit)}
+}
+
+// seen returns the record of a block, creating it if the block is new. It must
+// be called with t.lock held.
+func (t *propagationTracker) seen(peer string, hash common.Hash, number uint64, source string, at time.Time) *BlockPropagation {
+	if p, ok := t.blocks.Get(hash); ok {
+		return p
+	}
+	p := &BlockPropagation{
+		Hash:      hash,
+		Number:    number,
+		FirstSeen: at,
+		FirstPeer: peer,
+		Source:    source,
+	}
+	t.blocks.Add(hash, p)
+	return p
+}
+
+// announced records an announcement of an unknown block.
+func (t *propagationTracker) announced(peer string, hash common.Hash, number uint64, at time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	p := t.seen(peer, hash, number, SourceAnnounce, at)
+	p.Announces++
+	if p.announced.IsZero() {
+		p.announced = at
+	}
+}
+
+// arrived records the arrival of an unknown block, either broadcast by a peer
+// or retrieved after an announcement.
+func (t *propagationTracker) arrived(peer string, block *types.Block, at time.Time, broadcast bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	source := SourceAnnounce
+	if broadcast {
+		source = SourceBroadcast
+	}
+	p := t.seen(peer, block.Hash(), block.NumberU64(), source, at)
+	if broadcast {
+		p.Broadcasts++
+	}
+	if p.Arrived != nil {
+		return
+	}
+	p.Arrived, p.ArrivalPeer = &at, peer
+	if !p.announced.IsZero() && p.announced.Before(at) {
+		p.ArrivalGap = at.Sub(p.announced)
+		propagationGapHist.Update(p.ArrivalGap.Nanoseconds())
+	}
+	if p.FirstSeen == at {
+		if age := at.Sub(time.Unix(int64(block.Time()), 0)); age > 0 {
+			propagationAgeHist.Update(age.Nanoseconds())
+		}
+	}
+}
+
+// known records an announcement or broadcast of a block which is known locally.
+// Only blocks received from the network before are tracked.
+func (t *propagationTracker) known(hash common.Hash, broadcast bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if p, ok := t.blocks.Peek(hash); ok {
+		if broadcast {
+			p.Broadcasts++
+		} else {
+			p.Announces++
+		}
+	}
+}
+
+// imported records the completion of the import of a block and notifies the
+// subscribers.
+func (t *propagationTracker) imported(hash common.Hash, at time.Time, err error) {
+	t.lock.Lock()
+	p, ok := t.blocks.Peek(hash)
+	if !ok || p.Imported != nil {
+		t.lock.Unlock()
+		return
+	}
+	p.Imported, p.ImportDelay = &at, at.Sub(p.FirstSeen)
+	if err != nil {
+		p.ImportError = err.Error()
+	} else {
+		propagationImportHist.Update(p.ImportDelay.Nanoseconds())
+	}
+	event := *p
+	t.lock.Unlock()
+
+	t.feed.Send(event)
+}
+
+// get returns a copy of the record of a block.
+func (t *propagationTracker) get(hash common.Hash) *BlockPropagation {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if p, ok := t.blocks.Peek(hash); ok {
+		cpy := *p
+		return &cpy
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/vars"
+	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	errInvalidTargetBlocks = errors.New("invalid target blocks")
+	errInvalidConfidence   = errors.New("invalid confidence level")
+)
+
+const (
+	// estimateSampleBlocks is the number of recent blocks sampled by the fee
+	// estimator for inclusion statistics.
+	estimateSampleBlocks = 128
+
+	// maxTargetBlocks is the furthest inclusion target the fee estimator
+	// accepts, leaving enough windows of blocks in the sample.
+	maxTargetBlocks = estimateSampleBlocks / 4
+)
+
+var (
+	// DefaultTargetBlocks are the inclusion targets estimated if none are requested.
+	DefaultTargetBlocks = []uint64{1, 3, 10}
+
+	// DefaultConfidence are the confidence levels estimated if none are requested.
+	DefaultConfidence = []float64{0.5, 0.9, 0.99}
+)
+
+// MempoolBackend is implemented by oracle backends with access to the local
+// transaction pool and miner, which makes the fee estimator aware of the
+// pending demand for block space.
+type MempoolBackend interface {
+	GetPoolTransactions() (types.Transactions, error)
+	MinerGasTip() *big.Int
+}
+
+// FeeEstimate is a fee recommendation for including a transaction within a
+// number of blocks with a given confidence.
+type FeeEstimate struct {
+	TargetBlocks uint64
+	Confidence   float64
+	BaseFee      *big.Int // Projected base fee of the target block, nil before London
+	TipCap       *big.Int
+	FeeCap       *big.Int
+	WaitTime     time.Duration
+}
+
+// FeeEstimates are the fee recommendations for transactions built on top of
+// a head block.
+type FeeEstimates struct {
+	BlockNumber uint64
+	NextBaseFee *big.Int // nil before London
+	MinTip      *big.Int
+	PendingGas  uint64
+	BlockTime   time.Duration
+	Estimates   []FeeEstimate
+}
+
+// blockInclusion summarizes a sampled block for the fee estimator.
+type blockInclusion struct {
+	time    uint64
+	gasUsed uint64
+
+	// clearing is the lowest tip included in a full block, or nil if the
+	// block had room to spare, in which case any transaction paying the
+	// miners' minimum tip could have been included.
+	clearing *big.Int
+}
+
+// pendingTx is the demand for block space of a pooled transaction.
+type pendingTx struct {
+	tip *big.Int
+	gas uint64
+}
+
+// FeeEstimate recommends fees for inclusion within each of the target number
+// of blocks with each of the confidence levels.
+//
+// Unlike SuggestTipCap, the estimate does not assume recent blocks to be full:
+// blocks with room to spare are taken to include any transaction paying the
+// miners' minimum tip, the pending transaction pool is queued against the
+// block space of the target blocks, and the base fee is projected over them.
+// On chains paying the base fee to a vault, miners are only rewarded by the
+// tip, so it alone decides the order of inclusion.
+func (oracle *Oracle) FeeEstimate(ctx context.Context, targetBlocks []uint64, confidence []float64) (*FeeEstimates, error) {
+	if len(targetBlocks) == 0 {
+		targetBlocks = DefaultTargetBlocks
+	}
+	if len(confidence) == 0 {
+		confidence = DefaultConfidence
+	}
+	for _, target := range targetBlocks {
+		if target < 1 || target > maxTargetBlocks {
+			return nil, errInvalidTargetBlocks
+		}
+	}
+	for _, c := range confidence {
+		if !(c > 0 && c <= 1) {
+			return nil, errInvalidConfidence
+		}
+	}
+	head, err := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return nil, err
+	}
+	samples, err := oracle.sampleInclusion(ctx, head)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		config = oracle.backend.ChainConfig()
+		london = config.IsEnabled(config.GetEIP1559Transition, new(big.Int).Add(head.Number, common.Big1))
+		res    = &FeeEstimates{BlockNumber: head.Number.Uint64(), MinTip: new(big.Int).Set(oracle.ignorePrice)}
+	)
+	if london {
+		res.NextBaseFee = eip1559.CalcBaseFee(config, head)
+	}
+	var pending []pendingTx
+	if mempool, ok := oracle.backend.(MempoolBackend); ok {
+		if tip := mempool.MinerGasTip(); tip != nil && tip.Cmp(res.MinTip) > 0 {
+			res.MinTip.Set(tip)
+		}
+		txs, err := mempool.GetPoolTransactions()
+		if err != nil {
+			return nil, err
+		}
+		pending = pendingDemand(txs, res.NextBaseFee, res.MinTip)
+	}
+	for _, tx := range pending {
+		res.PendingGas += tx.gas
+	}
+	var avgGasUsed uint64
+	if len(samples) > 0 {
+		for _, s := range samples {
+			avgGasUsed += s.gasUsed
+		}
+		avgGasUsed /= uint64(len(samples))
+	}
+	if len(samples) > 1 {
+		span := samples[0].time - samples[len(samples)-1].time
+		res.BlockTime = time.Duration(span) * time.Second / time.Duration(len(samples)-1)
+	}
+	for _, target := range targetBlocks {
+		for _, c := range confidence {
+			tip := historicalTip(samples, target, c, res.MinTip)
+
+			// The share of the target blocks left to the pending transactions
+			// shrinks with the confidence, leaving room for competing
+			// transactions arriving in the meantime.
+			capacity := uint64(float64(target*head.GasLimit) * (1 - c/2))
+			if demand := demandTip(pending, capacity); demand != nil && demand.Cmp(tip) > 0 {
+				tip = demand
+			}
+			if tip.Cmp(oracle.maxPrice) > 0 {
+				tip = new(big.Int).Set(oracle.maxPrice)
+			}
+			estimate := FeeEstimate{
+				TargetBlocks: target,
+				Confidence:   c,
+				TipCap:       new(big.Int).Set(tip),
+				FeeCap:       new(big.Int).Set(tip),
+				WaitTime:     time.Duration(target) * res.BlockTime,
+			}
+			if london {
+				estimate.BaseFee = oracle.projectBaseFee(head, target, res.PendingGas, avgGasUsed, c)
+				estimate.FeeCap.Add(estimate.FeeCap, estimate.BaseFee)
+			}
+			res.Estimates = append(res.Estimates, estimate)
+		}
+	}
+	return res, nil
+}
+
+// sampleInclusion summarizes the recent blocks up to the head, newest first.
+func (oracle *Oracle) sampleInclusion(ctx context.Context, head *types.Header) ([]blockInclusion, error) {
+	var samples []blockInclusion
+	for number := head.Number.Uint64(); number > 0 && len(samples) < estimateSampleBlocks; number-- {
+		header, err := oracle.backend.HeaderByNumber(ctx, rpc.BlockNumber(number))
+		if header == nil {
+			return nil, err
+		}
+		hash := header.Hash()
+		if sample, ok := oracle.inclusionCache.Get(hash); ok {
+			samples = append(samples, sample)
+			continue
+		}
+		block, err := oracle.backend.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if block == nil {
+			return nil, err
+		}
+		sample := oracle.processInclusion(block)
+		oracle.inclusionCache.Add(hash, sample)
+		samples = append(samples, sample)
+	}
+	return samples, nil
+}
+
+// processInclusion summarizes a block for the fee estimator.
+func (oracle *Oracle) processInclusion(block *types.Block) blockInclusion {
+	sample := blockInclusion{time: block.Time(), gasUsed: block.GasUsed()}
+	if block.GasUsed()+vars.TxGas <= block.GasLimit() {
+		return sample
+	}
+	signer := types.MakeSigner(oracle.backend.ChainConfig(), block.Number(), block.Time())
+	for _, tx := range block.Transactions() {
+		tip, err := tx.EffectiveGasTip(block.BaseFee())
+		if err != nil || tip.Cmp(oracle.ignorePrice) < 0 {
+			continue
+		}
+		// Transactions of the miner itself tell nothing about its minimum tip.
+		if sender, err := types.Sender(signer, tx); err != nil || sender == block.Coinbase() {
+			continue
+		}
+		if sample.clearing == nil || tip.Cmp(sample.clearing) < 0 {
+			sample.clearing = tip
+		}
+	}
+	return sample
+}
+
+// historicalTip returns the tip which would have been included within target
+// blocks in the given fraction of the sampled windows of consecutive blocks.
+func historicalTip(samples []blockInclusion, target uint64, confidence float64, floor *big.Int) *big.Int {
+	var tips []*big.Int
+	for i := 0; i+int(target) <= len(samples); i++ {
+		// The window includes any tip above the lowest clearing tip of its
+		// blocks, or above the floor if any of them had room to spare.
+		var tip *big.Int
+		for _, sample := range samples[i : i+int(target)] {
+			if sample.clearing == nil {
+				tip = floor
+				break
+			}
+			if tip == nil || sample.clearing.Cmp(tip) < 0 {
+				tip = sample.clearing
+			}
+		}
+		tips = append(tips, tip)
+	}
+	if len(tips) == 0 {
+		return new(big.Int).Set(floor)
+	}
+	slices.SortFunc(tips, func(a, b *big.Int) int { return a.Cmp(b) })
+	index := int(math.Ceil(confidence*float64(len(tips)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	if tips[index].Cmp(floor) < 0 {
+		return new(big.Int).Set(floor)
+	}
+	return new(big.Int).Set(tips[index])
+}
+
+// pendingDemand returns the pooled transactions miners would include on top of
+// the next base fee, ordered by decreasing tip.
+func pendingDemand(txs types.Transactions, baseFee *big.Int, floor *big.Int) []pendingTx {
+	pending := make([]pendingTx, 0, len(txs))
+	for _, tx := range txs {
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil || tip.Cmp(floor) < 0 {
+			continue
+		}
+		pending = append(pending, pendingTx{tip: tip, gas: tx.Gas()})
+	}
+	slices.SortStableFunc(pending, func(a, b pendingTx) int { return b.tip.Cmp(a.tip) })
+	return pending
+}
+
+// demandTip returns the tip needed to outbid the pending transactions beyond
+// the given block space, or nil if they all fit.
+func demandTip(pending []pendingTx, capacity uint64) *big.Int {
+	var gas uint64
+	for _, tx := range pending {
+		if gas += tx.gas; gas > capacity {
+			return new(big.Int).Add(tx.tip, common.Big1)
+		}
+	}
+	return nil
+}
+
+// projectBaseFee projects the base fee of the target block after the head.
+//
+// The expected projection fills the blocks with the pending transactions, or
+// with the average recent usage if higher, while the worst case fills them
+// entirely. The result lies between the two in proportion to the confidence.
+func (oracle *Oracle) projectBaseFee(head *types.Header, target uint64, pendingGas uint64, avgGasUsed uint64, confidence float64) *big.Int {
+	config := oracle.backend.ChainConfig()
+	project := func(worst bool) *big.Int {
+		var (
+			parent  = head
+			pending = pendingGas
+		)
+		for i := uint64(1); ; i++ {
+			baseFee := eip1559.CalcBaseFee(config, parent)
+			if i == target {
+				return baseFee
+			}
+			gasUsed := parent.GasLimit
+			if !worst {
+				gasUsed = min(pending, parent.GasLimit)
+				pending -= gasUsed
+				gasUsed = max(gasUsed, avgGasUsed)
+			}
+			parent = &types.Header{
+				Number:   new(big.Int).Add(parent.Number, common.Big1),
+				GasLimit: parent.GasLimit,
+				GasUsed:  gasUsed,
+				BaseFee:  baseFee,
+			}
+		}
+	}
+	expected, worst := project(false), project(true)
+	if worst.Cmp(expected) <= 0 {
+		return expected
+	}
+	margin := new(big.Int).Sub(worst, expected)
+	margin.Mul(margin, big.NewInt(int64(confidence*1000)))
+	margin.Div(margin, big.NewInt(1000))
+	return margin.Add(margin, expected)
+}
+
+// suggestMempoolTipCap returns the tip cap recommended by the fee estimator for
+// inclusion in the next block, with the oracle percentile as confidence.
+func (oracle *Oracle) suggestMempoolTipCap(ctx context.Context) (*big.Int, error) {
+	confidence := float64(max(oracle.percentile, 1)) / 100
+	estimates, err := oracle.FeeEstimate(ctx, []uint64{1}, []float64{confidence})
+	if err != nil {
+		return nil, err
+	}
+	return estimates.Estimates[0].TipCap, nil
+}
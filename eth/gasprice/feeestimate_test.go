@@ -0,0 +1,180 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+// testMempoolBackend extends the test backend with a transaction pool and a
+// miner tip.
+type testMempoolBackend struct {
+	*testBackend
+	txs types.Transactions
+	tip *big.Int
+}
+
+func (b *testMempoolBackend) GetPoolTransactions() (types.Transactions, error) {
+	return b.txs, nil
+}
+
+func (b *testMempoolBackend) MinerGasTip() *big.Int {
+	return b.tip
+}
+
+func TestHistoricalTip(t *testing.T) {
+	floor := big.NewInt(1)
+	samples := []blockInclusion{
+		{clearing: big.NewInt(5)},
+		{},
+		{clearing: big.NewInt(3)},
+		{clearing: big.NewInt(7)},
+	}
+	var cases = []struct {
+		target     uint64
+		confidence float64
+		expect     int64
+	}{
+		{1, 0.5, 3},
+		{1, 1, 7},
+		{2, 0.5, 1},
+		{2, 1, 3},
+		{4, 1, 1},
+		{5, 1, 1}, // Not enough samples
+	}
+	for i, c := range cases {
+		tip := historicalTip(samples, c.target, c.confidence, floor)
+		if tip.Int64() != c.expect {
+			t.Errorf("case %d: tip mismatch, want %d, got %d", i, c.expect, tip)
+		}
+	}
+}
+
+func TestFeeEstimate(t *testing.T) {
+	backend := newTestBackend(t, big.NewInt(0), false)
+	defer backend.teardown()
+
+	// The test chain has room to spare in every block, so without a pool the
+	// estimator falls back to the lowest tip.
+	oracle := NewOracle(backend, Config{Blocks: 3, Percentile: 60, Default: big.NewInt(vars.GWei)})
+	res, err := oracle.FeeEstimate(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("failed to estimate fees: %v", err)
+	}
+	if len(res.Estimates) != len(DefaultTargetBlocks)*len(DefaultConfidence) {
+		t.Fatalf("estimate count mismatch: have %d", len(res.Estimates))
+	}
+	for _, e := range res.Estimates {
+		if e.TipCap.Cmp(DefaultIgnorePrice) != 0 {
+			t.Errorf("target %d, confidence %v: tip mismatch, want %d, got %d", e.TargetBlocks, e.Confidence, DefaultIgnorePrice, e.TipCap)
+		}
+	}
+	if res.BlockTime != 10*time.Second {
+		t.Errorf("block time mismatch: have %v, want %v", res.BlockTime, 10*time.Second)
+	}
+
+	// Queue pooled transactions filling one and a half blocks.
+	head := backend.chain.CurrentHeader()
+	var txs types.Transactions
+	for _, tip := range []int64{5, 4, 3, 0} {
+		txs = append(txs, types.NewTx(&types.DynamicFeeTx{
+			Gas:       head.GasLimit / 2,
+			GasFeeCap: big.NewInt(100 * vars.GWei),
+			GasTipCap: big.NewInt(tip * vars.GWei),
+		}))
+	}
+	mempool := &testMempoolBackend{testBackend: backend, txs: txs, tip: big.NewInt(vars.GWei)}
+	oracle = NewOracle(mempool, Config{Blocks: 3, Percentile: 60, Default: big.NewInt(vars.GWei), Mempool: true})
+
+	res, err = oracle.FeeEstimate(context.Background(), []uint64{1, 3}, []float64{0.5, 0.99})
+	if err != nil {
+		t.Fatalf("failed to estimate fees: %v", err)
+	}
+	if res.MinTip.Cmp(mempool.tip) != 0 {
+		t.Errorf("min tip mismatch: have %d, want %d", res.MinTip, mempool.tip)
+	}
+	// The transaction below the miner tip is not part of the demand.
+	if res.PendingGas != 3*(head.GasLimit/2) {
+		t.Errorf("pending gas mismatch: have %d, want %d", res.PendingGas, 3*(head.GasLimit/2))
+	}
+	outbid := big.NewInt(4*vars.GWei + 1)
+	var expect = []struct {
+		target uint64
+		tip    *big.Int
+	}{
+		{1, outbid},
+		{1, outbid},
+		{3, mempool.tip},
+		{3, mempool.tip},
+	}
+	for i, e := range res.Estimates {
+		if e.TargetBlocks != expect[i].target || e.TipCap.Cmp(expect[i].tip) != 0 {
+			t.Errorf("estimate %d: mismatch, want target %d tip %d, got target %d tip %d", i, expect[i].target, expect[i].tip, e.TargetBlocks, e.TipCap)
+		}
+		if e.FeeCap.Cmp(new(big.Int).Add(e.BaseFee, e.TipCap)) != 0 {
+			t.Errorf("estimate %d: fee cap %d is not base fee %d plus tip %d", i, e.FeeCap, e.BaseFee, e.TipCap)
+		}
+		if e.WaitTime != time.Duration(e.TargetBlocks)*res.BlockTime {
+			t.Errorf("estimate %d: wait time mismatch, have %v", i, e.WaitTime)
+		}
+	}
+	if res.Estimates[0].BaseFee.Cmp(res.NextBaseFee) != 0 {
+		t.Errorf("next block base fee mismatch: have %d, want %d", res.Estimates[0].BaseFee, res.NextBaseFee)
+	}
+	if res.Estimates[3].BaseFee.Cmp(res.Estimates[2].BaseFee) <= 0 {
+		t.Errorf("base fee projection does not grow with the confidence")
+	}
+	// In mempool mode, the tip suggestion follows the estimator.
+	tip, err := oracle.SuggestTipCap(context.Background())
+	if err != nil {
+		t.Fatalf("failed to suggest tip: %v", err)
+	}
+	if tip.Cmp(outbid) != 0 {
+		t.Errorf("suggested tip mismatch: have %d, want %d", tip, outbid)
+	}
+
+	if _, err := oracle.FeeEstimate(context.Background(), []uint64{0}, nil); !errors.Is(err, errInvalidTargetBlocks) {
+		t.Errorf("invalid target accepted: %v", err)
+	}
+	if _, err := oracle.FeeEstimate(context.Background(), nil, []float64{1.5}); !errors.Is(err, errInvalidConfidence) {
+		t.Errorf("invalid confidence accepted: %v", err)
+	}
+}
+
+func TestFeeEstimateLegacy(t *testing.T) {
+	backend := newTestBackend(t, nil, false)
+	defer backend.teardown()
+
+	oracle := NewOracle(backend, Config{Blocks: 3, Percentile: 60})
+	res, err := oracle.FeeEstimate(context.Background(), []uint64{2}, []float64{0.9})
+	if err != nil {
+		t.Fatalf("failed to estimate fees: %v", err)
+	}
+	if res.NextBaseFee != nil || res.Estimates[0].BaseFee != nil {
+		t.Fatalf("base fee estimated before London")
+	}
+	if res.Estimates[0].FeeCap.Cmp(res.Estimates[0].TipCap) != 0 {
+		t.Fatalf("gas price mismatch: have %d, want %d", res.Estimates[0].FeeCap, res.Estimates[0].TipCap)
+	}
+}
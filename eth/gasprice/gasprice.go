@@ -48,6 +48,10 @@ type Config struct {
 	Default          *big.Int `toml:",omitempty"`
 	MaxPrice         *big.Int `toml:",omitempty"`
 	IgnorePrice      *big.Int `toml:",omitempty"`
+
+	// Mempool makes SuggestTipCap use the mempool-aware fee estimator rather
+	// than sampling the tips of recent blocks.
+	Mempool bool
 }
 
 // OracleBackend includes all necessary background APIs for oracle.
@@ -74,7 +78,9 @@ type Oracle struct {
 	checkBlocks, percentile           int
 	maxHeaderHistory, maxBlockHistory uint64
 
-	historyCache *lru.Cache[cacheKey, processedFees]
+	historyCache   *lru.Cache[cacheKey, processedFees]
+	inclusionCache *lru.Cache[common.Hash, blockInclusion]
+	mempool        bool
 }
 
 // NewOracle returns a new gasprice oracle which can recommend suitable
@@ -139,6 +145,8 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 		maxHeaderHistory: maxHeaderHistory,
 		maxBlockHistory:  maxBlockHistory,
 		historyCache:     cache,
+		inclusionCache:   lru.NewCache[common.Hash, blockInclusion](estimateSampleBlocks * 2),
+		mempool:          params.Mempool,
 	}
 }
 
@@ -148,7 +156,13 @@ func NewOracle(backend OracleBackend, params Config) *Oracle {
 // Note, for legacy transactions and the legacy eth_gasPrice RPC call, it will be
 // necessary to add the basefee to the returned number to fall back to the legacy
 // behavior.
+//
+// In mempool mode, the tip cap recommended by the fee estimator for inclusion
+// in the next block is returned instead.
 func (oracle *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
+	if oracle.mempool {
+		return oracle.suggestMempoolTipCap(ctx)
+	}
 	head, _ := oracle.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
 	headHash := head.Hash()
 
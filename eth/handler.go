@@ -28,6 +28,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/beacon"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/checkpoint"
 	"github.com/ethereum/go-ethereum/core/forkid"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool"
@@ -35,12 +36,14 @@ import (
 	"github.com/ethereum/go-ethereum/eth/downloader"
 	"github.com/ethereum/go-ethereum/eth/fetcher"
 	"github.com/ethereum/go-ethereum/eth/protocols/eth"
+	"github.com/ethereum/go-ethereum/eth/protocols/lhp"
 	"github.com/ethereum/go-ethereum/eth/protocols/snap"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/vars"
 	"github.com/ethereum/go-ethereum/triedb/pathdb"
@@ -86,21 +89,28 @@ type txPool interface {
 // handlerConfig is the collection of initialization parameters to create a full
 // node network handler.
 type handlerConfig struct {
-	Database       ethdb.Database            // Database for direct sync insertions
-	Chain          *core.BlockChain          // Blockchain to serve data from
-	TxPool         txPool                    // Transaction pool to propagate from
-	Merger         *consensus.Merger         // The manager for eth1/2 transition
-	Network        uint64                    // Network identifier to advertise
-	Sync           downloader.SyncMode       // Whether to snap or full sync
-	BloomCache     uint64                    // Megabytes to alloc for snap sync bloom
-	EventMux       *event.TypeMux            // Legacy event mux, deprecate for `feed`
-	Checkpoint     *ctypes.TrustedCheckpoint // Hard coded checkpoint for sync challenges
-	RequiredBlocks map[uint64]common.Hash    // Hard coded map of required block hashes for sync challenges
+	Database         ethdb.Database            // Database for direct sync insertions
+	Chain            *core.BlockChain          // Blockchain to serve data from
+	TxPool           txPool                    // Transaction pool to propagate from
+	Merger           *consensus.Merger         // The manager for eth1/2 transition
+	Network          uint64                    // Network identifier to advertise
+	Sync             downloader.SyncMode       // Whether to snap or full sync
+	BloomCache       uint64                    // Megabytes to alloc for snap sync bloom
+	EventMux         *event.TypeMux            // Legacy event mux, deprecate for `feed`
+	Checkpoint       *ctypes.TrustedCheckpoint // Hard coded checkpoint for sync challenges
+	SignedCheckpoint *checkpoint.Checkpoint    // Verified signed checkpoint to enforce during sync
+	RequiredBlocks   map[uint64]common.Hash    // Hard coded map of required block hashes for sync challenges
+	PrivateTxPeers   []*enode.Node             // Trusted peers to relay private transactions to
+	PrivateTxExpiry  uint64                    // Number of blocks private transactions are kept out of the gossip for
+	CHT              *lhp.CHT                  // Canonical hash tries to serve light clients from (nil = not serving)
+	LightPeers       int                       // Maximum number of light clients to serve
+	LightNoSyncServe bool                      // Whether to serve light clients before syncing
 }
 
 type handler struct {
 	networkID  uint64
-	forkFilter forkid.Filter // Fork ID filter, constant across the lifetime of the node
+	forkFilter forkid.Filter   // Fork ID filter, constant across the lifetime of the node
+	nodeFilter *eth.NodeFilter // Fork ID filter of dial candidates, with rejection statistics
 
 	snapSync atomic.Bool // Flag whether snap sync is enabled (gets disabled if we already have blocks)
 	synced   atomic.Bool // Flag whether we're considered synchronised (enables transaction processing)
@@ -116,9 +126,15 @@ type handler struct {
 	downloader   *downloader.Downloader
 	blockFetcher *fetcher.BlockFetcher
 	txFetcher    *fetcher.TxFetcher
+	privateTxs   *privateTxRelay
 	peers        *peerSet
 	merger       *consensus.Merger
 
+	cht              *lhp.CHT     // Canonical hash tries served to light clients
+	lightPeers       int          // Maximum number of light clients to serve
+	lightPeerCount   atomic.Int32 // Number of light clients being served
+	lightNoSyncServe bool         // Whether to serve light clients before syncing
+
 	eventMux      *event.TypeMux
 	txsCh         chan core.NewTxsEvent
 	txsSub        event.Subscription
@@ -145,6 +161,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	h := &handler{
 		networkID:      config.Network,
 		forkFilter:     forkid.NewFilter(config.Chain),
+		nodeFilter:     eth.NewNodeFilter(config.Chain),
 		eventMux:       config.EventMux,
 		database:       config.Database,
 		txpool:         config.TxPool,
@@ -189,12 +206,21 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		h.checkpointNumber = (config.Checkpoint.SectionIndex+1)*vars.CHTFrequency - 1
 		h.checkpointHash = config.Checkpoint.SectionHead
 	}
+	// A signed checkpoint is explicitly requested by the user, prefer it over any
+	// bundled one
+	if config.SignedCheckpoint != nil {
+		h.checkpointNumber = config.SignedCheckpoint.Number
+		h.checkpointHash = config.SignedCheckpoint.Hash
+	}
 	// If snap sync is requested but snapshots are disabled, fail loudly
 	if h.snapSync.Load() && config.Chain.Snapshots() == nil {
 		return nil, errors.New("snap sync not supported with snapshots disabled")
 	}
 	// Construct the downloader (long sync)
 	h.downloader = downloader.New(h.checkpointNumber, config.Database, h.eventMux, h.chain, nil, h.removePeer, h.enableSyncedFeatures)
+	if config.SignedCheckpoint != nil {
+		h.downloader.SetSignedCheckpoint(config.SignedCheckpoint)
+	}
 	if ttd := h.chain.Config().GetEthashTerminalTotalDifficulty(); ttd != nil {
 		if h.chain.Config().GetEthashTerminalTotalDifficultyPassed() {
 			log.Info("Chain post-merge, sync via beacon client")
@@ -295,7 +321,7 @@ func newHandler(config *handlerConfig) (*handler, error) {
 		}
 		return n, err
 	}
-	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.removePeer)
+	h.blockFetcher = fetcher.NewBlockFetcher(false, nil, h.chain.GetBlockByHash, validator, h.BroadcastBlock, heighter, nil, inserter, h.penalizePeer)
 
 	fetchTx := func(peer string, hashes []common.Hash) error {
 		p := h.peers.peer(peer)
@@ -307,7 +333,13 @@ func newHandler(config *handlerConfig) (*handler, error) {
 	addTxs := func(txs []*types.Transaction) []error {
 		return h.txpool.Add(txs, false, false)
 	}
-	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx, h.removePeer)
+	// The transaction fetcher only drops peers violating their announcements.
+	dropTxPeer := func(peer string) {
+		h.penalizePeer(peer, p2p.OffenseProtocolViolation)
+	}
+	h.txFetcher = fetcher.NewTxFetcher(h.txpool.Has, addTxs, fetchTx, dropTxPeer)
+	h.cht, h.lightPeers, h.lightNoSyncServe = config.CHT, config.LightPeers, config.LightNoSyncServe
+	h.privateTxs = newPrivateTxRelay(h.chain, h.txpool, config.PrivateTxPeers, config.PrivateTxExpiry, h.BroadcastTransactions)
 	h.chainSync = newChainSyncer(h)
 	return h, nil
 }
@@ -373,10 +405,18 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 		td      = h.chain.GetTd(hash, number)
 	)
 	forkID := forkid.NewID(h.chain.Config(), genesis, number, head.Time)
-	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter); err != nil {
+	if err := peer.Handshake(h.networkID, td, hash, genesis.Hash(), forkID, h.forkFilter, h.servedBlockRange()); err != nil {
 		peer.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
 	}
+	// Fork choice is still by total difficulty, estimate it if the peer didn't
+	// advertise it.
+	if served := peer.BlockRange(); served != nil {
+		td, difficulty := h.estimatePeerTD(served.LatestBlockHash, served.LatestBlock)
+		peer.SetHead(served.LatestBlockHash, td, difficulty)
+	}
+	// The peer is on our chain, prefer it as a dial candidate in later sessions.
+	peer.Peer.MarkVerified()
 	reject := false // reserved peer slots
 	if h.snapSync.Load() {
 		if snap == nil {
@@ -473,7 +513,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 
 			case <-timeout.C:
 				peer.Log().Warn("Checkpoint challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
-				h.removePeer(peer.ID())
+				h.penalizePeer(peer.ID(), p2p.OffenseTimeout)
 
 			case <-dead:
 				// Peer handler terminated, abort all goroutines
@@ -511,6 +551,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 				}
 				if headers[0].Number.Uint64() != number || headers[0].Hash() != hash {
 					peer.Log().Info("Required block mismatch, dropping peer", "number", number, "hash", headers[0].Hash(), "want", hash)
+					peer.Penalize(p2p.OffenseInvalidHeader)
 					res.Done <- errors.New("required block mismatch")
 					return
 				}
@@ -518,7 +559,7 @@ func (h *handler) runEthPeer(peer *eth.Peer, handler eth.Handler) error {
 				res.Done <- nil
 			case <-timeout.C:
 				peer.Log().Warn("Required block challenge timed out, dropping", "addr", peer.RemoteAddr(), "type", peer.Name())
-				h.removePeer(peer.ID())
+				h.penalizePeer(peer.ID(), p2p.OffenseTimeout)
 			}
 		}(number, hash, req)
 	}
@@ -558,6 +599,16 @@ func (h *handler) removePeer(id string) {
 	}
 }
 
+// penalizePeer records an offense of a peer in its reputation and requests its
+// disconnection.
+func (h *handler) penalizePeer(id string, offense p2p.Offense) {
+	peer := h.peers.peer(id)
+	if peer != nil {
+		peer.Peer.Penalize(offense)
+		peer.Peer.Disconnect(p2p.DiscUselessPeer)
+	}
+}
+
 // unregisterPeer removes a peer from the downloader, fetchers and main peer set.
 func (h *handler) unregisterPeer(id string) {
 	// Create a custom logger to avoid printing the entire id
@@ -598,6 +649,9 @@ func (h *handler) Start(maxPeers int) {
 	h.txsSub = h.txpool.SubscribeTransactions(h.txsCh, false)
 	go h.txBroadcastLoop()
 
+	// relay private transactions and publish the expired ones
+	h.privateTxs.start()
+
 	// broadcast mined blocks
 	h.wg.Add(1)
 	h.minedBlockSub = h.eventMux.Subscribe(core.NewMinedBlockEvent{})
@@ -607,6 +661,10 @@ func (h *handler) Start(maxPeers int) {
 	h.wg.Add(1)
 	go h.chainSync.loop()
 
+	// announce the range of served blocks to eth/69 peers
+	h.wg.Add(1)
+	go h.blockRangeLoop()
+
 	// start artificial finality safety loop
 	h.wg.Add(1)
 	go h.artificialFinalitySafetyLoop()
@@ -619,6 +677,7 @@ func (h *handler) Start(maxPeers int) {
 func (h *handler) Stop() {
 	h.txsSub.Unsubscribe()        // quits txBroadcastLoop
 	h.minedBlockSub.Unsubscribe() // quits blockBroadcastLoop
+	h.privateTxs.stop()
 
 	// Quit chainSync and txsync64.
 	// After this is done, no new peers will be accepted.
@@ -749,7 +808,9 @@ func (h *handler) txBroadcastLoop() {
 	for {
 		select {
 		case event := <-h.txsCh:
-			h.BroadcastTransactions(event.Txs)
+			if txs := h.privateTxs.filter(event.Txs); len(txs) > 0 {
+				h.BroadcastTransactions(txs)
+			}
 		case <-h.txsSub.Err():
 			return
 		}
@@ -104,6 +104,8 @@ func (h *ethHandler) handleBlockAnnounces(peer *eth.Peer, hashes []common.Hash,
 		if !h.chain.HasBlock(hashes[i], numbers[i]) {
 			unknownHashes = append(unknownHashes, hashes[i])
 			unknownNumbers = append(unknownNumbers, numbers[i])
+		} else {
+			h.blockFetcher.NotifyKnown(peer.ID(), hashes[i])
 		}
 	}
 	for i := 0; i < len(unknownHashes); i++ {
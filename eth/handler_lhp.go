@@ -0,0 +1,80 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/eth/protocols/lhp"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+// lhpHandler implements the lhp.Backend interface to serve headers and proofs
+// to light clients.
+type lhpHandler handler
+
+// Chain retrieves the blockchain object to serve data.
+func (h *lhpHandler) Chain() *core.BlockChain { return h.chain }
+
+// CHT retrieves the canonical hash tries to prove headers against.
+func (h *lhpHandler) CHT() *lhp.CHT { return h.cht }
+
+// RunPeer is invoked when a light client joins on the `lhp` protocol.
+func (h *lhpHandler) RunPeer(peer *lhp.Peer, hand lhp.Handler) error {
+	if !(*handler)(h).incHandlers() {
+		return p2p.DiscQuitting
+	}
+	defer (*handler)(h).decHandlers()
+
+	// Until synced our head is stale, don't advertise it unless requested to.
+	if !h.synced.Load() && !h.lightNoSyncServe {
+		return p2p.DiscUselessPeer
+	}
+	if count := h.lightPeerCount.Add(1); int(count) > h.lightPeers && !peer.Trusted() {
+		h.lightPeerCount.Add(-1)
+		return p2p.DiscTooManyPeers
+	}
+	defer h.lightPeerCount.Add(-1)
+
+	var (
+		genesis = h.chain.Genesis()
+		head    = h.chain.CurrentHeader()
+		hash    = head.Hash()
+		number  = head.Number.Uint64()
+		td      = new(big.Int)
+	)
+	if headTd := h.chain.GetTd(hash, number); headTd != nil {
+		td.Set(headTd)
+	}
+	status := lhp.StatusPacket{
+		NetworkID:   h.networkID,
+		Genesis:     genesis.Hash(),
+		ForkID:      forkid.NewID(h.chain.Config(), genesis, number, head.Time),
+		Head:        hash,
+		HeadNumber:  number,
+		TD:          td,
+		CHTSections: h.cht.Sections(),
+	}
+	if err := peer.Handshake(status, h.forkFilter); err != nil {
+		peer.Log().Debug("Light handshake failed", "err", err)
+		return err
+	}
+	peer.Log().Debug("Serving light client", "sections", status.CHTSections)
+	return hand(peer)
+}
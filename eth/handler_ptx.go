@@ -0,0 +1,36 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/eth/protocols/ptx"
+)
+
+// ptxHandler implements the ptx.Backend interface to relay private transactions
+// to and from trusted peers.
+type ptxHandler handler
+
+// RunPeer is invoked when a peer joins on the `ptx` protocol.
+func (h *ptxHandler) RunPeer(peer *ptx.Peer, hand ptx.Handler) error {
+	return h.privateTxs.runPeer(peer, hand)
+}
+
+// Handle is invoked from a peer's message handler when it receives private
+// transactions.
+func (h *ptxHandler) Handle(peer *ptx.Peer, packet *ptx.TransactionsPacket) error {
+	return h.privateTxs.handle(peer, packet)
+}
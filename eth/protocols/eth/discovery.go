@@ -17,12 +17,25 @@
 package eth
 
 import (
+	"errors"
+	"sync"
+
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+// Reasons for rejecting dial candidates.
+const (
+	RejectNoEntry      = "no-eth-entry"  // Node doesn't advertise the eth protocol
+	RejectInvalidEntry = "invalid-entry" // Node advertises an undecodable entry
+	RejectRemoteStale  = "remote-stale"  // Node is on our chain, but needs an update
+	RejectIncompatible = "incompatible"  // Node is on another chain or fork
+)
+
 // enrEntry is the ENR entry which advertises `eth` protocol on the discovery.
 type enrEntry struct {
 	ForkID forkid.ID // Fork identifier per EIP-2124
@@ -64,3 +77,84 @@ func currentENREntry(chain *core.BlockChain) *enrEntry {
 		ForkID: forkid.NewID(chain.Config(), chain.Genesis(), head.Number.Uint64(), head.Time),
 	}
 }
+
+// NodeFilter checks the `eth` entry of node records against the local fork ID,
+// rejecting nodes of other chains before they are dialed. It keeps statistics
+// of the rejected nodes by reason.
+type NodeFilter struct {
+	filter forkid.Filter
+
+	mu       sync.Mutex
+	accepted uint64
+	rejected map[string]uint64
+}
+
+// NodeFilterStats counts the nodes checked by a NodeFilter.
+type NodeFilterStats struct {
+	Accepted uint64            `json:"accepted"`
+	Rejected map[string]uint64 `json:"rejected"` // Rejected nodes by reason
+}
+
+// NewNodeFilter creates a node filter for the given chain.
+func NewNodeFilter(chain *core.BlockChain) *NodeFilter {
+	return newNodeFilter(forkid.NewFilter(chain))
+}
+
+func newNodeFilter(filter forkid.Filter) *NodeFilter {
+	return &NodeFilter{filter: filter, rejected: make(map[string]uint64)}
+}
+
+// Check reports whether a node advertises a fork ID compatible with the local
+// chain.
+func (f *NodeFilter) Check(n *enode.Node) bool {
+	reason := f.check(n)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if reason == "" {
+		f.accepted++
+	} else {
+		f.rejected[reason]++
+	}
+	if metrics.Enabled {
+		if reason == "" {
+			metrics.GetOrRegisterMeter("eth/protocols/eth/dialfilter/accepted", nil).Mark(1)
+		} else {
+			metrics.GetOrRegisterMeter("eth/protocols/eth/dialfilter/rejected/"+reason, nil).Mark(1)
+		}
+	}
+	return reason == ""
+}
+
+// check returns the reason for rejecting a node, or the empty string if the
+// node is accepted.
+func (f *NodeFilter) check(n *enode.Node) string {
+	var entry enrEntry
+	if err := n.Load(&entry); err != nil {
+		if enr.IsNotFound(err) {
+			return RejectNoEntry
+		}
+		return RejectInvalidEntry
+	}
+	switch err := f.filter(entry.ForkID); {
+	case err == nil:
+		return ""
+	case errors.Is(err, forkid.ErrRemoteStale):
+		return RejectRemoteStale
+	default:
+		return RejectIncompatible
+	}
+}
+
+// Stats returns the number of nodes accepted and rejected by the filter.
+func (f *NodeFilter) Stats() NodeFilterStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stats := NodeFilterStats{Accepted: f.accepted, Rejected: make(map[string]uint64, len(f.rejected))}
+	for reason, n := range f.rejected {
+		stats.Rejected[reason] = n
+	}
+	return stats
+}
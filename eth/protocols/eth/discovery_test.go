@@ -0,0 +1,79 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+func TestNodeFilter(t *testing.T) {
+	var (
+		local = forkid.ID{Hash: [4]byte{1}}
+		stale = forkid.ID{Hash: [4]byte{2}}
+		other = forkid.ID{Hash: [4]byte{3}}
+	)
+	filter := newNodeFilter(func(id forkid.ID) error {
+		switch id {
+		case local:
+			return nil
+		case stale:
+			return forkid.ErrRemoteStale
+		default:
+			return forkid.ErrLocalIncompatibleOrStale
+		}
+	})
+	node := func(i byte, entry enr.Entry) *enode.Node {
+		var r enr.Record
+		if entry != nil {
+			r.Set(entry)
+		}
+		return enode.SignNull(&r, enode.ID{i})
+	}
+	tests := []struct {
+		node *enode.Node
+		ok   bool
+	}{
+		{node(1, &enrEntry{ForkID: local}), true},
+		{node(2, &enrEntry{ForkID: local}), true},
+		{node(3, &enrEntry{ForkID: stale}), false},
+		{node(4, &enrEntry{ForkID: other}), false},
+		{node(5, nil), false},
+		{node(6, enr.WithEntry("eth", "garbage")), false},
+	}
+	for i, tt := range tests {
+		if ok := filter.Check(tt.node); ok != tt.ok {
+			t.Errorf("node %d: have %v, want %v", i, ok, tt.ok)
+		}
+	}
+	want := NodeFilterStats{
+		Accepted: 2,
+		Rejected: map[string]uint64{
+			RejectRemoteStale:  1,
+			RejectIncompatible: 1,
+			RejectNoEntry:      1,
+			RejectInvalidEntry: 1,
+		},
+	}
+	if have := filter.Stats(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("wrong stats\nhave %+v\nwant %+v", have, want)
+	}
+}
@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"time"
@@ -89,8 +90,9 @@ type TxPool interface {
 	Get(hash common.Hash) *types.Transaction
 }
 
-// MakeProtocols constructs the P2P protocol definitions for `eth`.
-func MakeProtocols(backend Backend, network uint64, protocolVersions []uint, dnsdisc enode.Iterator) []p2p.Protocol {
+// MakeProtocols constructs the P2P protocol definitions for `eth`. Nodes found
+// through discovery are checked with filter before dialing, if it is set.
+func MakeProtocols(backend Backend, network uint64, protocolVersions []uint, dnsdisc enode.Iterator, filter *NodeFilter) []p2p.Protocol {
 	protocols := make([]p2p.Protocol, 0, len(protocolVersions))
 	for _, version := range protocolVersions {
 		version := version // Closure
@@ -102,7 +104,7 @@ func MakeProtocols(backend Backend, network uint64, protocolVersions []uint, dns
 			continue
 		}
 
-		protocols = append(protocols, p2p.Protocol{
+		proto := p2p.Protocol{
 			Name:    ProtocolName,
 			Version: version,
 			Length:  protocolLengths[version],
@@ -122,11 +124,22 @@ func MakeProtocols(backend Backend, network uint64, protocolVersions []uint, dns
 			},
 			Attributes:     []enr.Entry{currentENREntry(backend.Chain())},
 			DialCandidates: dnsdisc,
-		})
+			Priority:       isBlockPropagation,
+		}
+		if filter != nil {
+			proto.DialFilter = filter.Check
+		}
+		protocols = append(protocols, proto)
 	}
 	return protocols
 }
 
+// isBlockPropagation reports whether a message announces or propagates a new
+// block. These are sent ahead of bulk sync and transaction traffic.
+func isBlockPropagation(code uint64) bool {
+	return code == NewBlockHashesMsg || code == NewBlockMsg
+}
+
 // NodeInfo represents a short summary of the `eth` sub-protocol metadata
 // known about the host peer.
 type NodeInfo struct {
@@ -158,6 +171,12 @@ func Handle(backend Backend, peer *Peer) error {
 	for {
 		if err := handleMessage(backend, peer); err != nil {
 			peer.Log().Debug("Message handling failed in `eth`", "err", err)
+			switch {
+			case errors.Is(err, errDecode), errors.Is(err, errMsgTooLarge), errors.Is(err, errInvalidMsgCode):
+				peer.Penalize(p2p.OffenseProtocolViolation)
+			case errors.Is(err, errDanglingResponse), errors.Is(err, errMismatchingResponseType):
+				peer.Penalize(p2p.OffenseUselessResponse)
+			}
 			return err
 		}
 	}
@@ -184,6 +203,22 @@ var eth68 = map[uint64]msgHandler{
 	PooledTransactionsMsg:         handlePooledTransactions,
 }
 
+var eth69 = map[uint64]msgHandler{
+	NewBlockHashesMsg:             handleNewBlockhashes,
+	NewBlockMsg:                   handleNewBlock,
+	TransactionsMsg:               handleTransactions,
+	NewPooledTransactionHashesMsg: handleNewPooledTransactionHashes,
+	GetBlockHeadersMsg:            handleGetBlockHeaders,
+	BlockHeadersMsg:               handleBlockHeaders,
+	GetBlockBodiesMsg:             handleGetBlockBodies,
+	BlockBodiesMsg:                handleBlockBodies,
+	GetReceiptsMsg:                handleGetReceipts69,
+	ReceiptsMsg:                   handleReceipts69,
+	GetPooledTransactionsMsg:      handleGetPooledTransactions,
+	PooledTransactionsMsg:         handlePooledTransactions,
+	BlockRangeUpdateMsg:           handleBlockRangeUpdate,
+}
+
 // handleMessage is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
 func handleMessage(backend Backend, peer *Peer) error {
@@ -198,6 +233,9 @@ func handleMessage(backend Backend, peer *Peer) error {
 	defer msg.Discard()
 
 	var handlers = eth68
+	if peer.Version() >= ETH69 {
+		handlers = eth69
+	}
 
 	// Track the amount of time it takes to serve the request and run the handler
 	if metrics.Enabled {
@@ -35,14 +35,30 @@ const (
 )
 
 // Handshake executes the eth protocol handshake, negotiating version number,
-// network IDs, difficulties, head and genesis blocks.
-func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter) error {
+// network IDs, difficulties, head and genesis blocks. On eth/69 and newer, the
+// range of blocks served is advertised instead of the total difficulty, which
+// stays zero until the caller estimates it with SetHead.
+func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis common.Hash, forkID forkid.ID, forkFilter forkid.Filter, served BlockRangeUpdatePacket) error {
 	// Send out own handshake in a new thread
 	errc := make(chan error, 2)
 
-	var status StatusPacket // safe to read after two values have been received from errc
-
+	var (
+		status   StatusPacket   // safe to read after two values have been received from errc
+		status69 StatusPacket69 // safe to read after two values have been received from errc
+	)
 	go func() {
+		if p.version >= ETH69 {
+			errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket69{
+				ProtocolVersion: uint32(p.version),
+				NetworkID:       network,
+				Genesis:         genesis,
+				ForkID:          forkID,
+				EarliestBlock:   served.EarliestBlock,
+				LatestBlock:     served.LatestBlock,
+				LatestBlockHash: served.LatestBlockHash,
+			})
+			return
+		}
 		errc <- p2p.Send(p.rw, StatusMsg, &StatusPacket{
 			ProtocolVersion: uint32(p.version),
 			NetworkID:       network,
@@ -53,6 +69,10 @@ func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 		})
 	}()
 	go func() {
+		if p.version >= ETH69 {
+			errc <- p.readStatus69(network, &status69, genesis, forkFilter)
+			return
+		}
 		errc <- p.readStatus(network, &status, genesis, forkFilter)
 	}()
 	timeout := time.NewTimer(handshakeTimeout)
@@ -69,6 +89,15 @@ func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 			return p2p.DiscReadTimeout
 		}
 	}
+	if p.version >= ETH69 {
+		p.td, p.head, p.forkid = new(big.Int), status69.LatestBlockHash, status69.ForkID
+		p.blockRange = &BlockRangeUpdatePacket{
+			EarliestBlock:   status69.EarliestBlock,
+			LatestBlock:     status69.LatestBlock,
+			LatestBlockHash: status69.LatestBlockHash,
+		}
+		return nil
+	}
 	p.td, p.head, p.forkid = status.TD, status.Head, status.ForkID
 
 	// TD at mainnet block #7753254 is 76 bits. If it becomes 100 million times
@@ -81,6 +110,30 @@ func (p *Peer) Handshake(network uint64, td *big.Int, head common.Hash, genesis
 
 // readStatus reads the remote handshake message.
 func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.Hash, forkFilter forkid.Filter) error {
+	if err := p.readStatusMsg(status); err != nil {
+		return err
+	}
+	return p.checkStatus(network, genesis, forkFilter, status.ProtocolVersion, status.NetworkID, status.Genesis, status.ForkID)
+}
+
+// readStatus69 reads the remote handshake message on eth/69 and newer.
+func (p *Peer) readStatus69(network uint64, status *StatusPacket69, genesis common.Hash, forkFilter forkid.Filter) error {
+	if err := p.readStatusMsg(status); err != nil {
+		return err
+	}
+	if err := p.checkStatus(network, genesis, forkFilter, status.ProtocolVersion, status.NetworkID, status.Genesis, status.ForkID); err != nil {
+		return err
+	}
+	served := BlockRangeUpdatePacket{
+		EarliestBlock:   status.EarliestBlock,
+		LatestBlock:     status.LatestBlock,
+		LatestBlockHash: status.LatestBlockHash,
+	}
+	return served.validate()
+}
+
+// readStatusMsg reads and decodes the remote handshake message.
+func (p *Peer) readStatusMsg(status interface{}) error {
 	msg, err := p.rw.ReadMsg()
 	if err != nil {
 		return err
@@ -91,20 +144,24 @@ func (p *Peer) readStatus(network uint64, status *StatusPacket, genesis common.H
 	if msg.Size > maxMessageSize {
 		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
 	}
-	// Decode the handshake and make sure everything matches
-	if err := msg.Decode(&status); err != nil {
+	if err := msg.Decode(status); err != nil {
 		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
 	}
-	if status.NetworkID != network {
-		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, status.NetworkID, network)
+	return nil
+}
+
+// checkStatus makes sure the remote handshake matches the local chain.
+func (p *Peer) checkStatus(network uint64, genesis common.Hash, forkFilter forkid.Filter, version uint32, remoteNetwork uint64, remoteGenesis common.Hash, remoteForkID forkid.ID) error {
+	if remoteNetwork != network {
+		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, remoteNetwork, network)
 	}
-	if uint(status.ProtocolVersion) != p.version {
-		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, status.ProtocolVersion, p.version)
+	if uint(version) != p.version {
+		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, version, p.version)
 	}
-	if status.Genesis != genesis {
-		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, status.Genesis, genesis)
+	if remoteGenesis != genesis {
+		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, remoteGenesis, genesis)
 	}
-	if err := forkFilter(status.ForkID); err != nil {
+	if err := forkFilter(remoteForkID); err != nil {
 		return fmt.Errorf("%w: %v", errForkIDRejected, err)
 	}
 	return nil
@@ -78,6 +78,8 @@ type Peer struct {
 	forkid          forkid.ID   // Advertised forkid at time of handshake
 	blockDifficulty *big.Int    // Latest advertised head block difficulty
 
+	blockRange *BlockRangeUpdatePacket // Latest advertised range of served blocks (eth/69)
+
 	knownBlocks     *knownCache            // Set of block hashes known to be known by this peer
 	queuedBlocks    chan *blockPropagation // Queue of blocks to broadcast to the peer
 	queuedBlockAnns chan *types.Block      // Queue of blocks to announce to the peer
@@ -161,6 +163,38 @@ func (p *Peer) SetHead(hash common.Hash, td, blockDifficulty *big.Int) {
 	p.blockDifficulty.Set(blockDifficulty)
 }
 
+// BlockRange retrieves the latest range of blocks the peer advertised to serve
+// bodies and receipts for. It's nil for peers on eth/68 and older.
+func (p *Peer) BlockRange() *BlockRangeUpdatePacket {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	if p.blockRange == nil {
+		return nil
+	}
+	cpy := *p.blockRange
+	return &cpy
+}
+
+// setBlockRange updates the range of blocks the peer serves.
+func (p *Peer) setBlockRange(packet *BlockRangeUpdatePacket) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	cpy := *packet
+	p.blockRange = &cpy
+}
+
+// ServesBlock reports whether the peer serves the body and receipts of a block.
+// Peers on eth/68 and older don't advertise the range and are assumed to serve
+// all blocks.
+func (p *Peer) ServesBlock(number uint64) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return p.blockRange == nil || number >= p.blockRange.EarliestBlock
+}
+
 // ForkID retrieves the reported forkid at the time of handshake.
 func (p *Peer) ForkID() forkid.ID {
 	return p.forkid
@@ -332,6 +366,11 @@ func (p *Peer) ReplyReceiptsRLP(id uint64, receipts []rlp.RawValue) error {
 	})
 }
 
+// SendBlockRangeUpdate announces the range of blocks we serve to the peer.
+func (p *Peer) SendBlockRangeUpdate(packet BlockRangeUpdatePacket) error {
+	return p2p.Send(p.rw, BlockRangeUpdateMsg, &packet)
+}
+
 // RequestOneHeader is a wrapper around the header query functions to fetch a
 // single header. It is used solely by the fetcher.
 func (p *Peer) RequestOneHeader(hash common.Hash, sink chan *Response) (*Request, error) {
@@ -86,6 +86,7 @@ func TestEmptyMessages(t *testing.T) {
 		// Receipts
 		GetReceiptsPacket{1111, nil},
 		ReceiptsPacket{1111, nil},
+		ReceiptsPacket69{1111, nil},
 		// Transactions
 		GetPooledTransactionsPacket{1111, nil},
 		PooledTransactionsPacket{1111, nil},
@@ -100,6 +101,7 @@ func TestEmptyMessages(t *testing.T) {
 		// Receipts
 		GetReceiptsPacket{1111, GetReceiptsRequest([]common.Hash{})},
 		ReceiptsPacket{1111, ReceiptsResponse([][]*types.Receipt{})},
+		ReceiptsPacket69{1111, ReceiptsResponse69([][]*Receipt69{})},
 		// Transactions
 		GetPooledTransactionsPacket{1111, GetPooledTransactionsRequest([]common.Hash{})},
 		PooledTransactionsPacket{1111, PooledTransactionsResponse([]*types.Transaction{})},
@@ -246,3 +248,43 @@ func TestMessages(t *testing.T) {
 		}
 	}
 }
+
+// Tests that eth/69 receipts leave out the bloom filters, and that the receipts
+// restored from them match the originals.
+func TestReceipts69(t *testing.T) {
+	logs := []*types.Log{{
+		Address: common.BytesToAddress([]byte{0x11}),
+		Topics:  []common.Hash{common.HexToHash("dead"), common.HexToHash("beef")},
+		Data:    []byte{0x01, 0x00, 0xff},
+	}}
+	receipts := []*types.Receipt{
+		{Type: types.LegacyTxType, Status: types.ReceiptStatusFailed, CumulativeGasUsed: 1, Logs: logs},
+		{Type: types.LegacyTxType, PostState: common.HexToHash("0x01").Bytes(), CumulativeGasUsed: 2},
+		{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: 3, Logs: logs},
+	}
+	for _, receipt := range receipts {
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	}
+	blob, err := rlp.EncodeToBytes(&ReceiptsPacket69{1111, NewReceiptsResponse69([][]*types.Receipt{receipts})})
+	if err != nil {
+		t.Fatalf("failed to encode receipts: %v", err)
+	}
+	var packet ReceiptsPacket69
+	if err := rlp.DecodeBytes(blob, &packet); err != nil {
+		t.Fatalf("failed to decode receipts: %v", err)
+	}
+	restored, err := packet.Receipts()
+	if err != nil {
+		t.Fatalf("failed to restore receipts: %v", err)
+	}
+	have, _ := rlp.EncodeToBytes(restored)
+	want, _ := rlp.EncodeToBytes(ReceiptsResponse{receipts})
+	if !bytes.Equal(have, want) {
+		t.Errorf("restored receipts mismatch: have\n\t%x\nwant\n\t%x", have, want)
+	}
+	// Receipts with a malformed status are rejected
+	packet.ReceiptsResponse69[0][0].PostStateOrStatus = []byte{0x02}
+	if _, err := packet.Receipts(); err == nil {
+		t.Errorf("invalid receipt status accepted")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	receiptStatusFailed     = []byte{}
+	receiptStatusSuccessful = []byte{0x01}
+)
+
+// Receipt69 is the eth/69 network encoding of a receipt. The bloom filter is
+// left out, as it can be recomputed from the logs.
+type Receipt69 struct {
+	Type              uint8
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Logs              []*types.Log
+}
+
+// newReceipt69 converts a receipt into its eth/69 network encoding.
+func newReceipt69(receipt *types.Receipt) *Receipt69 {
+	status := receipt.PostState
+	if len(status) == 0 {
+		status = receiptStatusSuccessful
+		if receipt.Status == types.ReceiptStatusFailed {
+			status = receiptStatusFailed
+		}
+	}
+	return &Receipt69{
+		Type:              receipt.Type,
+		PostStateOrStatus: status,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		Logs:              receipt.Logs,
+	}
+}
+
+// receipt converts the eth/69 network encoding back into a receipt, deriving
+// its bloom filter.
+func (r *Receipt69) receipt() (*types.Receipt, error) {
+	receipt := &types.Receipt{
+		Type:              r.Type,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Logs:              r.Logs,
+	}
+	switch {
+	case bytes.Equal(r.PostStateOrStatus, receiptStatusSuccessful):
+		receipt.Status = types.ReceiptStatusSuccessful
+	case bytes.Equal(r.PostStateOrStatus, receiptStatusFailed):
+		receipt.Status = types.ReceiptStatusFailed
+	case len(r.PostStateOrStatus) == len(common.Hash{}):
+		receipt.PostState = r.PostStateOrStatus
+	default:
+		return nil, fmt.Errorf("invalid receipt status %x", r.PostStateOrStatus)
+	}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+	return receipt, nil
+}
+
+// ReceiptsResponse69 is the network packet for block receipts distribution on
+// eth/69 and newer.
+type ReceiptsResponse69 [][]*Receipt69
+
+// ReceiptsPacket69 is the network packet for block receipts distribution with
+// request ID wrapping on eth/69 and newer.
+type ReceiptsPacket69 struct {
+	RequestId uint64
+	ReceiptsResponse69
+}
+
+// NewReceiptsResponse69 converts the receipts of a batch of blocks into their
+// eth/69 network encoding.
+func NewReceiptsResponse69(receipts [][]*types.Receipt) ReceiptsResponse69 {
+	response := make(ReceiptsResponse69, len(receipts))
+	for i, block := range receipts {
+		response[i] = make([]*Receipt69, len(block))
+		for j, receipt := range block {
+			response[i][j] = newReceipt69(receipt)
+		}
+	}
+	return response
+}
+
+// Receipts converts the eth/69 network encoding back into receipts, with their
+// bloom filters derived from the logs.
+func (p *ReceiptsResponse69) Receipts() (ReceiptsResponse, error) {
+	receipts := make(ReceiptsResponse, len(*p))
+	for i, block := range *p {
+		receipts[i] = make([]*types.Receipt, len(block))
+		for j, r := range block {
+			receipt, err := r.receipt()
+			if err != nil {
+				return nil, err
+			}
+			receipts[i][j] = receipt
+		}
+	}
+	return receipts, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package lhp
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+	"github.com/ethereum/go-ethereum/triedb"
+)
+
+const (
+	// CHTSectionSize is the number of blocks in a CHT section. The CHT of a
+	// section covers all the blocks up to its end.
+	CHTSectionSize = 32768
+
+	// CHTConfirmations is the number of confirmations before a section is
+	// added to the CHT.
+	CHTConfirmations = 2048
+
+	// chtThrottling is the time to wait between processing two consecutive
+	// sections.
+	chtThrottling = 100 * time.Millisecond
+)
+
+var (
+	chtTablePrefix = "lhp-cht-" // Prefix of the CHT trie nodes and indexer metadata
+	chtRootPrefix  = []byte("root")
+
+	errMissingTD = errors.New("missing total difficulty")
+)
+
+// CHT maintains the canonical hash tries of the local chain, mapping the number
+// of each block to its hash and total difficulty.
+type CHT struct {
+	db      ethdb.Database
+	triedb  *triedb.Database
+	indexer *core.ChainIndexer
+}
+
+// NewCHT creates the canonical hash tries of a chain database, with sections of
+// the given size.
+func NewCHT(db ethdb.Database, size, confirms uint64) *CHT {
+	table := rawdb.NewTable(db, chtTablePrefix)
+	c := &CHT{
+		db:     table,
+		triedb: triedb.NewDatabase(table, triedb.HashDefaults),
+	}
+	backend := &chtIndexer{cht: c, chainDb: db}
+	c.indexer = core.NewChainIndexer(db, table, backend, size, confirms, chtThrottling, "cht")
+	return c
+}
+
+// Start starts indexing the chain.
+func (c *CHT) Start(chain core.ChainIndexerChain) {
+	c.indexer.Start(chain)
+}
+
+// Close stops indexing the chain.
+func (c *CHT) Close() error {
+	return c.indexer.Close()
+}
+
+// Sections returns the number of sections in the CHT.
+func (c *CHT) Sections() uint64 {
+	sections, _, _ := c.indexer.Sections()
+	return sections
+}
+
+// Root returns the root of the CHT up to the end of a section.
+func (c *CHT) Root(section uint64) common.Hash {
+	blob, _ := c.db.Get(chtRootKey(section))
+	return common.BytesToHash(blob)
+}
+
+// Prove writes the proof of the CHT entry of a block, against the root of a
+// section, into proofDb.
+func (c *CHT) Prove(section, number uint64, proofDb ethdb.KeyValueWriter) error {
+	if section >= c.Sections() {
+		return fmt.Errorf("section %d not indexed", section)
+	}
+	tr, err := trie.New(trie.TrieID(c.Root(section)), c.triedb)
+	if err != nil {
+		return err
+	}
+	return tr.Prove(chtKey(number), proofDb)
+}
+
+// VerifyCHTProof verifies the proof of the CHT entry of a block against the
+// root of a section, returning the hash and total difficulty of the block.
+func VerifyCHTProof(root common.Hash, number uint64, proofDb ethdb.KeyValueReader) (*CHTNode, error) {
+	blob, err := trie.VerifyProof(root, chtKey(number), proofDb)
+	if err != nil {
+		return nil, err
+	}
+	if len(blob) == 0 {
+		return nil, fmt.Errorf("block %d not in CHT", number)
+	}
+	node := new(CHTNode)
+	if err := rlp.DecodeBytes(blob, node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func chtKey(number uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, number)
+}
+
+func chtRootKey(section uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte{}, chtRootPrefix...), section)
+}
+
+// chtIndexer implements core.ChainIndexerBackend, adding the blocks of each
+// section to the trie of the previous one.
+type chtIndexer struct {
+	cht     *CHT
+	chainDb ethdb.Database
+
+	section uint64
+	root    common.Hash
+	trie    *trie.Trie
+}
+
+// Reset implements core.ChainIndexerBackend, opening the trie of the previous
+// section.
+func (c *chtIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	root := types.EmptyRootHash
+	if section > 0 {
+		root = c.cht.Root(section - 1)
+	}
+	tr, err := trie.New(trie.TrieID(root), c.cht.triedb)
+	if err != nil {
+		return err
+	}
+	c.section, c.root, c.trie = section, root, tr
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding a block to the trie.
+func (c *chtIndexer) Process(ctx context.Context, header *types.Header) error {
+	hash, number := header.Hash(), header.Number.Uint64()
+	td := rawdb.ReadTd(c.chainDb, hash, number)
+	if td == nil {
+		return fmt.Errorf("%w: block %d", errMissingTD, number)
+	}
+	return c.trie.Update(chtKey(number), encodeCHTNode(hash, td))
+}
+
+// Commit implements core.ChainIndexerBackend, writing the trie of the section
+// into the database.
+func (c *chtIndexer) Commit() error {
+	root, nodes, err := c.trie.Commit(false)
+	if err != nil {
+		return err
+	}
+	if nodes != nil {
+		if err := c.cht.triedb.Update(root, c.root, 0, trienode.NewWithNodeSet(nodes), nil); err != nil {
+			return err
+		}
+		if err := c.cht.triedb.Commit(root, false); err != nil {
+			return err
+		}
+	}
+	return c.cht.db.Put(chtRootKey(c.section), root.Bytes())
+}
+
+// Prune implements core.ChainIndexerBackend, the CHT is never pruned.
+func (c *chtIndexer) Prune(threshold uint64) error {
+	return nil
+}
+
+// totalDifficulty returns a copy of the total difficulty of a block.
+func totalDifficulty(chain *core.BlockChain, hash common.Hash, number uint64) *big.Int {
+	if td := chain.GetTd(hash, number); td != nil {
+		return new(big.Int).Set(td)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package lhp
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+)
+
+// Handler is a callback to invoke from an outside runner after the boilerplate
+// exchanges have passed.
+type Handler func(peer *Peer) error
+
+// Backend defines the data retrieval methods to serve remote requests and the
+// callback methods to invoke on remote deliveries.
+type Backend interface {
+	// Chain retrieves the blockchain object to serve data.
+	Chain() *core.BlockChain
+
+	// CHT retrieves the canonical hash tries to prove headers against.
+	CHT() *CHT
+
+	// RunPeer is invoked when a peer joins on the `lhp` protocol. The handler
+	// should do any peer maintenance work, handshakes and validations. If all
+	// is passed, control should be given back to the `handler` to process the
+	// inbound messages going forward.
+	RunPeer(peer *Peer, handler Handler) error
+}
+
+// MakeProtocols constructs the P2P protocol definitions for serving `lhp`.
+func MakeProtocols(backend Backend) []p2p.Protocol {
+	protocols := make([]p2p.Protocol, len(ProtocolVersions))
+	for i, version := range ProtocolVersions {
+		version := version // Closure
+
+		protocols[i] = p2p.Protocol{
+			Name:    ProtocolName,
+			Version: version,
+			Length:  protocolLengths[version],
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				return backend.RunPeer(NewPeer(version, p, rw), func(peer *Peer) error {
+					return Handle(backend, peer)
+				})
+			},
+		}
+	}
+	return protocols
+}
+
+// Handle is the callback invoked to manage the life cycle of a `lhp` peer
+// served by the local node. When this function terminates, the peer is
+// disconnected.
+func Handle(backend Backend, peer *Peer) error {
+	for {
+		if err := HandleMessage(backend, peer); err != nil {
+			peer.Log().Debug("Message handling failed in `lhp`", "err", err)
+			return err
+		}
+	}
+}
+
+// HandleMessage is invoked whenever an inbound request is received from a
+// remote peer on the `lhp` protocol. The remote connection is torn down upon
+// returning any error.
+func HandleMessage(backend Backend, peer *Peer) error {
+	msg, err := peer.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	defer msg.Discard()
+
+	switch msg.Code {
+	case GetHeadersMsg:
+		var req GetHeadersPacket
+		if err := decode(msg, &req); err != nil {
+			return err
+		}
+		return p2p.Send(peer.rw, HeadersMsg, ServiceGetHeadersQuery(backend.Chain(), &req))
+
+	case GetCHTProofsMsg:
+		var req GetCHTProofsPacket
+		if err := decode(msg, &req); err != nil {
+			return err
+		}
+		return p2p.Send(peer.rw, CHTProofsMsg, ServiceGetCHTProofsQuery(backend.Chain(), backend.CHT(), &req))
+
+	case GetProofsMsg:
+		var req GetProofsPacket
+		if err := decode(msg, &req); err != nil {
+			return err
+		}
+		return p2p.Send(peer.rw, ProofsMsg, ServiceGetProofsQuery(backend.Chain(), &req))
+
+	default:
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+}
+
+// ServiceGetHeadersQuery assembles the response to a header query. It is
+// exposed to allow external packages to test protocol behavior.
+func ServiceGetHeadersQuery(chain *core.BlockChain, req *GetHeadersPacket) *HeadersPacket {
+	res := &HeadersPacket{RequestId: req.RequestId, Headers: []*types.Header{}}
+	for i := uint64(0); i < min(req.Amount, maxHeadersServe); i++ {
+		header := chain.GetHeaderByNumber(req.Origin + i)
+		if header == nil {
+			break
+		}
+		res.Headers = append(res.Headers, header)
+	}
+	if len(res.Headers) > 0 {
+		res.TD = totalDifficulty(chain, res.Headers[0].Hash(), req.Origin)
+	}
+	if res.TD == nil {
+		res.Headers, res.TD = []*types.Header{}, nil
+	}
+	return res
+}
+
+// ServiceGetCHTProofsQuery assembles the response to a CHT proof query. It is
+// exposed to allow external packages to test protocol behavior.
+func ServiceGetCHTProofsQuery(chain *core.BlockChain, cht *CHT, req *GetCHTProofsPacket) *CHTProofsPacket {
+	res := &CHTProofsPacket{RequestId: req.RequestId, Headers: []*types.Header{}, Proof: [][]byte{}}
+	if cht == nil || req.Section >= cht.Sections() {
+		return res
+	}
+	proof := trienode.NewProofSet()
+	for i, number := range req.Numbers {
+		if i >= maxCHTProofsServe {
+			break
+		}
+		header := chain.GetHeaderByNumber(number)
+		if header == nil {
+			break
+		}
+		if err := cht.Prove(req.Section, number, proof); err != nil {
+			break
+		}
+		res.Headers = append(res.Headers, header)
+	}
+	for _, node := range proof.List() {
+		res.Proof = append(res.Proof, node)
+	}
+	return res
+}
+
+// ServiceGetProofsQuery assembles the response to an account and storage
+// proof query. It is exposed to allow external packages to test protocol
+// behavior.
+func ServiceGetProofsQuery(chain *core.BlockChain, req *GetProofsPacket) *ProofsPacket {
+	res := &ProofsPacket{RequestId: req.RequestId, AccountProof: [][]byte{}, StorageProofs: [][][]byte{}}
+
+	header := chain.GetHeaderByHash(req.BlockHash)
+	if header == nil {
+		return res
+	}
+	tr, err := trie.NewStateTrie(trie.StateTrieID(header.Root), chain.TrieDB())
+	if err != nil {
+		return res
+	}
+	var accountProof trienode.ProofList
+	if err := tr.Prove(crypto.Keccak256(req.Address.Bytes()), &accountProof); err != nil {
+		return res
+	}
+	account, err := tr.GetAccount(req.Address)
+	if err != nil {
+		return res
+	}
+	// The storage of missing accounts and accounts without storage is proven
+	// empty by the account proof.
+	var storage *trie.StateTrie
+	if account != nil && account.Root != types.EmptyRootHash {
+		id := trie.StorageTrieID(header.Root, crypto.Keccak256Hash(req.Address.Bytes()), account.Root)
+		if storage, err = trie.NewStateTrie(id, chain.TrieDB()); err != nil {
+			return res
+		}
+	}
+	storageProofs := make([][][]byte, 0, len(req.StorageKeys))
+	for i, key := range req.StorageKeys {
+		if i >= maxStorageProofsServe {
+			break
+		}
+		var proof trienode.ProofList
+		if storage != nil {
+			if err := storage.Prove(crypto.Keccak256(key.Bytes()), &proof); err != nil {
+				return res
+			}
+		}
+		storageProofs = append(storageProofs, proofBytes(proof))
+	}
+	res.AccountProof, res.StorageProofs = proofBytes(accountProof), storageProofs
+	return res
+}
+
+func proofBytes(proof trienode.ProofList) [][]byte {
+	nodes := make([][]byte, len(proof))
+	for i, node := range proof {
+		nodes[i] = node
+	}
+	return nodes
+}
+
+// HandleResponses is the callback invoked to manage the life cycle of a `lhp`
+// peer serving the local node, delivering its responses to the requests in
+// flight. When this function terminates, the peer is disconnected and the
+// requests fail.
+func HandleResponses(peer *Peer) error {
+	defer peer.close()
+
+	for {
+		if err := handleResponse(peer); err != nil {
+			peer.Log().Debug("Response handling failed in `lhp`", "err", err)
+			return err
+		}
+	}
+}
+
+func handleResponse(peer *Peer) error {
+	msg, err := peer.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	defer msg.Discard()
+
+	switch msg.Code {
+	case HeadersMsg:
+		res := new(HeadersPacket)
+		if err := decode(msg, res); err != nil {
+			return err
+		}
+		return peer.deliver(res.RequestId, res)
+
+	case CHTProofsMsg:
+		res := new(CHTProofsPacket)
+		if err := decode(msg, res); err != nil {
+			return err
+		}
+		return peer.deliver(res.RequestId, res)
+
+	case ProofsMsg:
+		res := new(ProofsPacket)
+		if err := decode(msg, res); err != nil {
+			return err
+		}
+		return peer.deliver(res.RequestId, res)
+
+	default:
+		return fmt.Errorf("%w: %v", errInvalidMsgCode, msg.Code)
+	}
+}
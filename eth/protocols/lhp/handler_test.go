@@ -0,0 +1,361 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package lhp
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+)
+
+var (
+	testAddr     = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+	testBalance  = big.NewInt(1_000_000_000_000_000_000)
+	testContract = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	testSlot     = common.HexToHash("0x01")
+	testValue    = common.HexToHash("0x2a")
+)
+
+// testBackend is a mock implementation of the live Ethereum message handler.
+type testBackend struct {
+	chain *core.BlockChain
+	cht   *CHT
+}
+
+// newTestBackend creates a chain with a number of blocks, indexing CHT sections
+// of the given size.
+func newTestBackend(t *testing.T, blocks int, sectionSize uint64) *testBackend {
+	t.Helper()
+
+	var (
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: genesisT.GenesisAlloc{
+				testAddr:     {Balance: testBalance},
+				testContract: {Balance: new(big.Int), Code: []byte{0x00}, Storage: map[common.Hash]common.Hash{testSlot: testValue}},
+			},
+		}
+		engine = ethash.NewFaker()
+	)
+	chain, err := core.NewBlockChain(db, nil, gspec, nil, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	_, bs, _ := core.GenerateChainWithGenesis(gspec, engine, blocks, nil)
+	if _, err := chain.InsertChain(bs); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	cht := NewCHT(db, sectionSize, 1)
+	cht.Start(chain)
+
+	b := &testBackend{chain: chain, cht: cht}
+	t.Cleanup(b.close)
+
+	sections := (uint64(blocks) + 1) / sectionSize
+	for deadline := time.Now().Add(5 * time.Second); cht.Sections() < sections; {
+		if time.Now().After(deadline) {
+			t.Fatalf("CHT sections not indexed: have %d, want %d", cht.Sections(), sections)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return b
+}
+
+func (b *testBackend) close() {
+	b.cht.Close()
+	b.chain.Stop()
+}
+
+func (b *testBackend) Chain() *core.BlockChain { return b.chain }
+func (b *testBackend) CHT() *CHT               { return b.cht }
+
+func (b *testBackend) RunPeer(peer *Peer, handler Handler) error {
+	panic("not implemented")
+}
+
+func TestServiceGetHeaders(t *testing.T) {
+	backend := newTestBackend(t, 40, 16)
+	chain := backend.chain
+
+	tests := []struct {
+		origin, amount uint64
+		expect         int
+	}{
+		{0, 1, 1},
+		{10, 5, 5},
+		{38, 5, 3},   // truncated at the head
+		{41, 5, 0},   // beyond the head
+		{0, 500, 41}, // capped by the chain before the serving limit
+	}
+	for i, tt := range tests {
+		res := ServiceGetHeadersQuery(chain, &GetHeadersPacket{RequestId: uint64(i), Origin: tt.origin, Amount: tt.amount})
+		if res.RequestId != uint64(i) {
+			t.Errorf("test %d: request id mismatch: have %d, want %d", i, res.RequestId, i)
+		}
+		if len(res.Headers) != tt.expect {
+			t.Errorf("test %d: header count mismatch: have %d, want %d", i, len(res.Headers), tt.expect)
+			continue
+		}
+		for j, header := range res.Headers {
+			if want := chain.GetHeaderByNumber(tt.origin + uint64(j)).Hash(); header.Hash() != want {
+				t.Errorf("test %d: header %d mismatch: have %x, want %x", i, j, header.Hash(), want)
+			}
+		}
+		if tt.expect > 0 {
+			if want := chain.GetTd(res.Headers[0].Hash(), tt.origin); res.TD.Cmp(want) != 0 {
+				t.Errorf("test %d: total difficulty mismatch: have %v, want %v", i, res.TD, want)
+			}
+		} else if res.TD != nil {
+			t.Errorf("test %d: unexpected total difficulty %v", i, res.TD)
+		}
+	}
+}
+
+func TestServiceGetCHTProofs(t *testing.T) {
+	backend := newTestBackend(t, 40, 16)
+	chain, cht := backend.chain, backend.cht
+
+	res := ServiceGetCHTProofsQuery(chain, cht, &GetCHTProofsPacket{RequestId: 1, Section: 1, Numbers: []uint64{3, 17, 31}})
+	if len(res.Headers) != 3 {
+		t.Fatalf("header count mismatch: have %d, want 3", len(res.Headers))
+	}
+	proof := proofSet(res.Proof)
+	for _, header := range res.Headers {
+		number := header.Number.Uint64()
+		node, err := VerifyCHTProof(cht.Root(1), number, proof)
+		if err != nil {
+			t.Fatalf("block %d: failed to verify proof: %v", number, err)
+		}
+		if node.Hash != header.Hash() {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", number, node.Hash, header.Hash())
+		}
+		if want := chain.GetTd(header.Hash(), number); node.TD.Cmp(want) != 0 {
+			t.Errorf("block %d: total difficulty mismatch: have %v, want %v", number, node.TD, want)
+		}
+	}
+	// Sections only cover the blocks up to their end
+	absence := trienode.NewProofSet()
+	if err := cht.Prove(0, 20, absence); err != nil {
+		t.Fatalf("failed to prove absence: %v", err)
+	}
+	if _, err := VerifyCHTProof(cht.Root(0), 20, absence); err == nil {
+		t.Errorf("block beyond section proven present")
+	}
+	// Sections not indexed yet are not served
+	res = ServiceGetCHTProofsQuery(chain, cht, &GetCHTProofsPacket{RequestId: 2, Section: 2, Numbers: []uint64{3}})
+	if len(res.Headers) != 0 || len(res.Proof) != 0 {
+		t.Errorf("unindexed section served: %d headers, %d nodes", len(res.Headers), len(res.Proof))
+	}
+}
+
+func TestServiceGetProofs(t *testing.T) {
+	backend := newTestBackend(t, 10, 16)
+	chain := backend.chain
+	head := chain.CurrentHeader()
+
+	// An account without storage, an account with storage and a missing one
+	tests := []struct {
+		address common.Address
+		balance *big.Int
+		value   common.Hash
+	}{
+		{testAddr, testBalance, common.Hash{}},
+		{testContract, new(big.Int), testValue},
+		{common.HexToAddress("0xdead"), nil, common.Hash{}},
+	}
+	for i, tt := range tests {
+		res := ServiceGetProofsQuery(chain, &GetProofsPacket{RequestId: 1, BlockHash: head.Hash(), Address: tt.address, StorageKeys: []common.Hash{testSlot}})
+		if len(res.StorageProofs) != 1 {
+			t.Fatalf("test %d: storage proof count mismatch: have %d, want 1", i, len(res.StorageProofs))
+		}
+		blob, err := trie.VerifyProof(head.Root, crypto.Keccak256(tt.address.Bytes()), proofSet(res.AccountProof))
+		if err != nil {
+			t.Fatalf("test %d: failed to verify account proof: %v", i, err)
+		}
+		if tt.balance == nil {
+			if len(blob) != 0 {
+				t.Errorf("test %d: missing account proven present", i)
+			}
+			continue
+		}
+		var account types.StateAccount
+		if err := rlp.DecodeBytes(blob, &account); err != nil {
+			t.Fatalf("test %d: failed to decode account: %v", i, err)
+		}
+		if account.Balance.ToBig().Cmp(tt.balance) != 0 {
+			t.Errorf("test %d: balance mismatch: have %v, want %v", i, account.Balance, tt.balance)
+		}
+		if account.Root == types.EmptyRootHash {
+			continue
+		}
+		blob, err = trie.VerifyProof(account.Root, crypto.Keccak256(testSlot.Bytes()), proofSet(res.StorageProofs[0]))
+		if err != nil {
+			t.Fatalf("test %d: failed to verify storage proof: %v", i, err)
+		}
+		_, content, _, _ := rlp.Split(blob)
+		if value := common.BytesToHash(content); value != tt.value {
+			t.Errorf("test %d: storage value mismatch: have %x, want %x", i, value, tt.value)
+		}
+	}
+	// Unknown blocks are not served
+	res := ServiceGetProofsQuery(chain, &GetProofsPacket{RequestId: 1, BlockHash: common.Hash{1}, Address: testAddr})
+	if len(res.AccountProof) != 0 {
+		t.Errorf("unknown block served")
+	}
+}
+
+// Tests that requests are answered over a connection after the handshake.
+func TestRequests(t *testing.T) {
+	backend := newTestBackend(t, 40, 16)
+	chain := backend.chain
+
+	server, client := newTestPeers(t, backend)
+	if _, number := client.Head(); number != 0 {
+		t.Errorf("client advertised head %d", number)
+	}
+	if hash, number := server.Head(); hash != chain.CurrentHeader().Hash() || number != 40 {
+		t.Errorf("server head mismatch: have %d %x", number, hash)
+	}
+	if sections := server.CHTSections(); sections != 2 {
+		t.Errorf("server sections mismatch: have %d, want 2", sections)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	headers, err := server.RequestHeaders(ctx, 5, 10)
+	if err != nil {
+		t.Fatalf("failed to request headers: %v", err)
+	}
+	if len(headers.Headers) != 10 || headers.Headers[0].Hash() != chain.GetHeaderByNumber(5).Hash() {
+		t.Errorf("served headers mismatch")
+	}
+	chtProofs, err := server.RequestCHTProofs(ctx, 0, []uint64{7})
+	if err != nil {
+		t.Fatalf("failed to request CHT proofs: %v", err)
+	}
+	if _, err := VerifyCHTProof(backend.cht.Root(0), 7, proofSet(chtProofs.Proof)); err != nil {
+		t.Errorf("failed to verify served CHT proof: %v", err)
+	}
+	proofs, err := server.RequestProofs(ctx, chain.CurrentHeader().Hash(), testAddr, nil)
+	if err != nil {
+		t.Fatalf("failed to request proofs: %v", err)
+	}
+	if _, err := trie.VerifyProof(chain.CurrentHeader().Root, crypto.Keccak256(testAddr.Bytes()), proofSet(proofs.AccountProof)); err != nil {
+		t.Errorf("failed to verify served account proof: %v", err)
+	}
+}
+
+// Tests that the handshake rejects peers on other chains.
+func TestHandshakeGenesisMismatch(t *testing.T) {
+	backend := newTestBackend(t, 1, 16)
+	status := newTestStatus(backend.chain)
+
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+	defer net.Close()
+
+	local := NewPeer(LHP1, p2p.NewPeer(enode.ID{1}, "local", nil), app)
+	remote := NewPeer(LHP1, p2p.NewPeer(enode.ID{2}, "remote", nil), net)
+
+	errc := make(chan error, 1)
+	go func() {
+		other := status
+		other.Genesis = common.Hash{1}
+		errc <- remote.Handshake(other, forkid.NewFilter(backend.chain))
+	}()
+	if err := local.Handshake(status, forkid.NewFilter(backend.chain)); !errors.Is(err, errGenesisMismatch) {
+		t.Errorf("handshake error mismatch: have %v, want %v", err, errGenesisMismatch)
+	}
+	<-errc
+}
+
+// newTestStatus creates the status advertised by the server of a chain.
+func newTestStatus(chain *core.BlockChain) StatusPacket {
+	head := chain.CurrentHeader()
+	return StatusPacket{
+		NetworkID:  1,
+		Genesis:    chain.Genesis().Hash(),
+		ForkID:     forkid.NewID(chain.Config(), chain.Genesis(), head.Number.Uint64(), head.Time),
+		Head:       head.Hash(),
+		HeadNumber: head.Number.Uint64(),
+		TD:         chain.GetTd(head.Hash(), head.Number.Uint64()),
+	}
+}
+
+// newTestPeers connects a client to a server of the backend, returning the
+// server as seen by the client and the client as seen by the server.
+func newTestPeers(t *testing.T, backend *testBackend) (server *Peer, client *Peer) {
+	app, net := p2p.MsgPipe()
+	t.Cleanup(func() {
+		app.Close()
+		net.Close()
+	})
+	server = NewPeer(LHP1, p2p.NewPeer(enode.ID{1}, "server", nil), app)
+	client = NewPeer(LHP1, p2p.NewPeer(enode.ID{2}, "client", nil), net)
+
+	filter := forkid.NewFilter(backend.chain)
+	status := newTestStatus(backend.chain)
+	status.CHTSections = backend.cht.Sections()
+
+	errc := make(chan error, 1)
+	go func() {
+		if err := client.Handshake(status, filter); err != nil {
+			errc <- err
+			return
+		}
+		errc <- nil
+		Handle(backend, client)
+	}()
+	local := status
+	local.Head, local.HeadNumber, local.TD, local.CHTSections = common.Hash{}, 0, nil, 0
+	if err := server.Handshake(local, filter); err != nil {
+		t.Fatalf("client handshake failed: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("server handshake failed: %v", err)
+	}
+	go HandleResponses(server)
+	return server, client
+}
+
+// proofSet converts served proof nodes into a database to verify proofs with.
+func proofSet(nodes [][]byte) *trienode.ProofSet {
+	proof := make(trienode.ProofList, len(nodes))
+	for i, node := range nodes {
+		proof[i] = node
+	}
+	return proof.Set()
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package lhp
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+const (
+	// handshakeTimeout is the maximum allowed time for the `lhp` handshake to
+	// complete before dropping the connection.
+	handshakeTimeout = 5 * time.Second
+)
+
+// Handshake executes the lhp protocol handshake, exchanging the network IDs,
+// genesis blocks and fork IDs, along with the head and CHT sections served.
+func (p *Peer) Handshake(status StatusPacket, forkFilter forkid.Filter) error {
+	status.ProtocolVersion = uint32(p.version)
+	if status.TD == nil {
+		status.TD = new(big.Int)
+	}
+	errc := make(chan error, 2)
+
+	var remote StatusPacket // safe to read after two values have been received from errc
+	go func() {
+		errc <- p2p.Send(p.rw, StatusMsg, &status)
+	}()
+	go func() {
+		errc <- p.readStatus(&status, &remote, forkFilter)
+	}()
+	timeout := time.NewTimer(handshakeTimeout)
+	defer timeout.Stop()
+	for i := 0; i < 2; i++ {
+		select {
+		case err := <-errc:
+			if err != nil {
+				return err
+			}
+		case <-timeout.C:
+			return p2p.DiscReadTimeout
+		}
+	}
+	p.status = &remote
+	return nil
+}
+
+// readStatus reads the remote handshake message and makes sure it matches the
+// local chain.
+func (p *Peer) readStatus(local *StatusPacket, remote *StatusPacket, forkFilter forkid.Filter) error {
+	msg, err := p.rw.ReadMsg()
+	if err != nil {
+		return err
+	}
+	if msg.Code != StatusMsg {
+		return fmt.Errorf("%w: first msg has code %x (!= %x)", errNoStatusMsg, msg.Code, StatusMsg)
+	}
+	if msg.Size > maxMessageSize {
+		return fmt.Errorf("%w: %v > %v", errMsgTooLarge, msg.Size, maxMessageSize)
+	}
+	if err := msg.Decode(remote); err != nil {
+		return fmt.Errorf("%w: message %v: %v", errDecode, msg, err)
+	}
+	if remote.NetworkID != local.NetworkID {
+		return fmt.Errorf("%w: %d (!= %d)", errNetworkIDMismatch, remote.NetworkID, local.NetworkID)
+	}
+	if uint(remote.ProtocolVersion) != p.version {
+		return fmt.Errorf("%w: %d (!= %d)", errProtocolVersionMismatch, remote.ProtocolVersion, p.version)
+	}
+	if remote.Genesis != local.Genesis {
+		return fmt.Errorf("%w: %x (!= %x)", errGenesisMismatch, remote.Genesis, local.Genesis)
+	}
+	if err := forkFilter(remote.ForkID); err != nil {
+		return fmt.Errorf("%w: %v", errForkIDRejected, err)
+	}
+	if remote.TD == nil {
+		remote.TD = new(big.Int)
+	}
+	if tdlen := remote.TD.BitLen(); tdlen > 100 {
+		return fmt.Errorf("too large total difficulty: bitlen %d", tdlen)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package lhp
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+)
+
+var errPeerClosed = errors.New("peer closed")
+
+// Peer is a collection of relevant information we have about a `lhp` peer.
+type Peer struct {
+	id string // Unique ID for the peer, cached
+
+	*p2p.Peer                   // The embedded P2P package peer
+	rw        p2p.MsgReadWriter // Input/output streams for lhp
+	version   uint              // Protocol version negotiated
+	status    *StatusPacket     // Status advertised by the peer in the handshake
+
+	lock    sync.Mutex
+	pending map[uint64]chan Packet // Responses awaited by the requests in flight
+	closed  bool
+
+	logger log.Logger // Contextual logger with the peer id injected
+}
+
+// NewPeer creates a wrapper for a network connection and negotiated protocol
+// version.
+func NewPeer(version uint, p *p2p.Peer, rw p2p.MsgReadWriter) *Peer {
+	id := p.ID().String()
+	return &Peer{
+		id:      id,
+		Peer:    p,
+		rw:      rw,
+		version: version,
+		pending: make(map[uint64]chan Packet),
+		logger:  log.New("peer", id[:8]),
+	}
+}
+
+// ID retrieves the peer's unique identifier.
+func (p *Peer) ID() string {
+	return p.id
+}
+
+// Version retrieves the peer's negotiated `lhp` protocol version.
+func (p *Peer) Version() uint {
+	return p.version
+}
+
+// Log overrides the P2P logger with the higher level one containing only the id.
+func (p *Peer) Log() log.Logger {
+	return p.logger
+}
+
+// Head returns the head block advertised by the peer in the handshake.
+func (p *Peer) Head() (hash common.Hash, number uint64) {
+	return p.status.Head, p.status.HeadNumber
+}
+
+// CHTSections returns the number of CHT sections advertised by the peer in the
+// handshake.
+func (p *Peer) CHTSections() uint64 {
+	return p.status.CHTSections
+}
+
+// RequestHeaders fetches a batch of consecutive canonical headers, starting at
+// the given block number.
+func (p *Peer) RequestHeaders(ctx context.Context, origin, amount uint64) (*HeadersPacket, error) {
+	id := rand.Uint64()
+	res, err := p.request(ctx, id, &GetHeadersPacket{RequestId: id, Origin: origin, Amount: amount})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*HeadersPacket), nil
+}
+
+// RequestCHTProofs fetches the canonical headers of the given block numbers
+// along with their proofs against the CHT of a section.
+func (p *Peer) RequestCHTProofs(ctx context.Context, section uint64, numbers []uint64) (*CHTProofsPacket, error) {
+	id := rand.Uint64()
+	res, err := p.request(ctx, id, &GetCHTProofsPacket{RequestId: id, Section: section, Numbers: numbers})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*CHTProofsPacket), nil
+}
+
+// RequestProofs fetches the proofs of an account and of some of its storage
+// slots at the state of a block.
+func (p *Peer) RequestProofs(ctx context.Context, block common.Hash, address common.Address, keys []common.Hash) (*ProofsPacket, error) {
+	id := rand.Uint64()
+	res, err := p.request(ctx, id, &GetProofsPacket{RequestId: id, BlockHash: block, Address: address, StorageKeys: keys})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*ProofsPacket), nil
+}
+
+// request sends a request and waits for the response with the same ID, which
+// is delivered by HandleResponses.
+func (p *Peer) request(ctx context.Context, id uint64, req Packet) (Packet, error) {
+	ch := make(chan Packet, 1)
+
+	p.lock.Lock()
+	if p.closed {
+		p.lock.Unlock()
+		return nil, errPeerClosed
+	}
+	p.pending[id] = ch
+	p.lock.Unlock()
+
+	defer func() {
+		p.lock.Lock()
+		delete(p.pending, id)
+		p.lock.Unlock()
+	}()
+	p.logger.Trace("Sending request", "type", req.Name(), "reqid", id)
+	if err := p2p.Send(p.rw, uint64(req.Kind()), req); err != nil {
+		return nil, err
+	}
+	select {
+	case res, ok := <-ch:
+		if !ok {
+			return nil, errPeerClosed
+		}
+		return res, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// deliver hands a response to the request waiting for it.
+func (p *Peer) deliver(id uint64, res Packet) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	ch, ok := p.pending[id]
+	if !ok {
+		return errUnrequestedResponse
+	}
+	delete(p.pending, id)
+	ch <- res
+	return nil
+}
+
+// close fails the requests in flight and any further one.
+func (p *Peer) close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.closed = true
+	for id, ch := range p.pending {
+		close(ch)
+		delete(p.pending, id)
+	}
+}
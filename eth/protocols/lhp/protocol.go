@@ -0,0 +1,199 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package lhp implements the light header and proof protocol. Full nodes serve
+// light clients the headers of proof-of-work chains along with their total
+// difficulty, proofs of canonical headers against canonical hash tries (CHT),
+// and proofs of accounts and storage slots against the state root of a block,
+// all of which the clients verify locally.
+package lhp
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	LHP1 = 1
+)
+
+// ProtocolName is the official short name of the `lhp` protocol used during
+// devp2p capability negotiation.
+const ProtocolName = "lhp"
+
+// ProtocolVersions are the supported versions of the `lhp` protocol (first
+// is primary).
+var ProtocolVersions = []uint{LHP1}
+
+// protocolLengths are the number of implemented message corresponding to
+// different protocol versions.
+var protocolLengths = map[uint]uint64{LHP1: 7}
+
+// maxMessageSize is the maximum cap on the size of a protocol message.
+const maxMessageSize = 10 * 1024 * 1024
+
+const (
+	// maxHeadersServe is the maximum number of headers to serve per request.
+	maxHeadersServe = 192
+
+	// maxCHTProofsServe is the maximum number of CHT proofs to serve per
+	// request.
+	maxCHTProofsServe = 64
+
+	// maxStorageProofsServe is the maximum number of storage slots to prove
+	// per request.
+	maxStorageProofsServe = 256
+)
+
+const (
+	StatusMsg       = 0x00
+	GetHeadersMsg   = 0x01
+	HeadersMsg      = 0x02
+	GetCHTProofsMsg = 0x03
+	CHTProofsMsg    = 0x04
+	GetProofsMsg    = 0x05
+	ProofsMsg       = 0x06
+)
+
+var (
+	errNoStatusMsg             = errors.New("no status message")
+	errMsgTooLarge             = errors.New("message too long")
+	errDecode                  = errors.New("invalid message")
+	errInvalidMsgCode          = errors.New("invalid message code")
+	errProtocolVersionMismatch = errors.New("protocol version mismatch")
+	errNetworkIDMismatch       = errors.New("network ID mismatch")
+	errGenesisMismatch         = errors.New("genesis mismatch")
+	errForkIDRejected          = errors.New("fork ID rejected")
+	errUnrequestedResponse     = errors.New("unrequested response")
+)
+
+// StatusPacket is the network packet for the status message. Servers advertise
+// their head and the number of CHT sections they can prove headers against,
+// clients leave them empty.
+type StatusPacket struct {
+	ProtocolVersion uint32
+	NetworkID       uint64
+	Genesis         common.Hash
+	ForkID          forkid.ID
+	Head            common.Hash
+	HeadNumber      uint64
+	TD              *big.Int
+	CHTSections     uint64
+}
+
+// GetHeadersPacket requests Amount consecutive canonical headers, starting at
+// block number Origin.
+type GetHeadersPacket struct {
+	RequestId uint64
+	Origin    uint64
+	Amount    uint64
+}
+
+// HeadersPacket is the response to GetHeadersPacket. TD is the total difficulty
+// of the first header, from which the client derives the others.
+type HeadersPacket struct {
+	RequestId uint64
+	Headers   []*types.Header
+	TD        *big.Int
+}
+
+// GetCHTProofsPacket requests the canonical headers of the given block numbers
+// along with their proofs against the CHT of a section.
+type GetCHTProofsPacket struct {
+	RequestId uint64
+	Section   uint64
+	Numbers   []uint64
+}
+
+// CHTProofsPacket is the response to GetCHTProofsPacket. Proof is the union of
+// the trie nodes proving all the headers.
+type CHTProofsPacket struct {
+	RequestId uint64
+	Headers   []*types.Header
+	Proof     [][]byte
+}
+
+// GetProofsPacket requests the proofs of an account and of some of its storage
+// slots at the state of a block, as eth_getProof.
+type GetProofsPacket struct {
+	RequestId   uint64
+	BlockHash   common.Hash
+	Address     common.Address
+	StorageKeys []common.Hash
+}
+
+// ProofsPacket is the response to GetProofsPacket, with the proof of each
+// storage slot in the order requested.
+type ProofsPacket struct {
+	RequestId     uint64
+	AccountProof  [][]byte
+	StorageProofs [][][]byte
+}
+
+// CHTNode is the value of the CHT entry of a block, keyed by its big endian
+// block number.
+type CHTNode struct {
+	Hash common.Hash
+	TD   *big.Int
+}
+
+// Packet represents a p2p message in the `lhp` protocol.
+type Packet interface {
+	Name() string // Name returns a string corresponding to the message type.
+	Kind() byte   // Kind returns the message type.
+}
+
+func (*StatusPacket) Name() string { return "Status" }
+func (*StatusPacket) Kind() byte   { return StatusMsg }
+
+func (*GetHeadersPacket) Name() string { return "GetHeaders" }
+func (*GetHeadersPacket) Kind() byte   { return GetHeadersMsg }
+
+func (*HeadersPacket) Name() string { return "Headers" }
+func (*HeadersPacket) Kind() byte   { return HeadersMsg }
+
+func (*GetCHTProofsPacket) Name() string { return "GetCHTProofs" }
+func (*GetCHTProofsPacket) Kind() byte   { return GetCHTProofsMsg }
+
+func (*CHTProofsPacket) Name() string { return "CHTProofs" }
+func (*CHTProofsPacket) Kind() byte   { return CHTProofsMsg }
+
+func (*GetProofsPacket) Name() string { return "GetProofs" }
+func (*GetProofsPacket) Kind() byte   { return GetProofsMsg }
+
+func (*ProofsPacket) Name() string { return "Proofs" }
+func (*ProofsPacket) Kind() byte   { return ProofsMsg }
+
+// decode decodes a message into a packet, wrapping the errors.
+func decode(msg interface{ Decode(interface{}) error }, packet Packet) error {
+	if err := msg.Decode(packet); err != nil {
+		return fmt.Errorf("%w: message %s: %v", errDecode, packet.Name(), err)
+	}
+	return nil
+}
+
+// encodeCHTNode encodes the CHT entry of a block.
+func encodeCHTNode(hash common.Hash, td *big.Int) []byte {
+	blob, _ := rlp.EncodeToBytes(&CHTNode{Hash: hash, TD: td})
+	return blob
+}
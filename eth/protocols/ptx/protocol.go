@@ -0,0 +1,63 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ptx implements the private transaction relay protocol. It carries
+// transactions submitted privately to trusted mining peers, which keep them out
+// of the public transaction gossip until they expire.
+package ptx
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	PTX1 = 1
+)
+
+// ProtocolName is the official short name of the `ptx` protocol used during
+// devp2p capability negotiation.
+const ProtocolName = "ptx"
+
+// ProtocolVersions are the supported versions of the `ptx` protocol (first
+// is primary).
+var ProtocolVersions = []uint{PTX1}
+
+// protocolLengths are the number of implemented message corresponding to
+// different protocol versions.
+var protocolLengths = map[uint]uint64{PTX1: 1}
+
+// maxMessageSize is the maximum cap on the size of a protocol message.
+const maxMessageSize = 10 * 1024 * 1024
+
+const (
+	TransactionsMsg = 0x00
+)
+
+var (
+	errMsgTooLarge    = errors.New("message too long")
+	errDecode         = errors.New("invalid message")
+	errInvalidMsgCode = errors.New("invalid message code")
+)
+
+// TransactionsPacket relays private transactions. They must not be broadcast
+// before the block number Expiry.
+type TransactionsPacket struct {
+	Expiry       uint64
+	Transactions []*types.Transaction
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -33,6 +34,10 @@ import (
 const (
 	forceSyncCycle      = 10 * time.Second // Time interval to force syncs, even if few peers are available
 	defaultMinSyncPeers = 5                // Amount of peers desired to start syncing
+
+	// blockRangeUpdateInterval is the number of blocks the head advances before
+	// the range of served blocks is announced again to eth/69 peers.
+	blockRangeUpdateInterval = 32
 )
 
 var (
@@ -76,12 +81,89 @@ func (h *handler) artificialFinalitySafetyLoop() {
 	}
 }
 
+// servedBlockRange returns the range of blocks whose bodies and receipts we
+// serve. The ones below the tail of the ancient store were pruned.
+func (h *handler) servedBlockRange() eth.BlockRangeUpdatePacket {
+	head := h.chain.CurrentBlock()
+	number := head.Number.Uint64()
+
+	var earliest uint64
+	if tail, err := h.database.Tail(); err == nil {
+		earliest = min(tail, number)
+	}
+	return eth.BlockRangeUpdatePacket{
+		EarliestBlock:   earliest,
+		LatestBlock:     number,
+		LatestBlockHash: head.Hash(),
+	}
+}
+
+// estimatePeerTD estimates the total difficulty of the head advertised by an
+// eth/69 peer, as the handshake doesn't carry it anymore. It's exact if we know
+// the block. Otherwise a peer ahead of us is assumed to extend our chain at our
+// current difficulty, so that we sync from it. The total difficulty of an
+// unknown block at or below our head can't be told from its height, so it is
+// left zero until the peer announces a block along with its total difficulty.
+func (h *handler) estimatePeerTD(hash common.Hash, number uint64) (td *big.Int, difficulty *big.Int) {
+	if header := h.chain.GetHeader(hash, number); header != nil {
+		if td := h.chain.GetTd(hash, number); td != nil {
+			return new(big.Int).Set(td), header.Difficulty
+		}
+	}
+	head := h.chain.CurrentHeader()
+	if number <= head.Number.Uint64() {
+		return new(big.Int), new(big.Int)
+	}
+	td = new(big.Int)
+	if local := h.chain.GetTd(head.Hash(), head.Number.Uint64()); local != nil {
+		td.Set(local)
+	}
+	ahead := new(big.Int).SetUint64(number - head.Number.Uint64())
+	td.Add(td, ahead.Mul(ahead, head.Difficulty))
+	return td, head.Difficulty
+}
+
+// blockRangeLoop announces the range of served blocks to eth/69 peers whenever
+// the head advanced enough, reorged below the last announcement, or history
+// was pruned.
+func (h *handler) blockRangeLoop() {
+	defer h.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 10)
+	sub := h.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	last := h.servedBlockRange()
+	for {
+		select {
+		case <-heads:
+			served := h.servedBlockRange()
+			if served.EarliestBlock == last.EarliestBlock && served.LatestBlock >= last.LatestBlock &&
+				served.LatestBlock < last.LatestBlock+blockRangeUpdateInterval {
+				continue
+			}
+			last = served
+			for _, peer := range h.peers.peersWithVersion(eth.ETH69) {
+				if err := peer.SendBlockRangeUpdate(served); err != nil {
+					peer.Log().Debug("Failed to announce served block range", "err", err)
+				}
+			}
+		case <-sub.Err():
+			return
+		case <-h.quitSync:
+			return
+		}
+	}
+}
+
 // syncTransactions starts sending all currently pending transactions to the given peer.
 func (h *handler) syncTransactions(p *eth.Peer) {
 	var hashes []common.Hash
 	for _, batch := range h.txpool.Pending(txpool.PendingFilter{OnlyPlainTxs: true}) {
 		for _, tx := range batch {
-			hashes = append(hashes, tx.Hash)
+			if !h.privateTxs.isPrivate(tx.Hash) {
+				hashes = append(hashes, tx.Hash)
+			}
 		}
 	}
 	if len(hashes) == 0 {
@@ -291,6 +373,9 @@ func (h *handler) doSync(op *chainSyncOp) error {
 	// Run the sync cycle, and disable snap sync if we're past the pivot block
 	err := h.downloader.LegacySync(op.peer.ID(), op.head, op.td, h.chain.Config().GetEthashTerminalTotalDifficulty(), op.mode)
 	if err != nil {
+		if offense, ok := downloader.PeerOffense(err); ok {
+			op.peer.Penalize(offense)
+		}
 		return err
 	}
 	h.enableSyncedFeatures()
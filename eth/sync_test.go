@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
@@ -400,3 +401,31 @@ func TestArtificialFinalitySafetyLoopTimeComparison(t *testing.T) {
 		t.Fatal("bad unit logic!")
 	}
 }
+
+// Tests that the total difficulty of an eth/69 peer is taken from the chain if
+// its head is known, extrapolated if it is ahead of us, and not guessed
+// otherwise.
+func TestEstimatePeerTD(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHandlerWithBlocks(10)
+	defer h.close()
+
+	head := h.chain.CurrentHeader()
+	localTD := h.chain.GetTd(head.Hash(), head.Number.Uint64())
+
+	known := h.chain.GetHeaderByNumber(5)
+	td, difficulty := h.handler.estimatePeerTD(known.Hash(), 5)
+	if want := h.chain.GetTd(known.Hash(), 5); td.Cmp(want) != 0 || difficulty.Cmp(known.Difficulty) != 0 {
+		t.Errorf("known head: have td %v difficulty %v, want td %v difficulty %v", td, difficulty, want, known.Difficulty)
+	}
+	td, _ = h.handler.estimatePeerTD(common.Hash{1}, head.Number.Uint64())
+	if td.Sign() != 0 {
+		t.Errorf("unknown head at our height: have td %v, want 0", td)
+	}
+	td, _ = h.handler.estimatePeerTD(common.Hash{1}, head.Number.Uint64()+2)
+	want := new(big.Int).Add(localTD, new(big.Int).Mul(big.NewInt(2), head.Difficulty))
+	if td.Cmp(want) != 0 {
+		t.Errorf("unknown head ahead of us: have td %v, want %v", td, want)
+	}
+}
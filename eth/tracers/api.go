@@ -225,7 +225,16 @@ func getTraceConfigFromTraceCallConfig(config *TraceCallConfig) *TraceConfig {
 
 // TraceChain returns the structured logs created during the execution of EVM
 // between two blocks (excluding start) and returns them as a JSON object.
-func (api *API) TraceChain(ctx context.Context, start, end rpc.BlockNumber, config *TraceConfig) (*rpc.Subscription, error) { // Fetch the block interval that we want to trace
+func (api *API) TraceChain(ctx context.Context, start, end rpc.BlockNumber, config *TraceConfig) (*rpc.Subscription, error) {
+	return api.traceChainSubscription(ctx, start, end, config, nil)
+}
+
+// traceChainSubscription traces the given block interval, streaming the results
+// to a new subscription. If filter is non-nil, it is applied to every block result
+// and only the non-nil results it returns are sent, apart from the end block which
+// is always reported.
+func (api *API) traceChainSubscription(ctx context.Context, start, end rpc.BlockNumber, config *TraceConfig, filter func(*blockTraceResult) *blockTraceResult) (*rpc.Subscription, error) {
+	// Fetch the block interval that we want to trace
 	from, err := api.blockByNumber(ctx, start)
 	if err != nil {
 		return nil, err
@@ -247,6 +256,17 @@ func (api *API) TraceChain(ctx context.Context, start, end rpc.BlockNumber, conf
 	resCh := api.traceChain(from, to, config, notifier.Closed())
 	go func() {
 		for result := range resCh {
+			if filter != nil {
+				filtered := filter(result)
+				if filtered == nil {
+					// Drop the result, unless it's the final one marking the end of the interval
+					if uint64(result.Block) != to.NumberU64() {
+						continue
+					}
+					filtered = &blockTraceResult{Block: result.Block, Hash: result.Hash, Traces: []*txTraceResult{}}
+				}
+				result = filtered
+			}
 			notifier.Notify(sub.ID, result)
 		}
 	}()
@@ -286,6 +306,11 @@ func (api *API) traceChain(start, end *types.Block, config *TraceConfig, closed
 					signer   = types.MakeSigner(api.backend.ChainConfig(), task.block.Number(), task.block.Time())
 					blockCtx = core.NewEVMBlockContext(task.block.Header(), api.chainContext(ctx), nil)
 				)
+				// Store the parent block hash in the history contract as per EIP-2935
+				if api.backend.ChainConfig().IsEnabled(api.backend.ChainConfig().GetEIP2935Transition, task.block.Number()) {
+					vmenv := vm.NewEVM(blockCtx, vm.TxContext{}, task.statedb, api.backend.ChainConfig(), vm.Config{})
+					core.ProcessParentBlockHash(task.block.ParentHash(), vmenv, task.statedb)
+				}
 				// Trace all the transactions contained within
 				for i, tx := range task.block.Transactions() {
 					msg, _ := core.TransactionToMessage(tx, signer, task.block.BaseFee())
@@ -544,6 +569,10 @@ func (api *API) IntermediateRoots(ctx context.Context, hash common.Hash, config
 		vmctx              = core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
 		deleteEmptyObjects = api.backend.ChainConfig().IsEnabled(api.backend.ChainConfig().GetEIP161dTransition, block.Number())
 	)
+	if chainConfig.IsEnabled(chainConfig.GetEIP2935Transition, block.Number()) {
+		vmenv := vm.NewEVM(vmctx, vm.TxContext{}, statedb, chainConfig, vm.Config{})
+		core.ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
 	for i, tx := range block.Transactions() {
 		if err := ctx.Err(); err != nil {
 			return nil, err
@@ -604,6 +633,12 @@ func (api *API) traceBlock(ctx context.Context, block *types.Block, config *Trac
 	}
 	defer release()
 
+	// Store the parent block hash in the history contract as per EIP-2935
+	if chainConfig := api.backend.ChainConfig(); chainConfig.IsEnabled(chainConfig.GetEIP2935Transition, block.Number()) {
+		blockCtx := core.NewEVMBlockContext(block.Header(), api.chainContext(ctx), nil)
+		vmenv := vm.NewEVM(blockCtx, vm.TxContext{}, statedb, chainConfig, vm.Config{})
+		core.ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
 	// JS tracers have high overhead. In this case run a parallel
 	// process that generates states in one thread and traces txes
 	// in separate worker threads.
@@ -775,6 +810,10 @@ func (api *API) standardTraceBlockToFile(ctx context.Context, block *types.Block
 		// Note: This copies the config, to not screw up the main config
 		chainConfig, canon = overrideConfig(chainConfig, config.Overrides)
 	}
+	if chainConfig.IsEnabled(chainConfig.GetEIP2935Transition, block.Number()) {
+		vmenv := vm.NewEVM(vmctx, vm.TxContext{}, statedb, chainConfig, vm.Config{})
+		core.ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
 	for i, tx := range block.Transactions() {
 		// Prepare the transaction for un-traced execution
 		var (
@@ -1067,6 +1106,12 @@ func (api *API) traceTx(ctx context.Context, message *core.Message, txctx *Conte
 
 // APIs return the collection of RPC services the tracer package offers.
 func APIs(backend Backend) []rpc.API {
+	return IndexedAPIs(backend, nil)
+}
+
+// IndexedAPIs returns the collection of RPC services the tracer package offers,
+// serving trace_block and trace_filter from the given trace index if non-nil.
+func IndexedAPIs(backend Backend, indexer *TraceIndexer) []rpc.API {
 	debugAPI := NewAPI(backend)
 
 	// Append all the local APIs and return
@@ -1077,7 +1122,7 @@ func APIs(backend Backend) []rpc.API {
 		},
 		{
 			Namespace: "trace",
-			Service:   NewTraceAPI(debugAPI),
+			Service:   &TraceAPI{debugAPI: debugAPI, indexer: indexer},
 		},
 	}
 }
@@ -20,23 +20,26 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params/mutations"
 	"github.com/ethereum/go-ethereum/rpc"
 )
 
 // TraceFilterArgs represents the arguments for a call.
 type TraceFilterArgs struct {
-	FromBlock   hexutil.Uint64  `json:"fromBlock,omitempty"`   // Trace from this starting block
-	ToBlock     hexutil.Uint64  `json:"toBlock,omitempty"`     // Trace utill this end block
-	FromAddress *common.Address `json:"fromAddress,omitempty"` // Sent from these addresses
-	ToAddress   *common.Address `json:"toAddress,omitempty"`   // Sent to these addresses
-	After       uint64          `json:"after,omitempty"`       // The offset trace number
-	Count       uint64          `json:"count,omitempty"`       // Integer number of traces to display in a batch
+	FromBlock   hexutil.Uint64   `json:"fromBlock,omitempty"`   // Trace from this starting block
+	ToBlock     hexutil.Uint64   `json:"toBlock,omitempty"`     // Trace utill this end block
+	FromAddress []common.Address `json:"fromAddress,omitempty"` // Sent from these addresses
+	ToAddress   []common.Address `json:"toAddress,omitempty"`   // Sent to these addresses
+	After       uint64           `json:"after,omitempty"`       // The offset trace number
+	Count       uint64           `json:"count,omitempty"`       // Integer number of traces to display in a batch
 }
 
 // ParityTrace A trace in the desired format (Parity/OpenEtherum) See: https://Parity.github.io/wiki/JSONRPC-trace-module
@@ -60,6 +63,79 @@ type TraceRewardAction struct {
 	RewardType string          `json:"rewardType,omitempty"`
 }
 
+// TraceReplayResult is the Parity formatted result of replaying a transaction
+// with the requested trace types.
+type TraceReplayResult struct {
+	Output          hexutil.Bytes   `json:"output"`
+	StateDiff       json.RawMessage `json:"stateDiff"`
+	Trace           json.RawMessage `json:"trace"`
+	VMTrace         json.RawMessage `json:"vmTrace"`
+	TransactionHash *common.Hash    `json:"transactionHash,omitempty"`
+}
+
+// replayTracers maps the Parity trace types to the tracers producing them.
+var replayTracers = map[string]string{
+	"trace":     "callTracerParity",
+	"stateDiff": "stateDiffTracer",
+	"vmTrace":   "vmTracerParity",
+}
+
+// replayTraceConfig returns a trace config running all the tracers needed for
+// the requested Parity trace types at once. The call tracer is always run, as
+// it provides the output of the transaction.
+func replayTraceConfig(traceTypes []string) (*TraceConfig, error) {
+	cfg := map[string]json.RawMessage{replayTracers["trace"]: json.RawMessage("{}")}
+	for _, typ := range traceTypes {
+		tracer, ok := replayTracers[typ]
+		if !ok {
+			return nil, fmt.Errorf("invalid trace type %q", typ)
+		}
+		cfg[tracer] = json.RawMessage("{}")
+	}
+	tracerConfig, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	tracer := "muxTracer"
+	return &TraceConfig{Tracer: &tracer, TracerConfig: tracerConfig}, nil
+}
+
+// newTraceReplayResult assembles the Parity formatted replay result of the
+// requested trace types from the output of the tracers set by replayTraceConfig.
+func newTraceReplayResult(res interface{}, traceTypes []string) (*TraceReplayResult, error) {
+	raw, ok := res.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected trace result type %T", res)
+	}
+	var results map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &results); err != nil {
+		return nil, err
+	}
+	var calls []struct {
+		Result *struct {
+			Output hexutil.Bytes `json:"output"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(results[replayTracers["trace"]], &calls); err != nil {
+		return nil, err
+	}
+	out := &TraceReplayResult{Output: hexutil.Bytes{}, Trace: json.RawMessage("[]")}
+	if len(calls) > 0 && calls[0].Result != nil && calls[0].Result.Output != nil {
+		out.Output = calls[0].Result.Output
+	}
+	for _, typ := range traceTypes {
+		switch typ {
+		case "trace":
+			out.Trace = results[replayTracers[typ]]
+		case "stateDiff":
+			out.StateDiff = results[replayTracers[typ]]
+		case "vmTrace":
+			out.VMTrace = results[replayTracers[typ]]
+		}
+	}
+	return out, nil
+}
+
 // setTraceConfigDefaultTracer sets the default tracer to "callTracerParity" if none set
 func setTraceConfigDefaultTracer(config *TraceConfig) *TraceConfig {
 	if config == nil {
@@ -92,6 +168,7 @@ func setTraceCallConfigDefaultTracer(config *TraceCallConfig) *TraceCallConfig {
 // the private debugging endpoint.
 type TraceAPI struct {
 	debugAPI *API
+	indexer  *TraceIndexer // Optional trace index serving trace_block and trace_filter
 }
 
 // NewTraceAPI creates a new API definition for the full node-related
@@ -194,7 +271,21 @@ func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber, config *
 	if err != nil {
 		return nil, err
 	}
+	if api.indexer != nil && isParityTraceConfig(config) {
+		if traces := api.indexer.BlockTraces(block.NumberU64(), block.Hash()); traces != nil {
+			results := make([]interface{}, len(traces))
+			for i, trace := range traces {
+				results[i] = trace
+			}
+			return results, nil
+		}
+	}
+	return api.blockTraces(ctx, block, config)
+}
 
+// blockTraces traces all the transactions of the block and appends the block
+// and uncle reward traces.
+func (api *TraceAPI) blockTraces(ctx context.Context, block *types.Block, config *TraceConfig) ([]interface{}, error) {
 	traceResults, err := api.debugAPI.traceBlock(ctx, block, config)
 	if err != nil {
 		return nil, err
@@ -246,6 +337,10 @@ func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash, config *
 // Filter configures a new tracer according to the provided configuration, and
 // executes all the transactions contained within. The return value will be one item
 // per transaction, dependent on the requested tracer.
+//
+// The address filters and the after/count pagination only apply to the default
+// callTracerParity tracer. If a trace index covering the interval is available,
+// the results are served from it instead of re-executing the blocks.
 func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs, config *TraceConfig) (*rpc.Subscription, error) {
 	config = setTraceConfigDefaultTracer(config)
 
@@ -253,7 +348,223 @@ func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs, config *T
 	start := rpc.BlockNumber(args.FromBlock)
 	end := rpc.BlockNumber(args.ToBlock)
 
-	return api.debugAPI.TraceChain(ctx, start, end, config)
+	if !isParityTraceConfig(config) {
+		return api.debugAPI.TraceChain(ctx, start, end, config)
+	}
+	if api.indexer != nil && uint64(args.FromBlock) < uint64(args.ToBlock) && api.indexer.Covers(uint64(args.FromBlock)+1, uint64(args.ToBlock)) {
+		return api.filterIndexed(ctx, args)
+	}
+	return api.debugAPI.traceChainSubscription(ctx, start, end, config, newTraceFilter(args))
+}
+
+// filterIndexed serves trace_filter from the trace index. Like the re-executing
+// version, the interval excludes the start block and the end block is always
+// reported.
+func (api *TraceAPI) filterIndexed(ctx context.Context, args TraceFilterArgs) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	var (
+		from    = uint64(args.FromBlock) + 1
+		to      = uint64(args.ToBlock)
+		numbers []uint64
+	)
+	switch {
+	case len(args.FromAddress) > 0:
+		numbers = api.indexer.AddressBlocks(args.FromAddress, from, to)
+	case len(args.ToAddress) > 0:
+		numbers = api.indexer.AddressBlocks(args.ToAddress, from, to)
+	default:
+		for n := from; n <= to; n++ {
+			numbers = append(numbers, n)
+		}
+	}
+	if len(numbers) == 0 || numbers[len(numbers)-1] != to {
+		numbers = append(numbers, to)
+	}
+	sub := notifier.CreateSubscription()
+	filter := newTraceFilter(args)
+
+	go func() {
+		ctx := context.Background()
+		for _, number := range numbers {
+			select {
+			case <-notifier.Closed():
+				return
+			default:
+			}
+			block, err := api.debugAPI.blockByNumber(ctx, rpc.BlockNumber(number))
+			if err != nil {
+				log.Warn("Failed to retrieve block for trace filter", "number", number, "err", err)
+				return
+			}
+			traces := api.indexer.BlockTraces(number, block.Hash())
+			if traces == nil {
+				// Entry missing or not yet updated after a reorg, trace it on the fly
+				res, err := api.blockTraces(ctx, block, setTraceConfigDefaultTracer(nil))
+				if err != nil {
+					log.Warn("Failed to trace block for trace filter", "number", number, "err", err)
+					return
+				}
+				if traces, err = encodeTraces(res); err != nil {
+					return
+				}
+			}
+			result := filter(groupTxTraces(block, traces))
+			if result == nil {
+				if number != to {
+					continue
+				}
+				result = &blockTraceResult{Block: hexutil.Uint64(number), Hash: block.Hash(), Traces: []*txTraceResult{}}
+			}
+			notifier.Notify(sub.ID, result)
+		}
+	}()
+	return sub, nil
+}
+
+// isParityTraceConfig reports whether the trace config requests the flat Parity
+// call traces, which is what the trace index stores.
+func isParityTraceConfig(config *TraceConfig) bool {
+	return config != nil && config.Tracer != nil && *config.Tracer == "callTracerParity"
+}
+
+// traceFilterEntry is the subset of a flat Parity trace needed for filtering.
+type traceFilterEntry struct {
+	Action struct {
+		From          *common.Address `json:"from"`
+		To            *common.Address `json:"to"`
+		Author        *common.Address `json:"author"`
+		Address       *common.Address `json:"address"`
+		RefundAddress *common.Address `json:"refundAddress"`
+	} `json:"action"`
+	Result *struct {
+		Address *common.Address `json:"address"`
+	} `json:"result"`
+	TransactionHash *common.Hash `json:"transactionHash"`
+}
+
+// traceAddresses returns the sender and recipient side addresses of a flat Parity
+// trace, following OpenEthereum's trace_filter semantics: calls match on from and
+// to, creations on from and the created address, self-destructs on the destructed
+// contract and the refund address, and rewards on the author as recipient.
+func traceAddresses(trace json.RawMessage) (from []common.Address, to []common.Address, err error) {
+	var entry traceFilterEntry
+	if err := json.Unmarshal(trace, &entry); err != nil {
+		return nil, nil, err
+	}
+	for _, addr := range []*common.Address{entry.Action.From, entry.Action.Address} {
+		if addr != nil {
+			from = append(from, *addr)
+		}
+	}
+	recipients := []*common.Address{entry.Action.To, entry.Action.RefundAddress, entry.Action.Author}
+	if entry.Result != nil {
+		recipients = append(recipients, entry.Result.Address)
+	}
+	for _, addr := range recipients {
+		if addr != nil {
+			to = append(to, *addr)
+		}
+	}
+	return from, to, nil
+}
+
+// containsAddress reports whether any of the addresses is in the set.
+func containsAddress(set []common.Address, addrs []common.Address) bool {
+	for _, want := range set {
+		for _, addr := range addrs {
+			if addr == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newTraceFilter returns a function filtering the flat Parity traces of block
+// results by the addresses of the trace_filter arguments, applying the after and
+// count pagination across all the results passed through it. Results left with
+// no traces are dropped by returning nil.
+func newTraceFilter(args TraceFilterArgs) func(*blockTraceResult) *blockTraceResult {
+	var skipped, sent uint64
+	return func(res *blockTraceResult) *blockTraceResult {
+		if args.Count > 0 && sent >= args.Count {
+			return nil
+		}
+		out := &blockTraceResult{Block: res.Block, Hash: res.Hash}
+		for _, txres := range res.Traces {
+			if txres.Result == nil {
+				// Failed traces can't be matched against addresses
+				if len(args.FromAddress) == 0 && len(args.ToAddress) == 0 && args.After == 0 && args.Count == 0 {
+					out.Traces = append(out.Traces, txres)
+				}
+				continue
+			}
+			blob, err := json.Marshal(txres.Result)
+			if err != nil {
+				continue
+			}
+			var traces []json.RawMessage
+			if err := json.Unmarshal(blob, &traces); err != nil {
+				continue
+			}
+			var matched []json.RawMessage
+			for _, trace := range traces {
+				from, to, err := traceAddresses(trace)
+				if err != nil {
+					continue
+				}
+				if len(args.FromAddress) > 0 && !containsAddress(args.FromAddress, from) {
+					continue
+				}
+				if len(args.ToAddress) > 0 && !containsAddress(args.ToAddress, to) {
+					continue
+				}
+				if skipped < args.After {
+					skipped++
+					continue
+				}
+				if args.Count > 0 && sent >= args.Count {
+					break
+				}
+				matched = append(matched, trace)
+				sent++
+			}
+			if len(matched) > 0 {
+				out.Traces = append(out.Traces, &txTraceResult{TxHash: txres.TxHash, Result: matched})
+			}
+		}
+		if len(out.Traces) == 0 {
+			return nil
+		}
+		return out
+	}
+}
+
+// groupTxTraces groups the flat Parity traces of a block by transaction, in the
+// format of the re-executing trace_filter. Reward traces are not tied to any
+// transaction and are left out.
+func groupTxTraces(block *types.Block, traces []json.RawMessage) *blockTraceResult {
+	var (
+		res   = &blockTraceResult{Block: hexutil.Uint64(block.NumberU64()), Hash: block.Hash()}
+		index = make(map[common.Hash]*txTraceResult)
+	)
+	for _, trace := range traces {
+		var entry traceFilterEntry
+		if err := json.Unmarshal(trace, &entry); err != nil || entry.TransactionHash == nil {
+			continue
+		}
+		txres, ok := index[*entry.TransactionHash]
+		if !ok {
+			txres = &txTraceResult{TxHash: *entry.TransactionHash, Result: []json.RawMessage{}}
+			index[txres.TxHash] = txres
+			res.Traces = append(res.Traces, txres)
+		}
+		txres.Result = append(txres.Result.([]json.RawMessage), trace)
+	}
+	return res
 }
 
 // Call lets you trace a given eth_call. It collects the structured logs created during the execution of EVM
@@ -276,3 +587,127 @@ func (api *TraceAPI) CallMany(ctx context.Context, txs []ethapi.TransactionArgs,
 	config = setTraceCallConfigDefaultTracer(config)
 	return api.debugAPI.TraceCallMany(ctx, txs, blockNrOrHash, config)
 }
+
+// ReplayTransaction replays a transaction, returning the requested Parity trace
+// types ("trace", "stateDiff" and "vmTrace") along with its output.
+func (api *TraceAPI) ReplayTransaction(ctx context.Context, hash common.Hash, traceTypes []string) (*TraceReplayResult, error) {
+	config, err := replayTraceConfig(traceTypes)
+	if err != nil {
+		return nil, err
+	}
+	res, err := api.debugAPI.TraceTransaction(ctx, hash, config)
+	if err != nil {
+		return nil, err
+	}
+	return newTraceReplayResult(res, traceTypes)
+}
+
+// ReplayBlockTransactions replays all the transactions of a block, returning the
+// requested Parity trace types for each of them.
+func (api *TraceAPI) ReplayBlockTransactions(ctx context.Context, number rpc.BlockNumber, traceTypes []string) ([]*TraceReplayResult, error) {
+	config, err := replayTraceConfig(traceTypes)
+	if err != nil {
+		return nil, err
+	}
+	block, err := api.debugAPI.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	traceResults, err := api.debugAPI.traceBlock(ctx, block, config)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*TraceReplayResult, len(traceResults))
+	for i, result := range traceResults {
+		if result.Error != "" {
+			return nil, errors.New(result.Error)
+		}
+		if results[i], err = newTraceReplayResult(result.Result, traceTypes); err != nil {
+			return nil, err
+		}
+		txHash := result.TxHash
+		results[i].TransactionHash = &txHash
+	}
+	return results, nil
+}
+
+// RawTransaction traces a signed raw transaction on top of the latest block
+// without broadcasting it, returning the requested Parity trace types.
+//
+// The transaction runs against the state after the latest block, using that
+// block's header as the EVM block context (number, time, coinbase and base fee),
+// and is treated as the first transaction in it: logs and tracers see tx index 0.
+func (api *TraceAPI) RawTransaction(ctx context.Context, input hexutil.Bytes, traceTypes []string) (*TraceReplayResult, error) {
+	config, err := replayTraceConfig(traceTypes)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return nil, err
+	}
+	block, err := api.debugAPI.blockByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	statedb, release, err := api.debugAPI.backend.StateAtBlock(ctx, block, defaultTraceReexec, nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	signer := types.MakeSigner(api.debugAPI.backend.ChainConfig(), block.Number(), block.Time())
+	msg, err := core.TransactionToMessage(tx, signer, block.BaseFee())
+	if err != nil {
+		return nil, err
+	}
+	vmctx := core.NewEVMBlockContext(block.Header(), api.debugAPI.chainContext(ctx), nil)
+	statedb.SetTxContext(tx.Hash(), 0)
+	res, err := api.debugAPI.traceTx(ctx, msg, &Context{TxHash: tx.Hash(), TxIndex: 0}, vmctx, statedb, config)
+	if err != nil {
+		return nil, err
+	}
+	return newTraceReplayResult(res, traceTypes)
+}
+
+// Get returns the Parity formatted trace of a transaction at the given trace
+// address, or nil if the transaction has no such trace.
+func (api *TraceAPI) Get(ctx context.Context, hash common.Hash, indices []hexutil.Uint64) (interface{}, error) {
+	res, err := api.Transaction(ctx, hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := res.(json.RawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected trace result type %T", res)
+	}
+	var traces []json.RawMessage
+	if err := json.Unmarshal(raw, &traces); err != nil {
+		return nil, err
+	}
+	for _, trace := range traces {
+		var addr struct {
+			TraceAddress []int `json:"traceAddress"`
+		}
+		if err := json.Unmarshal(trace, &addr); err != nil {
+			return nil, err
+		}
+		if traceAddressEqual(addr.TraceAddress, indices) {
+			return trace, nil
+		}
+	}
+	return nil, nil
+}
+
+// traceAddressEqual reports whether a trace address matches the given indices.
+func traceAddressEqual(address []int, indices []hexutil.Uint64) bool {
+	if len(address) != len(indices) {
+		return false
+	}
+	for i, index := range indices {
+		if uint64(address[i]) != uint64(index) {
+			return false
+		}
+	}
+	return true
+}
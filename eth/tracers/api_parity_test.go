@@ -1,6 +1,7 @@
 package tracers
 
 import (
+	"encoding/json"
 	"testing"
 )
 
@@ -39,3 +40,30 @@ func BenchmarkTraceResultsAppend2(b *testing.B) {
 		results = append(results, traceResults...) // nolint:ineffassign,staticcheck
 	}
 }
+
+func TestTraceReplayResult(t *testing.T) {
+	if _, err := replayTraceConfig([]string{"trace", "bogus"}); err == nil {
+		t.Fatal("invalid trace type accepted")
+	}
+	config, err := replayTraceConfig([]string{"vmTrace"})
+	if err != nil {
+		t.Fatalf("failed to create trace config: %v", err)
+	}
+	var tracers map[string]json.RawMessage
+	if err := json.Unmarshal(config.TracerConfig, &tracers); err != nil {
+		t.Fatalf("failed to decode tracer config: %v", err)
+	}
+	if _, ok := tracers["callTracerParity"]; !ok || len(tracers) != 2 {
+		t.Fatalf("tracer config mismatch: %s", config.TracerConfig)
+	}
+	res := json.RawMessage(`{"callTracerParity":[{"result":{"output":"0x01"}}],"vmTracerParity":{"code":"0x","ops":[]}}`)
+	replay, err := newTraceReplayResult(res, []string{"vmTrace"})
+	if err != nil {
+		t.Fatalf("failed to assemble replay result: %v", err)
+	}
+	have, _ := json.Marshal(replay)
+	want := `{"output":"0x01","stateDiff":null,"trace":[],"vmTrace":{"code":"0x","ops":[]}}`
+	if string(have) != want {
+		t.Fatalf("replay result mismatch: have %s, want %s", have, want)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -42,6 +43,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/types/genesisT"
 	"github.com/ethereum/go-ethereum/params/vars"
@@ -170,6 +172,11 @@ func (b *testBackend) StateAtTransaction(ctx context.Context, block *types.Block
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, statedb, release, nil
 	}
+	if b.chainConfig.IsEnabled(b.chainConfig.GetEIP2935Transition, block.Number()) {
+		context := core.NewEVMBlockContext(block.Header(), b.chain, nil)
+		vmenv := vm.NewEVM(context, vm.TxContext{}, statedb, b.chainConfig, vm.Config{})
+		core.ProcessParentBlockHash(block.ParentHash(), vmenv, statedb)
+	}
 	// Recompute transactions up to the target index.
 	signer := types.MakeSigner(b.chainConfig, block.Number(), block.Time())
 	for idx, tx := range block.Transactions() {
@@ -551,6 +558,48 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+// Tests that block tracing stores the parent block hash in the EIP-2935 history
+// contract before replaying the transactions.
+func TestTraceBlockEIP2935(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(1)
+	genesis := &genesisT.Genesis{
+		Config: &coregeth.CoreGethChainConfig{
+			NetworkID:     1,
+			ChainID:       big.NewInt(1),
+			Ethash:        new(ctypes.EthashConfig),
+			EIP155Block:   big.NewInt(0),
+			EIP3855FBlock: big.NewInt(0),
+			EIP2935FBlock: big.NewInt(0),
+		},
+		Alloc: genesisT.GenesisAlloc{
+			accounts[0].addr: {Balance: big.NewInt(vars.Ether)},
+		},
+	}
+	params.PredeployHistoryStorage(genesis.Alloc)
+
+	// Query the history contract for the parent block hash in every block
+	genBlocks := 3
+	backend := newTestBackend(t, genBlocks, genesis, func(i int, b *core.BlockGen) {
+		parent := common.BigToHash(big.NewInt(int64(i)))
+		tx, _ := types.SignTx(types.NewTransaction(uint64(i), vars.HistoryStorageAddress, common.Big0, 100000, big.NewInt(vars.GWei), parent.Bytes()), types.HomesteadSigner{}, accounts[0].key)
+		b.AddTx(tx)
+	})
+	defer backend.chain.Stop()
+	api := NewAPI(backend)
+
+	result, err := api.TraceBlockByNumber(context.Background(), rpc.BlockNumber(genBlocks), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	have, _ := json.Marshal(result)
+	want := backend.chain.GetBlockByNumber(uint64(genBlocks - 1)).Hash()
+	if !strings.Contains(string(have), fmt.Sprintf(`"returnValue":"%x"`, want)) {
+		t.Errorf("parent block hash %x missing from trace: %s", want, have)
+	}
+}
+
 func TestTracingWithOverrides(t *testing.T) {
 	t.Parallel()
 	// Initialize test accounts
@@ -0,0 +1,452 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/snappy"
+)
+
+// The trace index database layout:
+//
+//	traceIndexTailKey                          -> first indexed block number
+//	traceIndexNextKey                          -> next block number to index
+//	traceBlockPrefix + num (uint64 big endian) -> block hash + snappy(JSON traces)
+//	traceAddrPrefix + address + num            -> empty, the address appears in the block traces
+var (
+	traceIndexTailKey = []byte("TraceIndexTail")
+	traceIndexNextKey = []byte("TraceIndexNext")
+
+	traceBlockPrefix = []byte("b")
+	traceAddrPrefix  = []byte("a")
+)
+
+// traceIndexTimeout is the per transaction trace timeout used by the indexer,
+// more lenient than the RPC default as a failure stalls the indexing.
+const traceIndexTimeout = "1m"
+
+// IndexerBackend is the chain access needed by the trace indexer on top of the
+// tracing backend.
+type IndexerBackend interface {
+	Backend
+	CurrentHeader() *types.Header
+	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
+}
+
+// TraceIndexer traces every canonical block in the background with the flat
+// callTracerParity tracer, storing the compressed results along with an index of
+// the addresses involved in them. Entries of blocks reorged out of the canonical
+// chain are dropped and the new canonical blocks indexed in their place.
+type TraceIndexer struct {
+	backend IndexerBackend
+	api     *TraceAPI
+	db      ethdb.Database
+	from    uint64 // Block number to start indexing from
+
+	tail atomic.Uint64 // First indexed block number
+	next atomic.Uint64 // Next block number to index
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewTraceIndexer creates a trace indexer storing its results in db, indexing
+// the canonical chain from block number from onwards. If the database holds an
+// index starting at a different block, it is discarded.
+func NewTraceIndexer(backend IndexerBackend, db ethdb.Database, from uint64) (*TraceIndexer, error) {
+	idx := &TraceIndexer{
+		backend: backend,
+		api:     &TraceAPI{debugAPI: NewAPI(backend)},
+		db:      db,
+		from:    from,
+		quit:    make(chan struct{}),
+	}
+	idx.ctx, idx.cancel = context.WithCancel(context.Background())
+
+	tail, tailOk := readTraceIndexNumber(db, traceIndexTailKey)
+	next, nextOk := readTraceIndexNumber(db, traceIndexNextKey)
+	if !tailOk || !nextOk || tail != from {
+		if tailOk {
+			log.Warn("Discarding trace index with different start block", "have", tail, "want", from)
+		}
+		if err := idx.reset(); err != nil {
+			return nil, err
+		}
+		tail, next = from, from
+	}
+	idx.tail.Store(tail)
+	idx.next.Store(next)
+	return idx, nil
+}
+
+// Start implements node.Lifecycle, starting the background indexing.
+func (idx *TraceIndexer) Start() error {
+	idx.wg.Add(1)
+	go idx.loop()
+	log.Info("Started trace indexer", "from", idx.tail.Load(), "next", idx.next.Load())
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the background indexing.
+func (idx *TraceIndexer) Stop() error {
+	idx.cancel()
+	close(idx.quit)
+	idx.wg.Wait()
+	return nil
+}
+
+// Covers reports whether all the blocks in the interval [from, to] are indexed.
+func (idx *TraceIndexer) Covers(from, to uint64) bool {
+	return from >= idx.tail.Load() && to < idx.next.Load()
+}
+
+// BlockTraces returns the stored flat traces of the given block, or nil if the
+// block is not indexed or the entry belongs to a different block of the same
+// number, such as one reorged out not yet dropped.
+func (idx *TraceIndexer) BlockTraces(number uint64, hash common.Hash) []json.RawMessage {
+	blob, err := idx.db.Get(traceBlockKey(number))
+	if err != nil || len(blob) < common.HashLength || common.BytesToHash(blob[:common.HashLength]) != hash {
+		return nil
+	}
+	traces, err := decodeTraceEntry(blob[common.HashLength:])
+	if err != nil {
+		log.Error("Invalid trace index entry", "number", number, "hash", hash, "err", err)
+		return nil
+	}
+	return traces
+}
+
+// AddressBlocks returns the numbers of the indexed blocks in the interval
+// [from, to] whose traces involve any of the given addresses, in ascending order.
+func (idx *TraceIndexer) AddressBlocks(addrs []common.Address, from, to uint64) []uint64 {
+	set := make(map[uint64]struct{})
+	for _, addr := range addrs {
+		prefix := append(append([]byte{}, traceAddrPrefix...), addr.Bytes()...)
+		it := idx.db.NewIterator(prefix, encodeTraceIndexNumber(from))
+		for it.Next() {
+			number := binary.BigEndian.Uint64(it.Key()[len(prefix):])
+			if number > to {
+				break
+			}
+			set[number] = struct{}{}
+		}
+		it.Release()
+	}
+	numbers := make([]uint64, 0, len(set))
+	for number := range set {
+		numbers = append(numbers, number)
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+	return numbers
+}
+
+// loop keeps the index in sync with the canonical chain, running an update on
+// every new chain head. Head events are consumed while an update is running so
+// that a long backfill doesn't block the chain event feed.
+func (idx *TraceIndexer) loop() {
+	defer idx.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 10)
+	sub := idx.backend.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	var (
+		done    chan struct{} // Non-nil while an update is running
+		pending bool          // Whether a new head arrived during the running update
+	)
+	run := func() {
+		done = make(chan struct{})
+		go func(done chan struct{}) {
+			idx.update()
+			close(done)
+		}(done)
+	}
+	run()
+	for {
+		select {
+		case <-heads:
+			if done == nil {
+				run()
+			} else {
+				pending = true
+			}
+		case <-done:
+			done = nil
+			if pending {
+				pending = false
+				run()
+			}
+		case <-sub.Err():
+			if done != nil {
+				<-done
+			}
+			return
+		case <-idx.quit:
+			if done != nil {
+				<-done
+			}
+			return
+		}
+	}
+}
+
+// update drops the entries of blocks no longer canonical, then indexes the
+// canonical chain up to the current head.
+func (idx *TraceIndexer) update() {
+	var (
+		start  = time.Now()
+		logged = time.Now()
+		first  = idx.next.Load()
+		config = setTraceConfigDefaultTracer(&TraceConfig{Timeout: new(string)})
+	)
+	*config.Timeout = traceIndexTimeout
+
+	if err := idx.rewind(); err != nil {
+		log.Error("Failed to rewind trace index", "err", err)
+		return
+	}
+	head := idx.backend.CurrentHeader().Number.Uint64()
+	for number := idx.next.Load(); number <= head; number = idx.next.Load() {
+		select {
+		case <-idx.quit:
+			return
+		default:
+		}
+		block, err := idx.backend.BlockByNumber(idx.ctx, rpc.BlockNumber(number))
+		if err != nil || block == nil {
+			log.Debug("Trace indexer failed to retrieve block", "number", number, "err", err)
+			return
+		}
+		// The chain may have been reorged under the indexer since the last
+		// rewind, make sure the stored parent is still canonical.
+		if number > idx.tail.Load() {
+			blob, err := idx.db.Get(traceBlockKey(number - 1))
+			if err != nil || len(blob) < common.HashLength || common.BytesToHash(blob[:common.HashLength]) != block.ParentHash() {
+				if err := idx.rewind(); err != nil {
+					log.Error("Failed to rewind trace index", "err", err)
+					return
+				}
+				continue
+			}
+		}
+		// The genesis block is not traceable, it has no transactions nor rewards
+		var res []interface{}
+		if number > 0 {
+			res, err = idx.api.blockTraces(idx.ctx, block, config)
+			if err != nil {
+				if !errors.Is(err, context.Canceled) {
+					log.Warn("Failed to trace block for indexing", "number", number, "hash", block.Hash(), "err", err)
+				}
+				return
+			}
+		}
+		traces, err := encodeTraces(res)
+		if err != nil {
+			log.Error("Failed to encode block traces", "number", number, "err", err)
+			return
+		}
+		if err := idx.indexBlock(block, traces); err != nil {
+			log.Error("Failed to index block traces", "number", number, "err", err)
+			return
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Indexing block traces", "number", number, "head", head, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if next := idx.next.Load(); next-first > 1 {
+		log.Info("Indexed block traces", "from", first, "to", next-1, "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+}
+
+// rewind drops the entries at the top of the index whose blocks are no longer
+// canonical.
+func (idx *TraceIndexer) rewind() error {
+	for next := idx.next.Load(); next > idx.tail.Load(); next-- {
+		number := next - 1
+		header, err := idx.backend.HeaderByNumber(idx.ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return err
+		}
+		blob, err := idx.db.Get(traceBlockKey(number))
+		if err != nil {
+			return fmt.Errorf("missing trace index entry %d: %v", number, err)
+		}
+		if header != nil && len(blob) >= common.HashLength && common.BytesToHash(blob[:common.HashLength]) == header.Hash() {
+			return nil
+		}
+		log.Debug("Dropping reorged block traces", "number", number, "hash", common.BytesToHash(blob[:common.HashLength]))
+		if err := idx.unindexBlock(number, blob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// indexBlock stores the traces of the block and the addresses involved in them,
+// advancing the index by one block.
+func (idx *TraceIndexer) indexBlock(block *types.Block, traces []json.RawMessage) error {
+	blob, err := json.Marshal(traces)
+	if err != nil {
+		return err
+	}
+	var (
+		number = block.NumberU64()
+		batch  = idx.db.NewBatch()
+		entry  = append(block.Hash().Bytes(), snappy.Encode(nil, blob)...)
+	)
+	batch.Put(traceBlockKey(number), entry)
+	for _, addr := range traceEntryAddresses(traces) {
+		batch.Put(traceAddrKey(addr, number), []byte{})
+	}
+	batch.Put(traceIndexNextKey, encodeTraceIndexNumber(number+1))
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	idx.next.Store(number + 1)
+	return nil
+}
+
+// unindexBlock drops the stored traces of the topmost indexed block.
+func (idx *TraceIndexer) unindexBlock(number uint64, entry []byte) error {
+	batch := idx.db.NewBatch()
+	if len(entry) >= common.HashLength {
+		if traces, err := decodeTraceEntry(entry[common.HashLength:]); err == nil {
+			for _, addr := range traceEntryAddresses(traces) {
+				batch.Delete(traceAddrKey(addr, number))
+			}
+		}
+	}
+	batch.Delete(traceBlockKey(number))
+	batch.Put(traceIndexNextKey, encodeTraceIndexNumber(number))
+
+	// Make sure the next index is lowered before the entry disappears
+	idx.next.Store(number)
+	return batch.Write()
+}
+
+// reset discards all the contents of the trace index database and marks it as
+// starting from the configured block.
+func (idx *TraceIndexer) reset() error {
+	it := idx.db.NewIterator(nil, nil)
+	defer it.Release()
+
+	batch := idx.db.NewBatch()
+	for it.Next() {
+		batch.Delete(common.CopyBytes(it.Key()))
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	batch.Put(traceIndexTailKey, encodeTraceIndexNumber(idx.from))
+	batch.Put(traceIndexNextKey, encodeTraceIndexNumber(idx.from))
+	return batch.Write()
+}
+
+// encodeTraces converts the traces of a block to their JSON encoding.
+func encodeTraces(res []interface{}) ([]json.RawMessage, error) {
+	traces := make([]json.RawMessage, len(res))
+	for i, trace := range res {
+		blob, err := json.Marshal(trace)
+		if err != nil {
+			return nil, err
+		}
+		traces[i] = blob
+	}
+	return traces, nil
+}
+
+// decodeTraceEntry decompresses and decodes stored block traces.
+func decodeTraceEntry(data []byte) ([]json.RawMessage, error) {
+	blob, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, err
+	}
+	var traces []json.RawMessage
+	if err := json.Unmarshal(blob, &traces); err != nil {
+		return nil, err
+	}
+	return traces, nil
+}
+
+// traceEntryAddresses returns the distinct addresses involved in the traces,
+// on either the sender or the recipient side.
+func traceEntryAddresses(traces []json.RawMessage) []common.Address {
+	var (
+		seen  = make(map[common.Address]struct{})
+		addrs []common.Address
+	)
+	for _, trace := range traces {
+		from, to, err := traceAddresses(trace)
+		if err != nil {
+			continue
+		}
+		for _, addr := range append(from, to...) {
+			if _, ok := seen[addr]; !ok {
+				seen[addr] = struct{}{}
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
+
+func encodeTraceIndexNumber(number uint64) []byte {
+	enc := make([]byte, 8)
+	binary.BigEndian.PutUint64(enc, number)
+	return enc
+}
+
+func readTraceIndexNumber(db ethdb.KeyValueReader, key []byte) (uint64, bool) {
+	data, err := db.Get(key)
+	if err != nil || len(data) != 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(data), true
+}
+
+func traceBlockKey(number uint64) []byte {
+	return append(append([]byte{}, traceBlockPrefix...), encodeTraceIndexNumber(number)...)
+}
+
+func traceAddrKey(addr common.Address, number uint64) []byte {
+	key := append(append([]byte{}, traceAddrPrefix...), addr.Bytes()...)
+	return append(key, encodeTraceIndexNumber(number)...)
+}
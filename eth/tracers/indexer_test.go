@@ -0,0 +1,202 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/params/vars"
+)
+
+type indexerTestBackend struct {
+	*testBackend
+}
+
+func (b *indexerTestBackend) CurrentHeader() *types.Header {
+	return b.chain.CurrentHeader()
+}
+
+func (b *indexerTestBackend) SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription {
+	return b.chain.SubscribeChainHeadEvent(ch)
+}
+
+// testBlockTraces creates flat Parity style traces for the transfers of the
+// block, followed by the block reward.
+func testBlockTraces(block *types.Block, signer types.Signer) []json.RawMessage {
+	var traces []json.RawMessage
+	for _, tx := range block.Transactions() {
+		from, _ := types.Sender(signer, tx)
+		traces = append(traces, json.RawMessage(fmt.Sprintf(`{"action":{"from":"%s","to":"%s"},"transactionHash":"%s","type":"call"}`, from.Hex(), tx.To().Hex(), tx.Hash().Hex())))
+	}
+	return append(traces, json.RawMessage(fmt.Sprintf(`{"action":{"author":"%s","rewardType":"block"},"type":"reward"}`, block.Coinbase().Hex())))
+}
+
+// Tests that the trace index stores block traces along with the addresses
+// involved in them, and drops the entries of blocks reorged out.
+func TestTraceIndexerReorg(t *testing.T) {
+	var (
+		accounts = newAccounts(3)
+		genesis  = &genesisT.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: genesisT.GenesisAlloc{
+				accounts[0].addr: {Balance: big.NewInt(vars.Ether)},
+			},
+		}
+		signer = types.HomesteadSigner{}
+	)
+	// Blocks 1-5 send a transfer to account 1, the blocks after the fork point
+	// send to account 2 on the side chain.
+	generator := func(fork bool) func(i int, b *core.BlockGen) {
+		return func(i int, b *core.BlockGen) {
+			to := accounts[1].addr
+			if fork && i >= 5 {
+				to = accounts[2].addr
+				b.SetCoinbase(common.Address{0x02})
+			}
+			tx, _ := types.SignTx(types.NewTransaction(uint64(i), to, big.NewInt(1000), vars.TxGas, b.BaseFee(), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	}
+	backend := newTestBackend(t, 10, genesis, generator(false))
+	defer backend.teardown()
+
+	idx, err := NewTraceIndexer(&indexerTestBackend{backend}, rawdb.NewMemoryDatabase(), 1)
+	if err != nil {
+		t.Fatalf("failed to create trace indexer: %v", err)
+	}
+	for n := uint64(1); n <= 10; n++ {
+		block := backend.chain.GetBlockByNumber(n)
+		if err := idx.indexBlock(block, testBlockTraces(block, signer)); err != nil {
+			t.Fatalf("failed to index block %d: %v", n, err)
+		}
+	}
+	if !idx.Covers(1, 10) || idx.Covers(0, 10) || idx.Covers(1, 11) {
+		t.Fatalf("index coverage mismatch: tail %d, next %d", idx.tail.Load(), idx.next.Load())
+	}
+	if have, want := idx.AddressBlocks([]common.Address{accounts[1].addr}, 3, 5), []uint64{3, 4, 5}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("address blocks mismatch: have %v, want %v", have, want)
+	}
+	block := backend.chain.GetBlockByNumber(7)
+	if traces := idx.BlockTraces(7, block.Hash()); len(traces) != 2 {
+		t.Fatalf("block traces mismatch: have %d, want %d", len(traces), 2)
+	}
+	if traces := idx.BlockTraces(7, common.Hash{0x01}); traces != nil {
+		t.Fatal("block traces returned for wrong hash")
+	}
+	// Reorg the chain onto a longer side chain forking after block 5
+	_, blocks, _ := core.GenerateChainWithGenesis(genesis, backend.engine, 12, generator(true))
+	if _, err := backend.chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert side chain: %v", err)
+	}
+	if head := backend.chain.CurrentBlock().Hash(); head != blocks[len(blocks)-1].Hash() {
+		t.Fatalf("chain not reorged")
+	}
+	if err := idx.rewind(); err != nil {
+		t.Fatalf("failed to rewind trace index: %v", err)
+	}
+	if next := idx.next.Load(); next != 6 {
+		t.Fatalf("next block mismatch after reorg: have %d, want %d", next, 6)
+	}
+	if have, want := idx.AddressBlocks([]common.Address{accounts[1].addr}, 0, 20), []uint64{1, 2, 3, 4, 5}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("address blocks mismatch after reorg: have %v, want %v", have, want)
+	}
+	if traces := idx.BlockTraces(7, block.Hash()); traces != nil {
+		t.Fatal("reorged block traces still served")
+	}
+	// Reopening the index with a different start block discards it
+	idx, err = NewTraceIndexer(&indexerTestBackend{backend}, idx.db, 2)
+	if err != nil {
+		t.Fatalf("failed to reopen trace indexer: %v", err)
+	}
+	if idx.tail.Load() != 2 || idx.next.Load() != 2 {
+		t.Fatalf("index not reset: tail %d, next %d", idx.tail.Load(), idx.next.Load())
+	}
+	if blocks := idx.AddressBlocks([]common.Address{accounts[1].addr}, 0, 20); len(blocks) != 0 {
+		t.Fatalf("address index not reset: %v", blocks)
+	}
+}
+
+// Tests the address filtering and pagination of trace_filter results.
+func TestTraceFilter(t *testing.T) {
+	var (
+		a = common.Address{0xa}
+		b = common.Address{0xb}
+		c = common.Address{0xc}
+	)
+	trace := func(from, to common.Address) json.RawMessage {
+		return json.RawMessage(fmt.Sprintf(`{"action":{"from":"%s","to":"%s"},"type":"call"}`, from.Hex(), to.Hex()))
+	}
+	res := &blockTraceResult{
+		Block: 1,
+		Traces: []*txTraceResult{
+			{TxHash: common.Hash{0x1}, Result: []json.RawMessage{trace(a, b), trace(b, c)}},
+			{TxHash: common.Hash{0x2}, Result: json.RawMessage(`[` + string(trace(a, c)) + `]`)},
+			{TxHash: common.Hash{0x3}, Error: "execution timeout"},
+		},
+	}
+	count := func(res *blockTraceResult) int {
+		if res == nil {
+			return 0
+		}
+		var n int
+		for _, txres := range res.Traces {
+			if txres.Result != nil {
+				n += len(txres.Result.([]json.RawMessage))
+			}
+		}
+		return n
+	}
+	tests := []struct {
+		args TraceFilterArgs
+		want int
+	}{
+		{TraceFilterArgs{}, 3},
+		{TraceFilterArgs{FromAddress: []common.Address{a}}, 2},
+		{TraceFilterArgs{ToAddress: []common.Address{c}}, 2},
+		{TraceFilterArgs{FromAddress: []common.Address{a}, ToAddress: []common.Address{c}}, 1},
+		{TraceFilterArgs{FromAddress: []common.Address{c}}, 0},
+		{TraceFilterArgs{After: 1, Count: 1}, 1},
+		{TraceFilterArgs{After: 3}, 0},
+	}
+	for i, tt := range tests {
+		if have := count(newTraceFilter(tt.args)(res)); have != tt.want {
+			t.Errorf("test %d: trace count mismatch: have %d, want %d", i, have, tt.want)
+		}
+	}
+	// Pagination carries over across blocks
+	filter := newTraceFilter(TraceFilterArgs{After: 2, Count: 2})
+	if have := count(filter(res)); have != 1 {
+		t.Fatalf("first block trace count mismatch: have %d, want %d", have, 1)
+	}
+	if have := count(filter(res)); have != 1 {
+		t.Fatalf("second block trace count mismatch: have %d, want %d", have, 1)
+	}
+	if have := filter(res); have != nil {
+		t.Fatalf("results returned past count: %v", have)
+	}
+}
@@ -0,0 +1,124 @@
+package tracetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/eth/tracers/native"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/tests"
+)
+
+// Tests that the vmTracerParity tracer reports the executed operations along
+// with their stack, memory and storage effects, nesting the trace of calls.
+func TestVMTracerParity(t *testing.T) {
+	var (
+		origin = common.HexToAddress("0x00000000000000000000000000000000000000ff")
+		caller = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+		callee = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	)
+	// callee: MSTORE8(0, 1); RETURN(0, 1)
+	calleeCode := common.FromHex("0x600160005360016000f3")
+	// caller: SSTORE(0, 0x2a); CALL(gas, callee, 0, 0, 0, 0, 1); DUP1; POP; STOP
+	callerCode := append(common.FromHex("0x602a60005560016000600060006000"), 0x73)
+	callerCode = append(callerCode, callee.Bytes()...)
+	callerCode = append(callerCode, common.FromHex("0x5af1805000")...)
+
+	alloc := genesisT.GenesisAlloc{
+		origin: {Balance: big.NewInt(1_000_000_000_000_000_000)},
+		caller: {Code: callerCode},
+		callee: {Code: calleeCode},
+	}
+	state := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false, rawdb.HashScheme)
+	defer state.Close()
+
+	tracer, err := tracers.DefaultDirectory.New("vmTracerParity", new(tracers.Context), nil)
+	if err != nil {
+		t.Fatalf("failed to create vm tracer: %v", err)
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		BlockNumber: big.NewInt(1),
+		Difficulty:  big.NewInt(1),
+		GasLimit:    10_000_000,
+		BaseFee:     big.NewInt(0),
+	}
+	evm := vm.NewEVM(context, vm.TxContext{Origin: origin, GasPrice: big.NewInt(0)}, state.StateDB, params.TestChainConfig, vm.Config{Tracer: tracer})
+	msg := &core.Message{
+		From:      origin,
+		To:        &caller,
+		Value:     big.NewInt(0),
+		GasLimit:  100_000,
+		GasPrice:  big.NewInt(0),
+		GasFeeCap: big.NewInt(0),
+		GasTipCap: big.NewInt(0),
+	}
+	if _, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(msg.GasLimit)); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	var trace native.VMTrace
+	if err := json.Unmarshal(res, &trace); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	if !bytes.Equal(trace.Code, callerCode) {
+		t.Fatalf("code mismatch: have %x, want %x", trace.Code, callerCode)
+	}
+	if len(trace.Ops) != 14 {
+		t.Fatalf("op count mismatch: have %d, want %d", len(trace.Ops), 14)
+	}
+	for i, op := range trace.Ops {
+		if op.Ex == nil {
+			t.Fatalf("op %d: missing execution results", i)
+		}
+		if op.Ex.Used >= msg.GasLimit {
+			t.Errorf("op %d: remaining gas %d not below gas limit", i, op.Ex.Used)
+		}
+	}
+	if push := trace.Ops[0].Ex.Push; len(push) != 1 || push[0].String() != "0x2a" {
+		t.Errorf("PUSH1 push mismatch: %v", push)
+	}
+	if store := trace.Ops[2].Ex.Store; store == nil || store.Key.String() != "0x0" || store.Val.String() != "0x2a" {
+		t.Errorf("SSTORE store mismatch: %+v", store)
+	}
+	call := trace.Ops[10]
+	if call.Pc != 37 {
+		t.Errorf("CALL pc mismatch: have %d, want %d", call.Pc, 37)
+	}
+	if push := call.Ex.Push; len(push) != 1 || push[0].String() != "0x1" {
+		t.Errorf("CALL push mismatch: %v", push)
+	}
+	if mem := call.Ex.Mem; mem == nil || mem.Off != 0 || !bytes.Equal(mem.Data, []byte{0x01}) {
+		t.Errorf("CALL mem mismatch: %+v", mem)
+	}
+	if push := trace.Ops[11].Ex.Push; len(push) != 2 {
+		t.Errorf("DUP1 push mismatch: %v", push)
+	}
+	sub := call.Sub
+	if sub == nil {
+		t.Fatal("CALL sub trace missing")
+	}
+	if !bytes.Equal(sub.Code, calleeCode) || len(sub.Ops) != 6 {
+		t.Fatalf("sub trace mismatch: code %x, %d ops", sub.Code, len(sub.Ops))
+	}
+	if mem := sub.Ops[2].Ex.Mem; mem == nil || mem.Off != 0 || !bytes.Equal(mem.Data, []byte{0x01}) {
+		t.Errorf("MSTORE8 mem mismatch: %+v", mem)
+	}
+	for i, op := range trace.Ops {
+		if op.Sub != nil && i != 10 {
+			t.Errorf("op %d: unexpected sub trace", i)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/holiman/uint256"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("erc7562Tracer", newERC7562Tracer, false)
+}
+
+// erc7562Entities names the entities validated in each phase of an ERC-4337
+// user operation validation. The EntryPoint separates the phases by executing
+// the NUMBER opcode in its own frame.
+var erc7562Entities = []string{"factory", "account", "paymaster"}
+
+// erc7562BannedOpcodes are the opcodes entities may not use during validation
+// (rule OP-011), as their result may differ between simulation and inclusion.
+var erc7562BannedOpcodes = map[vm.OpCode]bool{
+	vm.GASPRICE:     true,
+	vm.GASLIMIT:     true,
+	vm.DIFFICULTY:   true,
+	vm.TIMESTAMP:    true,
+	vm.BASEFEE:      true,
+	vm.BLOCKHASH:    true,
+	vm.NUMBER:       true,
+	vm.SELFBALANCE:  true,
+	vm.BALANCE:      true,
+	vm.ORIGIN:       true,
+	vm.CREATE:       true,
+	vm.COINBASE:     true,
+	vm.SELFDESTRUCT: true,
+	vm.BLOBHASH:     true,
+	vm.BLOBBASEFEE:  true,
+}
+
+// erc7562DepositToSelector is the selector of EntryPoint.depositTo(address),
+// the only EntryPoint method entities may call during validation (OP-052).
+var erc7562DepositToSelector = []byte{0xb7, 0x60, 0xfa, 0xf9}
+
+// erc7562AssociatedRange is the number of slots following keccak(A||x) which
+// are associated with address A.
+const erc7562AssociatedRange = 128
+
+type erc7562TracerConfig struct {
+	EntryPoint common.Address  `json:"entryPoint"`
+	Sender     common.Address  `json:"sender"`
+	Factory    *common.Address `json:"factory"`
+	Paymaster  *common.Address `json:"paymaster"`
+}
+
+// erc7562Violation is a validation rule broken by an entity. Violations with
+// StakedOK set are permitted if the entity is staked.
+type erc7562Violation struct {
+	Entity   string         `json:"entity"`
+	Address  common.Address `json:"address"`
+	Rule     string         `json:"rule"`
+	Reason   string         `json:"reason"`
+	StakedOK bool           `json:"stakedOk,omitempty"`
+}
+
+// erc7562Access is a storage slot access made while validating an entity.
+type erc7562Access struct {
+	phase    int
+	contract common.Address
+	slot     common.Hash
+	write    bool
+}
+
+// erc7562Phase summarizes the validation of a single entity.
+type erc7562Phase struct {
+	Entity  string         `json:"entity"`
+	Address common.Address `json:"address"`
+	Opcodes map[string]int `json:"opcodes"`
+}
+
+type erc7562Result struct {
+	Phases     []*erc7562Phase     `json:"phases"`
+	Violations []*erc7562Violation `json:"violations"`
+}
+
+// erc7562Tracer records the behaviour of the entities of an ERC-4337 user
+// operation while the EntryPoint's simulateValidation runs, and checks it
+// against the opcode and storage access rules of ERC-7562.
+type erc7562Tracer struct {
+	noopTracer
+	config erc7562TracerConfig
+	env    *vm.EVM
+
+	entities   [3]common.Address
+	phase      int
+	phases     []*erc7562Phase
+	violations []*erc7562Violation
+	accesses   []erc7562Access
+	keccak     map[common.Address][]common.Hash // keccak results of inputs prefixed by an address
+	precompile map[common.Address]bool
+	creates    int
+
+	gasDepth int // depth of a pending GAS opcode, which must be followed by a call
+
+	interrupt atomic.Bool // Atomic flag to signal execution interruption
+	reason    error       // Textual reason for the interruption
+}
+
+// newERC7562Tracer returns a native go tracer which checks the validation of
+// an ERC-4337 user operation against the ERC-7562 rules.
+func newERC7562Tracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
+	var config erc7562TracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
+	if config.EntryPoint == (common.Address{}) {
+		return nil, errors.New("erc7562Tracer: missing entryPoint")
+	}
+	t := &erc7562Tracer{
+		config: config,
+		keccak: make(map[common.Address][]common.Hash),
+	}
+	if config.Factory != nil {
+		t.entities[0] = *config.Factory
+	}
+	t.entities[1] = config.Sender
+	if config.Paymaster != nil {
+		t.entities[2] = *config.Paymaster
+	}
+	t.newPhase()
+	return t, nil
+}
+
+func (t *erc7562Tracer) newPhase() {
+	t.phases = append(t.phases, &erc7562Phase{
+		Entity:  erc7562Entities[t.phase],
+		Address: t.entities[t.phase],
+		Opcodes: make(map[string]int),
+	})
+}
+
+func (t *erc7562Tracer) violate(rule, format string, args ...interface{}) {
+	t.violations = append(t.violations, &erc7562Violation{
+		Entity:  erc7562Entities[t.phase],
+		Address: t.entities[t.phase],
+		Rule:    rule,
+		Reason:  fmt.Sprintf(format, args...),
+	})
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *erc7562Tracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.precompile = make(map[common.Address]bool)
+	for _, addr := range env.ActivePrecompiles() {
+		t.precompile[addr] = true
+	}
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *erc7562Tracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if err != nil || t.interrupt.Load() {
+		return
+	}
+	caller := scope.Contract.Address()
+	if caller == t.config.EntryPoint {
+		// The EntryPoint marks the start of the next entity's validation.
+		if depth == 1 && op == vm.NUMBER && t.phase < len(erc7562Entities)-1 {
+			t.phase++
+			t.newPhase()
+		}
+		return
+	}
+	stackData := scope.Stack.Data()
+	stackLen := len(stackData)
+
+	if t.gasDepth == depth {
+		t.gasDepth = 0
+		if op != vm.CALL && op != vm.DELEGATECALL && op != vm.STATICCALL && op != vm.CALLCODE {
+			t.violate("OP-012", "GAS opcode not followed by a call at %s", caller)
+		}
+	}
+	t.phases[t.phase].Opcodes[op.String()]++
+
+	switch {
+	case erc7562BannedOpcodes[op]:
+		t.violate("OP-011", "banned opcode %s used by %s", op, caller)
+
+	case op == vm.GAS:
+		t.gasDepth = depth
+
+	case op == vm.CREATE2:
+		t.creates++
+		if t.phase != 0 || t.creates > 1 {
+			t.violate("OP-031", "CREATE2 used by %s outside of sender deployment", caller)
+		}
+
+	case stackLen >= 1 && (op == vm.SLOAD || op == vm.SSTORE):
+		slot := common.Hash(stackData[stackLen-1].Bytes32())
+		t.accesses = append(t.accesses, erc7562Access{phase: t.phase, contract: caller, slot: slot, write: op == vm.SSTORE})
+
+	case stackLen >= 2 && op == vm.KECCAK256:
+		offset, size := stackData[stackLen-1], stackData[stackLen-2]
+		if size.Uint64() < 32 {
+			break
+		}
+		input, err := tracers.GetMemoryCopyPadded(scope.Memory, int64(offset.Uint64()), int64(size.Uint64()))
+		if err != nil || !bytes.Equal(input[:12], common.Hash{}.Bytes()[:12]) {
+			break
+		}
+		addr := common.BytesToAddress(input[12:32])
+		t.keccak[addr] = append(t.keccak[addr], crypto.Keccak256Hash(input))
+
+	case stackLen >= 1 && (op == vm.EXTCODESIZE || op == vm.EXTCODEHASH || op == vm.EXTCODECOPY):
+		addr := common.Address(stackData[stackLen-1].Bytes20())
+		if addr != t.config.Sender && !t.precompile[addr] && t.env.StateDB.GetCodeSize(addr) == 0 {
+			t.violate("OP-041", "%s accessed code of %s, which has no code", caller, addr)
+		}
+
+	case stackLen >= 2 && (op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL):
+		addr := common.Address(stackData[stackLen-2].Bytes20())
+		if isPrecompileRange(addr) && !t.precompile[addr] {
+			t.violate("OP-062", "%s called unknown precompile %s", caller, addr)
+		}
+	}
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *erc7562Tracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	if t.interrupt.Load() || from == t.config.EntryPoint {
+		return
+	}
+	if to == t.config.EntryPoint {
+		if len(input) > 0 && !bytes.HasPrefix(input, erc7562DepositToSelector) {
+			t.violate("OP-052", "%s called the EntryPoint with selector %#x", from, input[:min(len(input), 4)])
+		}
+		return
+	}
+	if value != nil && value.Sign() != 0 && typ != vm.SELFDESTRUCT {
+		t.violate("OP-061", "%s transferred value to %s", from, to)
+	}
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *erc7562Tracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if errors.Is(err, vm.ErrOutOfGas) {
+		t.violate("OP-020", "out of gas")
+	}
+}
+
+// associated reports whether slot is associated with addr, that is, it is
+// the address itself or follows closely a keccak of an input prefixed by it.
+func (t *erc7562Tracer) associated(addr common.Address, slot common.Hash) bool {
+	if slot == common.BytesToHash(addr.Bytes()) {
+		return true
+	}
+	s := new(uint256.Int).SetBytes(slot.Bytes())
+	for _, h := range t.keccak[addr] {
+		base := new(uint256.Int).SetBytes(h.Bytes())
+		if s.Cmp(base) >= 0 && new(uint256.Int).Sub(s, base).CmpUint64(erc7562AssociatedRange) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// checkStorage applies the storage access rules (STO-*) once all keccak
+// preimages are known.
+func (t *erc7562Tracer) checkStorage() (violations []*erc7562Violation) {
+	type key struct {
+		phase    int
+		contract common.Address
+		slot     common.Hash
+	}
+	written := make(map[key]bool)
+	for _, a := range t.accesses {
+		if a.write {
+			written[key{a.phase, a.contract, a.slot}] = true
+		}
+	}
+	seen := make(map[key]bool)
+	for _, a := range t.accesses {
+		k := key{a.phase, a.contract, a.slot}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+
+		entity := t.entities[a.phase]
+		violation := &erc7562Violation{
+			Entity:  erc7562Entities[a.phase],
+			Address: entity,
+		}
+		switch {
+		case a.contract == t.config.Sender:
+			continue // STO-010
+		case t.associated(t.config.Sender, a.slot):
+			if a.phase != 0 {
+				continue // STO-021
+			}
+			violation.Rule, violation.StakedOK = "STO-022", true
+		case a.contract == entity:
+			violation.Rule, violation.StakedOK = "STO-031", true
+		case entity != (common.Address{}) && t.associated(entity, a.slot):
+			violation.Rule, violation.StakedOK = "STO-032", true
+		case !written[k]:
+			violation.Rule, violation.StakedOK = "STO-033", true
+		default:
+			violation.Rule = "STO-010"
+		}
+		access := "read"
+		if written[k] {
+			access = "write"
+		}
+		violation.Reason = fmt.Sprintf("%s of slot %s of %s", access, a.slot, a.contract)
+		violations = append(violations, violation)
+	}
+	return violations
+}
+
+// GetResult returns the json-encoded validation summary and the rule
+// violations, and any error arising from the encoding or forceful
+// termination (via `Stop`).
+func (t *erc7562Tracer) GetResult() (json.RawMessage, error) {
+	violations := append([]*erc7562Violation{}, t.violations...)
+	violations = append(violations, t.checkStorage()...)
+	res, err := json.Marshal(erc7562Result{Phases: t.phases, Violations: violations})
+	if err != nil {
+		return nil, err
+	}
+	return res, t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *erc7562Tracer) Stop(err error) {
+	t.reason = err
+	t.interrupt.Store(true)
+}
+
+// isPrecompileRange reports whether addr lies in the range reserved for
+// precompiled contracts.
+func isPrecompileRange(addr common.Address) bool {
+	return new(big.Int).SetBytes(addr.Bytes()).Cmp(big.NewInt(0xffff)) <= 0
+}
@@ -65,6 +65,16 @@ func (t *muxTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Add
 	}
 }
 
+// CapturePreEVM forwards the EVM to the tracers needing to inspect the state
+// before the transaction is executed.
+func (t *muxTracer) CapturePreEVM(env *vm.EVM) {
+	for _, t := range t.tracers {
+		if capturer, ok := t.(vm.EVMLogger_StateCapturer); ok {
+			capturer.CapturePreEVM(env)
+		}
+	}
+}
+
 // CaptureEnd is called after the call finishes to finalize the tracing.
 func (t *muxTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
 	for _, t := range t.tracers {
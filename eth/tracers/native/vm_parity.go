@@ -0,0 +1,282 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/holiman/uint256"
+)
+
+func init() {
+	tracers.DefaultDirectory.Register("vmTracerParity", newVMParityTracer, false)
+}
+
+// maxVMTraceMemWrite caps the memory reported as written by a single operation,
+// to avoid huge copies on bogus (failing) memory offsets.
+const maxVMTraceMemWrite = 1 << 20
+
+// VMTrace is the Parity (OpenEthereum) formatted trace of the code executed in
+// a single call frame.
+type VMTrace struct {
+	Code hexutil.Bytes `json:"code"`
+	Ops  []*VMTraceOp  `json:"ops"`
+}
+
+// VMTraceOp is a single executed operation in a Parity formatted vmTrace.
+type VMTraceOp struct {
+	Cost uint64     `json:"cost"`
+	Ex   *VMTraceEx `json:"ex"` // Execution results, nil if the operation failed
+	Pc   uint64     `json:"pc"`
+	Sub  *VMTrace   `json:"sub"` // Trace of the call frame entered by the operation, if any
+}
+
+// VMTraceEx holds the effects of an executed operation.
+type VMTraceEx struct {
+	Mem   *VMTraceMem    `json:"mem"`
+	Push  []hexutil.U256 `json:"push"`
+	Store *VMTraceStore  `json:"store"`
+	Used  uint64         `json:"used"` // Gas remaining after the operation
+}
+
+// VMTraceMem is a memory region written by an operation.
+type VMTraceMem struct {
+	Data hexutil.Bytes `json:"data"`
+	Off  uint64        `json:"off"`
+}
+
+// VMTraceStore is a storage slot written by an operation.
+type VMTraceStore struct {
+	Key hexutil.U256 `json:"key"`
+	Val hexutil.U256 `json:"val"`
+}
+
+// vmParityFrame tracks the trace of a single call frame being executed.
+type vmParityFrame struct {
+	trace *VMTrace // Trace of the frame, nil until the frame executes code
+
+	pending   *VMTraceOp    // Last operation, awaiting its results
+	pendingOp vm.OpCode     // Opcode of the pending operation
+	gasAfter  uint64        // Gas left after the pending operation, if it ends the frame
+	memOff    uint64        // Offset of the memory written by the pending operation
+	memSize   uint64        // Size of the memory written by the pending operation
+	store     *VMTraceStore // Storage slot written by the pending operation
+}
+
+// vmParityTracer is a native go tracer producing Parity style vmTraces, a
+// nested record of every executed operation along with its effects on the
+// stack, memory and storage.
+type vmParityTracer struct {
+	frames    []*vmParityFrame
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+}
+
+func newVMParityTracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	return &vmParityTracer{}, nil
+}
+
+func (t *vmParityTracer) CaptureTxStart(gasLimit uint64) {}
+
+func (t *vmParityTracer) CaptureTxEnd(restGas uint64) {}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *vmParityTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.frames = []*vmParityFrame{{trace: &VMTrace{Code: hexutil.Bytes{}, Ops: []*VMTraceOp{}}}}
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *vmParityTracer) CaptureEnd(output []byte, gasUsed uint64, err error) {
+	if len(t.frames) > 0 {
+		t.frames[0].close()
+	}
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *vmParityTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if atomic.LoadUint32(&t.interrupt) > 0 || len(t.frames) == 0 {
+		return
+	}
+	frame := t.frames[len(t.frames)-1]
+	if frame.trace == nil {
+		// First operation of an entered frame, hook it up to the calling operation
+		frame.trace = &VMTrace{Ops: []*VMTraceOp{}}
+		if len(t.frames) > 1 {
+			if parent := t.frames[len(t.frames)-2]; parent.pending != nil {
+				parent.pending.Sub = frame.trace
+			}
+		}
+	}
+	if len(frame.trace.Ops) == 0 {
+		frame.trace.Code = common.CopyBytes(scope.Contract.Code)
+	}
+	// The current state holds the results of the previous operation
+	frame.finalize(scope, gas)
+
+	entry := &VMTraceOp{Cost: cost, Pc: pc}
+	frame.trace.Ops = append(frame.trace.Ops, entry)
+	if err != nil {
+		// Operation failed before executing, there are no results to report
+		return
+	}
+	frame.pending, frame.pendingOp = entry, op
+	frame.gasAfter = 0
+	if gas > cost {
+		frame.gasAfter = gas - cost
+	}
+	frame.memOff, frame.memSize = vmTraceMemWrite(op, scope.Stack.Data())
+
+	frame.store = nil
+	if stack := scope.Stack.Data(); op == vm.SSTORE && len(stack) >= 2 {
+		frame.store = &VMTraceStore{
+			Key: hexutil.U256(stack[len(stack)-1]),
+			Val: hexutil.U256(stack[len(stack)-2]),
+		}
+	}
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *vmParityTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, _ *vm.ScopeContext, depth int, err error) {
+	if len(t.frames) == 0 {
+		return
+	}
+	// The pending operation failed, leave its results empty
+	t.frames[len(t.frames)-1].pending = nil
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *vmParityTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.frames = append(t.frames, new(vmParityFrame))
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *vmParityTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	if len(t.frames) < 2 {
+		return
+	}
+	t.frames[len(t.frames)-1].close()
+	t.frames = t.frames[:len(t.frames)-1]
+}
+
+// GetResult returns the json-encoded vmTrace of the top level call frame, and
+// any error arising from the encoding or forceful termination (via `Stop`).
+func (t *vmParityTracer) GetResult() (json.RawMessage, error) {
+	if len(t.frames) == 0 {
+		return json.RawMessage("null"), t.reason
+	}
+	res, err := json.Marshal(t.frames[0].trace)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *vmParityTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// finalize fills in the results of the pending operation from the state of the
+// frame right after it was executed.
+func (f *vmParityFrame) finalize(scope *vm.ScopeContext, gas uint64) {
+	if f.pending == nil {
+		return
+	}
+	ex := &VMTraceEx{Push: []hexutil.U256{}, Store: f.store, Used: gas}
+
+	stack := scope.Stack.Data()
+	if n := vmTracePushes(f.pendingOp); n > 0 && n <= len(stack) {
+		for _, item := range stack[len(stack)-n:] {
+			ex.Push = append(ex.Push, hexutil.U256(item))
+		}
+	}
+	if mlen := uint64(scope.Memory.Len()); f.memSize > 0 && f.memOff <= mlen && f.memSize <= mlen-f.memOff {
+		ex.Mem = &VMTraceMem{
+			Data: scope.Memory.GetCopy(int64(f.memOff), int64(f.memSize)),
+			Off:  f.memOff,
+		}
+	}
+	f.pending.Ex, f.pending = ex, nil
+}
+
+// close fills in the results of the last operation of the frame, which ended
+// the frame's execution.
+func (f *vmParityFrame) close() {
+	if f.pending == nil {
+		return
+	}
+	f.pending.Ex = &VMTraceEx{Push: []hexutil.U256{}, Store: f.store, Used: f.gasAfter}
+	f.pending = nil
+}
+
+// vmTracePushes returns the number of stack items reported as pushed by an
+// operation. Following Parity, DUPn and SWAPn report all the items they touch.
+func vmTracePushes(op vm.OpCode) int {
+	switch {
+	case op >= vm.DUP1 && op <= vm.DUP16:
+		return int(op-vm.DUP1) + 2
+	case op >= vm.SWAP1 && op <= vm.SWAP16:
+		return int(op-vm.SWAP1) + 2
+	case op >= vm.LOG0 && op <= vm.LOG4:
+		return 0
+	}
+	switch op {
+	case vm.STOP, vm.POP, vm.MSTORE, vm.MSTORE8, vm.SSTORE, vm.TSTORE, vm.JUMP, vm.JUMPI, vm.JUMPDEST,
+		vm.CALLDATACOPY, vm.CODECOPY, vm.EXTCODECOPY, vm.RETURNDATACOPY, vm.MCOPY,
+		vm.RETURN, vm.REVERT, vm.SELFDESTRUCT, vm.INVALID:
+		return 0
+	}
+	return 1
+}
+
+// vmTraceMemWrite returns the memory region an operation is about to write to,
+// given the stack before its execution.
+func vmTraceMemWrite(op vm.OpCode, stack []uint256.Int) (uint64, uint64) {
+	var offset, size *uint256.Int
+	peek := func(n int) *uint256.Int {
+		if n >= len(stack) {
+			return nil
+		}
+		return &stack[len(stack)-1-n]
+	}
+	switch op {
+	case vm.MSTORE:
+		offset, size = peek(0), uint256.NewInt(32)
+	case vm.MSTORE8:
+		offset, size = peek(0), uint256.NewInt(1)
+	case vm.CALLDATACOPY, vm.CODECOPY, vm.RETURNDATACOPY, vm.MCOPY:
+		offset, size = peek(0), peek(2)
+	case vm.EXTCODECOPY:
+		offset, size = peek(1), peek(3)
+	case vm.CALL, vm.CALLCODE:
+		offset, size = peek(5), peek(6)
+	case vm.DELEGATECALL, vm.STATICCALL:
+		offset, size = peek(4), peek(5)
+	}
+	if offset == nil || size == nil || !offset.IsUint64() || !size.IsUint64() || size.Uint64() > maxVMTraceMemWrite {
+		return 0, 0
+	}
+	return offset.Uint64(), size.Uint64()
+}
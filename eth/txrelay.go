@@ -0,0 +1,330 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/ptx"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxPrivateTxExpiry is the maximum number of blocks transactions are kept
+// private for.
+const maxPrivateTxExpiry = 256
+
+var (
+	privateTxSubmitMeter   = metrics.NewRegisteredMeter("eth/txrelay/private/submitted", nil)
+	privateTxRelayMeter    = metrics.NewRegisteredMeter("eth/txrelay/private/relayed", nil)
+	privateTxReceiveMeter  = metrics.NewRegisteredMeter("eth/txrelay/private/received", nil)
+	privateTxFallbackMeter = metrics.NewRegisteredMeter("eth/txrelay/private/fallback", nil)
+)
+
+var errUntrustedPrivateTxs = errors.New("private transactions from untrusted peer")
+
+// privateTxKey is the context key marking transactions submitted over RPC as
+// private. The value is the number of blocks to keep them private for.
+type privateTxKey struct{}
+
+// withPrivateTx returns a context marking submitted transactions as private.
+func withPrivateTx(ctx context.Context, expiry uint64) context.Context {
+	return context.WithValue(ctx, privateTxKey{}, expiry)
+}
+
+// privateTxExpiry reports whether transactions submitted with the given RPC
+// context are private, either explicitly or because they were submitted over
+// one of the private transports, and the number of blocks to keep them private
+// for. Zero means the default expiry.
+func privateTxExpiry(ctx context.Context, transports []string) (uint64, bool) {
+	if expiry, ok := ctx.Value(privateTxKey{}).(uint64); ok {
+		return expiry, true
+	}
+	if transport := rpc.PeerInfoFromContext(ctx).Transport; transport != "" && slices.Contains(transports, transport) {
+		return 0, true
+	}
+	return 0, false
+}
+
+// privateTx is a transaction kept out of the public transaction gossip.
+type privateTx struct {
+	tx     *types.Transaction
+	expiry uint64 // Block number from which the transaction is public
+	local  bool   // Whether the transaction was submitted locally
+}
+
+// privateTxRelay keeps privately submitted transactions out of the transaction
+// gossip and relays them to trusted mining peers instead. Transactions still
+// pending once they expire are broadcast publicly.
+type privateTxRelay struct {
+	chain     *core.BlockChain
+	txpool    txPool
+	relays    map[enode.ID]bool        // Peers to relay private transactions to
+	expiry    uint64                   // Default number of blocks to keep transactions private for
+	broadcast func(types.Transactions) // Public broadcast of expired transactions
+
+	lock  sync.RWMutex
+	txs   map[common.Hash]*privateTx
+	peers map[string]*ptx.Peer // Connected relay peers
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newPrivateTxRelay(chain *core.BlockChain, pool txPool, relays []*enode.Node, expiry uint64, broadcast func(types.Transactions)) *privateTxRelay {
+	r := &privateTxRelay{
+		chain:     chain,
+		txpool:    pool,
+		relays:    make(map[enode.ID]bool),
+		expiry:    min(expiry, maxPrivateTxExpiry),
+		broadcast: broadcast,
+		txs:       make(map[common.Hash]*privateTx),
+		peers:     make(map[string]*ptx.Peer),
+		quit:      make(chan struct{}),
+	}
+	for _, n := range relays {
+		r.relays[n.ID()] = true
+	}
+	return r
+}
+
+func (r *privateTxRelay) start() {
+	r.wg.Add(1)
+	go r.loop()
+}
+
+func (r *privateTxRelay) stop() {
+	close(r.quit)
+	r.wg.Wait()
+}
+
+// submit adds a local transaction to the pool and relays it to the connected
+// relay peers without broadcasting it. If expiry is zero, the transaction is
+// kept private for the default number of blocks.
+func (r *privateTxRelay) submit(tx *types.Transaction, expiry uint64) error {
+	if expiry == 0 {
+		expiry = r.expiry
+	}
+	entry := &privateTx{
+		tx:     tx,
+		expiry: r.chain.CurrentBlock().Number.Uint64() + min(expiry, maxPrivateTxExpiry),
+		local:  true,
+	}
+	// The transaction is marked private before it enters the pool, so that it's
+	// never seen by the broadcast loop as a public one. The pool is called with
+	// the lock released, as it may feed the new transaction synchronously to the
+	// broadcast loop, which filters it through the relay.
+	hash := tx.Hash()
+
+	r.lock.Lock()
+	prev := r.txs[hash]
+	r.txs[hash] = entry
+	r.lock.Unlock()
+
+	if err := r.txpool.Add([]*types.Transaction{tx}, true, false)[0]; err != nil {
+		r.lock.Lock()
+		if r.txs[hash] == entry {
+			if prev != nil {
+				r.txs[hash] = prev
+			} else {
+				delete(r.txs, hash)
+			}
+		}
+		r.lock.Unlock()
+		return err
+	}
+	r.lock.RLock()
+	peers := make([]*ptx.Peer, 0, len(r.peers))
+	for _, peer := range r.peers {
+		peers = append(peers, peer)
+	}
+	r.lock.RUnlock()
+
+	privateTxSubmitMeter.Mark(1)
+	if len(r.relays) == 0 {
+		log.Warn("Private transaction submitted without relay peers", "hash", hash)
+	}
+	for _, peer := range peers {
+		r.send(peer, entry)
+	}
+	return nil
+}
+
+// send relays a private transaction to a peer.
+func (r *privateTxRelay) send(peer *ptx.Peer, entry *privateTx) {
+	if err := peer.SendTransactions(entry.expiry, types.Transactions{entry.tx}); err != nil {
+		peer.Log().Debug("Failed to relay private transaction", "hash", entry.tx.Hash(), "err", err)
+		return
+	}
+	privateTxRelayMeter.Mark(1)
+}
+
+// isPrivate reports whether a transaction is kept private.
+func (r *privateTxRelay) isPrivate(hash common.Hash) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	_, ok := r.txs[hash]
+	return ok
+}
+
+// filter returns the transactions which aren't private.
+func (r *privateTxRelay) filter(txs types.Transactions) types.Transactions {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if len(r.txs) == 0 {
+		return txs
+	}
+	public := make(types.Transactions, 0, len(txs))
+	for _, tx := range txs {
+		if _, ok := r.txs[tx.Hash()]; !ok {
+			public = append(public, tx)
+		}
+	}
+	return public
+}
+
+// runPeer tracks the connected relay peers and sends them the pending private
+// transactions.
+func (r *privateTxRelay) runPeer(peer *ptx.Peer, handler ptx.Handler) error {
+	if !r.relays[peer.Node().ID()] {
+		return handler(peer)
+	}
+	r.lock.Lock()
+	r.peers[peer.ID()] = peer
+	var pending []*privateTx
+	for _, entry := range r.txs {
+		if entry.local {
+			pending = append(pending, entry)
+		}
+	}
+	r.lock.Unlock()
+
+	defer func() {
+		r.lock.Lock()
+		delete(r.peers, peer.ID())
+		r.lock.Unlock()
+	}()
+	peer.Log().Debug("Private transaction relay peer connected", "pending", len(pending))
+	for _, entry := range pending {
+		r.send(peer, entry)
+	}
+	return handler(peer)
+}
+
+// handle adds the private transactions relayed by a trusted peer to the pool.
+func (r *privateTxRelay) handle(peer *ptx.Peer, packet *ptx.TransactionsPacket) error {
+	if !peer.Trusted() {
+		return errUntrustedPrivateTxs
+	}
+	privateTxReceiveMeter.Mark(int64(len(packet.Transactions)))
+
+	expiry := min(packet.Expiry, r.chain.CurrentBlock().Number.Uint64()+maxPrivateTxExpiry)
+
+	r.lock.Lock()
+	for _, tx := range packet.Transactions {
+		if _, ok := r.txs[tx.Hash()]; !ok {
+			r.txs[tx.Hash()] = &privateTx{tx: tx, expiry: expiry}
+		}
+	}
+	r.lock.Unlock()
+
+	// Add the transactions with the lock released, see submit
+	errs := r.txpool.Add(packet.Transactions, false, false)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, err := range errs {
+		if err != nil {
+			hash := packet.Transactions[i].Hash()
+			if entry, ok := r.txs[hash]; ok && !entry.local {
+				delete(r.txs, hash)
+			}
+			peer.Log().Trace("Failed to add private transaction", "hash", hash, "err", err)
+		}
+	}
+	return nil
+}
+
+// loop drops private transactions no longer pending and publishes the expired
+// ones on every new head.
+func (r *privateTxRelay) loop() {
+	defer r.wg.Done()
+
+	heads := make(chan core.ChainHeadEvent, 10)
+	sub := r.chain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case head := <-heads:
+			r.expire(head.Block.NumberU64())
+		case <-sub.Err():
+			return
+		case <-r.quit:
+			return
+		}
+	}
+}
+
+// expire forgets the private transactions which were included or dropped from
+// the pool, and broadcasts the local ones which expired at the given block.
+func (r *privateTxRelay) expire(number uint64) {
+	// Look the transactions up in the pool with the lock released, see submit
+	r.lock.RLock()
+	entries := maps.Clone(r.txs)
+	r.lock.RUnlock()
+
+	dropped := make(map[common.Hash]*privateTx)
+	for hash, entry := range entries {
+		if !r.txpool.Has(hash) {
+			dropped[hash] = entry
+		}
+	}
+	var public types.Transactions
+
+	r.lock.Lock()
+	for hash, entry := range r.txs {
+		switch {
+		case dropped[hash] == entry:
+			delete(r.txs, hash)
+		case number >= entry.expiry:
+			delete(r.txs, hash)
+			if entry.local {
+				public = append(public, entry.tx)
+			}
+		}
+	}
+	r.lock.Unlock()
+
+	if len(public) > 0 {
+		log.Info("Broadcasting expired private transactions", "count", len(public))
+		privateTxFallbackMeter.Mark(int64(len(public)))
+		r.broadcast(public)
+	}
+}
@@ -0,0 +1,200 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/protocols/ptx"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+// Tests that private transactions are kept out of the broadcast, relayed to the
+// configured peers and broadcast publicly once they expire.
+func TestPrivateTxRelay(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	var (
+		relayNode = enode.SignNull(new(enr.Record), enode.ID{1})
+		broadcast types.Transactions
+	)
+	relay := newPrivateTxRelay(handler.chain, handler.txpool, []*enode.Node{relayNode}, 2, func(txs types.Transactions) {
+		broadcast = append(broadcast, txs...)
+	})
+
+	// Connect the relay peer, which must receive the private transactions
+	app, net := p2p.MsgPipe()
+	defer app.Close()
+	defer net.Close()
+
+	peer := ptx.NewPeer(ptx.PTX1, p2p.NewPeer(relayNode.ID(), "relay", nil), app)
+	running, done := make(chan struct{}), make(chan struct{})
+	defer close(done)
+	go relay.runPeer(peer, func(*ptx.Peer) error {
+		close(running)
+		<-done
+		return nil
+	})
+	<-running
+
+	private := types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+	private, _ = types.SignTx(private, types.HomesteadSigner{}, testKey)
+	public := types.NewTransaction(1, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+	public, _ = types.SignTx(public, types.HomesteadSigner{}, testKey)
+
+	errc := make(chan error, 1)
+	go func() { errc <- relay.submit(private, 0) }()
+
+	msg, err := net.ReadMsg()
+	if err != nil {
+		t.Fatalf("failed to read relayed transactions: %v", err)
+	}
+	var packet ptx.TransactionsPacket
+	if err := msg.Decode(&packet); err != nil {
+		t.Fatalf("failed to decode relayed transactions: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("failed to submit private transaction: %v", err)
+	}
+	if len(packet.Transactions) != 1 || packet.Transactions[0].Hash() != private.Hash() {
+		t.Fatalf("relayed transactions mismatch: have %v, want %x", packet.Transactions, private.Hash())
+	}
+	if packet.Expiry != 2 {
+		t.Errorf("relayed expiry mismatch: have %d, want %d", packet.Expiry, 2)
+	}
+	if !handler.txpool.Has(private.Hash()) {
+		t.Fatalf("private transaction missing from the pool")
+	}
+	if txs := relay.filter(types.Transactions{private, public}); len(txs) != 1 || txs[0] != public {
+		t.Errorf("filtered transactions mismatch: have %v, want %x", txs, public.Hash())
+	}
+
+	// The transaction stays private until it expires
+	relay.expire(1)
+	if len(broadcast) != 0 || !relay.isPrivate(private.Hash()) {
+		t.Fatalf("private transaction published before expiry")
+	}
+	relay.expire(2)
+	if len(broadcast) != 1 || broadcast[0] != private {
+		t.Fatalf("expired transaction not broadcast: %v", broadcast)
+	}
+	if relay.isPrivate(private.Hash()) {
+		t.Errorf("expired transaction still private")
+	}
+}
+
+// Tests that private transactions are only accepted from trusted peers.
+func TestPrivateTxRelayUntrusted(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	relay := newPrivateTxRelay(handler.chain, handler.txpool, nil, 2, func(types.Transactions) {})
+
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+
+	peer := ptx.NewPeer(ptx.PTX1, p2p.NewPeer(enode.ID{1}, "untrusted", nil), nil)
+	err := relay.handle(peer, &ptx.TransactionsPacket{Expiry: 10, Transactions: types.Transactions{tx}})
+	if !errors.Is(err, errUntrustedPrivateTxs) {
+		t.Fatalf("error mismatch: have %v, want %v", err, errUntrustedPrivateTxs)
+	}
+	if handler.txpool.Has(tx.Hash()) || relay.isPrivate(tx.Hash()) {
+		t.Errorf("transaction from untrusted peer accepted")
+	}
+}
+
+// feedingTxPool is a transaction pool which feeds the added transactions to the
+// relay filter synchronously, like the broadcast loop of a blocking pool feed.
+type feedingTxPool struct {
+	txPool
+	relay  *privateTxRelay
+	fed    types.Transactions
+	reject error // Error to reject all added transactions with
+}
+
+func (p *feedingTxPool) Add(txs []*types.Transaction, local bool, sync bool) []error {
+	if p.reject != nil {
+		errs := make([]error, len(txs))
+		for i := range errs {
+			errs[i] = p.reject
+		}
+		return errs
+	}
+	errs := p.txPool.Add(txs, local, sync)
+	p.fed = append(p.fed, p.relay.filter(txs)...)
+	return errs
+}
+
+// Tests that the relay doesn't hold its lock while adding transactions to the
+// pool, which may filter them through the relay before returning.
+func TestPrivateTxRelayPoolFeed(t *testing.T) {
+	t.Parallel()
+
+	handler := newTestHandler()
+	defer handler.close()
+
+	pool := &feedingTxPool{txPool: handler.txpool}
+	relay := newPrivateTxRelay(handler.chain, pool, nil, 2, func(types.Transactions) {})
+	pool.relay = relay
+
+	tx := types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+
+	done := make(chan error, 1)
+	go func() { done <- relay.submit(tx, 0) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("failed to submit private transaction: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("relay deadlocked on the pool feed")
+	}
+	if len(pool.fed) != 0 {
+		t.Errorf("private transaction fed as public: %v", pool.fed)
+	}
+	// A rejected resubmission keeps the original private transaction
+	pool.reject = errors.New("rejected")
+	if err := relay.submit(tx, 0); !errors.Is(err, pool.reject) {
+		t.Fatalf("resubmission error mismatch: have %v, want %v", err, pool.reject)
+	}
+	if !relay.isPrivate(tx.Hash()) {
+		t.Errorf("rejected resubmission dropped the private transaction")
+	}
+	// A rejected new transaction isn't kept private
+	other := types.NewTransaction(1, common.Address{}, big.NewInt(0), 100000, big.NewInt(0), nil)
+	other, _ = types.SignTx(other, types.HomesteadSigner{}, testKey)
+	if err := relay.submit(other, 0); !errors.Is(err, pool.reject) {
+		t.Fatalf("submission error mismatch: have %v, want %v", err, pool.reject)
+	}
+	if relay.isPrivate(other.Hash()) {
+		t.Errorf("rejected transaction kept private")
+	}
+}
@@ -1145,14 +1145,19 @@ trace_traceTransaction
 var allRPCMethods = []string{
 	"admin_addPeer",
 	"admin_addTrustedPeer",
+	"admin_banPeer",
 	"admin_datadir",
 	"admin_ecbp1100",
 	"admin_exportChain",
+	"admin_forkFilterStats",
 	"admin_importChain",
 	"admin_maxPeers",
 	"admin_nodeInfo",
+	"admin_peerScores",
+	"admin_peerTraffic",
 	"admin_peers",
 	"admin_peerEvents",
+	"admin_reachability",
 	"admin_removePeer",
 	"admin_removeTrustedPeer",
 	"admin_startHTTP",
@@ -1161,16 +1166,20 @@ var allRPCMethods = []string{
 	"admin_stopHTTP",
 	"admin_stopRPC",
 	"admin_stopWS",
+	"admin_unbanPeer",
 	"debug_accountRange",
 	"debug_blockProfile",
+	"debug_blockPropagation",
 	"debug_chaindbCompact",
 	"debug_chaindbProperty",
+	"debug_chtRoot",
 	"debug_cpuProfile",
 	"debug_dbAncient",
 	"debug_dbAncients",
 	"debug_dbGet",
 	"debug_discoveryV4Table",
 	"debug_dumpBlock",
+	"debug_finalityWindow",
 	"debug_freeOSMemory",
 	"debug_gcStats",
 	"debug_getAccessibleState",
@@ -1186,8 +1195,10 @@ var allRPCMethods = []string{
 	"debug_intermediateRoots",
 	"debug_memStats",
 	"debug_mutexProfile",
+	"debug_orphanStats",
 	"debug_preimage",
 	"debug_printBlock",
+	"debug_reorgPenalty",
 	"debug_seedHash",
 	"debug_setBlockProfileRate",
 	"debug_setGCPercent",
@@ -1226,6 +1237,7 @@ var allRPCMethods = []string{
 	"eth_createAccessList",
 	"eth_estimateGas",
 	"eth_etherbase",
+	"eth_feeEstimate",
 	"eth_feeHistory",
 	"eth_fillTransaction",
 	"eth_gasPrice",
@@ -1270,6 +1282,7 @@ var allRPCMethods = []string{
 	"eth_newPendingTransactions",
 	"eth_pendingTransactions",
 	"eth_resend",
+	"eth_sendPrivateRawTransaction",
 	"eth_sendRawTransaction",
 	"eth_sendTransaction",
 	"eth_sign",
@@ -1293,6 +1306,7 @@ var allRPCMethods = []string{
 	"miner_stop",
 	"net_listening",
 	"net_peerCount",
+	"net_reachability",
 	"net_version",
 	"personal_deriveAccount",
 	"personal_ecRecover",
@@ -1312,6 +1326,10 @@ var allRPCMethods = []string{
 	"trace_call",
 	"trace_callMany",
 	"trace_filter",
+	"trace_get",
+	"trace_rawTransaction",
+	"trace_replayBlockTransactions",
+	"trace_replayTransaction",
 	"trace_subscribe",
 	"trace_transaction",
 	"trace_unsubscribe",
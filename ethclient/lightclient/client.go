@@ -0,0 +1,481 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package lightclient implements a light client of proof-of-work chains on top
+// of the lhp protocol. It follows the chain of headers served by full nodes,
+// verifying their proof-of-work and total difficulty locally, and verifies the
+// state proofs they serve against the verified headers.
+package lightclient
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/protocols/lhp"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/ethereum/go-ethereum/trie/trienode"
+)
+
+const (
+	// headersBatch is the number of headers requested at once while following
+	// the chain.
+	headersBatch = 192
+
+	// resyncDepth is the number of blocks below the head refetched on every
+	// sync, following the reorganisations up to this depth without walking
+	// back to the common ancestor.
+	resyncDepth = 16
+)
+
+var (
+	errNoServers       = errors.New("no light servers configured")
+	errNoEngine        = errors.New("no consensus engine configured")
+	errUnknownAncestor = errors.New("served headers don't extend the verified chain")
+	errBrokenChain     = errors.New("served headers are not a chain")
+	errInvalidTD       = errors.New("served total difficulty doesn't match the headers")
+	errSectionMissing  = errors.New("server doesn't serve the checkpoint section")
+	errStateMissing    = errors.New("server doesn't serve the state of the block")
+)
+
+// Checkpoint is a trusted CHT root, from which the client proves the headers up
+// to the end of its section instead of verifying them from the genesis.
+type Checkpoint struct {
+	Section uint64
+	Root    common.Hash
+}
+
+// Config are the settings of a light client.
+type Config struct {
+	Genesis    *genesisT.Genesis // Genesis of the chain to follow
+	NetworkID  uint64            // Network identifier of the servers
+	Engine     consensus.Engine  // Consensus engine verifying the proof-of-work
+	Servers    []*enode.Node     // Full nodes serving lhp to connect to
+	Checkpoint *Checkpoint       // Trusted CHT root to start from (nil = genesis)
+
+	// CHTSectionSize is the size of the CHT sections of the servers (0 = lhp
+	// default).
+	CHTSectionSize uint64
+
+	// PrivateKey is the node key of the client (nil = ephemeral).
+	PrivateKey *ecdsa.PrivateKey
+}
+
+// Client is a light client following the chain served by lhp servers.
+type Client struct {
+	config      Config
+	genesis     *types.Block
+	forkFilter  forkid.Filter
+	sectionSize uint64
+
+	server *p2p.Server
+	store  *headerStore
+	anchor uint64 // Number of the earliest header verified by proof-of-work
+
+	peersLock sync.Mutex
+	peers     map[string]*lhp.Peer
+	peersCh   chan struct{} // Closed when a peer joins
+
+	syncLock sync.Mutex
+}
+
+// New creates a light client and starts connecting to the servers.
+func New(config Config) (*Client, error) {
+	if len(config.Servers) == 0 {
+		return nil, errNoServers
+	}
+	c, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+	key := config.PrivateKey
+	if key == nil {
+		if key, err = crypto.GenerateKey(); err != nil {
+			return nil, err
+		}
+	}
+	var protocols []p2p.Protocol
+	for _, version := range lhp.ProtocolVersions {
+		version := version // Closure
+
+		protocols = append(protocols, p2p.Protocol{
+			Name:    lhp.ProtocolName,
+			Version: version,
+			Length:  uint64(lhp.ProofsMsg + 1),
+			Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+				return c.runPeer(lhp.NewPeer(version, p, rw))
+			},
+		})
+	}
+	c.server = &p2p.Server{Config: p2p.Config{
+		PrivateKey:  key,
+		MaxPeers:    len(config.Servers),
+		DialRatio:   1,
+		NoDiscovery: true,
+		StaticNodes: config.Servers,
+		Protocols:   protocols,
+		Name:        "lightclient",
+	}}
+	if err := c.server.Start(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newClient creates a light client without networking.
+func newClient(config Config) (*Client, error) {
+	if config.Engine == nil {
+		return nil, errNoEngine
+	}
+	genesis := core.GenesisToBlock(config.Genesis, nil)
+	c := &Client{
+		config:      config,
+		genesis:     genesis,
+		forkFilter:  forkid.NewStaticFilter(config.Genesis.Config, genesis),
+		sectionSize: config.CHTSectionSize,
+		store:       newHeaderStore(config.Genesis.Config, genesis.Header(), genesis.Difficulty()),
+		peers:       make(map[string]*lhp.Peer),
+		peersCh:     make(chan struct{}),
+	}
+	if c.sectionSize == 0 {
+		c.sectionSize = lhp.CHTSectionSize
+	}
+	return c, nil
+}
+
+// Close disconnects from the servers.
+func (c *Client) Close() {
+	if c.server != nil {
+		c.server.Stop()
+	}
+}
+
+// runPeer manages the lifecycle of a server connection.
+func (c *Client) runPeer(peer *lhp.Peer) error {
+	head := c.store.CurrentHeader()
+	status := lhp.StatusPacket{
+		NetworkID: c.config.NetworkID,
+		Genesis:   c.genesis.Hash(),
+		ForkID:    forkid.NewID(c.config.Genesis.Config, c.genesis, head.Number.Uint64(), head.Time),
+	}
+	if err := peer.Handshake(status, c.forkFilter); err != nil {
+		peer.Log().Debug("Light handshake failed", "err", err)
+		return err
+	}
+	c.peersLock.Lock()
+	c.peers[peer.ID()] = peer
+	close(c.peersCh)
+	c.peersCh = make(chan struct{})
+	c.peersLock.Unlock()
+
+	defer func() {
+		c.peersLock.Lock()
+		delete(c.peers, peer.ID())
+		c.peersLock.Unlock()
+	}()
+	return lhp.HandleResponses(peer)
+}
+
+// peer returns a connected server, waiting for one if needed.
+func (c *Client) peer(ctx context.Context) (*lhp.Peer, error) {
+	for {
+		c.peersLock.Lock()
+		for _, peer := range c.peers {
+			c.peersLock.Unlock()
+			return peer, nil
+		}
+		ch := c.peersCh
+		c.peersLock.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Head follows the chain to the head of a server and returns it.
+func (c *Client) Head(ctx context.Context) (*types.Header, error) {
+	if err := c.sync(ctx); err != nil {
+		return nil, err
+	}
+	return c.store.CurrentHeader(), nil
+}
+
+// HeaderByNumber returns a verified canonical header. The latest header is
+// returned if number is nil.
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return c.Head(ctx)
+	}
+	if !number.IsUint64() {
+		return nil, ethereum.NotFound
+	}
+	n := number.Uint64()
+	if err := c.anchorCheckpoint(ctx); err != nil {
+		return nil, err
+	}
+	if n < c.anchor {
+		if n > 0 {
+			header, _, err := c.proveHeader(ctx, n)
+			return header, err
+		}
+		return c.genesis.Header(), nil
+	}
+	if header := c.store.GetHeaderByNumber(n); header != nil {
+		return header, nil
+	}
+	if err := c.sync(ctx); err != nil {
+		return nil, err
+	}
+	if header := c.store.GetHeaderByNumber(n); header != nil {
+		return header, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+// sync follows the chain of a server from the verified head, verifying the
+// linkage, the total difficulty and the proof-of-work of its headers.
+func (c *Client) sync(ctx context.Context) error {
+	if err := c.anchorCheckpoint(ctx); err != nil {
+		return err
+	}
+	peer, err := c.peer(ctx)
+	if err != nil {
+		return err
+	}
+	c.syncLock.Lock()
+	defer c.syncLock.Unlock()
+
+	origin := c.store.CurrentHeader().Number.Uint64()
+	origin -= min(resyncDepth, origin-c.anchor)
+	step := uint64(resyncDepth)
+	for {
+		res, err := peer.RequestHeaders(ctx, origin+1, headersBatch)
+		if err != nil {
+			return err
+		}
+		if len(res.Headers) == 0 {
+			return nil
+		}
+		if err := c.verifyHeaders(res); err != nil {
+			// The server reorganised deeper than the refetched blocks, walk back
+			// with growing steps until its headers link to a verified one. Only a
+			// chain forking below the anchor is useless.
+			if errors.Is(err, errUnknownAncestor) && origin > c.anchor {
+				step *= 2
+				origin -= min(step, origin-c.anchor)
+				continue
+			}
+			peer.Disconnect(p2p.DiscUselessPeer)
+			return err
+		}
+		c.store.insert(res.Headers)
+		if len(res.Headers) < headersBatch {
+			return nil
+		}
+		origin += headersBatch
+	}
+}
+
+// verifyHeaders checks a batch of served headers against the verified chain.
+func (c *Client) verifyHeaders(res *lhp.HeadersPacket) error {
+	first := res.Headers[0]
+	td := c.store.GetTd(first.ParentHash, first.Number.Uint64()-1)
+	if td == nil {
+		return errUnknownAncestor
+	}
+	if res.TD == nil || td.Add(td, first.Difficulty).Cmp(res.TD) != 0 {
+		return errInvalidTD
+	}
+	for i := 1; i < len(res.Headers); i++ {
+		if res.Headers[i].ParentHash != res.Headers[i-1].Hash() {
+			return errBrokenChain
+		}
+	}
+	seals := make([]bool, len(res.Headers))
+	for i := range seals {
+		seals[i] = true
+	}
+	abort, results := c.config.Engine.VerifyHeaders(c.store, res.Headers, seals)
+	defer close(abort)
+
+	for i := range res.Headers {
+		if err := <-results; err != nil {
+			return fmt.Errorf("invalid header %d: %w", res.Headers[i].Number, err)
+		}
+	}
+	return nil
+}
+
+// anchorCheckpoint anchors the verified chain at the end of the checkpoint
+// section, proven against the trusted CHT root, unless it is already.
+func (c *Client) anchorCheckpoint(ctx context.Context) error {
+	if c.config.Checkpoint == nil {
+		return nil
+	}
+	c.syncLock.Lock()
+	defer c.syncLock.Unlock()
+
+	number := (c.config.Checkpoint.Section+1)*c.sectionSize - 1
+	if c.anchor == number {
+		return nil
+	}
+	header, td, err := c.proveHeader(ctx, number)
+	if err != nil {
+		return err
+	}
+	c.store.reset(header, td)
+	c.anchor = number
+	return nil
+}
+
+// proveHeader fetches a canonical header covered by the checkpoint, verifying
+// it against the trusted CHT root.
+func (c *Client) proveHeader(ctx context.Context, number uint64) (*types.Header, *big.Int, error) {
+	peer, err := c.peer(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	checkpoint := c.config.Checkpoint
+	if peer.CHTSections() <= checkpoint.Section {
+		return nil, nil, errSectionMissing
+	}
+	res, err := peer.RequestCHTProofs(ctx, checkpoint.Section, []uint64{number})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(res.Headers) != 1 {
+		return nil, nil, fmt.Errorf("%w: header %d not proven", errSectionMissing, number)
+	}
+	node, err := lhp.VerifyCHTProof(checkpoint.Root, number, proofSet(res.Proof))
+	if err != nil {
+		return nil, nil, err
+	}
+	if header := res.Headers[0]; header.Hash() == node.Hash {
+		return header, node.TD, nil
+	}
+	return nil, nil, fmt.Errorf("served header %d doesn't match the CHT", number)
+}
+
+// AccountResult is the verified state of an account and of some of its storage
+// slots.
+type AccountResult struct {
+	Address     common.Address
+	Balance     *big.Int
+	CodeHash    common.Hash
+	Nonce       uint64
+	StorageHash common.Hash
+	Storage     []StorageResult
+}
+
+// StorageResult is the verified value of a storage slot.
+type StorageResult struct {
+	Key   common.Hash
+	Value common.Hash
+}
+
+// GetProof returns the state of an account and of some of its storage slots at
+// a block, verified against the state root of the block. The latest block is
+// used if blockNumber is nil. Servers only serve the recent states they keep.
+func (c *Client) GetProof(ctx context.Context, account common.Address, keys []common.Hash, blockNumber *big.Int) (*AccountResult, error) {
+	header, err := c.HeaderByNumber(ctx, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	peer, err := c.peer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := peer.RequestProofs(ctx, header.Hash(), account, keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(res.AccountProof) == 0 {
+		return nil, errStateMissing
+	}
+	return verifyProofs(header.Root, account, keys, res)
+}
+
+// verifyProofs verifies the proofs of an account and of its storage slots
+// against a state root.
+func verifyProofs(root common.Hash, account common.Address, keys []common.Hash, res *lhp.ProofsPacket) (*AccountResult, error) {
+	blob, err := trie.VerifyProof(root, crypto.Keccak256(account.Bytes()), proofSet(res.AccountProof))
+	if err != nil {
+		return nil, fmt.Errorf("invalid account proof: %w", err)
+	}
+	result := &AccountResult{
+		Address:     account,
+		Balance:     new(big.Int),
+		CodeHash:    types.EmptyCodeHash,
+		StorageHash: types.EmptyRootHash,
+	}
+	if len(blob) > 0 {
+		var state types.StateAccount
+		if err := rlp.DecodeBytes(blob, &state); err != nil {
+			return nil, fmt.Errorf("invalid account: %w", err)
+		}
+		result.Balance = state.Balance.ToBig()
+		result.CodeHash = common.BytesToHash(state.CodeHash)
+		result.Nonce = state.Nonce
+		result.StorageHash = state.Root
+	}
+	if len(res.StorageProofs) != len(keys) {
+		return nil, fmt.Errorf("got %d storage proofs, want %d", len(res.StorageProofs), len(keys))
+	}
+	for i, key := range keys {
+		slot := StorageResult{Key: key}
+		if result.StorageHash != types.EmptyRootHash {
+			blob, err := trie.VerifyProof(result.StorageHash, crypto.Keccak256(key.Bytes()), proofSet(res.StorageProofs[i]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid storage proof of %x: %w", key, err)
+			}
+			if len(blob) > 0 {
+				_, content, _, err := rlp.Split(blob)
+				if err != nil {
+					return nil, fmt.Errorf("invalid storage value of %x: %w", key, err)
+				}
+				slot.Value = common.BytesToHash(content)
+			}
+		}
+		result.Storage = append(result.Storage, slot)
+	}
+	return result, nil
+}
+
+// proofSet converts served proof nodes into a database to verify proofs with.
+func proofSet(nodes [][]byte) *trienode.ProofSet {
+	proof := make(trienode.ProofList, len(nodes))
+	for i, node := range nodes {
+		proof[i] = node
+	}
+	return proof.Set()
+}
@@ -0,0 +1,313 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/forkid"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/protocols/lhp"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+const testSectionSize = 16
+
+var (
+	testAddr     = common.HexToAddress("0x71562b71999873db5b286df957af199ec94617f7")
+	testBalance  = big.NewInt(1_000_000_000_000_000_000)
+	testContract = common.HexToAddress("0x000000000000000000000000000000000000c0de")
+	testSlot     = common.HexToHash("0x01")
+	testValue    = common.HexToHash("0x2a")
+
+	testGenesis = &genesisT.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: genesisT.GenesisAlloc{
+			testAddr:     {Balance: testBalance},
+			testContract: {Balance: new(big.Int), Code: []byte{0x00}, Storage: map[common.Hash]common.Hash{testSlot: testValue}},
+		},
+	}
+)
+
+// testServer is a full node serving lhp.
+type testServer struct {
+	chain *core.BlockChain
+	cht   *lhp.CHT
+}
+
+func newTestServer(t *testing.T, blocks int) *testServer {
+	t.Helper()
+
+	db := rawdb.NewMemoryDatabase()
+	chain, err := core.NewBlockChain(db, nil, testGenesis, nil, ethash.NewFaker(), vm.Config{}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	_, bs, _ := core.GenerateChainWithGenesis(testGenesis, ethash.NewFaker(), blocks, nil)
+	if _, err := chain.InsertChain(bs); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	cht := lhp.NewCHT(db, testSectionSize, 1)
+	cht.Start(chain)
+
+	s := &testServer{chain: chain, cht: cht}
+	t.Cleanup(func() {
+		cht.Close()
+		chain.Stop()
+	})
+	sections := (uint64(blocks) + 1) / testSectionSize
+	for deadline := time.Now().Add(5 * time.Second); cht.Sections() < sections; {
+		if time.Now().After(deadline) {
+			t.Fatalf("CHT sections not indexed: have %d, want %d", cht.Sections(), sections)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return s
+}
+
+func (s *testServer) Chain() *core.BlockChain { return s.chain }
+func (s *testServer) CHT() *lhp.CHT           { return s.cht }
+
+func (s *testServer) RunPeer(peer *lhp.Peer, hand lhp.Handler) error {
+	head := s.chain.CurrentHeader()
+	status := lhp.StatusPacket{
+		NetworkID:   1,
+		Genesis:     s.chain.Genesis().Hash(),
+		ForkID:      forkid.NewID(s.chain.Config(), s.chain.Genesis(), head.Number.Uint64(), head.Time),
+		Head:        head.Hash(),
+		HeadNumber:  head.Number.Uint64(),
+		TD:          new(big.Int).Set(s.chain.GetTd(head.Hash(), head.Number.Uint64())),
+		CHTSections: s.cht.Sections(),
+	}
+	if err := peer.Handshake(status, forkid.NewFilter(s.chain)); err != nil {
+		return err
+	}
+	return hand(peer)
+}
+
+// newTestClient creates a light client connected to the server.
+func newTestClient(t *testing.T, server *testServer, checkpoint *Checkpoint) *Client {
+	t.Helper()
+
+	client, err := newClient(Config{
+		Genesis:        testGenesis,
+		NetworkID:      1,
+		Engine:         ethash.NewFaker(),
+		Checkpoint:     checkpoint,
+		CHTSectionSize: testSectionSize,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	app, net := p2p.MsgPipe()
+	t.Cleanup(func() {
+		app.Close()
+		net.Close()
+	})
+	go server.RunPeer(lhp.NewPeer(lhp.LHP1, p2p.NewPeer(enode.ID{2}, "client", nil), net), func(peer *lhp.Peer) error {
+		return lhp.Handle(server, peer)
+	})
+	go client.runPeer(lhp.NewPeer(lhp.LHP1, p2p.NewPeer(enode.ID{1}, "server", nil), app))
+	return client
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// Tests that the client follows the chain of the server from the genesis.
+func TestHead(t *testing.T) {
+	server := newTestServer(t, 250) // More than a batch of headers
+	client := newTestClient(t, server, nil)
+	ctx := testContext(t)
+
+	head, err := client.Head(ctx)
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if want := server.chain.CurrentHeader(); head.Hash() != want.Hash() {
+		t.Fatalf("head mismatch: have %d %x, want %d %x", head.Number, head.Hash(), want.Number, want.Hash())
+	}
+	for _, number := range []uint64{0, 1, 100, 250} {
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve header: %v", number, err)
+		}
+		if want := server.chain.GetHeaderByNumber(number).Hash(); header.Hash() != want {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", number, header.Hash(), want)
+		}
+	}
+	if _, err := client.HeaderByNumber(ctx, big.NewInt(251)); !errors.Is(err, ethereum.NotFound) {
+		t.Errorf("future block error mismatch: have %v, want %v", err, ethereum.NotFound)
+	}
+}
+
+// Tests that the client follows a reorganisation of the server deeper than the
+// refetched blocks, walking back to the common ancestor.
+func TestDeepReorg(t *testing.T) {
+	server := newTestServer(t, 40)
+	client := newTestClient(t, server, nil)
+	ctx := testContext(t)
+
+	if _, err := client.Head(ctx); err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	// Fork off the chain of the server at block 10 with a longer chain.
+	_, fork, _ := core.GenerateChainWithGenesis(testGenesis, ethash.NewFaker(), 60, func(i int, b *core.BlockGen) {
+		if i >= 10 {
+			b.SetCoinbase(common.Address{0x01})
+		}
+	})
+	if _, err := server.chain.InsertChain(fork[10:]); err != nil {
+		t.Fatalf("failed to insert fork: %v", err)
+	}
+	head, err := client.Head(ctx)
+	if err != nil {
+		t.Fatalf("failed to follow the reorg: %v", err)
+	}
+	if want := server.chain.CurrentHeader(); head.Hash() != want.Hash() {
+		t.Fatalf("head mismatch: have %d %x, want %d %x", head.Number, head.Hash(), want.Number, want.Hash())
+	}
+	for _, number := range []uint64{10, 11, 40} {
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve header: %v", number, err)
+		}
+		if want := fork[number-1].Hash(); header.Hash() != want {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", number, header.Hash(), want)
+		}
+	}
+}
+
+// Tests that the client starts from a checkpoint, proving the older headers
+// against its CHT root.
+func TestCheckpoint(t *testing.T) {
+	server := newTestServer(t, 40)
+	checkpoint := &Checkpoint{Section: 1, Root: server.cht.Root(1)}
+	client := newTestClient(t, server, checkpoint)
+	ctx := testContext(t)
+
+	head, err := client.Head(ctx)
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if head.Number.Uint64() != 40 {
+		t.Fatalf("head mismatch: have %d, want 40", head.Number)
+	}
+	if client.anchor != 2*testSectionSize-1 {
+		t.Errorf("anchor mismatch: have %d, want %d", client.anchor, 2*testSectionSize-1)
+	}
+	for _, number := range []uint64{0, 5, 20, 31, 35} {
+		header, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve header: %v", number, err)
+		}
+		if want := server.chain.GetHeaderByNumber(number).Hash(); header.Hash() != want {
+			t.Errorf("block %d: hash mismatch: have %x, want %x", number, header.Hash(), want)
+		}
+	}
+	// A wrong checkpoint doesn't verify
+	client = newTestClient(t, server, &Checkpoint{Section: 1, Root: server.cht.Root(0)})
+	if _, err := client.Head(ctx); err == nil {
+		t.Errorf("wrong checkpoint accepted")
+	}
+}
+
+// Tests that the state served is verified against the headers.
+func TestGetProof(t *testing.T) {
+	server := newTestServer(t, 10)
+	client := newTestClient(t, server, nil)
+	ctx := testContext(t)
+
+	res, err := client.GetProof(ctx, testAddr, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve account: %v", err)
+	}
+	if res.Balance.Cmp(testBalance) != 0 || res.StorageHash != types.EmptyRootHash {
+		t.Errorf("account mismatch: balance %v, storage %x", res.Balance, res.StorageHash)
+	}
+	res, err = client.GetProof(ctx, testContract, []common.Hash{testSlot, {0xff}}, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("failed to retrieve contract: %v", err)
+	}
+	if len(res.Storage) != 2 || res.Storage[0].Value != testValue || res.Storage[1].Value != (common.Hash{}) {
+		t.Errorf("storage mismatch: %v", res.Storage)
+	}
+	res, err = client.GetProof(ctx, common.HexToAddress("0xdead"), []common.Hash{testSlot}, nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve missing account: %v", err)
+	}
+	if res.Balance.Sign() != 0 || res.Nonce != 0 || res.CodeHash != types.EmptyCodeHash || res.Storage[0].Value != (common.Hash{}) {
+		t.Errorf("missing account not empty: %+v", res)
+	}
+	// Proofs against another state root don't verify
+	head := server.chain.CurrentHeader()
+	proofs := lhp.ServiceGetProofsQuery(server.chain, &lhp.GetProofsPacket{BlockHash: head.Hash(), Address: testAddr})
+	if _, err := verifyProofs(head.Root, testAddr, nil, proofs); err != nil {
+		t.Fatalf("failed to verify proof: %v", err)
+	}
+	if _, err := verifyProofs(server.chain.Genesis().Root(), testAddr, nil, proofs); err == nil {
+		t.Errorf("proof verified against wrong root")
+	}
+}
+
+// Tests that served headers are rejected unless they extend the verified chain
+// with the total difficulty claimed.
+func TestVerifyHeaders(t *testing.T) {
+	server := newTestServer(t, 10)
+	client := newTestClient(t, server, nil)
+
+	res := lhp.ServiceGetHeadersQuery(server.chain, &lhp.GetHeadersPacket{Origin: 1, Amount: 5})
+	if err := client.verifyHeaders(res); err != nil {
+		t.Fatalf("valid headers rejected: %v", err)
+	}
+	res.TD = new(big.Int).Add(res.TD, common.Big1)
+	if err := client.verifyHeaders(res); !errors.Is(err, errInvalidTD) {
+		t.Errorf("total difficulty error mismatch: have %v, want %v", err, errInvalidTD)
+	}
+	res = lhp.ServiceGetHeadersQuery(server.chain, &lhp.GetHeadersPacket{Origin: 2, Amount: 5})
+	if err := client.verifyHeaders(res); !errors.Is(err, errUnknownAncestor) {
+		t.Errorf("ancestor error mismatch: have %v, want %v", err, errUnknownAncestor)
+	}
+	res = lhp.ServiceGetHeadersQuery(server.chain, &lhp.GetHeadersPacket{Origin: 1, Amount: 5})
+	res.Headers[2], res.Headers[3] = res.Headers[3], res.Headers[2]
+	if err := client.verifyHeaders(res); !errors.Is(err, errBrokenChain) {
+		t.Errorf("linkage error mismatch: have %v, want %v", err, errBrokenChain)
+	}
+	res = lhp.ServiceGetHeadersQuery(server.chain, &lhp.GetHeadersPacket{Origin: 1, Amount: 1})
+	header := types.CopyHeader(res.Headers[0])
+	header.Difficulty = new(big.Int).Add(header.Difficulty, common.Big1)
+	res.Headers[0], res.TD = header, new(big.Int).Add(server.chain.Genesis().Difficulty(), header.Difficulty)
+	if err := client.verifyHeaders(res); err == nil {
+		t.Errorf("header with wrong difficulty accepted")
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package lightclient
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+)
+
+// headerStore keeps the verified headers in memory along with their total
+// difficulty, tracking the canonical chain by total difficulty. It implements
+// consensus.ChainHeaderReader to verify the headers extending it.
+type headerStore struct {
+	config ctypes.ChainConfigurator
+
+	lock    sync.RWMutex
+	headers map[common.Hash]*types.Header
+	tds     map[common.Hash]*big.Int
+	canon   map[uint64]common.Hash
+	head    *types.Header
+}
+
+// newHeaderStore creates a store anchored at a trusted header.
+func newHeaderStore(config ctypes.ChainConfigurator, anchor *types.Header, td *big.Int) *headerStore {
+	s := &headerStore{
+		config:  config,
+		headers: make(map[common.Hash]*types.Header),
+		tds:     make(map[common.Hash]*big.Int),
+		canon:   make(map[uint64]common.Hash),
+	}
+	s.reset(anchor, td)
+	return s
+}
+
+// reset drops all the headers, anchoring the store at a trusted header.
+func (s *headerStore) reset(anchor *types.Header, td *big.Int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	hash := anchor.Hash()
+	s.headers = map[common.Hash]*types.Header{hash: anchor}
+	s.tds = map[common.Hash]*big.Int{hash: new(big.Int).Set(td)}
+	s.canon = map[uint64]common.Hash{anchor.Number.Uint64(): hash}
+	s.head = anchor
+}
+
+// insert adds a batch of verified headers, each the child of the previous and
+// the first a child of a stored header. If the last header has the highest
+// total difficulty, it becomes the new head.
+func (s *headerStore) insert(headers []*types.Header) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	td := new(big.Int).Set(s.tds[headers[0].ParentHash])
+	for _, header := range headers {
+		td.Add(td, header.Difficulty)
+		hash := header.Hash()
+		s.headers[hash] = header
+		s.tds[hash] = new(big.Int).Set(td)
+	}
+	last := headers[len(headers)-1]
+	if td.Cmp(s.tds[s.head.Hash()]) <= 0 {
+		return
+	}
+	// Reorganise the canonical chain onto the new head
+	for number := last.Number.Uint64() + 1; number <= s.head.Number.Uint64(); number++ {
+		delete(s.canon, number)
+	}
+	for header := last; header != nil; header = s.headers[header.ParentHash] {
+		number, hash := header.Number.Uint64(), header.Hash()
+		if s.canon[number] == hash {
+			break
+		}
+		s.canon[number] = hash
+	}
+	s.head = last
+}
+
+// Config retrieves the chain configuration.
+func (s *headerStore) Config() ctypes.ChainConfigurator {
+	return s.config
+}
+
+// CurrentHeader retrieves the head of the canonical chain.
+func (s *headerStore) CurrentHeader() *types.Header {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.head
+}
+
+// GetHeader retrieves a verified header by hash and number.
+func (s *headerStore) GetHeader(hash common.Hash, number uint64) *types.Header {
+	header := s.GetHeaderByHash(hash)
+	if header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return header
+}
+
+// GetHeaderByNumber retrieves a canonical header by number.
+func (s *headerStore) GetHeaderByNumber(number uint64) *types.Header {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	hash, ok := s.canon[number]
+	if !ok {
+		return nil
+	}
+	return s.headers[hash]
+}
+
+// GetHeaderByHash retrieves a verified header by hash.
+func (s *headerStore) GetHeaderByHash(hash common.Hash) *types.Header {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.headers[hash]
+}
+
+// GetTd retrieves the total difficulty of a verified header.
+func (s *headerStore) GetTd(hash common.Hash, number uint64) *big.Int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if header := s.headers[hash]; header == nil || header.Number.Uint64() != number {
+		return nil
+	}
+	return new(big.Int).Set(s.tds[hash])
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/fetcher"
 	ethproto "github.com/ethereum/go-ethereum/eth/protocols/eth"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
@@ -56,6 +57,9 @@ const (
 	txChanSize = 4096
 	// chainHeadChanSize is the size of channel listening to ChainHeadEvent.
 	chainHeadChanSize = 10
+	// propagationChanSize is the size of channel listening to block propagation
+	// events.
+	propagationChanSize = 64
 
 	messageSizeLimit = 15 * 1024 * 1024
 )
@@ -80,6 +84,12 @@ type fullNodeBackend interface {
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
 }
 
+// propagationBackend is implemented by full nodes able to report the propagation
+// of blocks received from the network.
+type propagationBackend interface {
+	SubscribeBlockPropagation(ch chan<- fetcher.BlockPropagation) event.Subscription
+}
+
 // miningNodeBackend encompasses the functionality necessary for a mining node
 // reporting to ethstats
 type miningNodeBackend interface {
@@ -101,8 +111,11 @@ type Service struct {
 	pongCh chan struct{} // Pong notifications are fed into this channel
 	histCh chan []uint64 // History request block numbers are fed into this channel
 
+	propagation bool // Whether to report the propagation of received blocks
+
 	headSub event.Subscription
 	txSub   event.Subscription
+	propSub event.Subscription
 }
 
 // connWrapper is a wrapper to prevent concurrent-write or concurrent-read on the
@@ -179,8 +192,9 @@ func parseEthstatsURL(url string) (parts []string, err error) {
 	return []string{nodename, pass, host}, nil
 }
 
-// New returns a monitoring service ready for stats reporting.
-func New(node *node.Node, backend backend, engine consensus.Engine, url string) error {
+// New returns a monitoring service ready for stats reporting. If propagation is
+// set, the propagation of blocks received from the network is reported too.
+func New(node *node.Node, backend backend, engine consensus.Engine, url string, propagation bool) error {
 	parts, err := parseEthstatsURL(url)
 	if err != nil {
 		return err
@@ -194,6 +208,8 @@ func New(node *node.Node, backend backend, engine consensus.Engine, url string)
 		host:    parts[2],
 		pongCh:  make(chan struct{}),
 		histCh:  make(chan []uint64, 1),
+
+		propagation: propagation,
 	}
 
 	node.RegisterLifecycle(ethstats)
@@ -207,7 +223,16 @@ func (s *Service) Start() error {
 	s.headSub = s.backend.SubscribeChainHeadEvent(chainHeadCh)
 	txEventCh := make(chan core.NewTxsEvent, txChanSize)
 	s.txSub = s.backend.SubscribeNewTxsEvent(txEventCh)
-	go s.loop(chainHeadCh, txEventCh)
+	var propCh chan fetcher.BlockPropagation
+	if s.propagation {
+		if backend, ok := s.backend.(propagationBackend); ok {
+			propCh = make(chan fetcher.BlockPropagation, propagationChanSize)
+			s.propSub = backend.SubscribeBlockPropagation(propCh)
+		} else {
+			log.Warn("Block propagation reporting not supported by backend")
+		}
+	}
+	go s.loop(chainHeadCh, txEventCh, propCh)
 
 	log.Info("Stats daemon started")
 	return nil
@@ -217,18 +242,22 @@ func (s *Service) Start() error {
 func (s *Service) Stop() error {
 	s.headSub.Unsubscribe()
 	s.txSub.Unsubscribe()
+	if s.propSub != nil {
+		s.propSub.Unsubscribe()
+	}
 	log.Info("Stats daemon stopped")
 	return nil
 }
 
 // loop keeps trying to connect to the netstats server, reporting chain events
 // until termination.
-func (s *Service) loop(chainHeadCh chan core.ChainHeadEvent, txEventCh chan core.NewTxsEvent) {
+func (s *Service) loop(chainHeadCh chan core.ChainHeadEvent, txEventCh chan core.NewTxsEvent, propEventCh chan fetcher.BlockPropagation) {
 	// Start a goroutine that exhausts the subscriptions to avoid events piling up
 	var (
 		quitCh = make(chan struct{})
 		headCh = make(chan *types.Block, 1)
 		txCh   = make(chan struct{}, 1)
+		propCh = make(chan fetcher.BlockPropagation, propagationChanSize)
 	)
 	go func() {
 		var lastTx mclock.AbsTime
@@ -255,6 +284,13 @@ func (s *Service) loop(chainHeadCh chan core.ChainHeadEvent, txEventCh chan core
 				default:
 				}
 
+			// Notify of block propagation events, but drop if reporting lags
+			case prop := <-propEventCh:
+				select {
+				case propCh <- prop:
+				default:
+				}
+
 			// node stopped
 			case <-s.txSub.Err():
 				break HandleLoop
@@ -349,6 +385,10 @@ func (s *Service) loop(chainHeadCh chan core.ChainHeadEvent, txEventCh chan core
 					if err = s.reportPending(conn); err != nil {
 						log.Warn("Transaction stats report failed", "err", err)
 					}
+				case prop := <-propCh:
+					if err = s.reportPropagation(conn, prop); err != nil {
+						log.Warn("Block propagation report failed", "err", err)
+					}
 				}
 			}
 			fullReport.Stop()
@@ -628,6 +668,45 @@ func (s *Service) reportBlock(conn *connWrapper, block *types.Block) error {
 	return conn.WriteJSON(report)
 }
 
+// propagationStats is the information to report about the propagation of a
+// block received from the network. Durations are in milliseconds.
+type propagationStats struct {
+	Number      uint64      `json:"number"`
+	Hash        common.Hash `json:"hash"`
+	FirstSeen   int64       `json:"firstSeen"` // Unix milliseconds
+	Source      string      `json:"source"`
+	Announces   int         `json:"announces"`
+	Broadcasts  int         `json:"broadcasts"`
+	ArrivalGap  int64       `json:"arrivalGap"`
+	ImportDelay int64       `json:"importDelay"`
+	ImportError string      `json:"importError,omitempty"`
+}
+
+// reportPropagation reports the propagation of a block to the stats server.
+// Peer identities aren't reported.
+func (s *Service) reportPropagation(conn *connWrapper, prop fetcher.BlockPropagation) error {
+	log.Trace("Sending block propagation to ethstats", "number", prop.Number, "hash", prop.Hash)
+
+	stats := map[string]interface{}{
+		"id": s.node,
+		"propagation": &propagationStats{
+			Number:      prop.Number,
+			Hash:        prop.Hash,
+			FirstSeen:   prop.FirstSeen.UnixMilli(),
+			Source:      prop.Source,
+			Announces:   prop.Announces,
+			Broadcasts:  prop.Broadcasts,
+			ArrivalGap:  prop.ArrivalGap.Milliseconds(),
+			ImportDelay: prop.ImportDelay.Milliseconds(),
+			ImportError: prop.ImportError,
+		},
+	}
+	report := map[string][]interface{}{
+		"emit": {"block-propagation", stats},
+	}
+	return conn.WriteJSON(report)
+}
+
 // assembleBlockStats retrieves any required metadata to report a single block
 // and assembles the block stats. If block is nil, the current head is processed.
 func (s *Service) assembleBlockStats(block *types.Block) *blockStats {
@@ -77,11 +77,21 @@ func (s *EthereumAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
 		return nil, err
 	}
 	if head := s.b.CurrentHeader(); head.BaseFee != nil {
-		tipcap.Add(tipcap, head.BaseFee)
+		tipcap.Add(tipcap, flooredBaseFee(s.b.ChainConfig(), head))
 	}
 	return (*hexutil.Big)(tipcap), err
 }
 
+// flooredBaseFee returns the base fee of head, raised to the base fee floor
+// scheduled for the block after it.
+func flooredBaseFee(config ctypes.ChainConfigurator, head *types.Header) *big.Int {
+	next := new(big.Int).Add(head.Number, common.Big1)
+	if floor := ctypes.MinBaseFee(config, next); floor != nil && floor.Cmp(head.BaseFee) > 0 {
+		return floor
+	}
+	return head.BaseFee
+}
+
 // MaxPriorityFeePerGas returns a suggestion for a gas tip cap for dynamic fee transactions.
 func (s *EthereumAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
 	tipcap, err := s.b.SuggestGasTipCap(ctx)
@@ -91,6 +101,58 @@ func (s *EthereumAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, e
 	return (*hexutil.Big)(tipcap), err
 }
 
+type feeEstimate struct {
+	TargetBlocks         hexutil.Uint64 `json:"targetBlocks"`
+	Confidence           float64        `json:"confidence"`
+	BaseFee              *hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	WaitTime             float64        `json:"waitTime"`
+}
+
+type feeEstimateResult struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	NextBaseFee *hexutil.Big   `json:"nextBaseFeePerGas,omitempty"`
+	MinTip      *hexutil.Big   `json:"minPriorityFeePerGas"`
+	PendingGas  hexutil.Uint64 `json:"pendingGas"`
+	BlockTime   float64        `json:"blockTime"`
+	Estimates   []feeEstimate  `json:"estimates"`
+}
+
+// FeeEstimate returns fee recommendations for including a transaction within
+// each of the target number of blocks with each of the confidence levels,
+// taking the pending transactions and the miners' minimum tip into account.
+// Wait and block times are in seconds.
+func (s *EthereumAPI) FeeEstimate(ctx context.Context, targetBlocks []math.HexOrDecimal64, confidence []float64) (*feeEstimateResult, error) {
+	targets := make([]uint64, len(targetBlocks))
+	for i, target := range targetBlocks {
+		targets[i] = uint64(target)
+	}
+	estimates, err := s.b.FeeEstimate(ctx, targets, confidence)
+	if err != nil {
+		return nil, err
+	}
+	results := &feeEstimateResult{
+		BlockNumber: hexutil.Uint64(estimates.BlockNumber),
+		NextBaseFee: (*hexutil.Big)(estimates.NextBaseFee),
+		MinTip:      (*hexutil.Big)(estimates.MinTip),
+		PendingGas:  hexutil.Uint64(estimates.PendingGas),
+		BlockTime:   estimates.BlockTime.Seconds(),
+		Estimates:   make([]feeEstimate, len(estimates.Estimates)),
+	}
+	for i, e := range estimates.Estimates {
+		results.Estimates[i] = feeEstimate{
+			TargetBlocks:         hexutil.Uint64(e.TargetBlocks),
+			Confidence:           e.Confidence,
+			BaseFee:              (*hexutil.Big)(e.BaseFee),
+			MaxPriorityFeePerGas: (*hexutil.Big)(e.TipCap),
+			MaxFeePerGas:         (*hexutil.Big)(e.FeeCap),
+			WaitTime:             e.WaitTime.Seconds(),
+		}
+	}
+	return results, nil
+}
+
 type feeHistoryResult struct {
 	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
 	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
@@ -1454,28 +1516,29 @@ func (s *BlockChainAPI) rpcMarshalBlock(ctx context.Context, b *types.Block, inc
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash           *common.Hash      `json:"blockHash"`
-	BlockNumber         *hexutil.Big      `json:"blockNumber"`
-	From                common.Address    `json:"from"`
-	Gas                 hexutil.Uint64    `json:"gas"`
-	GasPrice            *hexutil.Big      `json:"gasPrice"`
-	GasFeeCap           *hexutil.Big      `json:"maxFeePerGas,omitempty"`
-	GasTipCap           *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
-	MaxFeePerBlobGas    *hexutil.Big      `json:"maxFeePerBlobGas,omitempty"`
-	Hash                common.Hash       `json:"hash"`
-	Input               hexutil.Bytes     `json:"input"`
-	Nonce               hexutil.Uint64    `json:"nonce"`
-	To                  *common.Address   `json:"to"`
-	TransactionIndex    *hexutil.Uint64   `json:"transactionIndex"`
-	Value               *hexutil.Big      `json:"value"`
-	Type                hexutil.Uint64    `json:"type"`
-	Accesses            *types.AccessList `json:"accessList,omitempty"`
-	ChainID             *hexutil.Big      `json:"chainId,omitempty"`
-	BlobVersionedHashes []common.Hash     `json:"blobVersionedHashes,omitempty"`
-	V                   *hexutil.Big      `json:"v"`
-	R                   *hexutil.Big      `json:"r"`
-	S                   *hexutil.Big      `json:"s"`
-	YParity             *hexutil.Uint64   `json:"yParity,omitempty"`
+	BlockHash           *common.Hash                 `json:"blockHash"`
+	BlockNumber         *hexutil.Big                 `json:"blockNumber"`
+	From                common.Address               `json:"from"`
+	Gas                 hexutil.Uint64               `json:"gas"`
+	GasPrice            *hexutil.Big                 `json:"gasPrice"`
+	GasFeeCap           *hexutil.Big                 `json:"maxFeePerGas,omitempty"`
+	GasTipCap           *hexutil.Big                 `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerBlobGas    *hexutil.Big                 `json:"maxFeePerBlobGas,omitempty"`
+	Hash                common.Hash                  `json:"hash"`
+	Input               hexutil.Bytes                `json:"input"`
+	Nonce               hexutil.Uint64               `json:"nonce"`
+	To                  *common.Address              `json:"to"`
+	TransactionIndex    *hexutil.Uint64              `json:"transactionIndex"`
+	Value               *hexutil.Big                 `json:"value"`
+	Type                hexutil.Uint64               `json:"type"`
+	Accesses            *types.AccessList            `json:"accessList,omitempty"`
+	ChainID             *hexutil.Big                 `json:"chainId,omitempty"`
+	BlobVersionedHashes []common.Hash                `json:"blobVersionedHashes,omitempty"`
+	AuthorizationList   []types.SetCodeAuthorization `json:"authorizationList,omitempty"`
+	V                   *hexutil.Big                 `json:"v"`
+	R                   *hexutil.Big                 `json:"r"`
+	S                   *hexutil.Big                 `json:"s"`
+	YParity             *hexutil.Uint64              `json:"yParity,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
@@ -1550,6 +1613,22 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		}
 		result.MaxFeePerBlobGas = (*hexutil.Big)(tx.BlobGasFeeCap())
 		result.BlobVersionedHashes = tx.BlobHashes()
+
+	case types.SetCodeTxType:
+		al := tx.AccessList()
+		yparity := hexutil.Uint64(v.Sign())
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+		result.YParity = &yparity
+		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		// if the transaction has been mined, compute the effective gas price
+		if baseFee != nil && blockHash != (common.Hash{}) {
+			result.GasPrice = (*hexutil.Big)(effectiveGasPrice(tx, baseFee))
+		} else {
+			result.GasPrice = (*hexutil.Big)(tx.GasFeeCap())
+		}
+		result.AuthorizationList = tx.SetCodeAuthorizations()
 	}
 	return result
 }
@@ -2301,6 +2380,15 @@ func (s *NetAPI) PeerCount() hexutil.Uint {
 	return hexutil.Uint(s.net.PeerCount())
 }
 
+// Reachability returns whether other nodes can connect to the node: reachable,
+// unreachable, unknown or not listening. See admin_reachability for details.
+func (s *NetAPI) Reachability() string {
+	if info := s.net.Reachability(); info != nil {
+		return info.Status
+	}
+	return p2p.ReachabilityUnknown
+}
+
 // Version returns the current ethereum protocol version.
 func (s *NetAPI) Version() string {
 	return fmt.Sprintf("%d", s.networkVersion)
@@ -143,6 +143,17 @@ web3._extend({
 			call: 'admin_removeTrustedPeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'banPeer',
+			call: 'admin_banPeer',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
+		new web3._extend.Method({
+			name: 'unbanPeer',
+			call: 'admin_unbanPeer',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -211,6 +222,22 @@ web3._extend({
 			name: 'peers',
 			getter: 'admin_peers'
 		}),
+		new web3._extend.Property({
+			name: 'peerScores',
+			getter: 'admin_peerScores'
+		}),
+		new web3._extend.Property({
+			name: 'peerTraffic',
+			getter: 'admin_peerTraffic'
+		}),
+		new web3._extend.Property({
+			name: 'forkFilterStats',
+			getter: 'admin_forkFilterStats'
+		}),
+		new web3._extend.Property({
+			name: 'reachability',
+			getter: 'admin_reachability'
+		}),
 		new web3._extend.Property({
 			name: 'datadir',
 			getter: 'admin_datadir'
@@ -512,6 +539,34 @@ web3._extend({
 			call: 'debug_getTrieFlushInterval',
 			params: 0
 		}),
+		new web3._extend.Method({
+			name: 'orphanStats',
+			call: 'debug_orphanStats',
+			params: 2,
+			inputFormatter:[web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'finalityWindow',
+			call: 'debug_finalityWindow',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'reorgPenalty',
+			call: 'debug_reorgPenalty',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter],
+		}),
+		new web3._extend.Method({
+			name: 'blockPropagation',
+			call: 'debug_blockPropagation',
+			params: 1,
+		}),
+		new web3._extend.Method({
+			name: 'chtRoot',
+			call: 'debug_chtRoot',
+			params: 1,
+			inputFormatter: [web3._extend.utils.toHex],
+		}),
 	],
 	properties: []
 });
@@ -532,6 +587,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'sendPrivateRawTransaction',
+			call: 'eth_sendPrivateRawTransaction',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'resend',
 			call: 'eth_resend',
@@ -617,6 +678,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'feeEstimate',
+			call: 'eth_feeEstimate',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'getLogs',
 			call: 'eth_getLogs',
@@ -714,6 +781,10 @@ web3._extend({
 			name: 'version',
 			getter: 'net_version'
 		}),
+		new web3._extend.Property({
+			name: 'reachability',
+			getter: 'net_reachability'
+		}),
 	]
 });
 `
@@ -831,6 +902,27 @@ web3._extend({
 				});
 			}, web3._extend.formatters.inputBlockNumberFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'replayTransaction',
+			call: 'trace_replayTransaction',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'replayBlockTransactions',
+			call: 'trace_replayBlockTransactions',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'rawTransaction',
+			call: 'trace_rawTransaction',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'get',
+			call: 'trace_get',
+			params: 2
+		}),
 	],
 	properties: []
 });
@@ -209,6 +209,11 @@ func (miner *Miner) SetGasTip(tip *big.Int) error {
 	return nil
 }
 
+// GasTip returns the minimum tip needed for including a non-local transaction.
+func (miner *Miner) GasTip() *big.Int {
+	return miner.worker.gasTip()
+}
+
 // SetRecommitInterval sets the interval for sealing work resubmitting.
 func (miner *Miner) SetRecommitInterval(interval time.Duration) {
 	miner.worker.setRecommitInterval(interval)
@@ -386,6 +386,13 @@ func (w *worker) setGasTip(tip *big.Int) {
 	w.tip = uint256.MustFromBig(tip)
 }
 
+// gasTip returns the minimum miner tip needed to include a non-local transaction.
+func (w *worker) gasTip() *big.Int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.tip.ToBig()
+}
+
 // setRecommitInterval updates the interval for miner sealing work recommitting.
 func (w *worker) setRecommitInterval(interval time.Duration) {
 	select {
@@ -1119,7 +1126,7 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 	if w.chainConfig.IsEnabled(w.chainConfig.GetEIP1559Transition, header.Number) {
 		header.BaseFee = eip1559.CalcBaseFee(w.chainConfig, parent)
 		if !w.chainConfig.IsEnabled(w.chainConfig.GetEIP1559Transition, parent.Number) {
-			parentGasLimit := parent.GasLimit * w.chainConfig.GetElasticityMultiplier()
+			parentGasLimit := parent.GasLimit * ctypes.ElasticityMultiplier(w.chainConfig, header.Number)
 			header.GasLimit = core.CalcGasLimit(parentGasLimit, w.config.GasCeil)
 		}
 	}
@@ -1196,6 +1203,11 @@ func (w *worker) prepareWork(genParams *generateParams) (*environment, error) {
 		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, w.chainConfig, vm.Config{})
 		core.ProcessBeaconBlockRoot(*header.ParentBeaconRoot, vmenv, env.state)
 	}
+	if w.chainConfig.IsEnabled(w.chainConfig.GetEIP2935Transition, header.Number) {
+		context := core.NewEVMBlockContext(header, w.chain, nil)
+		vmenv := vm.NewEVM(context, vm.TxContext{}, env.state, w.chainConfig, vm.Config{})
+		core.ProcessParentBlockHash(header.ParentHash, vmenv, env.state)
+	}
 	return env, nil
 }
 
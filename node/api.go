@@ -19,7 +19,9 @@ package node
 import (
 	"context"
 	"fmt"
+	"net/netip"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -120,6 +122,138 @@ func (api *adminAPI) RemoveTrustedPeer(url string) (bool, error) {
 	return true, nil
 }
 
+// defaultBanDuration is the duration of bans requested without one.
+const defaultBanDuration = 24 * time.Hour
+
+// parseBanTarget parses the target of a ban, which is either a node URL or
+// ID, or an IP address or network in CIDR notation.
+func parseBanTarget(target string) (enode.ID, netip.Prefix, error) {
+	if prefix, err := netip.ParsePrefix(target); err == nil {
+		return enode.ID{}, prefix, nil
+	}
+	if addr, err := netip.ParseAddr(target); err == nil {
+		addr = addr.Unmap()
+		return enode.ID{}, netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	if id, err := enode.ParseID(target); err == nil {
+		return id, netip.Prefix{}, nil
+	}
+	node, err := enode.Parse(enode.ValidSchemes, target)
+	if err != nil {
+		return enode.ID{}, netip.Prefix{}, fmt.Errorf("invalid ban target %q: not a node or IP network", target)
+	}
+	return node.ID(), netip.Prefix{}, nil
+}
+
+// BanPeer bans a node, given by URL or ID, or all nodes within an IP network,
+// given by address or CIDR, disconnecting them if currently connected. The
+// ban lasts for the given duration, such as "90m", or a day if omitted.
+func (api *adminAPI) BanPeer(target string, duration *string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	id, prefix, err := parseBanTarget(target)
+	if err != nil {
+		return false, err
+	}
+	d := defaultBanDuration
+	if duration != nil {
+		if d, err = time.ParseDuration(*duration); err != nil {
+			return false, fmt.Errorf("invalid ban duration: %v", err)
+		}
+		if d <= 0 {
+			return false, fmt.Errorf("invalid ban duration: %v", d)
+		}
+	}
+	if prefix.IsValid() {
+		err = server.BanNetwork(prefix, d)
+	} else {
+		err = server.BanPeer(id, d)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnbanPeer lifts the ban of a node, given by URL or ID, forgiving its past
+// offenses, or of an IP network, given by address or CIDR.
+func (api *adminAPI) UnbanPeer(target string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	id, prefix, err := parseBanTarget(target)
+	if err != nil {
+		return false, err
+	}
+	if prefix.IsValid() {
+		err = server.UnbanNetwork(prefix)
+	} else {
+		err = server.UnbanPeer(id)
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// peerScores is the reputation of the known nodes and the banned networks.
+type peerScores struct {
+	Peers    []p2p.PeerReputation `json:"peers"`
+	Networks map[string]time.Time `json:"networks"`
+}
+
+// PeerScores retrieves the reputation of the nodes which misbehaved or were
+// banned, worst first, along with the banned IP networks.
+func (api *adminAPI) PeerScores() (*peerScores, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	scores := &peerScores{
+		Peers:    server.PeerReputations(),
+		Networks: make(map[string]time.Time),
+	}
+	for prefix, until := range server.BannedNetworks() {
+		scores.Networks[prefix.String()] = until
+	}
+	return scores, nil
+}
+
+// PeerTraffic retrieves the traffic exchanged with each connected peer, in
+// total and per protocol.
+func (api *adminAPI) PeerTraffic() ([]*p2p.PeerTraffic, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	peers := server.Peers()
+	traffic := make([]*p2p.PeerTraffic, len(peers))
+	for i, p := range peers {
+		traffic[i] = p.Traffic()
+	}
+	return traffic, nil
+}
+
+// Reachability diagnoses whether other nodes can connect to the node, from the
+// endpoints stated by discovery peers, the inbound connections and a local
+// loopback dial of the advertised endpoint.
+func (api *adminAPI) Reachability() (*p2p.ReachabilityInfo, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	info := server.Reachability()
+	if info == nil {
+		return nil, ErrNodeStopped
+	}
+	return info, nil
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *adminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
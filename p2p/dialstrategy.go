@@ -0,0 +1,152 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Dial strategies, see Config.DialStrategy.
+const (
+	DialMixed     = "mixed"
+	DialVerified  = "verified"
+	DialDiscovery = "discovery"
+)
+
+// verifiedNodeMaxAge is how long verified nodes remain dial candidates after
+// they were last verified.
+const verifiedNodeMaxAge = 7 * 24 * time.Hour
+
+// dialCandidates returns the iterator feeding the dial scheduler according to
+// the dial strategy.
+func (srv *Server) dialCandidates() (enode.Iterator, error) {
+	switch srv.DialStrategy {
+	case "", DialMixed:
+		if nodes := srv.nodedb.VerifiedNodes(verifiedNodeMaxAge); len(nodes) > 0 {
+			srv.log.Debug("Adding verified dial candidates", "count", len(nodes))
+			srv.discmix.AddSource(enode.IterNodes(nodes))
+		}
+		return srv.discmix, nil
+	case DialVerified:
+		nodes := srv.nodedb.VerifiedNodes(verifiedNodeMaxAge)
+		srv.log.Debug("Dialing verified nodes first", "count", len(nodes))
+		return &concatIter{iters: []enode.Iterator{enode.IterNodes(nodes), srv.discmix}}, nil
+	case DialDiscovery:
+		return srv.discmix, nil
+	default:
+		return nil, fmt.Errorf("unknown dial strategy %q", srv.DialStrategy)
+	}
+}
+
+// dialFilter returns the combined dial filter of the protocols, or nil if no
+// protocol filters its dial candidates.
+func (srv *Server) dialFilter() func(*enode.Node) bool {
+	var (
+		filters []func(*enode.Node) bool
+		added   = make(map[string]bool)
+	)
+	for _, proto := range srv.Protocols {
+		if proto.DialFilter != nil && !added[proto.Name] {
+			filters = append(filters, proto.DialFilter)
+			added[proto.Name] = true
+		}
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+	return func(n *enode.Node) bool {
+		for _, filter := range filters {
+			if !filter(n) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// markVerified records a verified peer in the node database. Only dialed peers
+// are recorded, the endpoint of inbound ones isn't known to accept connections.
+func (srv *Server) markVerified(p *Peer) {
+	if p.Inbound() || srv.nodedb == nil {
+		return
+	}
+	if err := srv.nodedb.UpdateVerifiedNode(p.Node(), time.Now()); err != nil {
+		p.log.Debug("Failed to record verified peer", "err", err)
+	}
+}
+
+// resolveIter resolves the records of nodes found through discovery v4, whose
+// neighbor lists only carry the endpoints of nodes. Nodes whose record can't
+// be resolved are returned as is.
+type resolveIter struct {
+	enode.Iterator
+	resolve func(*enode.Node) (*enode.Node, error)
+	node    *enode.Node
+}
+
+func newResolveIter(it enode.Iterator, resolve func(*enode.Node) (*enode.Node, error)) *resolveIter {
+	return &resolveIter{Iterator: it, resolve: resolve}
+}
+
+func (it *resolveIter) Next() bool {
+	if !it.Iterator.Next() {
+		it.node = nil
+		return false
+	}
+	it.node = it.Iterator.Node()
+	if it.node.Seq() == 0 {
+		if n, err := it.resolve(it.node); err == nil {
+			it.node = n
+		}
+	}
+	return true
+}
+
+func (it *resolveIter) Node() *enode.Node {
+	return it.node
+}
+
+// concatIter returns the nodes of its iterators one after the other.
+type concatIter struct {
+	iters []enode.Iterator
+	cur   int
+}
+
+func (it *concatIter) Next() bool {
+	for ; it.cur < len(it.iters); it.cur++ {
+		if it.iters[it.cur].Next() {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *concatIter) Node() *enode.Node {
+	if it.cur >= len(it.iters) {
+		return nil
+	}
+	return it.iters[it.cur].Node()
+}
+
+func (it *concatIter) Close() {
+	for _, iter := range it.iters {
+		iter.Close()
+	}
+}
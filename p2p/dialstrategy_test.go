@@ -0,0 +1,107 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+// This test checks that discovered nodes get their records resolved and are
+// kept as is if that fails.
+func TestResolveIter(t *testing.T) {
+	var (
+		known   = newNode(uintID(1), "127.0.0.1:30303")
+		unknown = newNode(uintID(2), "127.0.0.2:30303")
+		r       enr.Record
+	)
+	r.SetSeq(3)
+	resolved := enode.SignNull(&r, known.ID())
+
+	it := newResolveIter(enode.IterNodes([]*enode.Node{known, unknown}), func(n *enode.Node) (*enode.Node, error) {
+		if n.ID() == known.ID() {
+			return resolved, nil
+		}
+		return nil, errors.New("timeout")
+	})
+	nodes := enode.ReadNodes(it, 10)
+	if len(nodes) != 2 {
+		t.Fatalf("wrong node count: %d", len(nodes))
+	}
+	for _, n := range nodes {
+		switch n.ID() {
+		case known.ID():
+			if n.Seq() != 3 {
+				t.Errorf("node record not resolved: %v", n)
+			}
+		case unknown.ID():
+			if n != unknown {
+				t.Errorf("unresolvable node changed: %v", n)
+			}
+		}
+	}
+}
+
+// This test checks that the verified dial strategy returns the nodes verified
+// in earlier sessions before the discovered ones.
+func TestDialCandidatesVerified(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+
+	verified := []*enode.Node{
+		newNode(uintID(1), "127.0.0.1:30303"),
+		newNode(uintID(2), "127.0.0.2:30303"),
+	}
+	db.UpdateVerifiedNode(verified[0], time.Now().Add(-time.Minute))
+	db.UpdateVerifiedNode(verified[1], time.Now())
+	db.UpdateVerifiedNode(newNode(uintID(3), "127.0.0.3:30303"), time.Now().Add(-2*verifiedNodeMaxAge))
+
+	discovered := newNode(uintID(4), "127.0.0.4:30303")
+	srv := &Server{
+		Config: Config{DialStrategy: DialVerified},
+		nodedb: db,
+		log:    log.Root(),
+	}
+	srv.discmix = enode.NewFairMix(0)
+	srv.discmix.AddSource(enode.IterNodes([]*enode.Node{discovered}))
+
+	it, err := srv.dialCandidates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+
+	want := []enode.ID{verified[1].ID(), verified[0].ID(), discovered.ID()}
+	for i, id := range want {
+		if !it.Next() {
+			t.Fatalf("iterator ended at %d", i)
+		}
+		if it.Node().ID() != id {
+			t.Fatalf("wrong candidate %d: have %v, want %v", i, it.Node().ID(), id)
+		}
+	}
+
+	srv.DialStrategy = "nearest"
+	if _, err := srv.dialCandidates(); err == nil {
+		t.Fatal("no error for unknown dial strategy")
+	}
+}
@@ -213,6 +213,42 @@ func (ln *LocalNode) SetFallbackUDP(port int) {
 	ln.updateEndpoints()
 }
 
+// SetFallbackUDP6 sets the last-resort UDP-on-IPv6 port, for when it differs from
+// the IPv4 one, e.g. because the IPv4 port is forwarded to a different port by a
+// NAT. It must be called after SetFallbackUDP.
+func (ln *LocalNode) SetFallbackUDP6(port int) {
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+
+	ln.endpoint6.fallbackUDP = uint16(port)
+	ln.updateEndpoints()
+}
+
+// EndpointStats summarizes the statements about the UDP endpoint of the local node
+// made by other nodes, for one address family.
+type EndpointStats struct {
+	Predicted  netip.AddrPort         // Endpoint in use, if enough statements agree on it
+	Statements map[netip.AddrPort]int // Number of nodes stating each endpoint
+	FullCone   bool                   // Whether statements came from nodes we didn't contact
+}
+
+// EndpointStats returns the statements about the local node's IPv4 and IPv6 UDP
+// endpoints.
+func (ln *LocalNode) EndpointStats() (v4, v6 EndpointStats) {
+	ln.mu.Lock()
+	defer ln.mu.Unlock()
+
+	return ln.endpoint4.stats(), ln.endpoint6.stats()
+}
+
+func (e *lnEndpoint) stats() EndpointStats {
+	return EndpointStats{
+		Predicted:  e.track.PredictEndpoint(),
+		Statements: e.track.Statements(),
+		FullCone:   e.track.PredictFullConeNAT(),
+	}
+}
+
 // UDPEndpointStatement should be called whenever a statement about the local node's
 // UDP endpoint is received. It feeds the local endpoint predictor.
 func (ln *LocalNode) UDPEndpointStatement(fromaddr, endpoint netip.AddrPort) {
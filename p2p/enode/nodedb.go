@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"net/netip"
 	"os"
+	"sort"
 	"sync"
 	"time"
 
@@ -54,6 +55,13 @@ const (
 	// Local information is keyed by ID only, the full key is "local:<ID>:seq".
 	// Use localItemKey to create those keys.
 	dbLocalSeq = "seq"
+
+	// Reputations and verified nodes are keyed by ID and network bans by CIDR,
+	// apart from the node entries so that they are not dropped along with
+	// expired nodes.
+	dbReputationPrefix = "rep:"
+	dbNetBanPrefix     = "netban:"
+	dbVerifiedPrefix   = "verified:"
 )
 
 const (
@@ -442,6 +450,150 @@ func (db *DB) storeLocalSeq(id ID, n uint64) {
 	db.storeUint64(localItemKey(id, dbLocalSeq), n)
 }
 
+// Reputation is the standing of a remote node with the local node.
+type Reputation struct {
+	Penalty  uint64 // Penalty points accumulated as of Updated
+	Updated  uint64 // Unix time of the last penalty
+	BanUntil uint64 // Unix time until which the node is banned
+	Bans     uint64 // Number of times the node was banned automatically
+}
+
+// reputationKey returns the database key of a node reputation.
+func reputationKey(id ID) []byte {
+	return append([]byte(dbReputationPrefix), id[:]...)
+}
+
+// Reputation retrieves the reputation of a node, which is zero if unknown.
+func (db *DB) Reputation(id ID) Reputation {
+	var rep Reputation
+	blob, err := db.lvl.Get(reputationKey(id), nil)
+	if err != nil {
+		return rep
+	}
+	if err := rlp.DecodeBytes(blob, &rep); err != nil {
+		return Reputation{}
+	}
+	return rep
+}
+
+// UpdateReputation stores the reputation of a node.
+func (db *DB) UpdateReputation(id ID, rep Reputation) error {
+	blob, err := rlp.EncodeToBytes(&rep)
+	if err != nil {
+		return err
+	}
+	return db.lvl.Put(reputationKey(id), blob, nil)
+}
+
+// DeleteReputation deletes the reputation of a node.
+func (db *DB) DeleteReputation(id ID) {
+	db.lvl.Delete(reputationKey(id), nil)
+}
+
+// Reputations retrieves the reputations of all nodes.
+func (db *DB) Reputations() map[ID]Reputation {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbReputationPrefix)), nil)
+	defer it.Release()
+
+	reps := make(map[ID]Reputation)
+	for it.Next() {
+		var (
+			id  ID
+			rep Reputation
+		)
+		key := it.Key()[len(dbReputationPrefix):]
+		if len(key) != len(id) || rlp.DecodeBytes(it.Value(), &rep) != nil {
+			continue
+		}
+		copy(id[:], key)
+		reps[id] = rep
+	}
+	return reps
+}
+
+// NetBans retrieves the banned IP networks along with the end of their bans.
+func (db *DB) NetBans() map[netip.Prefix]time.Time {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbNetBanPrefix)), nil)
+	defer it.Release()
+
+	bans := make(map[netip.Prefix]time.Time)
+	for it.Next() {
+		prefix, err := netip.ParsePrefix(string(it.Key()[len(dbNetBanPrefix):]))
+		if err != nil {
+			continue
+		}
+		until, _ := binary.Varint(it.Value())
+		bans[prefix] = time.Unix(until, 0)
+	}
+	return bans
+}
+
+// UpdateNetBan bans an IP network until the given time.
+func (db *DB) UpdateNetBan(prefix netip.Prefix, until time.Time) error {
+	return db.storeInt64([]byte(dbNetBanPrefix+prefix.Masked().String()), until.Unix())
+}
+
+// DeleteNetBan lifts the ban of an IP network.
+func (db *DB) DeleteNetBan(prefix netip.Prefix) {
+	db.lvl.Delete([]byte(dbNetBanPrefix+prefix.Masked().String()), nil)
+}
+
+// verifiedNode is the database entry of a verified node.
+type verifiedNode struct {
+	Time   uint64 // Unix time of the last verification
+	Record enr.Record
+}
+
+// UpdateVerifiedNode records that a node was verified to be a useful peer, such
+// as one on the same chain, at the given time.
+func (db *DB) UpdateVerifiedNode(node *Node, at time.Time) error {
+	blob, err := rlp.EncodeToBytes(&verifiedNode{Time: uint64(at.Unix()), Record: node.r})
+	if err != nil {
+		return err
+	}
+	id := node.ID()
+	return db.lvl.Put(append([]byte(dbVerifiedPrefix), id[:]...), blob, nil)
+}
+
+// DeleteVerifiedNode forgets the verification of a node.
+func (db *DB) DeleteVerifiedNode(id ID) {
+	db.lvl.Delete(append([]byte(dbVerifiedPrefix), id[:]...), nil)
+}
+
+// VerifiedNodes retrieves the nodes verified within maxAge, most recently
+// verified first. Older entries are deleted.
+func (db *DB) VerifiedNodes(maxAge time.Duration) []*Node {
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbVerifiedPrefix)), nil)
+	defer it.Release()
+
+	var (
+		threshold = uint64(time.Now().Add(-maxAge).Unix())
+		nodes     []*Node
+		times     = make(map[ID]uint64)
+	)
+	for it.Next() {
+		var (
+			id    ID
+			entry verifiedNode
+		)
+		key := it.Key()[len(dbVerifiedPrefix):]
+		if len(key) != len(id) {
+			continue
+		}
+		copy(id[:], key)
+		if rlp.DecodeBytes(it.Value(), &entry) != nil || entry.Time < threshold {
+			db.DeleteVerifiedNode(id)
+			continue
+		}
+		nodes = append(nodes, newNodeWithID(&entry.Record, id))
+		times[id] = entry.Time
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return times[nodes[i].ID()] > times[nodes[j].ID()]
+	})
+	return nodes
+}
+
 // QuerySeeds retrieves random nodes to be used as potential seed nodes
 // for bootstrapping.
 func (db *DB) QuerySeeds(n int, maxAge time.Duration) []*Node {
@@ -470,3 +470,33 @@ func TestDBExpireV5(t *testing.T) {
 	db.UpdateFindFailsV5(ID{}, ip, 4)
 	db.expireNodes()
 }
+
+func TestDBVerifiedNodes(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	var (
+		now   = time.Now()
+		nodes = nodeDBSeedQueryNodes
+	)
+	db.UpdateVerifiedNode(nodes[0].node, now.Add(-2*time.Hour))
+	db.UpdateVerifiedNode(nodes[1].node, now.Add(-time.Minute))
+	db.UpdateVerifiedNode(nodes[2].node, now)
+	db.UpdateVerifiedNode(nodes[3].node, now.Add(-time.Second))
+	db.DeleteVerifiedNode(nodes[3].node.ID())
+
+	verified := db.VerifiedNodes(time.Hour)
+	if len(verified) != 2 {
+		t.Fatalf("wrong number of verified nodes: have %d, want 2", len(verified))
+	}
+	if verified[0].ID() != nodes[2].node.ID() || verified[1].ID() != nodes[1].node.ID() {
+		t.Fatalf("wrong verified nodes order: %v", verified)
+	}
+	if verified[0].IPAddr() != nodes[2].node.IPAddr() {
+		t.Fatalf("wrong verified node record: %v", verified[0])
+	}
+	// The expired entry is gone even for larger ages.
+	if have := db.VerifiedNodes(3 * time.Hour); len(have) != 2 {
+		t.Fatalf("expired verified node not deleted: %v", have)
+	}
+}
@@ -45,6 +45,7 @@ type Msg struct {
 	meterCap  Cap    // Protocol name and version for egress metering
 	meterCode uint64 // Message within protocol for egress metering
 	meterSize uint32 // Compressed message size for ingress metering
+	priority  bool   // Whether the message is exempt from rate limits
 }
 
 // Decode parses the RLP content of a message into
@@ -51,6 +51,9 @@ var (
 	dialSuccessMeter    metrics.Meter = metrics.NilMeter{}
 	dialConnectionError metrics.Meter = metrics.NilMeter{}
 
+	// reputation meters
+	peerBanMeter = metrics.NewRegisteredMeter("p2p/bans", nil)
+
 	// handshake error meters
 	dialTooManyPeers        = metrics.NewRegisteredMeter("p2p/dials/error/saturated", nil)
 	dialAlreadyConnected    = metrics.NewRegisteredMeter("p2p/dials/error/known", nil)
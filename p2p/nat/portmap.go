@@ -0,0 +1,118 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package nat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// PortRange is a range of ports forwarded manually on the gateway. The external
+// ports External..External+Count-1 are forwarded to the local ports
+// Internal..Internal+Count-1.
+type PortRange struct {
+	External uint16
+	Internal uint16
+	Count    uint16
+}
+
+func (r PortRange) String() string {
+	if r.Count == 1 {
+		if r.External == r.Internal {
+			return strconv.Itoa(int(r.External))
+		}
+		return fmt.Sprintf("%d:%d", r.External, r.Internal)
+	}
+	return fmt.Sprintf("%d-%d:%d-%d", r.External, int(r.External)+int(r.Count)-1, r.Internal, int(r.Internal)+int(r.Count)-1)
+}
+
+// PortMap is a set of ports forwarded manually on the gateway, for the gateways
+// which support neither UPnP nor NAT-PMP.
+type PortMap []PortRange
+
+// ParsePortMap parses a comma separated list of forwarded ports. Each entry is
+// an external port or port range, optionally followed by the local port or
+// port range it is forwarded to, if different.
+//
+//	"30303"                       external port 30303 forwarded to local port 30303
+//	"30313:30303"                 external port 30313 forwarded to local port 30303
+//	"40000-40009:30303-30312"     ten external ports forwarded to ten local ports
+func ParsePortMap(spec string) (PortMap, error) {
+	var m PortMap
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		external, internal, found := strings.Cut(entry, ":")
+		if !found {
+			internal = external
+		}
+		extFrom, extCount, err := parsePortRange(external)
+		if err != nil {
+			return nil, err
+		}
+		intFrom, intCount, err := parsePortRange(internal)
+		if err != nil {
+			return nil, err
+		}
+		if extCount != intCount {
+			return nil, fmt.Errorf("port ranges of different length in %q", entry)
+		}
+		m = append(m, PortRange{External: extFrom, Internal: intFrom, Count: extCount})
+	}
+	return m, nil
+}
+
+func parsePortRange(spec string) (from uint16, count uint16, err error) {
+	first, last, found := strings.Cut(spec, "-")
+	if !found {
+		last = first
+	}
+	lo, err := strconv.ParseUint(strings.TrimSpace(first), 10, 16)
+	if err != nil || lo == 0 {
+		return 0, 0, fmt.Errorf("invalid port %q", first)
+	}
+	hi, err := strconv.ParseUint(strings.TrimSpace(last), 10, 16)
+	if err != nil || hi == 0 {
+		return 0, 0, fmt.Errorf("invalid port %q", last)
+	}
+	if hi < lo {
+		return 0, 0, errors.New("invalid port range " + spec)
+	}
+	return uint16(lo), uint16(hi - lo + 1), nil
+}
+
+// External returns the external port forwarded to the given local port.
+func (m PortMap) External(port int) (int, bool) {
+	for _, r := range m {
+		if port >= int(r.Internal) && port < int(r.Internal)+int(r.Count) {
+			return int(r.External) + port - int(r.Internal), true
+		}
+	}
+	return 0, false
+}
+
+func (m PortMap) String() string {
+	entries := make([]string, len(m))
+	for i, r := range m {
+		entries[i] = r.String()
+	}
+	return strings.Join(entries, ",")
+}
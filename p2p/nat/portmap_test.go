@@ -0,0 +1,80 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package nat
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePortMap(t *testing.T) {
+	tests := []struct {
+		spec string
+		want PortMap
+		err  bool
+	}{
+		{spec: "", want: nil},
+		{spec: "30303", want: PortMap{{30303, 30303, 1}}},
+		{spec: "30313:30303", want: PortMap{{30313, 30303, 1}}},
+		{spec: "40000-40009:30303-30312", want: PortMap{{40000, 30303, 10}}},
+		{spec: "30303, 40000-40001", want: PortMap{{30303, 30303, 1}, {40000, 40000, 2}}},
+		{spec: "0", err: true},
+		{spec: "70000", err: true},
+		{spec: "30310-30303", err: true},
+		{spec: "40000-40009:30303-30304", err: true},
+		{spec: "port", err: true},
+	}
+	for _, test := range tests {
+		m, err := ParsePortMap(test.spec)
+		if test.err {
+			if err == nil {
+				t.Errorf("%q: expected error", test.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(m, test.want) {
+			t.Errorf("%q: got %v, want %v", test.spec, m, test.want)
+		}
+		if m2, err := ParsePortMap(m.String()); err != nil || !reflect.DeepEqual(m2, m) {
+			t.Errorf("%q: string %q doesn't roundtrip", test.spec, m.String())
+		}
+	}
+}
+
+func TestPortMapExternal(t *testing.T) {
+	m := PortMap{{30303, 30303, 1}, {40000, 30310, 10}}
+	tests := []struct {
+		port, want int
+		ok         bool
+	}{
+		{30303, 30303, true},
+		{30304, 0, false},
+		{30310, 40000, true},
+		{30319, 40009, true},
+		{30320, 0, false},
+	}
+	for _, test := range tests {
+		ext, ok := m.External(test.port)
+		if ext != test.want || ok != test.ok {
+			t.Errorf("port %d: got (%d, %v), want (%d, %v)", test.port, ext, ok, test.want, test.ok)
+		}
+	}
+}
@@ -94,6 +94,18 @@ func (it *IPTracker) PredictEndpoint() netip.AddrPort {
 	return max
 }
 
+// Statements returns the number of hosts which made a statement for each of the
+// endpoints stated within the window.
+func (it *IPTracker) Statements() map[netip.AddrPort]int {
+	it.gcStatements(it.clock.Now())
+
+	counts := make(map[netip.AddrPort]int)
+	for _, s := range it.statements {
+		counts[s.endpoint]++
+	}
+	return counts
+}
+
 // AddStatement records that a certain host thinks our external endpoint is the one given.
 func (it *IPTracker) AddStatement(host netip.Addr, endpoint netip.AddrPort) {
 	now := it.clock.Now()
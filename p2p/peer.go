@@ -115,6 +115,15 @@ type Peer struct {
 	pingRecv chan struct{}
 	disc     chan DiscReason
 
+	// penalize records offenses of the peer, if set
+	penalize func(Offense)
+
+	// verified records that the peer is useful, if set
+	verified func()
+
+	// traffic accounts the messages exchanged with the peer
+	traffic *trafficCounter
+
 	// events receives message send / receive events if set
 	events   *event.Feed
 	testPipe *MsgPipeRW // for testing
@@ -225,6 +234,11 @@ func (p *Peer) Inbound() bool {
 	return p.rw.is(inboundConn)
 }
 
+// Trusted returns true if the peer is a trusted node.
+func (p *Peer) Trusted() bool {
+	return p.rw.is(trustedConn)
+}
+
 func newPeer(log log.Logger, conn *conn, protocols []Protocol) *Peer {
 	protomap := matchProtocols(protocols, conn.caps, conn)
 	p := &Peer{
@@ -235,11 +249,29 @@ func newPeer(log log.Logger, conn *conn, protocols []Protocol) *Peer {
 		protoErr: make(chan error, len(protomap)+1), // protocols + pingLoop
 		closed:   make(chan struct{}),
 		pingRecv: make(chan struct{}, 16),
+		traffic:  newTrafficCounter(),
 		log:      log.New("id", conn.node.ID(), "conn", conn.flags),
 	}
 	return p
 }
 
+// Penalize lowers the reputation of the peer for an offense. Peers committing
+// enough offenses are disconnected and banned for a while.
+func (p *Peer) Penalize(offense Offense) {
+	if p.penalize != nil {
+		p.penalize(offense)
+	}
+}
+
+// MarkVerified records that the peer is useful, e.g. that it completed the
+// handshake of a protocol on the same chain. Verified nodes are preferred dial
+// candidates in later sessions, depending on Config.DialStrategy.
+func (p *Peer) MarkVerified() {
+	if p.verified != nil {
+		p.verified()
+	}
+}
+
 func (p *Peer) Log() log.Logger {
 	return p.log
 }
@@ -335,6 +367,9 @@ func (p *Peer) readLoop(errc chan<- error) {
 }
 
 func (p *Peer) handle(msg Msg) error {
+	if msg.Code < baseProtocolLength {
+		p.traffic.ingress(baseProtocolName, msg.meterSize)
+	}
 	switch {
 	case msg.Code == pingMsg:
 		msg.Discard()
@@ -357,6 +392,7 @@ func (p *Peer) handle(msg Msg) error {
 		if err != nil {
 			return fmt.Errorf("msg code out of range: %v", msg.Code)
 		}
+		p.traffic.ingress(proto.Name, msg.meterSize)
 		if metrics.Enabled {
 			m := fmt.Sprintf("%s/%s/%d/%#02x", ingressMeterName, proto.Name, proto.Version, msg.Code-proto.offset)
 			metrics.GetOrRegisterMeter(m, nil).Mark(int64(msg.meterSize))
@@ -410,11 +446,13 @@ outer:
 }
 
 func (p *Peer) startProtocols(writeStart <-chan struct{}, writeErr chan<- error) {
+	writePrio := make(chan struct{})
 	p.wg.Add(len(p.running))
 	for _, proto := range p.running {
 		proto := proto
 		proto.closed = p.closed
 		proto.wstart = writeStart
+		proto.wprio = writePrio
 		proto.werr = writeErr
 		var rw MsgReadWriter = proto
 		if p.events != nil {
@@ -451,6 +489,7 @@ type protoRW struct {
 	in     chan Msg        // receives read messages
 	closed <-chan struct{} // receives when peer is shutting down
 	wstart <-chan struct{} // receives when write may start
+	wprio  chan struct{}   // hands the write start over to priority messages
 	werr   chan<- error    // for write results
 	offset uint64
 	w      MsgWriter
@@ -464,21 +503,46 @@ func (rw *protoRW) WriteMsg(msg Msg) (err error) {
 	msg.meterCode = msg.Code
 
 	msg.Code += rw.offset
+	msg.priority = rw.Priority != nil && rw.Priority(msg.meterCode)
 
-	select {
-	case <-rw.wstart:
-		err = rw.w.WriteMsg(msg)
-		// Report write status back to Peer.run. It will initiate
-		// shutdown if the error is non-nil and unblock the next write
-		// otherwise. The calling protocol code should exit for errors
-		// as well but we don't want to rely on that.
-		rw.werr <- err
-	case <-rw.closed:
-		err = ErrShuttingDown
+	if err := rw.waitWrite(msg.priority); err != nil {
+		return err
 	}
+	err = rw.w.WriteMsg(msg)
+	// Report write status back to Peer.run. It will initiate
+	// shutdown if the error is non-nil and unblock the next write
+	// otherwise. The calling protocol code should exit for errors
+	// as well but we don't want to rely on that.
+	rw.werr <- err
 	return err
 }
 
+// waitWrite blocks until the message may be written. Other messages hand their
+// turn over to priority messages waiting on any protocol of the peer.
+func (rw *protoRW) waitWrite(priority bool) error {
+	var handover chan struct{} // only priority messages take over a turn
+	if priority {
+		handover = rw.wprio
+	}
+	for {
+		select {
+		case <-rw.wstart:
+			if priority {
+				return nil
+			}
+			select {
+			case rw.wprio <- struct{}{}:
+			default:
+				return nil
+			}
+		case <-handover:
+			return nil
+		case <-rw.closed:
+			return ErrShuttingDown
+		}
+	}
+}
+
 func (rw *protoRW) ReadMsg() (Msg, error) {
 	select {
 	case msg := <-rw.in:
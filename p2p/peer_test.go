@@ -101,6 +101,7 @@ func testPeer(protos []Protocol) (func(), *conn, *Peer, <-chan error) {
 	}
 
 	peer := newPeer(log.Root(), c1, protos)
+	t1.(*testTransport).setTraffic(peer.traffic, nil, nil)
 	errc := make(chan error, 1)
 	go func() {
 		_, err := peer.run()
@@ -168,6 +169,86 @@ func TestPeerProtoEncodeMsg(t *testing.T) {
 	}
 }
 
+func TestPeerTraffic(t *testing.T) {
+	sent := make(chan struct{})
+	proto := Protocol{
+		Name:   "a",
+		Length: 2,
+		Run: func(peer *Peer, rw MsgReadWriter) error {
+			if err := ExpectMsg(rw, 1, []string{"foo"}); err != nil {
+				t.Error(err)
+			}
+			if err := SendItems(rw, 1, "bar", "baz"); err != nil {
+				t.Errorf("write error: %v", err)
+			}
+			close(sent)
+			_, err := rw.ReadMsg()
+			return err
+		},
+	}
+	closer, rw, peer, _ := testPeer([]Protocol{proto})
+	defer closer()
+
+	if err := SendItems(rw, baseProtocolLength+1, "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExpectMsg(rw, baseProtocolLength+1, []string{"bar", "baz"}); err != nil {
+		t.Fatal(err)
+	}
+	<-sent
+
+	traffic := peer.Traffic()
+	stats := traffic.Protocols["a"]
+	if stats.IngressPackets != 1 || stats.EgressPackets != 1 {
+		t.Fatalf("wrong packet counts: %+v", stats)
+	}
+	if stats.Ingress == 0 || stats.Egress <= stats.Ingress {
+		t.Fatalf("wrong traffic sizes: %+v", stats)
+	}
+	if traffic.Total != stats {
+		t.Fatalf("wrong total traffic: have %+v, want %+v", traffic.Total, stats)
+	}
+}
+
+// This test checks that priority messages take over the turn of regular ones
+// waiting to be written.
+func TestProtoRWPriority(t *testing.T) {
+	var (
+		wstart = make(chan struct{}, 1)
+		closed = make(chan struct{})
+		rw     = &protoRW{wstart: wstart, wprio: make(chan struct{}), closed: closed}
+		order  = make(chan string, 2)
+	)
+	defer close(closed)
+
+	go func() {
+		if err := rw.waitWrite(true); err == nil {
+			order <- "priority"
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	go func() {
+		if err := rw.waitWrite(false); err == nil {
+			order <- "regular"
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	wstart <- struct{}{}
+	if first := <-order; first != "priority" {
+		t.Fatalf("%s message written first", first)
+	}
+	select {
+	case <-order:
+		t.Fatal("regular message written without its turn")
+	case <-time.After(50 * time.Millisecond):
+	}
+	wstart <- struct{}{}
+	if second := <-order; second != "regular" {
+		t.Fatalf("%s message written second", second)
+	}
+}
+
 func TestPeerPing(t *testing.T) {
 	closer, rw, _, _ := testPeer(nil)
 	defer closer()
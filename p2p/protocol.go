@@ -62,6 +62,17 @@ type Protocol struct {
 
 	// Attributes contains protocol specific information for the node record.
 	Attributes []enr.Entry
+
+	// Priority optionally reports whether messages with the given code, such as
+	// block propagation, are sent ahead of other pending messages to the peer
+	// and exempt from waiting for the upload rate limits.
+	Priority func(code uint64) bool
+
+	// DialFilter optionally reports whether a node found through the discovery
+	// DHT is worth dialing for this protocol, e.g. whether its node record
+	// advertises a compatible chain. The records of discovered nodes are
+	// resolved before they are checked.
+	DialFilter func(*enode.Node) bool
 }
 
 func (p Protocol) cap() Cap {
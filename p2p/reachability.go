@@ -0,0 +1,398 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/netutil"
+)
+
+const (
+	reachabilityCheckDelay    = 5 * time.Minute  // Delay of the first reachability check after startup
+	reachabilityCheckInterval = 30 * time.Minute // Interval of the reachability checks
+	reachabilityWindow        = time.Hour        // Inbound connections older than this don't prove reachability
+	loopbackDialTimeout       = 10 * time.Second
+)
+
+// Reachability states reported by Server.Reachability.
+const (
+	ReachabilityUnknown      = "unknown"       // No evidence yet either way
+	Reachable                = "reachable"     // Nodes on the internet connected, or the loopback dial succeeded
+	Unreachable              = "unreachable"   // No inbound connections while listening for the reachability window, and the loopback dial failed
+	ReachabilityNotListening = "not listening" // The node doesn't accept connections
+)
+
+// ReachabilityInfo is the diagnostic of whether other nodes can connect to the
+// local node.
+type ReachabilityInfo struct {
+	Status       string                   `json:"status"`
+	Enode        string                   `json:"enode"` // Node URL advertised to other nodes
+	ListenAddr   string                   `json:"listenAddr"`
+	NAT          string                   `json:"nat"`
+	Mappings     []*PortMappingInfo       `json:"portMappings"`
+	Endpoints    map[string]*EndpointInfo `json:"endpoints"` // Endpoints stated by other nodes, by address family
+	Inbound      InboundInfo              `json:"inbound"`
+	LoopbackDial *LoopbackDialInfo        `json:"loopbackDial"`
+	Hints        []string                 `json:"hints"`
+}
+
+// PortMappingInfo is the state of a listening port mapped on the gateway.
+type PortMappingInfo struct {
+	Protocol string `json:"protocol"`
+	Internal int    `json:"internalPort"`
+	External int    `json:"externalPort"` // Zero if the port isn't mapped
+	Manual   bool   `json:"manual"`       // Whether the port is forwarded manually, see Config.MappedPorts
+	Error    string `json:"error,omitempty"`
+}
+
+// EndpointInfo summarizes the UDP endpoints of the local node stated by other
+// nodes in discovery.
+type EndpointInfo struct {
+	Predicted  string         `json:"predicted"`  // Endpoint in use, if enough nodes agree on it
+	Statements map[string]int `json:"statements"` // Number of nodes stating each endpoint
+	FullCone   bool           `json:"fullCone"`   // Whether nodes we didn't contact reached us
+}
+
+// InboundInfo counts the inbound peers and connections.
+type InboundInfo struct {
+	Peers    int        `json:"peers"`                    // Connected inbound peers
+	Total    int        `json:"total"`                    // Inbound peers since startup
+	Public   int        `json:"public"`                   // Inbound peers from internet addresses since startup
+	Last     *time.Time `json:"last,omitempty"`           // Time of the last inbound peer from an internet address
+	Conns    int        `json:"connections"`              // Connections accepted from internet addresses since startup, peers or not
+	LastConn *time.Time `json:"lastConnection,omitempty"` // Time of the last connection from an internet address
+}
+
+// LoopbackDialInfo is the result of the last attempt to connect from this machine
+// to the advertised endpoint of the local node. It is a local loopback check, not
+// a dial from another node: behind NAT it only succeeds if the router supports
+// NAT loopback (hairpinning), so a failure alone doesn't prove that other nodes
+// can't connect.
+type LoopbackDialInfo struct {
+	Endpoint string    `json:"endpoint"`
+	Time     time.Time `json:"time"`
+	Reached  bool      `json:"reached"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// reachability gathers the evidence of whether other nodes can connect to the
+// local node.
+type reachability struct {
+	clock mclock.Clock
+
+	mu           sync.Mutex
+	started      mclock.AbsTime
+	inbound      int // Inbound peers since startup
+	public       int // Inbound peers from internet addresses since startup
+	lastPublicAt time.Time
+	conns        int            // Connections accepted from internet addresses since startup
+	lastConn     mclock.AbsTime // Last connection accepted from an internet address
+	lastConnAt   time.Time
+	mappings     map[string]*PortMappingInfo
+	loopbackDial *LoopbackDialInfo
+}
+
+func newReachability(clock mclock.Clock) *reachability {
+	return &reachability{
+		clock:    clock,
+		started:  clock.Now(),
+		mappings: make(map[string]*PortMappingInfo),
+	}
+}
+
+// inboundConn records a connection accepted from the given address. Whether or
+// not it becomes a peer, a connection from the internet proves that the listening
+// port is reachable.
+func (r *reachability) inboundConn(ip netip.Addr) {
+	if !ip.IsValid() || netutil.AddrIsLAN(ip) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.conns++
+	r.lastConn = r.clock.Now()
+	r.lastConnAt = time.Now()
+}
+
+// inboundPeer records an inbound peer connected from the given address.
+func (r *reachability) inboundPeer(ip netip.Addr) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.inbound++
+	if ip.IsValid() && !netutil.AddrIsLAN(ip) {
+		r.public++
+		r.lastPublicAt = time.Now()
+	}
+}
+
+// recentInbound reports whether a connection from an internet address was
+// accepted within the reachability window.
+func (r *reachability) recentInbound() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.conns > 0 && time.Duration(r.clock.Now()-r.lastConn) < reachabilityWindow
+}
+
+// listenedForWindow reports whether the node has been listening for the whole
+// reachability window, long enough for nodes on the internet to find and dial it.
+func (r *reachability) listenedForWindow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return time.Duration(r.clock.Now()-r.started) >= reachabilityWindow
+}
+
+// mapped records the result of mapping a listening port on the gateway.
+func (r *reachability) mapped(protocol string, internal, external int, manual bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m := &PortMappingInfo{Protocol: protocol, Internal: internal, External: external, Manual: manual}
+	if err != nil {
+		m.Error = err.Error()
+	}
+	r.mappings[protocol] = m
+}
+
+func (r *reachability) setLoopbackDial(result *LoopbackDialInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.loopbackDial = result
+}
+
+// Reachability returns the diagnostic of whether other nodes can connect to the
+// local node, or nil if the server isn't running.
+func (srv *Server) Reachability() *ReachabilityInfo {
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+	if !running {
+		return nil
+	}
+	self := srv.localnode.Node()
+	info := &ReachabilityInfo{
+		Enode:      self.URLv4(),
+		ListenAddr: srv.ListenAddr,
+		NAT:        "none",
+		Mappings:   []*PortMappingInfo{},
+		Endpoints:  make(map[string]*EndpointInfo),
+	}
+	if srv.NAT != nil {
+		info.NAT = srv.NAT.String()
+	}
+	v4, v6 := srv.localnode.EndpointStats()
+	info.Endpoints["ipv4"] = newEndpointInfo(v4)
+	info.Endpoints["ipv6"] = newEndpointInfo(v6)
+
+	for _, p := range srv.Peers() {
+		if p.Inbound() {
+			info.Inbound.Peers++
+		}
+	}
+	recent := srv.reach.recentInbound()
+	listened := srv.reach.listenedForWindow()
+
+	srv.reach.mu.Lock()
+	for _, m := range srv.reach.mappings {
+		mapping := *m
+		info.Mappings = append(info.Mappings, &mapping)
+	}
+	info.Inbound.Total = srv.reach.inbound
+	info.Inbound.Public = srv.reach.public
+	if srv.reach.public > 0 {
+		last := srv.reach.lastPublicAt
+		info.Inbound.Last = &last
+	}
+	info.Inbound.Conns = srv.reach.conns
+	if srv.reach.conns > 0 {
+		last := srv.reach.lastConnAt
+		info.Inbound.LastConn = &last
+	}
+	if srv.reach.loopbackDial != nil {
+		result := *srv.reach.loopbackDial
+		info.LoopbackDial = &result
+	}
+	srv.reach.mu.Unlock()
+	sort.Slice(info.Mappings, func(i, j int) bool { return info.Mappings[i].Protocol < info.Mappings[j].Protocol })
+
+	switch {
+	case srv.listener == nil:
+		info.Status = ReachabilityNotListening
+	case recent || (info.LoopbackDial != nil && info.LoopbackDial.Reached):
+		info.Status = Reachable
+	case info.LoopbackDial == nil || !listened:
+		// A failed loopback dial alone doesn't prove anything: routers without
+		// NAT loopback fail it while other nodes connect fine. The node is only
+		// reported unreachable once nobody connected for the whole window.
+		info.Status = ReachabilityUnknown
+	default:
+		info.Status = Unreachable
+	}
+	info.Hints = reachabilityHints(info, self, v4)
+	return info
+}
+
+func newEndpointInfo(stats enode.EndpointStats) *EndpointInfo {
+	info := &EndpointInfo{
+		Statements: make(map[string]int, len(stats.Statements)),
+		FullCone:   stats.FullCone,
+	}
+	if stats.Predicted.IsValid() {
+		info.Predicted = stats.Predicted.String()
+	}
+	for endpoint, count := range stats.Statements {
+		info.Statements[endpoint.String()] = count
+	}
+	return info
+}
+
+// reachabilityHints suggests how to make the local node reachable.
+func reachabilityHints(info *ReachabilityInfo, self *enode.Node, v4 enode.EndpointStats) []string {
+	hints := []string{}
+	if info.Status == ReachabilityNotListening {
+		return append(hints, "The node doesn't listen for connections, only outbound peers are possible")
+	}
+	ip := self.IPAddr()
+	if !ip.IsValid() || netutil.AddrIsLAN(ip) {
+		hints = append(hints, "No internet IP address is advertised: set it with --nat extip:<ip>, or enable UPnP or NAT-PMP on the router and use --nat any")
+	} else if v4.Predicted.IsValid() && ip.Is4() && v4.Predicted.Addr() != ip {
+		hints = append(hints, fmt.Sprintf("Other nodes see this node at %v, but %v is advertised", v4.Predicted.Addr(), ip))
+	}
+	for _, m := range info.Mappings {
+		if !m.Manual && m.Error != "" {
+			hints = append(hints, fmt.Sprintf("Mapping %s port %d through %s failed: forward it manually on the router and set --nat.ports", m.Protocol, m.Internal, info.NAT))
+		}
+	}
+	if info.Status == Unreachable {
+		hints = append(hints, fmt.Sprintf("No inbound connections from the internet for %v: check that TCP and UDP port %d are forwarded to this machine and allowed by the firewall", reachabilityWindow, self.TCP()))
+	}
+	return hints
+}
+
+// reachabilityLoop periodically checks whether other nodes can connect to the
+// local node, warning if they can't.
+func (srv *Server) reachabilityLoop() {
+	defer srv.loopWG.Done()
+
+	timer := srv.clock.NewTimer(reachabilityCheckDelay)
+	defer timer.Stop()
+
+	status := ReachabilityUnknown
+	for {
+		select {
+		case <-timer.C():
+			status = srv.checkReachability(status)
+			timer.Reset(reachabilityCheckInterval)
+		case <-srv.quit:
+			return
+		}
+	}
+}
+
+// checkReachability dials the advertised endpoint of the local node from this
+// machine unless nodes connected from the internet recently, and logs the
+// reachability if it changed or if the node is unreachable.
+func (srv *Server) checkReachability(prev string) string {
+	if !srv.reach.recentInbound() {
+		srv.reach.setLoopbackDial(srv.loopbackDial())
+	}
+	info := srv.Reachability()
+	if info == nil {
+		return prev
+	}
+	switch info.Status {
+	case Reachable:
+		if prev != Reachable {
+			srv.log.Info("Node is reachable from the internet", "enode", info.Enode, "inbound", info.Inbound.Public)
+		}
+	case Unreachable:
+		srv.log.Warn("Node is not reachable from the internet, no inbound connections and the local loopback dial failed, see admin.reachability", "enode", info.Enode, "nat", info.NAT, "window", reachabilityWindow)
+		for _, hint := range info.Hints {
+			srv.log.Warn(hint)
+		}
+	}
+	return info.Status
+}
+
+// loopbackDial connects from this machine to the advertised endpoint of the local
+// node, which is reached if the encryption handshake completes with our own key.
+// Behind NAT the connection only comes back if the router does hairpinning.
+func (srv *Server) loopbackDial() *LoopbackDialInfo {
+	result := &LoopbackDialInfo{Time: time.Now()}
+
+	self := srv.localnode.Node()
+	addr, ok := self.TCPEndpoint()
+	if !ok || netutil.AddrIsLAN(addr.Addr()) {
+		result.Error = "no internet endpoint advertised"
+		return result
+	}
+	result.Endpoint = addr.String()
+	if err := srv.dialLoopback(self); err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Reached = true
+	}
+	return result
+}
+
+func (srv *Server) dialLoopback(self *enode.Node) error {
+	ctx, cancel := context.WithTimeout(context.Background(), loopbackDialTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-srv.quit:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	fd, err := srv.dialsched.dialer.Dial(ctx, self)
+	if err != nil {
+		return err
+	}
+	t := srv.newTransport(fd, self.Pubkey())
+	defer t.close(nil)
+
+	_, err = t.doEncHandshake(srv.PrivateKey)
+	return err
+}
+
+// localIPv6 returns a global unicast IPv6 address of the local interfaces.
+func localIPv6() net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() == nil && ipnet.IP.IsGlobalUnicast() && !ipnet.IP.IsPrivate() {
+			return ipnet.IP
+		}
+	}
+	return nil
+}
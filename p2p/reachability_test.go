@@ -0,0 +1,160 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/internal/testlog"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/ethereum/go-ethereum/p2p/nat"
+)
+
+func startReachabilityServer(t *testing.T, config Config) *Server {
+	config.PrivateKey = newkey()
+	config.MaxPeers = 10
+	config.NoDiscovery = true
+	config.NoDial = true
+	config.Logger = testlog.Logger(t, log.LvlTrace)
+	if config.ListenAddr == "" {
+		config.ListenAddr = "127.0.0.1:0"
+	}
+	srv := &Server{Config: config}
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(srv.Stop)
+	return srv
+}
+
+func TestServerLoopbackDial(t *testing.T) {
+	srv := startReachabilityServer(t, Config{})
+
+	// The listener is reached through the loopback address.
+	self := srv.LocalNode().Node()
+	if err := srv.dialLoopback(self); err != nil {
+		t.Fatalf("loopback dial failed: %v", err)
+	}
+	// Another node listening on the same endpoint fails the handshake.
+	other := enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, self.TCP(), 0)
+	if err := srv.dialLoopback(other); err == nil {
+		t.Fatal("loopback dial of another node succeeded")
+	}
+	// The loopback dial isn't attempted without an internet endpoint.
+	if result := srv.loopbackDial(); result.Reached || result.Error == "" {
+		t.Fatalf("unexpected loopback dial result %+v", result)
+	}
+}
+
+func TestServerReachability(t *testing.T) {
+	clock := new(mclock.Simulated)
+	srv := startReachabilityServer(t, Config{clock: clock})
+
+	if status := srv.Reachability().Status; status != ReachabilityUnknown {
+		t.Fatalf("wrong status before the first check: %q", status)
+	}
+	// A failed loopback dial alone doesn't make the node unreachable.
+	srv.reach.setLoopbackDial(&LoopbackDialInfo{Endpoint: "192.0.2.1:30303", Error: "connection refused"})
+	if status := srv.Reachability().Status; status != ReachabilityUnknown {
+		t.Fatalf("wrong status after failed loopback dial: %q", status)
+	}
+	// Nobody connected while listening for the whole window.
+	clock.Run(reachabilityWindow)
+	info := srv.Reachability()
+	if info.Status != Unreachable {
+		t.Fatalf("wrong status without inbound connections: %q", info.Status)
+	}
+	if len(info.Hints) == 0 {
+		t.Fatal("no hints for unreachable node")
+	}
+	// Inbound peers from the local network prove nothing.
+	srv.reach.inboundConn(netip.MustParseAddr("192.168.1.10"))
+	srv.reach.inboundPeer(netip.MustParseAddr("192.168.1.10"))
+	if info := srv.Reachability(); info.Status != Unreachable || info.Inbound.Total != 1 || info.Inbound.Public != 0 || info.Inbound.Conns != 0 {
+		t.Fatalf("wrong reachability after LAN inbound peer: %+v", info)
+	}
+	// A connection from the internet is enough, even if it doesn't become a peer.
+	srv.reach.inboundConn(netip.MustParseAddr("192.0.2.7"))
+	info = srv.Reachability()
+	if info.Status != Reachable {
+		t.Fatalf("wrong status after inbound connection: %q", info.Status)
+	}
+	if info.Inbound.Conns != 1 || info.Inbound.LastConn == nil || info.Inbound.Public != 0 {
+		t.Fatalf("wrong inbound info %+v", info.Inbound)
+	}
+	srv.reach.inboundPeer(netip.MustParseAddr("192.0.2.7"))
+	if info := srv.Reachability(); info.Inbound.Total != 2 || info.Inbound.Public != 1 || info.Inbound.Last == nil {
+		t.Fatalf("wrong inbound info %+v", info.Inbound)
+	}
+	// The connection doesn't count anymore after the window.
+	clock.Run(reachabilityWindow)
+	if status := srv.Reachability().Status; status != Unreachable {
+		t.Fatalf("wrong status after the window: %q", status)
+	}
+}
+
+func TestServerMappedPorts(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	srv := startReachabilityServer(t, Config{
+		ListenAddr:  l.Addr().String(),
+		MappedPorts: nat.PortMap{{External: 40000, Internal: uint16(port), Count: 1}},
+	})
+	self := srv.LocalNode().Node()
+	if self.TCP() != 40000 {
+		t.Errorf("wrong TCP port in ENR: %d", self.TCP())
+	}
+	var tcp6 enr.TCP6
+	if err := self.Load(&tcp6); err != nil || int(tcp6) != port {
+		t.Errorf("wrong TCP6 port in ENR: %d, %v", tcp6, err)
+	}
+	info := srv.Reachability()
+	if len(info.Mappings) != 1 || !info.Mappings[0].Manual || info.Mappings[0].External != 40000 {
+		t.Errorf("wrong port mappings %+v", info.Mappings)
+	}
+}
+
+func TestServerIPv6(t *testing.T) {
+	ip6 := net.ParseIP("2001:db8::1")
+
+	// The IPv6 address isn't advertised when listening on IPv4 only.
+	srv := startReachabilityServer(t, Config{ExtIP6: ip6})
+	var entry enr.IPv6
+	if err := srv.LocalNode().Node().Load(&entry); err == nil {
+		t.Fatalf("IPv6 address %v advertised by IPv4 listener", net.IP(entry))
+	}
+
+	l, err := net.Listen("tcp", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 not available:", err)
+	}
+	l.Close()
+	srv = startReachabilityServer(t, Config{ListenAddr: "[::1]:0", ExtIP6: ip6})
+	if err := srv.LocalNode().Node().Load(&entry); err != nil || !net.IP(entry).Equal(ip6) {
+		t.Fatalf("wrong IPv6 address in ENR: %v, %v", net.IP(entry), err)
+	}
+}
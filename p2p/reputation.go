@@ -0,0 +1,272 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/netip"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Offense is a kind of misbehaviour lowering the reputation of a peer.
+type Offense uint8
+
+const (
+	OffenseTimeout           Offense = iota // Request not answered in time
+	OffenseUselessResponse                  // Unrequested, mismatching or empty response
+	OffenseProtocolViolation                // Malformed or unexpected message
+	OffenseInvalidHeader                    // Header failing validation
+	OffenseInvalidBlock                     // Block failing validation
+	offenseCount
+)
+
+var offenseNames = [offenseCount]string{
+	OffenseTimeout:           "timeout",
+	OffenseUselessResponse:   "useless response",
+	OffenseProtocolViolation: "protocol violation",
+	OffenseInvalidHeader:     "invalid header",
+	OffenseInvalidBlock:      "invalid block",
+}
+
+// offensePenalties are the penalty points of each offense. A peer reaching
+// banThreshold points is banned.
+var offensePenalties = [offenseCount]uint64{
+	OffenseTimeout:           10,
+	OffenseUselessResponse:   10,
+	OffenseProtocolViolation: 50,
+	OffenseInvalidHeader:     50,
+	OffenseInvalidBlock:      100,
+}
+
+func (o Offense) String() string {
+	if o < offenseCount {
+		return offenseNames[o]
+	}
+	return fmt.Sprintf("offense %d", o)
+}
+
+const (
+	banThreshold    = 100              // Penalty points at which a peer is banned
+	penaltyHalfLife = 30 * time.Minute // Time it takes for the penalty of a peer to halve
+
+	// The first automatic ban of a peer lasts minBanDuration, and every
+	// subsequent one twice as long as the previous, up to maxBanDuration.
+	minBanDuration = time.Hour
+	maxBanDuration = 7 * 24 * time.Hour
+)
+
+// PeerReputation is the standing of a remote node with the local node.
+type PeerReputation struct {
+	ID          enode.ID   `json:"id"`
+	Score       int64      `json:"score"` // Negated penalty points, zero for well-behaved nodes
+	Bans        uint64     `json:"bans"`
+	BannedUntil *time.Time `json:"bannedUntil,omitempty"`
+}
+
+// reputation scores peers on their misbehaviour and keeps track of banned
+// nodes and IP networks. It is persisted in the node database.
+type reputation struct {
+	db  *enode.DB
+	now func() time.Time
+
+	mu   sync.Mutex
+	nets map[netip.Prefix]time.Time // banned IP networks, cached from the database
+}
+
+func newReputation(db *enode.DB) *reputation {
+	return &reputation{db: db, now: time.Now, nets: db.NetBans()}
+}
+
+// decayedPenalty returns the penalty points of a node at the given time.
+func decayedPenalty(rep enode.Reputation, now time.Time) uint64 {
+	elapsed := now.Sub(time.Unix(int64(rep.Updated), 0))
+	if elapsed <= 0 {
+		return rep.Penalty
+	}
+	return uint64(float64(rep.Penalty) * math.Exp2(-float64(elapsed)/float64(penaltyHalfLife)))
+}
+
+// banDuration returns the duration of the automatic ban following the given
+// number of previous ones.
+func banDuration(bans uint64) time.Duration {
+	if bans >= 16 {
+		return maxBanDuration
+	}
+	if d := minBanDuration << bans; d < maxBanDuration {
+		return d
+	}
+	return maxBanDuration
+}
+
+// penalize records an offense of a node, banning it if its penalty reaches
+// the threshold. It returns the end of the ban, or the zero time if the node
+// was not banned.
+func (r *reputation) penalize(id enode.ID, offense Offense) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		now = r.now()
+		rep = r.db.Reputation(id)
+	)
+	rep.Penalty = decayedPenalty(rep, now) + offensePenalties[offense]
+	rep.Updated = uint64(now.Unix())
+
+	var until time.Time
+	if rep.Penalty >= banThreshold {
+		until = now.Add(banDuration(rep.Bans))
+		rep.BanUntil = max(rep.BanUntil, uint64(until.Unix()))
+		rep.Bans++
+		rep.Penalty = 0
+	}
+	r.db.UpdateReputation(id, rep)
+	return until
+}
+
+// ban bans a node for the given duration.
+func (r *reputation) ban(id enode.ID, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := r.db.Reputation(id)
+	rep.BanUntil = uint64(r.now().Add(duration).Unix())
+	r.db.UpdateReputation(id, rep)
+}
+
+// unban lifts the ban of a node and forgives its penalties.
+func (r *reputation) unban(id enode.ID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.db.DeleteReputation(id)
+}
+
+// banNet bans an IP network for the given duration.
+func (r *reputation) banNet(prefix netip.Prefix, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prefix = prefix.Masked()
+	until := r.now().Add(duration)
+	r.nets[prefix] = until
+	r.db.UpdateNetBan(prefix, until)
+}
+
+// unbanNet lifts the ban of an IP network.
+func (r *reputation) unbanNet(prefix netip.Prefix) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prefix = prefix.Masked()
+	delete(r.nets, prefix)
+	r.db.DeleteNetBan(prefix)
+}
+
+// banned reports whether a node or its IP network is banned.
+func (r *reputation) banned(id enode.ID, ip netip.Addr) bool {
+	if r == nil {
+		return false
+	}
+	if r.netBanned(ip) {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.db.Reputation(id).BanUntil > uint64(r.now().Unix())
+}
+
+// netBanned reports whether the IP address lies in a banned network.
+func (r *reputation) netBanned(ip netip.Addr) bool {
+	if r == nil || !ip.IsValid() {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ip = ip.Unmap()
+	now := r.now()
+	for prefix, until := range r.nets {
+		if !until.After(now) {
+			delete(r.nets, prefix)
+			r.db.DeleteNetBan(prefix)
+			continue
+		}
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// reputations returns the standing of all nodes with penalties or bans,
+// dropping the records of those which have been forgiven by now.
+func (r *reputation) reputations() []PeerReputation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		now  = r.now()
+		reps []PeerReputation
+	)
+	for id, rep := range r.db.Reputations() {
+		var (
+			penalty = decayedPenalty(rep, now)
+			banned  = rep.BanUntil > uint64(now.Unix())
+		)
+		if penalty == 0 && !banned && rep.Bans == 0 {
+			r.db.DeleteReputation(id)
+			continue
+		}
+		pr := PeerReputation{ID: id, Score: -int64(penalty), Bans: rep.Bans}
+		if banned {
+			until := time.Unix(int64(rep.BanUntil), 0)
+			pr.BannedUntil = &until
+		}
+		reps = append(reps, pr)
+	}
+	sort.Slice(reps, func(i, j int) bool {
+		if reps[i].Score != reps[j].Score {
+			return reps[i].Score < reps[j].Score
+		}
+		return bytes.Compare(reps[i].ID[:], reps[j].ID[:]) < 0
+	})
+	return reps
+}
+
+// bannedNets returns the banned IP networks along with the end of their bans.
+func (r *reputation) bannedNets() map[netip.Prefix]time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		now  = r.now()
+		nets = make(map[netip.Prefix]time.Time, len(r.nets))
+	)
+	for prefix, until := range r.nets {
+		if until.After(now) {
+			nets[prefix] = until
+		}
+	}
+	return nets
+}
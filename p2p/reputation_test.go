@@ -0,0 +1,141 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func newTestReputation(t *testing.T) (*reputation, *time.Time) {
+	db, err := enode.OpenDB("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(db.Close)
+
+	now := time.Unix(1700000000, 0)
+	r := newReputation(db)
+	r.now = func() time.Time { return now }
+	return r, &now
+}
+
+func TestReputationAutoBan(t *testing.T) {
+	r, now := newTestReputation(t)
+	id := enode.ID{1}
+
+	// Offenses below the threshold only lower the score.
+	if until := r.penalize(id, OffenseProtocolViolation); !until.IsZero() {
+		t.Fatalf("banned below threshold until %v", until)
+	}
+	if r.banned(id, netip.Addr{}) {
+		t.Fatal("node banned below threshold")
+	}
+	if reps := r.reputations(); len(reps) != 1 || reps[0].Score != -50 {
+		t.Fatalf("wrong reputations: %+v", reps)
+	}
+	// Reaching the threshold bans the node for minBanDuration.
+	until := r.penalize(id, OffenseInvalidHeader)
+	if want := now.Add(minBanDuration); !until.Equal(want) {
+		t.Fatalf("wrong ban end: have %v, want %v", until, want)
+	}
+	if !r.banned(id, netip.Addr{}) {
+		t.Fatal("node not banned")
+	}
+	// The ban expires, and the next one lasts twice as long.
+	*now = now.Add(minBanDuration)
+	if r.banned(id, netip.Addr{}) {
+		t.Fatal("node still banned after expiry")
+	}
+	until = r.penalize(id, OffenseInvalidBlock)
+	if want := now.Add(2 * minBanDuration); !until.Equal(want) {
+		t.Fatalf("wrong second ban end: have %v, want %v", until, want)
+	}
+	// Unbanning forgives the node entirely.
+	r.unban(id)
+	if r.banned(id, netip.Addr{}) {
+		t.Fatal("node still banned after unban")
+	}
+	if reps := r.reputations(); len(reps) != 0 {
+		t.Fatalf("reputation not forgiven: %+v", reps)
+	}
+}
+
+func TestReputationDecay(t *testing.T) {
+	r, now := newTestReputation(t)
+	id := enode.ID{2}
+
+	r.penalize(id, OffenseProtocolViolation)
+	*now = now.Add(penaltyHalfLife)
+	if reps := r.reputations(); len(reps) != 1 || reps[0].Score != -25 {
+		t.Fatalf("wrong decayed reputation: %+v", reps)
+	}
+	// Two protocol violations an hour apart don't add up to a ban.
+	*now = now.Add(penaltyHalfLife)
+	if until := r.penalize(id, OffenseProtocolViolation); !until.IsZero() {
+		t.Fatalf("banned despite decay until %v", until)
+	}
+}
+
+func TestReputationNetBan(t *testing.T) {
+	r, now := newTestReputation(t)
+
+	r.banNet(netip.MustParsePrefix("10.1.2.3/16"), time.Hour)
+	if !r.netBanned(netip.MustParseAddr("10.1.200.1")) {
+		t.Fatal("address in banned network not banned")
+	}
+	if !r.banned(enode.ID{3}, netip.MustParseAddr("::ffff:10.1.0.1")) {
+		t.Fatal("mapped address in banned network not banned")
+	}
+	if r.netBanned(netip.MustParseAddr("10.2.0.1")) {
+		t.Fatal("address outside banned network banned")
+	}
+	// Network bans survive a restart.
+	restarted := newReputation(r.db)
+	restarted.now = r.now
+	if nets := restarted.bannedNets(); len(nets) != 1 {
+		t.Fatalf("network ban not persisted: %v", nets)
+	}
+	// Network bans expire.
+	*now = now.Add(time.Hour)
+	if r.netBanned(netip.MustParseAddr("10.1.200.1")) {
+		t.Fatal("network still banned after expiry")
+	}
+	if nets := r.db.NetBans(); len(nets) != 0 {
+		t.Fatalf("expired network ban not deleted: %v", nets)
+	}
+}
+
+func TestServerBanBeforeStart(t *testing.T) {
+	srv := new(Server)
+	if err := srv.BanPeer(enode.ID{1}, time.Hour); err != errServerStopped {
+		t.Errorf("BanPeer: have %v, want %v", err, errServerStopped)
+	}
+	if err := srv.UnbanPeer(enode.ID{1}); err != errServerStopped {
+		t.Errorf("UnbanPeer: have %v, want %v", err, errServerStopped)
+	}
+	prefix := netip.MustParsePrefix("10.1.0.0/16")
+	if err := srv.BanNetwork(prefix, time.Hour); err != errServerStopped {
+		t.Errorf("BanNetwork: have %v, want %v", err, errServerStopped)
+	}
+	if err := srv.UnbanNetwork(prefix); err != errServerStopped {
+		t.Errorf("UnbanNetwork: have %v, want %v", err, errServerStopped)
+	}
+}
@@ -38,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/golang/snappy"
 	"golang.org/x/crypto/sha3"
+	"golang.org/x/time/rate"
 )
 
 // Conn is an RLPx network connection. It wraps a low-level network connection. The
@@ -55,6 +56,10 @@ type Conn struct {
 	// Compression is enabled if they are non-nil.
 	snappyReadBuffer  []byte
 	snappyWriteBuffer []byte
+
+	// Rate limits of the connection, disabled if empty.
+	readLimits, writeLimits []*rate.Limiter
+	writeDeadline           time.Time
 }
 
 // sessionState contains the session keys.
@@ -110,6 +115,13 @@ func (c *Conn) SetSnappy(snappy bool) {
 	}
 }
 
+// SetRateLimits sets the limiters throttling the traffic of the connection, in
+// bytes per second. Limiters may be shared between connections to limit their
+// aggregate traffic. This must be called before reading or writing messages.
+func (c *Conn) SetRateLimits(read, write []*rate.Limiter) {
+	c.readLimits, c.writeLimits = read, write
+}
+
 // SetReadDeadline sets the deadline for all future read operations.
 func (c *Conn) SetReadDeadline(time time.Time) error {
 	return c.conn.SetReadDeadline(time)
@@ -117,11 +129,13 @@ func (c *Conn) SetReadDeadline(time time.Time) error {
 
 // SetWriteDeadline sets the deadline for all future write operations.
 func (c *Conn) SetWriteDeadline(time time.Time) error {
+	c.writeDeadline = time
 	return c.conn.SetWriteDeadline(time)
 }
 
 // SetDeadline sets the deadline for all future read and write operations.
 func (c *Conn) SetDeadline(time time.Time) error {
+	c.writeDeadline = time
 	return c.conn.SetDeadline(time)
 }
 
@@ -142,6 +156,10 @@ func (c *Conn) Read() (code uint64, data []byte, wireSize int, err error) {
 	}
 	wireSize = len(data)
 
+	// Hold off reading the next frame while over the rate limits, leaving the
+	// remote end to block on the full TCP window.
+	throttle(c.readLimits, len(frame), false)
+
 	// If snappy is enabled, verify and decompress message.
 	if c.snappyReadBuffer != nil {
 		var actualSize int
@@ -207,7 +225,21 @@ func (h *sessionState) readFrame(conn io.Reader) ([]byte, error) {
 //
 // Write returns the written size of the message data. This may be less than or equal to
 // len(data) depending on whether snappy compression is enabled.
+//
+// If rate limits are set, Write waits until the message may be sent. The write deadline
+// is extended by the waiting time.
 func (c *Conn) Write(code uint64, data []byte) (uint32, error) {
+	return c.write(code, data, false)
+}
+
+// WritePriority writes a message to the connection like Write, but without waiting for
+// the rate limits. The size of the message still counts towards them, delaying
+// subsequent writes.
+func (c *Conn) WritePriority(code uint64, data []byte) (uint32, error) {
+	return c.write(code, data, true)
+}
+
+func (c *Conn) write(code uint64, data []byte, priority bool) (uint32, error) {
 	if c.session == nil {
 		panic("can't WriteMsg before handshake")
 	}
@@ -223,10 +255,41 @@ func (c *Conn) Write(code uint64, data []byte) (uint32, error) {
 	}
 
 	wireSize := uint32(len(data))
+	if delay := throttle(c.writeLimits, len(data)+rlp.IntSize(code), priority); delay > 0 && !c.writeDeadline.IsZero() {
+		c.conn.SetWriteDeadline(c.writeDeadline.Add(delay))
+	}
 	err := c.session.writeFrame(c.conn, code, data)
 	return wireSize, err
 }
 
+// throttle consumes n bytes from the given limiters and waits until all of them
+// allow the transfer, unless priority is set. It returns the waiting time.
+func throttle(limiters []*rate.Limiter, n int, priority bool) time.Duration {
+	var (
+		now   = time.Now()
+		delay time.Duration
+	)
+	for _, l := range limiters {
+		if l.Burst() <= 0 {
+			continue // unlimited
+		}
+		// Reserve in bursts, the limiter rejects larger reservations. The delay
+		// of the last one covers the ones before.
+		var r *rate.Reservation
+		for left := n; left > 0; left -= l.Burst() {
+			r = l.ReserveN(now, min(left, l.Burst()))
+		}
+		if r != nil && r.OK() {
+			delay = max(delay, r.DelayFrom(now))
+		}
+	}
+	if priority || delay <= 0 {
+		return 0
+	}
+	time.Sleep(delay)
+	return delay
+}
+
 func (h *sessionState) writeFrame(conn io.Writer, code uint64, data []byte) error {
 	h.wbuf.reset()
 
@@ -27,6 +27,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -34,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/simulations/pipes"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 )
 
 type message struct {
@@ -64,6 +66,42 @@ func TestReadWriteMsg(t *testing.T) {
 	checkMsgReadWrite(t, peer1, peer2, testCode, testData)
 }
 
+// This test checks that writes wait for the rate limits, except for priority ones.
+func TestWriteRateLimit(t *testing.T) {
+	peer1, peer2 := createPeers(t)
+	defer peer1.Close()
+	defer peer2.Close()
+
+	go func() {
+		for {
+			if _, _, _, err := peer1.Read(); err != nil {
+				return
+			}
+		}
+	}()
+	// Each message takes up a second worth of the limit, including its code.
+	peer2.SetRateLimits(nil, []*rate.Limiter{rate.NewLimiter(10*1024, 10*1024)})
+	data := make([]byte, 10*1024-1)
+
+	start := time.Now()
+	if _, err := peer2.Write(1, data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := peer2.WritePriority(1, data); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("writes within limits delayed by %v", elapsed)
+	}
+	// The priority write exceeded the limit, the next write has to wait for it.
+	if _, err := peer2.Write(1, data[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Fatalf("write over limits delayed only by %v", elapsed)
+	}
+}
+
 func checkMsgReadWrite(t *testing.T, p1, p2 *Conn, msgCode uint64, msgData []byte) {
 	// Set up the reader.
 	ch := make(chan message, 1)
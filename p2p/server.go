@@ -40,6 +40,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/nat"
 	"github.com/ethereum/go-ethereum/p2p/netutil"
 	"golang.org/x/exp/slices"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -90,6 +91,25 @@ type Config struct {
 	// Setting DialRatio to zero defaults it to 3.
 	DialRatio int `toml:",omitempty"`
 
+	// UploadRate and DownloadRate limit the aggregate traffic of all peers, in
+	// bytes per second. PeerUploadRate and PeerDownloadRate limit the traffic
+	// of each peer. Zero means unlimited.
+	UploadRate       int `toml:",omitempty"`
+	DownloadRate     int `toml:",omitempty"`
+	PeerUploadRate   int `toml:",omitempty"`
+	PeerDownloadRate int `toml:",omitempty"`
+
+	// DialStrategy selects the sources of dial candidates:
+	//
+	//   - "mixed" (the default) dials nodes verified in earlier sessions along
+	//     with nodes found through discovery.
+	//   - "verified" dials nodes verified in earlier sessions before any other.
+	//   - "discovery" ignores nodes verified in earlier sessions.
+	//
+	// Nodes are verified by the protocols, e.g. once they completed the
+	// handshake of a protocol, see Peer.MarkVerified.
+	DialStrategy string `toml:",omitempty"`
+
 	// NoDiscovery can be used to disable the peer discovery mechanism.
 	// Disabling is useful for protocol debugging (manual topology).
 	NoDiscovery bool
@@ -152,6 +172,16 @@ type Config struct {
 	// Internet.
 	NAT nat.Interface `toml:",omitempty"`
 
+	// MappedPorts are the ports forwarded manually on the gateway. The external
+	// ports forwarded to the listening ports are advertised in the local node
+	// record instead of mapping them through NAT.
+	MappedPorts nat.PortMap `toml:",omitempty"`
+
+	// ExtIP6 is the IPv6 address advertised in the local node record next to the
+	// IPv4 one, if listening on a dual-stack socket. If it is unspecified (::),
+	// a global unicast address of a local interface is advertised.
+	ExtIP6 net.IP `toml:",omitempty"`
+
 	// If Dialer is set to a non-nil value, the given Dialer
 	// is used to dial outbound peer connections.
 	Dialer NodeDialer `toml:"-"`
@@ -189,12 +219,17 @@ type Server struct {
 	peerFeed     event.Feed
 	log          log.Logger
 
-	nodedb    *enode.DB
-	localnode *enode.LocalNode
-	discv4    *discover.UDPv4
-	discv5    *discover.UDPv5
-	discmix   *enode.FairMix
-	dialsched *dialScheduler
+	nodedb     *enode.DB
+	reputation *reputation
+	reach      *reachability
+	localnode  *enode.LocalNode
+	discv4     *discover.UDPv4
+	discv5     *discover.UDPv5
+	discmix    *enode.FairMix
+	dialsched  *dialScheduler
+
+	// Aggregate traffic limits of all peers, nil if unlimited.
+	uploadLimiter, downloadLimiter *rate.Limiter
 
 	// This is read by the NAT port mapping loop.
 	portMappingRegister chan *portMapping
@@ -384,6 +419,92 @@ func (srv *Server) RemoveTrustedPeer(node *enode.Node) {
 	}
 }
 
+// penalizePeer lowers the reputation of a peer for an offense, disconnecting
+// it if it gets banned. Trusted peers are exempt from automatic bans.
+func (srv *Server) penalizePeer(p *Peer, offense Offense) {
+	if p.rw.is(trustedConn) {
+		p.log.Debug("Ignoring offense of trusted peer", "offense", offense)
+		return
+	}
+	until := srv.reputation.penalize(p.ID(), offense)
+	if until.IsZero() {
+		p.log.Debug("Penalized peer", "offense", offense)
+		return
+	}
+	p.log.Info("Banning misbehaving peer", "offense", offense, "until", until.Format(time.RFC3339))
+	peerBanMeter.Mark(1)
+	p.Disconnect(DiscUselessPeer)
+}
+
+// BanPeer bans the given node for the duration, disconnecting it if it is
+// currently connected as a peer. Bans are kept in the node database, so the
+// server must have been started.
+func (srv *Server) BanPeer(id enode.ID, duration time.Duration) error {
+	if srv.reputation == nil {
+		return errServerStopped
+	}
+	srv.reputation.ban(id, duration)
+	srv.doPeerOp(func(peers map[enode.ID]*Peer) {
+		if peer := peers[id]; peer != nil {
+			peer.Disconnect(DiscUselessPeer)
+		}
+	})
+	return nil
+}
+
+// UnbanPeer lifts the ban of the given node and forgives its past offenses.
+func (srv *Server) UnbanPeer(id enode.ID) error {
+	if srv.reputation == nil {
+		return errServerStopped
+	}
+	srv.reputation.unban(id)
+	return nil
+}
+
+// BanNetwork bans all nodes within the IP network for the duration,
+// disconnecting those currently connected as peers.
+func (srv *Server) BanNetwork(prefix netip.Prefix, duration time.Duration) error {
+	if srv.reputation == nil {
+		return errServerStopped
+	}
+	srv.reputation.banNet(prefix, duration)
+	srv.doPeerOp(func(peers map[enode.ID]*Peer) {
+		for _, peer := range peers {
+			if ip := peer.Node().IPAddr(); ip.IsValid() && prefix.Masked().Contains(ip.Unmap()) {
+				peer.Disconnect(DiscUselessPeer)
+			}
+		}
+	})
+	return nil
+}
+
+// UnbanNetwork lifts the ban of the given IP network.
+func (srv *Server) UnbanNetwork(prefix netip.Prefix) error {
+	if srv.reputation == nil {
+		return errServerStopped
+	}
+	srv.reputation.unbanNet(prefix)
+	return nil
+}
+
+// PeerReputations returns the standing of the nodes with penalties or bans,
+// worst first.
+func (srv *Server) PeerReputations() []PeerReputation {
+	if srv.reputation == nil {
+		return nil
+	}
+	return srv.reputation.reputations()
+}
+
+// BannedNetworks returns the banned IP networks along with the end of their
+// bans.
+func (srv *Server) BannedNetworks() map[netip.Prefix]time.Time {
+	if srv.reputation == nil {
+		return nil
+	}
+	return srv.reputation.bannedNets()
+}
+
 // SubscribeEvents subscribes the given channel to peer events
 func (srv *Server) SubscribeEvents(ch chan *PeerEvent) event.Subscription {
 	return srv.peerFeed.Subscribe(ch)
@@ -493,6 +614,9 @@ func (srv *Server) Start() (err error) {
 	srv.removetrusted = make(chan *enode.Node)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
+	srv.uploadLimiter = newRateLimiter(srv.UploadRate)
+	srv.downloadLimiter = newRateLimiter(srv.DownloadRate)
+	srv.reach = newReachability(srv.clock)
 
 	if err := srv.setupLocalNode(); err != nil {
 		return err
@@ -507,10 +631,16 @@ func (srv *Server) Start() (err error) {
 	if err := srv.setupDiscovery(); err != nil {
 		return err
 	}
-	srv.setupDialScheduler()
+	if err := srv.setupDialScheduler(); err != nil {
+		return err
+	}
 
 	srv.loopWG.Add(1)
 	go srv.run()
+	if srv.listener != nil {
+		srv.loopWG.Add(1)
+		go srv.reachabilityLoop()
+	}
 	return nil
 }
 
@@ -529,6 +659,7 @@ func (srv *Server) setupLocalNode() error {
 		return err
 	}
 	srv.nodedb = db
+	srv.reputation = newReputation(db)
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	// TODO: check conflicts
@@ -577,7 +708,11 @@ func (srv *Server) setupDiscovery() error {
 			return err
 		}
 		srv.discv4 = ntab
-		srv.discmix.AddSource(ntab.RandomNodes())
+		iter := ntab.RandomNodes()
+		if filter := srv.dialFilter(); filter != nil {
+			iter = enode.Filter(newResolveIter(iter, ntab.RequestENR), filter)
+		}
+		srv.discmix.AddSource(iter)
 	}
 	if srv.Config.DiscoveryV5 {
 		cfg := discover.Config{
@@ -590,6 +725,11 @@ func (srv *Server) setupDiscovery() error {
 		if err != nil {
 			return err
 		}
+		// The v5 DHT is shared with consensus layer clients, so its nodes
+		// are only dialed if the protocols filter them.
+		if filter := srv.dialFilter(); filter != nil {
+			srv.discmix.AddSource(enode.Filter(srv.discv5.RandomNodes(), filter))
+		}
 	}
 
 	// Add protocol-specific discovery sources.
@@ -603,13 +743,18 @@ func (srv *Server) setupDiscovery() error {
 	return nil
 }
 
-func (srv *Server) setupDialScheduler() {
+func (srv *Server) setupDialScheduler() error {
+	candidates, err := srv.dialCandidates()
+	if err != nil {
+		return err
+	}
 	config := dialConfig{
 		self:           srv.localnode.ID(),
 		maxDialPeers:   srv.maxDialedConns(),
 		maxActiveDials: srv.MaxPendingPeers,
 		log:            srv.Logger,
 		netRestrict:    srv.NetRestrict,
+		banned:         func(n *enode.Node) bool { return srv.reputation.banned(n.ID(), n.IPAddr()) },
 		dialer:         srv.Dialer,
 		clock:          srv.clock,
 	}
@@ -619,10 +764,11 @@ func (srv *Server) setupDialScheduler() {
 	if config.dialer == nil {
 		config.dialer = tcpDialer{&net.Dialer{Timeout: defaultDialTimeout}}
 	}
-	srv.dialsched = newDialScheduler(config, srv.discmix, srv.SetupConn)
+	srv.dialsched = newDialScheduler(config, candidates, srv.SetupConn)
 	for _, n := range srv.StaticNodes {
 		srv.dialsched.addStatic(n)
 	}
+	return nil
 }
 
 func (srv *Server) maxInboundConns() int {
@@ -657,7 +803,18 @@ func (srv *Server) setupListening() error {
 	tcp, isTCP := listener.Addr().(*net.TCPAddr)
 	if isTCP {
 		srv.localnode.Set(enr.TCP(tcp.Port))
-		if !tcp.IP.IsLoopback() && !tcp.IP.IsPrivate() {
+		if srv.ExtIP6 != nil {
+			srv.setupIPv6(tcp.IP)
+		}
+		if ext, ok := srv.MappedPorts.External(tcp.Port); ok {
+			// The port is forwarded manually, IPv6 connections still reach
+			// the listening port directly.
+			srv.localnode.Set(enr.TCP(ext))
+			if ext != tcp.Port {
+				srv.localnode.Set(enr.TCP6(tcp.Port))
+			}
+			srv.reach.mapped("TCP", tcp.Port, ext, true, nil)
+		} else if !tcp.IP.IsLoopback() && !tcp.IP.IsPrivate() {
 			srv.portMappingRegister <- &portMapping{
 				protocol: "TCP",
 				name:     "ethereum p2p",
@@ -671,6 +828,24 @@ func (srv *Server) setupListening() error {
 	return nil
 }
 
+// setupIPv6 advertises the configured IPv6 address in the local node record,
+// if the listener accepts IPv6 connections.
+func (srv *Server) setupIPv6(listenIP net.IP) {
+	if listenIP.To4() != nil {
+		srv.log.Warn("Not advertising IPv6 address, listening on IPv4 only", "addr", srv.ListenAddr)
+		return
+	}
+	ip := srv.ExtIP6
+	if ip.IsUnspecified() {
+		if ip = localIPv6(); ip == nil {
+			srv.log.Warn("Not advertising IPv6 address, no global address found")
+			return
+		}
+	}
+	srv.log.Info("Advertising IPv6 address", "ip", ip)
+	srv.localnode.SetStaticIP(ip)
+}
+
 func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
 	listenAddr := srv.ListenAddr
 
@@ -690,7 +865,11 @@ func (srv *Server) setupUDPListening() (*net.UDPConn, error) {
 	laddr := conn.LocalAddr().(*net.UDPAddr)
 	srv.localnode.SetFallbackUDP(laddr.Port)
 	srv.log.Debug("UDP listener up", "addr", laddr)
-	if !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
+	if ext, ok := srv.MappedPorts.External(laddr.Port); ok {
+		srv.localnode.SetFallbackUDP(ext)
+		srv.localnode.SetFallbackUDP6(laddr.Port)
+		srv.reach.mapped("UDP", laddr.Port, ext, true, nil)
+	} else if !laddr.IP.IsLoopback() && !laddr.IP.IsPrivate() {
 		srv.portMappingRegister <- &portMapping{
 			protocol: "UDP",
 			name:     "ethereum peer discovery",
@@ -781,6 +960,7 @@ running:
 				srv.dialsched.peerAdded(c)
 				if p.Inbound() {
 					inboundCount++
+					srv.reach.inboundPeer(c.node.IPAddr())
 					serveSuccessMeter.Mark(1)
 					activeInboundPeerGauge.Inc(1)
 				} else {
@@ -850,6 +1030,10 @@ func (srv *Server) addPeerChecks(peers map[enode.ID]*Peer, inboundCount int, c *
 	if len(srv.Protocols) > 0 && countMatchingProtocols(srv.Protocols, c.caps) == 0 {
 		return DiscUselessPeer
 	}
+	// Drop banned nodes, which are only let back in once their ban expires.
+	if srv.reputation.banned(c.node.ID(), c.node.IPAddr()) {
+		return DiscUselessPeer
+	}
 	// Repeat the post-handshake checks because the
 	// peer set might have changed since those checks were performed.
 	return srv.postHandshakeChecks(peers, inboundCount, c)
@@ -906,6 +1090,7 @@ func (srv *Server) listenLoop() {
 		}
 
 		remoteIP := netutil.AddrAddr(fd.RemoteAddr())
+		srv.reach.inboundConn(remoteIP)
 		if err := srv.checkInboundConn(remoteIP); err != nil {
 			srv.log.Debug("Rejected inbound connection", "addr", fd.RemoteAddr(), "err", err)
 			fd.Close()
@@ -933,6 +1118,10 @@ func (srv *Server) checkInboundConn(remoteIP netip.Addr) error {
 	if srv.NetRestrict != nil && !srv.NetRestrict.ContainsAddr(remoteIP) {
 		return errors.New("not in netrestrict list")
 	}
+	// Reject connections from banned networks.
+	if srv.reputation.netBanned(remoteIP) {
+		return errors.New("banned network")
+	}
 	// Reject Internet peers that try too often.
 	now := srv.clock.Now()
 	srv.inboundHistory.expire(now, nil)
@@ -1044,11 +1233,17 @@ func (srv *Server) checkpoint(c *conn, stage chan<- *conn) error {
 
 func (srv *Server) launchPeer(c *conn) *Peer {
 	p := newPeer(srv.log, c, srv.Protocols)
+	p.penalize = func(offense Offense) { srv.penalizePeer(p, offense) }
+	p.verified = func() { srv.markVerified(p) }
 	if srv.EnableMsgEvents {
 		// If message events are enabled, pass the peerFeed
 		// to the peer.
 		p.events = &srv.peerFeed
 	}
+	if t, ok := c.transport.(*rlpxTransport); ok {
+		read, write := srv.rateLimits()
+		t.setTraffic(p.traffic, read, write)
+	}
 	go srv.runPeer(p)
 	return p
 }
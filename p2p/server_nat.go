@@ -158,6 +158,7 @@ func (srv *Server) portMappingLoop() {
 
 				log.Trace("Attempting port mapping")
 				p, err := srv.NAT.AddMapping(m.protocol, external, m.port, m.name, portMapDuration)
+				srv.reach.mapped(m.protocol, m.port, int(p), false, err)
 				if err != nil {
 					log.Debug("Couldn't add port mapping", "err", err)
 					m.extPort = 0
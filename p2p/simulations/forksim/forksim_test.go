@@ -0,0 +1,119 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package forksim
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+func u64(v uint64) *uint64 { return &v }
+
+func TestApplyForks(t *testing.T) {
+	config := params.EthernovaChainConfig
+	upgraded, err := applyForks(config, ForkSchedule{"70000": u64(10)}, ForkSchedule{"EIP150": u64(12)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := upgraded.GetEIP2Transition(); b == nil || *b != 10 {
+		t.Errorf("EIP2 not moved: %v", b)
+	}
+	if b := upgraded.GetEIP150Transition(); b == nil || *b != 12 {
+		t.Errorf("EIP150 not overridden by name: %v", b)
+	}
+	if b := config.GetEIP2Transition(); b == nil || *b != 70000 {
+		t.Errorf("original config modified: %v", b)
+	}
+	legacy, err := applyForks(config, ForkSchedule{"70000": nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := legacy.GetEIP2Transition(); b != nil {
+		t.Errorf("EIP2 not unscheduled: %v", *b)
+	}
+	if _, err := applyForks(config, ForkSchedule{"EIP0": nil}); err == nil {
+		t.Error("no error for unknown transition")
+	}
+}
+
+// This test rehearses an upgrade with some nodes not upgrading. The upgraded
+// nodes must keep agreeing on the chain while the others split off.
+func TestRunSplit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	s := &Scenario{
+		Forks: ForkSchedule{"60000": u64(3), "70000": u64(6)},
+		Groups: []Group{
+			{Name: "upgraded", Nodes: 3, Miners: 2},
+			{Name: "legacy", Nodes: 2, Miners: 1, Forks: ForkSchedule{"70000": nil}},
+		},
+		Blocks:        12,
+		BlockInterval: Duration(time.Second),
+		Settle:        Duration(2 * time.Second),
+		Assertions: []Assertion{
+			{Type: AssertConverge, Groups: []string{"upgraded"}, MinBlock: 8},
+			{Type: AssertConverge, Groups: []string{"legacy"}},
+			{Type: AssertSplit, Groups: []string{"upgraded", "legacy"}, Block: 6},
+		},
+	}
+	report, err := Run(context.Background(), s, log.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range report.Nodes {
+		t.Logf("%s: head %d [%x], %d peers", n.Name, n.Head, n.Hash.Bytes()[:4], n.Peers)
+	}
+	for _, res := range report.Results {
+		if !res.OK {
+			t.Errorf("assertion failed: %s: %s", res.Assertion, res.Detail)
+		}
+	}
+}
+
+// This test checks that a split assertion fails when the nodes never reached
+// the block, rather than holding because nobody has it.
+func TestRunSplitMissingBlock(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+	s := &Scenario{
+		Groups: []Group{
+			{Name: "a", Nodes: 1, Miners: 1},
+			{Name: "b", Nodes: 1},
+		},
+		Blocks:        2,
+		BlockInterval: Duration(100 * time.Millisecond),
+		Settle:        Duration(time.Second),
+		Assertions: []Assertion{
+			{Type: AssertSplit, Groups: []string{"a", "b"}, Block: 10},
+		},
+	}
+	report, err := Run(context.Background(), s, log.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := report.Results[0]; res.OK {
+		t.Errorf("split assertion held without block %d", s.Assertions[0].Block)
+	} else {
+		t.Log(res.Detail)
+	}
+}
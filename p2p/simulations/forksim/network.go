@@ -0,0 +1,290 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package forksim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/simulations/pipes"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+var errPartitioned = errors.New("nodes are partitioned")
+
+// simNode is a node of the simulated network.
+type simNode struct {
+	name     string
+	group    string
+	miner    bool
+	coinbase common.Address
+	enode    *enode.Node
+	stack    *node.Node
+	eth      *eth.Ethereum
+}
+
+// network connects the nodes of a simulation over in-memory pipes.
+type network struct {
+	scenario *Scenario
+	nodes    []*simNode
+	byID     map[enode.ID]*simNode
+
+	mu        sync.Mutex
+	partition map[string]int // group -> partition set, nil if not partitioned
+	conns     map[[2]enode.ID][]net.Conn
+}
+
+func newNetwork(s *Scenario) *network {
+	return &network{
+		scenario: s,
+		byID:     make(map[enode.ID]*simNode),
+		conns:    make(map[[2]enode.ID][]net.Conn),
+	}
+}
+
+// startNode creates and starts a node of the given group.
+func (nw *network) startNode(group Group, index int, genesis *genesisT.Genesis, logger log.Logger) (*simNode, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	n := &simNode{
+		name:     fmt.Sprintf("%s-%d", group.Name, index),
+		group:    group.Name,
+		miner:    index < group.Miners,
+		coinbase: crypto.PubkeyToAddress(key.PublicKey),
+		enode:    enode.NewV4(&key.PublicKey, []byte{127, 0, 0, 1}, 30303+len(nw.nodes), 0),
+	}
+	n.stack, err = node.New(&node.Config{
+		Name: n.name,
+		P2P: p2p.Config{
+			PrivateKey:  key,
+			MaxPeers:    50,
+			NoDiscovery: true,
+			Dialer:      &simDialer{nw: nw, self: n},
+			Logger:      logger.New("node", n.name),
+		},
+		Logger: logger.New("node", n.name),
+	})
+	if err != nil {
+		return nil, err
+	}
+	config := ethconfig.Defaults
+	config.Genesis = genesis
+	if id := genesis.Config.GetNetworkID(); id != nil {
+		config.NetworkId = *id
+	}
+	config.SyncMode = downloader.FullSync
+	config.NoPruning = true
+	config.StateScheme = rawdb.HashScheme
+	config.Ethash = ethash.Config{PowMode: ethash.ModeFake}
+	config.Miner.Etherbase = n.coinbase
+	if n.eth, err = eth.New(n.stack, &config); err != nil {
+		n.stack.Close()
+		return nil, err
+	}
+	if err := n.stack.Start(); err != nil {
+		n.stack.Close()
+		return nil, err
+	}
+	// Propagated blocks are only imported once the node considers itself
+	// synced, which a fresh network never gets to by syncing.
+	n.eth.SetSynced()
+
+	nw.nodes = append(nw.nodes, n)
+	nw.byID[n.enode.ID()] = n
+	return n, nil
+}
+
+// connectAll makes every node connect to all other nodes.
+func (nw *network) connectAll() {
+	for i, n := range nw.nodes {
+		for _, other := range nw.nodes[i+1:] {
+			n.stack.Server().AddPeer(other.enode)
+		}
+	}
+}
+
+// waitConnected waits until all nodes completed the eth handshake with each
+// other. Blocks mined before would only reach the nodes through sync.
+func (nw *network) waitConnected(ctx context.Context, timeout time.Duration) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		connected := true
+		for _, n := range nw.nodes {
+			if ethPeers(n) < len(nw.nodes)-1 {
+				connected = false
+				break
+			}
+		}
+		if connected {
+			return nil
+		}
+		select {
+		case <-time.After(50 * time.Millisecond):
+		case <-deadline.C:
+			return fmt.Errorf("nodes didn't connect within %v", timeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// ethPeers returns the number of peers a node completed the eth handshake with.
+func ethPeers(n *simNode) int {
+	count := 0
+	for _, p := range n.stack.Server().PeersInfo() {
+		if info, ok := p.Protocols["eth"]; ok && info != "handshake" {
+			count++
+		}
+	}
+	return count
+}
+
+// latency returns the one-way latency of connections between two groups.
+func (nw *network) latency(a, b string) time.Duration {
+	for _, l := range nw.scenario.Links {
+		if (l.Groups[0] == a && l.Groups[1] == b) || (l.Groups[0] == b && l.Groups[1] == a) {
+			return time.Duration(l.Latency)
+		}
+	}
+	return time.Duration(nw.scenario.Latency)
+}
+
+// reachable reports whether two groups can connect. It must be called with
+// nw.mu held.
+func (nw *network) reachable(a, b string) bool {
+	if nw.partition == nil {
+		return true
+	}
+	return nw.partition[a] == nw.partition[b]
+}
+
+// pipe creates a connection between two nodes.
+func (nw *network) pipe(a, b *simNode) (net.Conn, net.Conn, error) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	if !nw.reachable(a.group, b.group) {
+		return nil, nil, errPartitioned
+	}
+	c1, c2, err := pipes.LatencyPipe(pipes.NetPipe, nw.latency(a.group, b.group))
+	if err != nil {
+		return nil, nil, err
+	}
+	key := pairKey(a, b)
+	nw.conns[key] = append(nw.conns[key], c1, c2)
+	return c1, c2, nil
+}
+
+// setPartition splits the network into the given sets of groups and drops the
+// connections between them.
+func (nw *network) setPartition(sets [][]string) {
+	nw.mu.Lock()
+	defer nw.mu.Unlock()
+
+	nw.partition = make(map[string]int)
+	for i, set := range sets {
+		for _, group := range set {
+			nw.partition[group] = i + 1
+		}
+	}
+	for key, conns := range nw.conns {
+		a, b := nw.byID[key[0]], nw.byID[key[1]]
+		if nw.reachable(a.group, b.group) {
+			continue
+		}
+		for _, c := range conns {
+			c.Close()
+		}
+		delete(nw.conns, key)
+	}
+}
+
+// heal removes the partition and reconnects the nodes that aren't connected.
+func (nw *network) heal() {
+	nw.mu.Lock()
+	nw.partition = nil
+	nw.mu.Unlock()
+
+	for i, a := range nw.nodes {
+		peers := make(map[enode.ID]bool)
+		for _, p := range a.stack.Server().Peers() {
+			peers[p.ID()] = true
+		}
+		for _, b := range nw.nodes[i+1:] {
+			if peers[b.enode.ID()] {
+				continue
+			}
+			c1, c2, err := nw.pipe(a, b)
+			if err != nil {
+				continue
+			}
+			go b.stack.Server().SetupConn(c1, 0, nil)
+			go a.stack.Server().SetupConn(c2, 0, b.enode)
+		}
+	}
+}
+
+// stop shuts down all nodes.
+func (nw *network) stop() {
+	for _, n := range nw.nodes {
+		n.stack.Close()
+	}
+}
+
+func pairKey(a, b *simNode) [2]enode.ID {
+	if a.name > b.name {
+		a, b = b, a
+	}
+	return [2]enode.ID{a.enode.ID(), b.enode.ID()}
+}
+
+// simDialer connects nodes of the simulated network.
+type simDialer struct {
+	nw   *network
+	self *simNode
+}
+
+func (d *simDialer) Dial(ctx context.Context, dest *enode.Node) (net.Conn, error) {
+	remote := d.nw.byID[dest.ID()]
+	if remote == nil {
+		return nil, fmt.Errorf("unknown node %v", dest.ID())
+	}
+	c1, c2, err := d.nw.pipe(d.self, remote)
+	if err != nil {
+		return nil, err
+	}
+	go remote.stack.Server().SetupConn(c1, 0, nil)
+	return c2, nil
+}
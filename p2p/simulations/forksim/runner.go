@@ -0,0 +1,273 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package forksim
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+const (
+	// connectTimeout bounds how long Run waits for the nodes to connect.
+	connectTimeout = 30 * time.Second
+
+	// maxSettle bounds how long Run waits for the chains to stop changing.
+	maxSettle = 2 * time.Minute
+)
+
+// Report is the outcome of a simulation.
+type Report struct {
+	Nodes   []NodeReport      `json:"nodes"`
+	Results []AssertionResult `json:"results"`
+}
+
+// NodeReport is the state of a node at the end of a simulation.
+type NodeReport struct {
+	Name  string      `json:"name"`
+	Group string      `json:"group"`
+	Head  uint64      `json:"head"`
+	Hash  common.Hash `json:"hash"`
+	Peers int         `json:"peers"`
+}
+
+// AssertionResult is the outcome of an assertion.
+type AssertionResult struct {
+	Assertion string `json:"assertion"`
+	OK        bool   `json:"ok"`
+	Detail    string `json:"detail,omitempty"`
+}
+
+// OK reports whether all assertions hold.
+func (r *Report) OK() bool {
+	for _, res := range r.Results {
+		if !res.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// Run runs a scenario and checks its assertions. The returned error reports
+// failures to run the simulation, failed assertions are only in the report.
+func Run(ctx context.Context, s *Scenario, logger log.Logger) (*Report, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	genesis, err := s.genesis()
+	if err != nil {
+		return nil, err
+	}
+	// Cloning configurations touches global state read by running nodes, so
+	// all of them are created before starting any node.
+	genesisByGroup := make(map[string]*genesisT.Genesis)
+	for _, group := range s.Groups {
+		config, err := applyForks(genesis.Config, s.Forks, group.Forks)
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %v", group.Name, err)
+		}
+		groupGenesis := *genesis
+		groupGenesis.Config = config
+		genesisByGroup[group.Name] = &groupGenesis
+	}
+	nw := newNetwork(s)
+	defer nw.stop()
+
+	for _, group := range s.Groups {
+		for i := 0; i < group.Nodes; i++ {
+			if _, err := nw.startNode(group, i, genesisByGroup[group.Name], logger); err != nil {
+				return nil, fmt.Errorf("group %s: %v", group.Name, err)
+			}
+		}
+	}
+	nw.connectAll()
+	if err := nw.waitConnected(ctx, connectTimeout); err != nil {
+		return nil, err
+	}
+
+	var miners []*simNode
+	for _, n := range nw.nodes {
+		if n.miner {
+			miners = append(miners, n)
+		}
+	}
+	ticker := time.NewTicker(time.Duration(s.BlockInterval))
+	defer ticker.Stop()
+	for mined := 0; mined < s.Blocks; mined++ {
+		for _, e := range s.Events {
+			if e.AfterBlocks == mined {
+				nw.apply(e)
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if err := mine(miners[mined%len(miners)]); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range s.Events {
+		if e.AfterBlocks >= s.Blocks {
+			nw.apply(e)
+		}
+	}
+	if err := nw.settle(ctx, time.Duration(s.Settle)); err != nil {
+		return nil, err
+	}
+	return nw.report(), nil
+}
+
+// mine creates a block on top of the head of a miner and announces it.
+func mine(n *simNode) error {
+	chain := n.eth.BlockChain()
+	parent := chain.GetBlockByHash(chain.CurrentBlock().Hash())
+	blocks, _ := core.GenerateChain(chain.Config(), parent, n.eth.Engine(), n.eth.ChainDb(), 1, func(i int, b *core.BlockGen) {
+		b.SetCoinbase(n.coinbase)
+		b.SetExtra([]byte(n.name))
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		return fmt.Errorf("%s can't import mined block: %v", n.name, err)
+	}
+	n.eth.EventMux().Post(core.NewMinedBlockEvent{Block: blocks[0]})
+	return nil
+}
+
+// apply applies an event to the network.
+func (nw *network) apply(e Event) {
+	if e.Heal {
+		nw.heal()
+	} else {
+		nw.setPartition(e.Partition)
+	}
+}
+
+// settle waits until the heads of all nodes didn't change for the given time.
+func (nw *network) settle(ctx context.Context, quiet time.Duration) error {
+	var (
+		deadline = time.NewTimer(maxSettle)
+		ticker   = time.NewTicker(quiet / 10)
+		heads    = nw.heads()
+		changed  = time.Now()
+	)
+	defer deadline.Stop()
+	defer ticker.Stop()
+	for time.Since(changed) < quiet {
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return fmt.Errorf("chains didn't settle within %v", maxSettle)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if current := nw.heads(); current != heads {
+			heads, changed = current, time.Now()
+		}
+	}
+	return nil
+}
+
+// heads returns the head hashes of all nodes as a comparable value.
+func (nw *network) heads() string {
+	var b strings.Builder
+	for _, n := range nw.nodes {
+		b.WriteString(n.eth.BlockChain().CurrentBlock().Hash().Hex())
+	}
+	return b.String()
+}
+
+// report collects the node states and evaluates the assertions.
+func (nw *network) report() *Report {
+	r := new(Report)
+	for _, n := range nw.nodes {
+		head := n.eth.BlockChain().CurrentBlock()
+		r.Nodes = append(r.Nodes, NodeReport{
+			Name:  n.name,
+			Group: n.group,
+			Head:  head.Number.Uint64(),
+			Hash:  head.Hash(),
+			Peers: n.stack.Server().PeerCount(),
+		})
+	}
+	for _, a := range nw.scenario.Assertions {
+		res := AssertionResult{Assertion: a.String()}
+		switch a.Type {
+		case AssertConverge:
+			res.OK, res.Detail = nw.checkConverge(a)
+		case AssertSplit:
+			res.OK, res.Detail = nw.checkSplit(a)
+		}
+		r.Results = append(r.Results, res)
+	}
+	return r
+}
+
+func (nw *network) groupNodes(groups []string) []*simNode {
+	var nodes []*simNode
+	for _, n := range nw.nodes {
+		for _, g := range groups {
+			if n.group == g {
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes
+}
+
+func (nw *network) checkConverge(a Assertion) (bool, string) {
+	nodes := nw.groupNodes(a.Groups)
+	first := nodes[0].eth.BlockChain().CurrentBlock()
+	for _, n := range nodes {
+		head := n.eth.BlockChain().CurrentBlock()
+		if head.Hash() != first.Hash() {
+			return false, fmt.Sprintf("%s has head %d [%x], %s has %d [%x]",
+				nodes[0].name, first.Number, first.Hash().Bytes()[:4], n.name, head.Number, head.Hash().Bytes()[:4])
+		}
+	}
+	if first.Number.Uint64() < a.MinBlock {
+		return false, fmt.Sprintf("converged at block %d", first.Number)
+	}
+	return true, fmt.Sprintf("converged at block %d", first.Number)
+}
+
+func (nw *network) checkSplit(a Assertion) (bool, string) {
+	n := max(a.Split, 1)
+	left, right := nw.groupNodes(a.Groups[:n]), nw.groupNodes(a.Groups[n:])
+	// A node that never reached the block can't prove anything about the split.
+	for _, nd := range append(append([]*simNode{}, left...), right...) {
+		if nd.eth.BlockChain().GetCanonicalHash(a.Block) == (common.Hash{}) {
+			return false, fmt.Sprintf("%s has no block %d (head %d)", nd.name, a.Block, nd.eth.BlockChain().CurrentBlock().Number.Uint64())
+		}
+	}
+	for _, l := range left {
+		lhash := l.eth.BlockChain().GetCanonicalHash(a.Block)
+		for _, r := range right {
+			rhash := r.eth.BlockChain().GetCanonicalHash(a.Block)
+			if lhash == rhash {
+				return false, fmt.Sprintf("%s and %s share block %d [%x]", l.name, r.name, a.Block, lhash.Bytes()[:4])
+			}
+		}
+	}
+	return true, ""
+}
@@ -0,0 +1,340 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package forksim rehearses hard forks on simulated networks of full nodes.
+//
+// A scenario launches groups of in-process nodes sharing a genesis block, each
+// group with its own fork schedule, connects them over in-memory pipes with
+// configurable latency, mines blocks on the miners of the groups in turn and
+// checks assertions on the chains of the groups once the network settled, such
+// as "the upgraded nodes converge, the non-upgraded ones split off at the fork".
+//
+// Blocks are mined with ethash in fake mode, which doesn't verify seals. They
+// are spaced ten seconds apart starting in the past, so scenarios can run many
+// blocks quickly without creating blocks from the future.
+package forksim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+// Scenario describes a simulation.
+type Scenario struct {
+	Name string `json:"name"`
+
+	// Genesis is the path of the genesis file of the network. The Ethernova
+	// main network genesis is used if empty.
+	Genesis string `json:"genesis,omitempty"`
+
+	// Forks overrides the fork schedule of all nodes, see ForkSchedule.
+	Forks ForkSchedule `json:"forks,omitempty"`
+
+	Groups []Group `json:"groups"`
+
+	// Blocks is the number of blocks mined, one per BlockInterval. The miners
+	// of all groups take turns. The interval should exceed the time blocks
+	// take to propagate, peers fetching announced blocks wait half a second.
+	Blocks        int      `json:"blocks"`
+	BlockInterval Duration `json:"blockInterval,omitempty"`
+
+	// Latency is the default one-way latency of connections.
+	Latency Duration `json:"latency,omitempty"`
+	Links   []Link   `json:"links,omitempty"`
+
+	Events     []Event     `json:"events,omitempty"`
+	Assertions []Assertion `json:"assertions"`
+
+	// Settle is how long the chains of all nodes must not change after mining
+	// before the assertions are checked.
+	Settle Duration `json:"settle,omitempty"`
+}
+
+// Group is a set of nodes running the same fork schedule.
+type Group struct {
+	Name   string `json:"name"`
+	Nodes  int    `json:"nodes"`
+	Miners int    `json:"miners,omitempty"` // Number of nodes of the group mining
+
+	// Forks overrides the fork schedule of the group's nodes on top of the
+	// scenario wide overrides.
+	Forks ForkSchedule `json:"forks,omitempty"`
+}
+
+// Link sets the latency of the connections between two groups, or within a
+// group if both are the same.
+type Link struct {
+	Groups  [2]string `json:"groups"`
+	Latency Duration  `json:"latency"`
+}
+
+// Event changes the network once the given number of blocks was mined.
+type Event struct {
+	AfterBlocks int `json:"afterBlocks"`
+
+	// Partition splits the network into the given sets of groups, which can't
+	// connect to each other. Groups not listed form another set.
+	Partition [][]string `json:"partition,omitempty"`
+
+	// Heal removes the partition and reconnects all nodes.
+	Heal bool `json:"heal,omitempty"`
+}
+
+// Assertion types.
+const (
+	// AssertConverge checks that all nodes of the groups have the same head,
+	// at least at MinBlock.
+	AssertConverge = "converge"
+
+	// AssertSplit checks that the nodes of the first groups have different
+	// canonical blocks at Block than the nodes of the other groups.
+	AssertSplit = "split"
+)
+
+// Assertion is a check of the chains of the nodes after the simulation.
+type Assertion struct {
+	Type     string   `json:"type"`
+	Groups   []string `json:"groups"`
+	MinBlock uint64   `json:"minBlock,omitempty"`
+
+	// Split assertions compare the nodes of the first Split groups with the
+	// nodes of the rest. If zero, the first group is compared with the rest.
+	Split int    `json:"split,omitempty"`
+	Block uint64 `json:"block,omitempty"`
+}
+
+func (a Assertion) String() string {
+	switch a.Type {
+	case AssertConverge:
+		return fmt.Sprintf("%v converge at block >= %d", a.Groups, a.MinBlock)
+	case AssertSplit:
+		n := max(a.Split, 1)
+		return fmt.Sprintf("%v split from %v at block %d", a.Groups[:n], a.Groups[n:], a.Block)
+	}
+	return a.Type
+}
+
+// ForkSchedule overrides the fork blocks of a chain configuration. Keys are
+// either a block number, moving all transitions scheduled at that block of the
+// genesis configuration, or the name of a transition such as EIP1559, which
+// takes precedence. A null value unschedules the transitions.
+//
+// For example {"70000": 40} rehearses the upgrade scheduled at block 70000 at
+// block 40, and {"70000": null} simulates nodes which weren't upgraded.
+type ForkSchedule map[string]*uint64
+
+// Duration is a time.Duration encoded as a string in JSON, such as "200ms".
+type Duration time.Duration
+
+// MarshalJSON implements json.Marshaler.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (d *Duration) UnmarshalJSON(input []byte) error {
+	var s string
+	if err := json.Unmarshal(input, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// LoadScenario reads a scenario from a JSON file.
+func LoadScenario(file string) (*Scenario, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var s Scenario
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %v", file, err)
+	}
+	return &s, nil
+}
+
+const (
+	defaultBlockInterval = time.Second
+	defaultSettle        = 3 * time.Second
+
+	// blockSpacing is the difference between the timestamps of consecutive
+	// blocks.
+	blockSpacing = 10 * time.Second
+)
+
+// validate checks the scenario for consistency and sets defaults.
+func (s *Scenario) validate() error {
+	if len(s.Groups) == 0 {
+		return errors.New("no groups")
+	}
+	groups := make(map[string]bool)
+	miners := 0
+	for _, g := range s.Groups {
+		switch {
+		case g.Name == "":
+			return errors.New("group without name")
+		case groups[g.Name]:
+			return fmt.Errorf("duplicate group %q", g.Name)
+		case g.Nodes <= 0:
+			return fmt.Errorf("group %q has no nodes", g.Name)
+		case g.Miners < 0 || g.Miners > g.Nodes:
+			return fmt.Errorf("group %q has %d miners for %d nodes", g.Name, g.Miners, g.Nodes)
+		}
+		groups[g.Name] = true
+		miners += g.Miners
+	}
+	if s.Blocks > 0 && miners == 0 {
+		return errors.New("no miners")
+	}
+	checkGroups := func(what string, names []string) error {
+		for _, name := range names {
+			if !groups[name] {
+				return fmt.Errorf("%s: unknown group %q", what, name)
+			}
+		}
+		return nil
+	}
+	for _, l := range s.Links {
+		if err := checkGroups("link", l.Groups[:]); err != nil {
+			return err
+		}
+	}
+	for i, e := range s.Events {
+		if e.Heal == (len(e.Partition) > 0) {
+			return fmt.Errorf("event %d: must either partition or heal", i)
+		}
+		for _, set := range e.Partition {
+			if err := checkGroups(fmt.Sprintf("event %d", i), set); err != nil {
+				return err
+			}
+		}
+	}
+	if len(s.Assertions) == 0 {
+		return errors.New("no assertions")
+	}
+	for i, a := range s.Assertions {
+		if err := checkGroups(fmt.Sprintf("assertion %d", i), a.Groups); err != nil {
+			return err
+		}
+		switch a.Type {
+		case AssertConverge:
+			if len(a.Groups) == 0 {
+				return fmt.Errorf("assertion %d: no groups", i)
+			}
+		case AssertSplit:
+			if len(a.Groups) < 2 || a.Split >= len(a.Groups) {
+				return fmt.Errorf("assertion %d: split needs groups on both sides", i)
+			}
+		default:
+			return fmt.Errorf("assertion %d: unknown type %q", i, a.Type)
+		}
+	}
+	if s.BlockInterval <= 0 {
+		s.BlockInterval = Duration(defaultBlockInterval)
+	}
+	if s.Settle <= 0 {
+		s.Settle = Duration(defaultSettle)
+	}
+	return nil
+}
+
+// genesis returns the genesis of the network, starting early enough for all
+// blocks of the scenario to be in the past.
+func (s *Scenario) genesis() (*genesisT.Genesis, error) {
+	genesis := params.DefaultEthernovaGenesisBlock()
+	if s.Genesis != "" {
+		data, err := os.ReadFile(s.Genesis)
+		if err != nil {
+			return nil, err
+		}
+		genesis = new(genesisT.Genesis)
+		if err := json.Unmarshal(data, genesis); err != nil {
+			return nil, fmt.Errorf("invalid genesis %s: %v", s.Genesis, err)
+		}
+	}
+	if genesis.Config.GetConsensusEngineType() != ctypes.ConsensusEngineT_Ethash {
+		return nil, errors.New("genesis is not an ethash network")
+	}
+	genesis.Timestamp = uint64(time.Now().Add(-time.Duration(s.Blocks+100) * blockSpacing).Unix())
+	return genesis, nil
+}
+
+// applyForks returns a copy of config with the fork schedules applied in order.
+func applyForks(config ctypes.ChainConfigurator, schedules ...ForkSchedule) (ctypes.ChainConfigurator, error) {
+	result, err := confp.CloneChainConfigurator(config)
+	if err != nil {
+		return nil, err
+	}
+	getters, names := confp.Transitions(config)
+	for i, get := range getters {
+		if !strings.HasSuffix(names[i], "Transition") {
+			continue // time based
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(names[i], "Get"), "Transition")
+		original := get()
+		value, ok := original, false
+		for _, schedule := range schedules {
+			if original != nil {
+				if v, found := schedule[strconv.FormatUint(*original, 10)]; found {
+					value, ok = v, true
+				}
+			}
+		}
+		for _, schedule := range schedules {
+			if v, found := schedule[name]; found {
+				value, ok = v, true
+			}
+		}
+		if !ok {
+			continue
+		}
+		set := reflect.ValueOf(result).MethodByName("Set" + name + "Transition")
+		if !set.IsValid() {
+			return nil, fmt.Errorf("transition %s can't be changed", name)
+		}
+		if err, _ := set.Call([]reflect.Value{reflect.ValueOf(value)})[0].Interface().(error); err != nil {
+			return nil, fmt.Errorf("can't set %s: %v", name, err)
+		}
+	}
+	// Make sure all named transitions exist.
+	for _, schedule := range schedules {
+		for key := range schedule {
+			if _, err := strconv.ParseUint(key, 10, 64); err == nil {
+				continue
+			}
+			if !reflect.ValueOf(result).MethodByName("Set" + key + "Transition").IsValid() {
+				return nil, fmt.Errorf("unknown transition %q", key)
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package pipes
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// LatencyPipe creates a pipe with pipe and delays the data written to either end
+// by latency before it arrives at the other end.
+func LatencyPipe(pipe func() (net.Conn, net.Conn, error), latency time.Duration) (net.Conn, net.Conn, error) {
+	c1, c2, err := pipe()
+	if err != nil || latency <= 0 {
+		return c1, c2, err
+	}
+	return DelayConn(c1, latency), DelayConn(c2, latency), nil
+}
+
+// DelayConn wraps a connection such that written data is sent after the given
+// latency. Writes return immediately, their data is queued for sending.
+func DelayConn(conn net.Conn, latency time.Duration) net.Conn {
+	c := &delayConn{
+		Conn:    conn,
+		latency: latency,
+		queue:   make(chan delayedWrite, 1024),
+		closed:  make(chan struct{}),
+	}
+	go c.loop()
+	return c
+}
+
+type delayConn struct {
+	net.Conn
+	latency time.Duration
+	queue   chan delayedWrite
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+type delayedWrite struct {
+	data []byte
+	due  time.Time
+}
+
+func (c *delayConn) Write(b []byte) (int, error) {
+	w := delayedWrite{data: append([]byte(nil), b...), due: time.Now().Add(c.latency)}
+	select {
+	case c.queue <- w:
+		return len(b), nil
+	case <-c.closed:
+		return 0, net.ErrClosed
+	}
+}
+
+func (c *delayConn) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	return c.Conn.Close()
+}
+
+// loop sends the queued writes when they are due.
+func (c *delayConn) loop() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	for {
+		select {
+		case w := <-c.queue:
+			if wait := time.Until(w.due); wait > 0 {
+				timer.Reset(wait)
+				select {
+				case <-timer.C:
+				case <-c.closed:
+					return
+				}
+			}
+			if _, err := c.Conn.Write(w.data); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
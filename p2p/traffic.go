@@ -0,0 +1,159 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/mclock"
+	"github.com/ethereum/go-ethereum/metrics"
+	"golang.org/x/time/rate"
+)
+
+// baseProtocolName is the name the traffic of the devp2p base protocol, such as
+// pings and disconnects, is accounted under.
+const baseProtocolName = "p2p"
+
+// TrafficStats is the traffic exchanged with a peer. Sizes are measured on the
+// wire, after compression.
+type TrafficStats struct {
+	Ingress        uint64 `json:"ingress"`        // Bytes received
+	Egress         uint64 `json:"egress"`         // Bytes sent
+	IngressPackets uint64 `json:"ingressPackets"` // Messages received
+	EgressPackets  uint64 `json:"egressPackets"`  // Messages sent
+}
+
+func (s *TrafficStats) add(other TrafficStats) {
+	s.Ingress += other.Ingress
+	s.Egress += other.Egress
+	s.IngressPackets += other.IngressPackets
+	s.EgressPackets += other.EgressPackets
+}
+
+// PeerTraffic is the traffic exchanged with a peer since it connected, in total
+// and per protocol.
+type PeerTraffic struct {
+	ID            string                  `json:"id"`
+	Name          string                  `json:"name"`
+	RemoteAddress string                  `json:"remoteAddress"`
+	Connected     uint64                  `json:"connected"` // Seconds since the peer connected
+	Total         TrafficStats            `json:"total"`
+	Protocols     map[string]TrafficStats `json:"protocols"`
+}
+
+// trafficCounter accounts the traffic of a peer per protocol.
+type trafficCounter struct {
+	mu        sync.Mutex
+	protocols map[string]*TrafficStats
+}
+
+func newTrafficCounter() *trafficCounter {
+	return &trafficCounter{protocols: make(map[string]*TrafficStats)}
+}
+
+// stats returns the counters of a protocol. The lock must be held.
+func (t *trafficCounter) stats(protocol string) *TrafficStats {
+	if protocol == "" {
+		protocol = baseProtocolName
+	}
+	s := t.protocols[protocol]
+	if s == nil {
+		s = new(TrafficStats)
+		t.protocols[protocol] = s
+	}
+	return s
+}
+
+// ingress accounts a message received over the given protocol.
+func (t *trafficCounter) ingress(protocol string, size uint32) {
+	t.mu.Lock()
+	s := t.stats(protocol)
+	s.Ingress += uint64(size)
+	s.IngressPackets++
+	t.mu.Unlock()
+
+	if metrics.Enabled {
+		markProtocolTraffic(ingressMeterName, protocol, size)
+	}
+}
+
+// egress accounts a message sent over the given protocol.
+func (t *trafficCounter) egress(protocol string, size uint32) {
+	t.mu.Lock()
+	s := t.stats(protocol)
+	s.Egress += uint64(size)
+	s.EgressPackets++
+	t.mu.Unlock()
+
+	if metrics.Enabled {
+		markProtocolTraffic(egressMeterName, protocol, size)
+	}
+}
+
+// markProtocolTraffic bumps the aggregate traffic meters of a protocol.
+func markProtocolTraffic(prefix string, protocol string, size uint32) {
+	if protocol == "" {
+		protocol = baseProtocolName
+	}
+	m := prefix + "/" + protocol
+	metrics.GetOrRegisterMeter(m, nil).Mark(int64(size))
+	metrics.GetOrRegisterMeter(m+"/packets", nil).Mark(1)
+}
+
+// Traffic returns the traffic exchanged with the peer since it connected.
+func (p *Peer) Traffic() *PeerTraffic {
+	traffic := &PeerTraffic{
+		ID:            p.ID().String(),
+		Name:          p.Fullname(),
+		RemoteAddress: p.RemoteAddr().String(),
+		Connected:     uint64(mclock.Now().Sub(p.created).Seconds()),
+		Protocols:     make(map[string]TrafficStats),
+	}
+	p.traffic.mu.Lock()
+	defer p.traffic.mu.Unlock()
+
+	for name, stats := range p.traffic.protocols {
+		traffic.Protocols[name] = *stats
+		traffic.Total.add(*stats)
+	}
+	return traffic
+}
+
+// newRateLimiter creates a limiter of the given bytes per second, allowing
+// bursts of a second worth of traffic. It returns nil if rate is not positive.
+func newRateLimiter(bytesPerSecond int) *rate.Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), bytesPerSecond)
+}
+
+// rateLimits returns the limiters throttling the traffic of a new peer: the
+// global ones shared by all peers, followed by the peer's own.
+func (srv *Server) rateLimits() (read, write []*rate.Limiter) {
+	for _, l := range []*rate.Limiter{srv.downloadLimiter, newRateLimiter(srv.PeerDownloadRate)} {
+		if l != nil {
+			read = append(read, l)
+		}
+	}
+	for _, l := range []*rate.Limiter{srv.uploadLimiter, newRateLimiter(srv.PeerUploadRate)} {
+		if l != nil {
+			write = append(write, l)
+		}
+	}
+	return read, write
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/p2p/rlpx"
 	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -50,12 +51,20 @@ type rlpxTransport struct {
 	rmu, wmu sync.Mutex
 	wbuf     bytes.Buffer
 	conn     *rlpx.Conn
+	traffic  *trafficCounter // accounts sent messages, if set
 }
 
 func newRLPX(conn net.Conn, dialDest *ecdsa.PublicKey) transport {
 	return &rlpxTransport{conn: rlpx.NewConn(conn, dialDest)}
 }
 
+// setTraffic makes the transport account sent messages in traffic and throttle
+// messages with the given limiters. It must be called before the peer runs.
+func (t *rlpxTransport) setTraffic(traffic *trafficCounter, read, write []*rate.Limiter) {
+	t.traffic = traffic
+	t.conn.SetRateLimits(read, write)
+}
+
 func (t *rlpxTransport) ReadMsg() (Msg, error) {
 	t.rmu.Lock()
 	defer t.rmu.Unlock()
@@ -91,7 +100,11 @@ func (t *rlpxTransport) WriteMsg(msg Msg) error {
 
 	// Write the message.
 	t.conn.SetWriteDeadline(time.Now().Add(frameWriteTimeout))
-	size, err := t.conn.Write(msg.Code, t.wbuf.Bytes())
+	write := t.conn.Write
+	if msg.priority {
+		write = t.conn.WritePriority
+	}
+	size, err := write(msg.Code, t.wbuf.Bytes())
 	if err != nil {
 		return err
 	}
@@ -103,6 +116,9 @@ func (t *rlpxTransport) WriteMsg(msg Msg) error {
 		metrics.GetOrRegisterMeter(m, nil).Mark(int64(msg.meterSize))
 		metrics.GetOrRegisterMeter(m+"/packets", nil).Mark(1)
 	}
+	if t.traffic != nil {
+		t.traffic.egress(msg.meterCap.Name, msg.meterSize)
+	}
 	return nil
 }
 
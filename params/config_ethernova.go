@@ -0,0 +1,94 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params/types/coregeth"
+	"github.com/ethereum/go-ethereum/params/types/ctypes"
+	"github.com/holiman/uint256"
+)
+
+var (
+	// EthernovaBaseFeeVault is the account collecting the base fees of the Ethernova main network.
+	EthernovaBaseFeeVault = common.HexToAddress("0x3a38560b66205bb6a31decbcb245450b2f15d4fd")
+
+	// EthernovaChainConfig is the chain parameters to run a node on the Ethernova main network,
+	// including the upgrades scheduled at blocks 60000 and 70000.
+	EthernovaChainConfig = &coregeth.CoreGethChainConfig{
+		NetworkID:                 77777,
+		ChainID:                   big.NewInt(77777),
+		SupportedProtocolVersions: []uint{69, 68},
+		Ethash:                    new(ctypes.EthashConfig),
+
+		EIP155Block: big.NewInt(0),
+
+		// Berlin and London eq, active from genesis
+		EIP2565FBlock: big.NewInt(0),
+		EIP2718FBlock: big.NewInt(0),
+		EIP2929FBlock: big.NewInt(0),
+		EIP2930FBlock: big.NewInt(0),
+		EIP1559FBlock: big.NewInt(0),
+
+		// Upgrade 60000: Constantinople, Istanbul and Shanghai/Cancun opcodes
+		EIP145FBlock:  big.NewInt(60000),
+		EIP1014FBlock: big.NewInt(60000),
+		EIP1052FBlock: big.NewInt(60000),
+		EIP152FBlock:  big.NewInt(60000),
+		EIP1108FBlock: big.NewInt(60000),
+		EIP1344FBlock: big.NewInt(60000),
+		EIP1884FBlock: big.NewInt(60000),
+		EIP2028FBlock: big.NewInt(60000),
+		EIP2200FBlock: big.NewInt(60000),
+		EIP3198FBlock: big.NewInt(60000),
+		EIP3651FBlock: big.NewInt(60000),
+		EIP3855FBlock: big.NewInt(60000),
+		EIP3860FBlock: big.NewInt(60000),
+		EIP1153FBlock: big.NewInt(60000),
+		EIP5656FBlock: big.NewInt(60000),
+		EIP6780FBlock: big.NewInt(60000),
+
+		// Upgrade 70000: Homestead, Tangerine Whistle, Spurious Dragon and Byzantium
+		EIP2FBlock:   big.NewInt(70000),
+		EIP7FBlock:   big.NewInt(70000),
+		EIP150Block:  big.NewInt(70000),
+		EIP160FBlock: big.NewInt(70000),
+		EIP161FBlock: big.NewInt(70000),
+		EIP170FBlock: big.NewInt(70000),
+		EIP100FBlock: big.NewInt(70000),
+		EIP140FBlock: big.NewInt(70000),
+		EIP198FBlock: big.NewInt(70000),
+		EIP211FBlock: big.NewInt(70000),
+		EIP212FBlock: big.NewInt(70000),
+		EIP213FBlock: big.NewInt(70000),
+		EIP214FBlock: big.NewInt(70000),
+		EIP658FBlock: big.NewInt(70000),
+
+		BaseFeeVault:          &EthernovaBaseFeeVault,
+		BaseFeeVaultFromBlock: big.NewInt(0),
+
+		BlockRewardSchedule: ctypes.Uint64Uint256MapEncodesHex{
+			0:       uint256.MustFromHex("0x8ac7230489e80000"),
+			2102400: uint256.MustFromHex("0x4563918244f40000"),
+			4204800: uint256.MustFromHex("0x22b1c8c1227a0000"),
+			6307200: uint256.MustFromHex("0x1158e460913d0000"),
+			8409600: uint256.MustFromHex("0xde0b6b3a7640000"),
+		},
+	}
+)
@@ -0,0 +1,46 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+// TestEthernovaChainConfigFile tests that the built-in Ethernova configuration
+// matches the upgrade configuration shipped with the repository.
+func TestEthernovaChainConfigFile(t *testing.T) {
+	data, err := os.ReadFile("../genesis-upgrade-70000.json")
+	if err != nil {
+		t.Skip(err)
+	}
+	var gen genesisT.Genesis
+	if err := json.Unmarshal(data, &gen); err != nil {
+		t.Fatal(err)
+	}
+	if err := confp.Equivalent(EthernovaChainConfig, gen.Config); err != nil {
+		t.Error(err)
+	}
+	if have, want := gen.Config.GetSupportedProtocolVersions(), EthernovaChainConfig.GetSupportedProtocolVersions(); !slices.Equal(have, want) {
+		t.Errorf("protocol versions mismatch: have %v, want %v", have, want)
+	}
+}
@@ -18,6 +18,7 @@ package params
 
 import (
 	"math/big"
+	"slices"
 	"testing"
 	"time"
 
@@ -371,3 +372,81 @@ func TestGenesisHashes(t *testing.T) {
 		}
 	}
 }
+
+func TestIsValidECBP1100AntigravityXCap(t *testing.T) {
+	config := *MessNetConfig
+	if err := confp.IsValid(&config, nil); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+	zero := uint64(0)
+	config.ECBP1100AntigravityXCap = &zero
+	if err := confp.IsValid(&config, nil); err == nil {
+		t.Errorf("zero antigravity xcap accepted")
+	}
+}
+
+func TestIsValidEIP2935RequiresEIP3855(t *testing.T) {
+	config := *MessNetConfig
+	config.EIP2935FBlock = big.NewInt(100)
+	if err := confp.IsValid(&config, nil); err == nil {
+		t.Errorf("EIP2935 without EIP3855 accepted")
+	}
+	config.EIP3855FBlock = big.NewInt(101)
+	if err := confp.IsValid(&config, nil); err == nil {
+		t.Errorf("EIP2935 before EIP3855 accepted")
+	}
+	config.EIP3855FBlock = big.NewInt(100)
+	if err := confp.IsValid(&config, nil); err != nil {
+		t.Errorf("EIP2935 with EIP3855 rejected: %v", err)
+	}
+}
+
+func TestIsValidEIP7702RequiresEIP1559(t *testing.T) {
+	config := *MessNetConfig
+	config.EIP1559FBlock = nil
+	config.EIP7702FBlock = big.NewInt(100)
+	if err := confp.IsValid(&config, nil); err == nil {
+		t.Errorf("EIP7702 without EIP1559 accepted")
+	}
+	config.EIP1559FBlock = big.NewInt(101)
+	if err := confp.IsValid(&config, nil); err == nil {
+		t.Errorf("EIP7702 before EIP1559 accepted")
+	}
+	config.EIP1559FBlock = big.NewInt(100)
+	if err := confp.IsValid(&config, nil); err != nil {
+		t.Errorf("EIP7702 with EIP1559 rejected: %v", err)
+	}
+}
+
+func TestCompatibleFeeMarketSchedules(t *testing.T) {
+	stored := *MessNetConfig
+	stored.ElasticityMultiplierSchedule = ctypes.Uint64Uint256MapEncodesHex{100: uint256.NewInt(4)}
+	next := stored
+	next.ElasticityMultiplierSchedule = ctypes.Uint64Uint256MapEncodesHex{100: uint256.NewInt(8)}
+
+	if err := confp.Compatible(big.NewInt(50), nil, &stored, &next); err != nil {
+		t.Errorf("schedule change above head rejected: %v", err)
+	}
+	err := confp.Compatible(big.NewInt(150), nil, &stored, &next)
+	if err == nil {
+		t.Fatal("schedule change below head accepted")
+	}
+	if err.RewindToBlock != 99 {
+		t.Errorf("rewind block mismatch: have %d, want 99", err.RewindToBlock)
+	}
+
+	next.ElasticityMultiplierSchedule = nil
+	next.MinBaseFeeSchedule = ctypes.Uint64Uint256MapEncodesHex{120: uint256.NewInt(1)}
+	err = confp.Compatible(big.NewInt(150), nil, &stored, &next)
+	if err == nil {
+		t.Fatal("schedule removal accepted")
+	}
+	if err.RewindToBlock != 99 {
+		t.Errorf("rewind block mismatch: have %d, want 99", err.RewindToBlock)
+	}
+
+	forks := confp.BlockForks(&next)
+	if !slices.Contains(forks, 120) {
+		t.Errorf("schedule activation missing from forks: %v", forks)
+	}
+}
@@ -173,6 +173,24 @@ func IsValid(conf ctypes.ChainConfigurator, head *uint64) *ConfigValidError {
 	if conf.GetNetworkID() == nil {
 		return NewValidErr("NetworkID cannot be nil", "!=nil", conf.GetNetworkID())
 	}
+	if xcap := conf.GetECBP1100AntigravityXCap(); xcap != nil && *xcap == 0 {
+		return NewValidErr("ECBP1100 antigravity xcap must be positive", ">0", *xcap)
+	}
+	// The EIP-2935 history contract code uses PUSH0, so EIP-3855 must be active
+	// no later than the EIP-2935 transition.
+	if n := conf.GetEIP2935Transition(); n != nil {
+		b3855, t3855 := conf.GetEIP3855Transition(), conf.GetEIP3855TransitionTime()
+		if !(b3855 != nil && *b3855 <= *n) && !(t3855 != nil && *t3855 == 0) {
+			return NewValidErr("EIP2935 requires EIP3855 (PUSH0). A:EIP2935/B:EIP3855", *n, b3855)
+		}
+	}
+	// EIP-7702 set code transactions carry EIP-1559 fee caps, so EIP-1559 must
+	// be active no later than the EIP-7702 transition.
+	if n := conf.GetEIP7702Transition(); n != nil {
+		if b1559 := conf.GetEIP1559Transition(); b1559 == nil || *b1559 > *n {
+			return NewValidErr("EIP7702 requires EIP1559. A:EIP7702/B:EIP1559", *n, b1559)
+		}
+	}
 	if head == nil {
 		return nil
 	}
@@ -253,6 +271,13 @@ func compatible(headBlock *big.Int, headTime *uint64, a, b ctypes.ChainConfigura
 				return err
 			}
 		}
+		aScheds, names := feeMarketSchedules(a)
+		bScheds, _ := feeMarketSchedules(b)
+		for i := range aScheds {
+			if err := checkScheduleCompatible(names[i], aScheds[i], bScheds[i], headBlock); err != nil {
+				return err
+			}
+		}
 		if a.IsEnabled(a.GetEIP155Transition, headBlock) {
 			if a.GetChainID().Cmp(b.GetChainID()) != 0 {
 				ta := a.GetEIP155Transition()
@@ -451,6 +476,59 @@ func Transitions(conf ctypes.ChainConfigurator) (fns []func() *uint64, names []s
 	return fns, names
 }
 
+// feeMarketSchedules gets the block-indexed fee market schedules and their names for a ChainConfigurator.
+func feeMarketSchedules(conf ctypes.ChainConfigurator) (schedules []ctypes.Uint64Uint256MapEncodesHex, names []string) {
+	schedules = []ctypes.Uint64Uint256MapEncodesHex{
+		conf.GetElasticityMultiplierSchedule(),
+		conf.GetBaseFeeChangeDenominatorSchedule(),
+		conf.GetMinBaseFeeSchedule(),
+	}
+	names = []string{
+		"ElasticityMultiplierSchedule",
+		"BaseFeeChangeDenominatorSchedule",
+		"MinBaseFeeSchedule",
+	}
+	return schedules, names
+}
+
+// checkScheduleCompatible returns an error for the lowest activation block at or
+// below head where the two schedules disagree.
+func checkScheduleCompatible(name string, a, b ctypes.Uint64Uint256MapEncodesHex, head *big.Int) *ConfigCompatError {
+	var (
+		lowest *big.Int
+		stored *big.Int
+		next   *big.Int
+	)
+	check := func(activation uint64) {
+		n := new(big.Int).SetUint64(activation)
+		if !isBlockForked(n, head) || (lowest != nil && lowest.Cmp(n) <= 0) {
+			return
+		}
+		av, aok := a[activation]
+		bv, bok := b[activation]
+		if aok == bok && (!aok || av.Eq(bv)) {
+			return
+		}
+		lowest, stored, next = n, nil, nil
+		if aok {
+			stored = n
+		}
+		if bok {
+			next = n
+		}
+	}
+	for activation := range a {
+		check(activation)
+	}
+	for activation := range b {
+		check(activation)
+	}
+	if lowest == nil {
+		return nil
+	}
+	return newBlockCompatError("incompatible fee schedule: "+name, stored, next)
+}
+
 // BlockForks returns non-nil, non <maxUin64>, unique sorted forks defined by block number for a ChainConfigurator.
 func BlockForks(conf ctypes.ChainConfigurator) []uint64 {
 	var forks []uint64
@@ -482,6 +560,22 @@ func BlockForks(conf ctypes.ChainConfigurator) []uint64 {
 			forksM[*response] = struct{}{}
 		}
 	}
+	// Fee market schedule entries change consensus rules at their activation
+	// blocks, so they are forks too.
+	schedules, _ := feeMarketSchedules(conf)
+	for _, schedule := range schedules {
+		for activation := range schedule {
+			if activation == math.MaxUint64 ||
+				activation == 0x7fffffffffffff ||
+				activation == 0x7FFFFFFFFFFFFFFF {
+				continue
+			}
+			if _, ok := forksM[activation]; !ok && activation != 0 {
+				forks = append(forks, activation)
+				forksM[activation] = struct{}{}
+			}
+		}
+	}
 	sort.Slice(forks, func(i, j int) bool {
 		return forks[i] < forks[j]
 	})
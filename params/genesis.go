@@ -176,3 +176,14 @@ func DeveloperGenesisBlock(gasLimit uint64, faucet *common.Address, useEthash bo
 	}
 	return genesis
 }
+
+// PredeployHistoryStorage adds the EIP-2935 history storage contract to the given
+// genesis allocation. The EIP-2935 system call is a no-op against an empty account,
+// so chains scheduling the transition need the contract in state before it activates.
+func PredeployHistoryStorage(alloc genesisT.GenesisAlloc) {
+	alloc[vars.HistoryStorageAddress] = genesisT.GenesisAccount{
+		Nonce:   1,
+		Code:    common.CopyBytes(vars.HistoryStorageCode),
+		Balance: new(big.Int),
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params/types/genesisT"
+)
+
+var EthernovaGenesisHash = common.HexToHash("0xc67bd6160c1439360ab14abf7414e8f07186f3bed095121df3f3b66fdc6c2183")
+
+// DefaultEthernovaGenesisBlock returns the Ethernova main network genesis block.
+func DefaultEthernovaGenesisBlock() *genesisT.Genesis {
+	return &genesisT.Genesis{
+		Config:     EthernovaChainConfig,
+		Nonce:      hexutil.MustDecodeUint64("0x0"),
+		ExtraData:  hexutil.MustDecode("0x4e4f5641204d41494e4e4554"),
+		GasLimit:   hexutil.MustDecodeUint64("0x1c9c380"),
+		Difficulty: hexutil.MustDecodeBig("0x400000"),
+		Timestamp:  0,
+		Coinbase:   EthernovaBaseFeeVault,
+		BaseFee:    hexutil.MustDecodeBig("0x3b9aca00"),
+		Alloc:      genesisT.GenesisAlloc{},
+	}
+}
@@ -176,6 +176,22 @@ type CoreGethChainConfig struct {
 	// https://github.com/ethereum/EIPs/pull/2537: BLS12-381 curve operations
 	EIP2537FBlock *big.Int `json:"eip2537FBlock,omitempty"`
 
+	// RIP-7212: Precompile for secp256r1 Curve Support (P256VERIFY)
+	// https://github.com/ethereum/RIPs/blob/master/RIPS/rip-7212.md
+	EIP7212FBlock *big.Int `json:"eip7212FBlock,omitempty"`
+
+	// EIP-7702: Set EOA account code
+	// https://eips.ethereum.org/EIPS/eip-7702
+	EIP7702FBlock *big.Int `json:"eip7702FBlock,omitempty"`
+
+	// EIP-2935: Serve historical block hashes from state
+	// https://eips.ethereum.org/EIPS/eip-2935
+	EIP2935FBlock *big.Int `json:"eip2935FBlock,omitempty"`
+
+	// EIP-7692: EVM Object Format (EOFv1) Meta
+	// https://eips.ethereum.org/EIPS/eip-7692
+	EIP7692FBlock *big.Int `json:"eip7692FBlock,omitempty"`
+
 	// EWASMBlock *big.Int `json:"ewasmBlock,omitempty"` // EWASM switch block (nil = no fork, 0 = already activated)
 
 	ECIP1010PauseBlock *big.Int `json:"ecip1010PauseBlock,omitempty"` // ECIP1010 pause HF block
@@ -188,6 +204,12 @@ type CoreGethChainConfig struct {
 	ECBP1100FBlock           *big.Int `json:"ecbp1100FBlock,omitempty"`                 // ECBP1100:MESS artificial finality
 	ECBP1100DeactivateFBlock *big.Int `json:"ecbp1100DeactivateFBlockFBlock,omitempty"` // Deactivate ECBP1100:MESS artificial finality
 
+	ECBP1100AntigravityXCap      *uint64 `json:"ecbp1100AntigravityXCap,omitempty"`      // ECBP1100:MESS antigravity curve ceiling time span (seconds)
+	ECBP1100AntigravityAmplitude *uint64 `json:"ecbp1100AntigravityAmplitude,omitempty"` // ECBP1100:MESS antigravity curve amplitude
+
+	EBPMaxReorgDepthFBlock *big.Int `json:"ebpMaxReorgDepthFBlock,omitempty"` // Max-reorg-depth artificial finality activation
+	EBPMaxReorgDepth       *uint64  `json:"ebpMaxReorgDepth,omitempty"`       // Max-reorg-depth artificial finality depth
+
 	// EIP-2315: Simple Subroutines
 	// https://eips.ethereum.org/EIPS/eip-2315
 	EIP2315FBlock *big.Int `json:"eip2315FBlock,omitempty"`
@@ -275,6 +297,10 @@ type CoreGethChainConfig struct {
 	TrustedCheckpoint       *ctypes.TrustedCheckpoint      `json:"trustedCheckpoint,omitempty"`
 	TrustedCheckpointOracle *ctypes.CheckpointOracleConfig `json:"trustedCheckpointOracle,omitempty"`
 
+	// CheckpointSigners configures the keys allowed to sign checkpoints used to
+	// bootstrap sync (see core/checkpoint).
+	CheckpointSigners *ctypes.CheckpointSignersConfig `json:"checkpointSigners,omitempty"`
+
 	DifficultyBombDelaySchedule ctypes.Uint64Uint256MapEncodesHex `json:"difficultyBombDelays,omitempty"` // JSON tag matches Parity's
 	BlockRewardSchedule         ctypes.Uint64Uint256MapEncodesHex `json:"blockReward,omitempty"`          // JSON tag matches Parity's
 
@@ -284,6 +310,14 @@ type CoreGethChainConfig struct {
 
 	BaseFeeVault          *common.Address `json:"baseFeeVault,omitempty"`
 	BaseFeeVaultFromBlock *big.Int        `json:"baseFeeVaultFromBlock,omitempty"`
+
+	// Block-indexed EIP-1559 fee market parameters. Each entry takes effect at its
+	// block number and holds until the next one; heights before the first entry use
+	// the protocol defaults. MinBaseFeeSchedule sets a floor below which the base
+	// fee cannot fall.
+	ElasticityMultiplierSchedule     ctypes.Uint64Uint256MapEncodesHex `json:"elasticityMultiplierSchedule,omitempty"`
+	BaseFeeChangeDenominatorSchedule ctypes.Uint64Uint256MapEncodesHex `json:"baseFeeChangeDenominatorSchedule,omitempty"`
+	MinBaseFeeSchedule               ctypes.Uint64Uint256MapEncodesHex `json:"minBaseFeeSchedule,omitempty"`
 }
 
 // String implements the fmt.Stringer interface.
@@ -184,6 +184,33 @@ func (c *CoreGethChainConfig) SetBaseFeeChangeDenominator(n uint64) error {
 	return internal.GlobalConfigurator().SetBaseFeeChangeDenominator(n)
 }
 
+func (c *CoreGethChainConfig) GetElasticityMultiplierSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return c.ElasticityMultiplierSchedule
+}
+
+func (c *CoreGethChainConfig) SetElasticityMultiplierSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	c.ElasticityMultiplierSchedule = m
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetBaseFeeChangeDenominatorSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return c.BaseFeeChangeDenominatorSchedule
+}
+
+func (c *CoreGethChainConfig) SetBaseFeeChangeDenominatorSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	c.BaseFeeChangeDenominatorSchedule = m
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetMinBaseFeeSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return c.MinBaseFeeSchedule
+}
+
+func (c *CoreGethChainConfig) SetMinBaseFeeSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	c.MinBaseFeeSchedule = m
+	return nil
+}
+
 func (c *CoreGethChainConfig) GetEIP7Transition() *uint64 {
 	return bigNewU64(c.EIP7FBlock)
 }
@@ -445,6 +472,42 @@ func (c *CoreGethChainConfig) SetEIP2537Transition(n *uint64) error {
 	return nil
 }
 
+func (c *CoreGethChainConfig) GetEIP7212Transition() *uint64 {
+	return bigNewU64(c.EIP7212FBlock)
+}
+
+func (c *CoreGethChainConfig) SetEIP7212Transition(n *uint64) error {
+	c.EIP7212FBlock = setBig(c.EIP7212FBlock, n)
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetEIP7702Transition() *uint64 {
+	return bigNewU64(c.EIP7702FBlock)
+}
+
+func (c *CoreGethChainConfig) SetEIP7702Transition(n *uint64) error {
+	c.EIP7702FBlock = setBig(c.EIP7702FBlock, n)
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetEIP2935Transition() *uint64 {
+	return bigNewU64(c.EIP2935FBlock)
+}
+
+func (c *CoreGethChainConfig) SetEIP2935Transition(n *uint64) error {
+	c.EIP2935FBlock = setBig(c.EIP2935FBlock, n)
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetEIP7692Transition() *uint64 {
+	return bigNewU64(c.EIP7692FBlock)
+}
+
+func (c *CoreGethChainConfig) SetEIP7692Transition(n *uint64) error {
+	c.EIP7692FBlock = setBig(c.EIP7692FBlock, n)
+	return nil
+}
+
 func (c *CoreGethChainConfig) GetECBP1100Transition() *uint64 {
 	return bigNewU64(c.ECBP1100FBlock)
 }
@@ -463,6 +526,42 @@ func (c *CoreGethChainConfig) SetECBP1100DeactivateTransition(n *uint64) error {
 	return nil
 }
 
+func (c *CoreGethChainConfig) GetECBP1100AntigravityXCap() *uint64 {
+	return c.ECBP1100AntigravityXCap
+}
+
+func (c *CoreGethChainConfig) SetECBP1100AntigravityXCap(n *uint64) error {
+	c.ECBP1100AntigravityXCap = n
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetECBP1100AntigravityAmplitude() *uint64 {
+	return c.ECBP1100AntigravityAmplitude
+}
+
+func (c *CoreGethChainConfig) SetECBP1100AntigravityAmplitude(n *uint64) error {
+	c.ECBP1100AntigravityAmplitude = n
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetEBPMaxReorgDepthTransition() *uint64 {
+	return bigNewU64(c.EBPMaxReorgDepthFBlock)
+}
+
+func (c *CoreGethChainConfig) SetEBPMaxReorgDepthTransition(n *uint64) error {
+	c.EBPMaxReorgDepthFBlock = setBig(c.EBPMaxReorgDepthFBlock, n)
+	return nil
+}
+
+func (c *CoreGethChainConfig) GetEBPMaxReorgDepth() *uint64 {
+	return c.EBPMaxReorgDepth
+}
+
+func (c *CoreGethChainConfig) SetEBPMaxReorgDepth(n *uint64) error {
+	c.EBPMaxReorgDepth = n
+	return nil
+}
+
 func (c *CoreGethChainConfig) GetEIP2315Transition() *uint64 {
 	return bigNewU64(c.EIP2315FBlock)
 }
@@ -74,6 +74,12 @@ type ProtocolSpecifier interface {
 	SetElasticityMultiplier(n uint64) error
 	GetBaseFeeChangeDenominator() uint64
 	SetBaseFeeChangeDenominator(n uint64) error
+	GetElasticityMultiplierSchedule() Uint64Uint256MapEncodesHex
+	SetElasticityMultiplierSchedule(m Uint64Uint256MapEncodesHex) error
+	GetBaseFeeChangeDenominatorSchedule() Uint64Uint256MapEncodesHex
+	SetBaseFeeChangeDenominatorSchedule(m Uint64Uint256MapEncodesHex) error
+	GetMinBaseFeeSchedule() Uint64Uint256MapEncodesHex
+	SetMinBaseFeeSchedule(m Uint64Uint256MapEncodesHex) error
 	GetBaseFeeVault() *common.Address
 	SetBaseFeeVault(a *common.Address) error
 	GetBaseFeeVaultFromBlock() *uint64
@@ -145,11 +151,45 @@ type ProtocolSpecifier interface {
 	GetEIP2537Transition() *uint64
 	SetEIP2537Transition(n *uint64) error
 
+	// GetEIP7212Transition implements RIP7212 - Precompile for secp256r1 Curve Support - https://github.com/ethereum/RIPs/blob/master/RIPS/rip-7212.md
+	GetEIP7212Transition() *uint64
+	SetEIP7212Transition(n *uint64) error
+
+	// GetEIP7702Transition implements EIP7702 - Set EOA account code - https://eips.ethereum.org/EIPS/eip-7702
+	GetEIP7702Transition() *uint64
+	SetEIP7702Transition(n *uint64) error
+
+	// GetEIP2935Transition implements EIP2935 - Serve historical block hashes from state - https://eips.ethereum.org/EIPS/eip-2935
+	GetEIP2935Transition() *uint64
+	SetEIP2935Transition(n *uint64) error
+
+	// GetEIP7692Transition implements EIP7692 - EVM Object Format (EOFv1) Meta - https://eips.ethereum.org/EIPS/eip-7692
+	GetEIP7692Transition() *uint64
+	SetEIP7692Transition(n *uint64) error
+
 	GetECBP1100Transition() *uint64
 	SetECBP1100Transition(n *uint64) error
 	GetECBP1100DeactivateTransition() *uint64
 	SetECBP1100DeactivateTransition(n *uint64) error
 
+	// GetECBP1100AntigravityXCap returns the time span (in seconds) at which the
+	// ECBP1100 antigravity curve reaches its ceiling. Nil means the ECIP-1100 default.
+	GetECBP1100AntigravityXCap() *uint64
+	SetECBP1100AntigravityXCap(n *uint64) error
+	// GetECBP1100AntigravityAmplitude returns the amplitude of the ECBP1100
+	// antigravity curve. Nil means the ECIP-1100 default.
+	GetECBP1100AntigravityAmplitude() *uint64
+	SetECBP1100AntigravityAmplitude(n *uint64) error
+
+	// GetEBPMaxReorgDepthTransition returns the activation block of the
+	// max-reorg-depth artificial finality rule, which rejects reorgs dropping
+	// more than GetEBPMaxReorgDepth canonical blocks.
+	// Like ECBP1100, it is a node policy and not a consensus hardfork.
+	GetEBPMaxReorgDepthTransition() *uint64
+	SetEBPMaxReorgDepthTransition(n *uint64) error
+	GetEBPMaxReorgDepth() *uint64
+	SetEBPMaxReorgDepth(n *uint64) error
+
 	GetEIP2315Transition() *uint64
 	SetEIP2315Transition(n *uint64) error
 
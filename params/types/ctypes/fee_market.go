@@ -0,0 +1,71 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package ctypes
+
+import (
+	"math/big"
+
+	"github.com/holiman/uint256"
+)
+
+// scheduleValueAt returns the value of the latest schedule entry activated at
+// or before block n, or nil if no entry applies.
+func scheduleValueAt(schedule Uint64Uint256MapEncodesHex, n *big.Int) *uint256.Int {
+	if len(schedule) == 0 || n == nil || !n.IsUint64() {
+		return nil
+	}
+	// Because the map is not necessarily sorted low-high, we
+	// have to ensure that we're walking upwards only.
+	var (
+		value          *uint256.Int
+		lastActivation uint64
+	)
+	for activation, v := range schedule {
+		if activation <= n.Uint64() && (value == nil || activation >= lastActivation) {
+			lastActivation = activation
+			value = v
+		}
+	}
+	return value
+}
+
+// ElasticityMultiplier returns the EIP-1559 elasticity multiplier in effect at
+// block n. A zero-valued schedule entry restores the protocol default.
+func ElasticityMultiplier(c ChainConfigurator, n *big.Int) uint64 {
+	if v := scheduleValueAt(c.GetElasticityMultiplierSchedule(), n); v != nil && !v.IsZero() && v.IsUint64() {
+		return v.Uint64()
+	}
+	return c.GetElasticityMultiplier()
+}
+
+// BaseFeeChangeDenominator returns the EIP-1559 base fee change denominator in
+// effect at block n. A zero-valued schedule entry restores the protocol default.
+func BaseFeeChangeDenominator(c ChainConfigurator, n *big.Int) uint64 {
+	if v := scheduleValueAt(c.GetBaseFeeChangeDenominatorSchedule(), n); v != nil && !v.IsZero() && v.IsUint64() {
+		return v.Uint64()
+	}
+	return c.GetBaseFeeChangeDenominator()
+}
+
+// MinBaseFee returns the base fee floor in effect at block n, or nil if the
+// base fee is unbounded at that height.
+func MinBaseFee(c ChainConfigurator, n *big.Int) *big.Int {
+	if v := scheduleValueAt(c.GetMinBaseFeeSchedule(), n); v != nil && !v.IsZero() {
+		return v.ToBig()
+	}
+	return nil
+}
@@ -362,6 +362,14 @@ type CheckpointOracleConfig struct {
 	Threshold uint64           `json:"threshold"`
 }
 
+// CheckpointSignersConfig is the set of keys allowed to sign block checkpoints
+// (number, hash and total difficulty) for the network, along with the number
+// of distinct signatures required for a checkpoint to be trusted.
+type CheckpointSignersConfig struct {
+	Signers   []common.Address `json:"signers"`
+	Threshold uint64           `json:"threshold"`
+}
+
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
 type EthashConfig struct{}
 
@@ -76,6 +76,46 @@ func (g *Genesis) SetBaseFeeChangeDenominator(n uint64) error {
 	return g.Config.SetBaseFeeChangeDenominator(n)
 }
 
+func (g *Genesis) GetElasticityMultiplierSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return g.Config.GetElasticityMultiplierSchedule()
+}
+
+func (g *Genesis) SetElasticityMultiplierSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	return g.Config.SetElasticityMultiplierSchedule(m)
+}
+
+func (g *Genesis) GetBaseFeeChangeDenominatorSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return g.Config.GetBaseFeeChangeDenominatorSchedule()
+}
+
+func (g *Genesis) SetBaseFeeChangeDenominatorSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	return g.Config.SetBaseFeeChangeDenominatorSchedule(m)
+}
+
+func (g *Genesis) GetMinBaseFeeSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return g.Config.GetMinBaseFeeSchedule()
+}
+
+func (g *Genesis) SetMinBaseFeeSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	return g.Config.SetMinBaseFeeSchedule(m)
+}
+
+func (g *Genesis) GetBaseFeeVault() *common.Address {
+	return g.Config.GetBaseFeeVault()
+}
+
+func (g *Genesis) SetBaseFeeVault(a *common.Address) error {
+	return g.Config.SetBaseFeeVault(a)
+}
+
+func (g *Genesis) GetBaseFeeVaultFromBlock() *uint64 {
+	return g.Config.GetBaseFeeVaultFromBlock()
+}
+
+func (g *Genesis) SetBaseFeeVaultFromBlock(n *uint64) error {
+	return g.Config.SetBaseFeeVaultFromBlock(n)
+}
+
 func (g *Genesis) GetEIP3651TransitionTime() *uint64 {
 	return g.Config.GetEIP3651TransitionTime()
 }
@@ -800,6 +840,38 @@ func (g *Genesis) SetEIP2537Transition(n *uint64) error {
 	return g.Config.SetEIP2537Transition(n)
 }
 
+func (g *Genesis) GetEIP7212Transition() *uint64 {
+	return g.Config.GetEIP7212Transition()
+}
+
+func (g *Genesis) SetEIP7212Transition(n *uint64) error {
+	return g.Config.SetEIP7212Transition(n)
+}
+
+func (g *Genesis) GetEIP7702Transition() *uint64 {
+	return g.Config.GetEIP7702Transition()
+}
+
+func (g *Genesis) SetEIP7702Transition(n *uint64) error {
+	return g.Config.SetEIP7702Transition(n)
+}
+
+func (g *Genesis) GetEIP2935Transition() *uint64 {
+	return g.Config.GetEIP2935Transition()
+}
+
+func (g *Genesis) SetEIP2935Transition(n *uint64) error {
+	return g.Config.SetEIP2935Transition(n)
+}
+
+func (g *Genesis) GetEIP7692Transition() *uint64 {
+	return g.Config.GetEIP7692Transition()
+}
+
+func (g *Genesis) SetEIP7692Transition(n *uint64) error {
+	return g.Config.SetEIP7692Transition(n)
+}
+
 func (g *Genesis) GetEIP2315Transition() *uint64 {
 	return g.Config.GetEIP2315Transition()
 }
@@ -904,6 +976,38 @@ func (g *Genesis) SetECBP1100DeactivateTransition(n *uint64) error {
 	return g.Config.SetECBP1100DeactivateTransition(n)
 }
 
+func (g *Genesis) GetECBP1100AntigravityXCap() *uint64 {
+	return g.Config.GetECBP1100AntigravityXCap()
+}
+
+func (g *Genesis) SetECBP1100AntigravityXCap(n *uint64) error {
+	return g.Config.SetECBP1100AntigravityXCap(n)
+}
+
+func (g *Genesis) GetECBP1100AntigravityAmplitude() *uint64 {
+	return g.Config.GetECBP1100AntigravityAmplitude()
+}
+
+func (g *Genesis) SetECBP1100AntigravityAmplitude(n *uint64) error {
+	return g.Config.SetECBP1100AntigravityAmplitude(n)
+}
+
+func (g *Genesis) GetEBPMaxReorgDepthTransition() *uint64 {
+	return g.Config.GetEBPMaxReorgDepthTransition()
+}
+
+func (g *Genesis) SetEBPMaxReorgDepthTransition(n *uint64) error {
+	return g.Config.SetEBPMaxReorgDepthTransition(n)
+}
+
+func (g *Genesis) GetEBPMaxReorgDepth() *uint64 {
+	return g.Config.GetEBPMaxReorgDepth()
+}
+
+func (g *Genesis) SetEBPMaxReorgDepth(n *uint64) error {
+	return g.Config.SetEBPMaxReorgDepth(n)
+}
+
 func (g *Genesis) IsEnabled(fn func() *uint64, n *big.Int) bool {
 	return g.Config.IsEnabled(fn, n)
 }
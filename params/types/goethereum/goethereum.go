@@ -92,20 +92,45 @@ type ChainConfig struct {
 	IsDevMode bool                 `json:"isDev,omitempty"`
 
 	// NOTE: These are not included in this type upstream.
-	TrustedCheckpoint       *ctypes.TrustedCheckpoint      `json:"trustedCheckpoint"`
-	TrustedCheckpointOracle *ctypes.CheckpointOracleConfig `json:"trustedCheckpointOracle"`
+	TrustedCheckpoint       *ctypes.TrustedCheckpoint       `json:"trustedCheckpoint"`
+	TrustedCheckpointOracle *ctypes.CheckpointOracleConfig  `json:"trustedCheckpointOracle"`
+	CheckpointSigners       *ctypes.CheckpointSignersConfig `json:"checkpointSigners,omitempty"`
 
 	EIP1706Transition  *big.Int `json:"-"`
 	ECIP1080Transition *big.Int `json:"-"`
 
+	// P256VerifyBlock activates the RIP-7212 secp256r1 signature verification precompile.
+	P256VerifyBlock *big.Int `json:"p256VerifyBlock,omitempty"`
+
+	// SetCodeBlock activates EIP-7702 set code transactions.
+	SetCodeBlock *big.Int `json:"setCodeBlock,omitempty"`
+
+	// HistoryStorageBlock activates the EIP-2935 block hash history contract.
+	HistoryStorageBlock *big.Int `json:"historyStorageBlock,omitempty"`
+
+	// EOFBlock activates the EVM Object Format (EOFv1) as specified by EIP-7692.
+	EOFBlock *big.Int `json:"eofBlock,omitempty"`
+
 	// Cache types for use with testing, but will not show up in config API.
 	ecbp1100Transition           *big.Int
 	ecbp1100DeactivateTransition *big.Int
+	ecbp1100AntigravityXCap      *uint64
+	ecbp1100AntigravityAmplitude *uint64
+	ebpMaxReorgDepthTransition   *big.Int
+	ebpMaxReorgDepth             *uint64
 
 	Lyra2NonceTransitionBlock *big.Int `json:"lyra2NonceTransitionBlock,omitempty"`
 
 	BaseFeeVault          *common.Address `json:"baseFeeVault,omitempty"`
 	BaseFeeVaultFromBlock *big.Int        `json:"baseFeeVaultFromBlock,omitempty"`
+
+	// Block-indexed EIP-1559 fee market parameters. Each entry takes effect at its
+	// block number and holds until the next one; heights before the first entry use
+	// the protocol defaults. MinBaseFeeSchedule sets a floor below which the base
+	// fee cannot fall.
+	ElasticityMultiplierSchedule     ctypes.Uint64Uint256MapEncodesHex `json:"elasticityMultiplierSchedule,omitempty"`
+	BaseFeeChangeDenominatorSchedule ctypes.Uint64Uint256MapEncodesHex `json:"baseFeeChangeDenominatorSchedule,omitempty"`
+	MinBaseFeeSchedule               ctypes.Uint64Uint256MapEncodesHex `json:"minBaseFeeSchedule,omitempty"`
 }
 
 // networkNames are user friendly names to use in the chain spec banner.
@@ -120,6 +120,33 @@ func (c *ChainConfig) SetBaseFeeChangeDenominator(n uint64) error {
 	return internal.GlobalConfigurator().SetBaseFeeChangeDenominator(n)
 }
 
+func (c *ChainConfig) GetElasticityMultiplierSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return c.ElasticityMultiplierSchedule
+}
+
+func (c *ChainConfig) SetElasticityMultiplierSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	c.ElasticityMultiplierSchedule = m
+	return nil
+}
+
+func (c *ChainConfig) GetBaseFeeChangeDenominatorSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return c.BaseFeeChangeDenominatorSchedule
+}
+
+func (c *ChainConfig) SetBaseFeeChangeDenominatorSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	c.BaseFeeChangeDenominatorSchedule = m
+	return nil
+}
+
+func (c *ChainConfig) GetMinBaseFeeSchedule() ctypes.Uint64Uint256MapEncodesHex {
+	return c.MinBaseFeeSchedule
+}
+
+func (c *ChainConfig) SetMinBaseFeeSchedule(m ctypes.Uint64Uint256MapEncodesHex) error {
+	c.MinBaseFeeSchedule = m
+	return nil
+}
+
 // GetNetworkID and the following Set/Getters for ChainID too
 // are... opinionated... because of where and how currently the NetworkID
 // value is designed.
@@ -470,6 +497,46 @@ func (c *ChainConfig) SetEIP2537Transition(n *uint64) error {
 	return nil
 }
 
+// GetEIP7212Transition implements RIP7212, the secp256r1 signature verification precompile.
+func (c *ChainConfig) GetEIP7212Transition() *uint64 {
+	return bigNewU64(c.P256VerifyBlock)
+}
+
+func (c *ChainConfig) SetEIP7212Transition(n *uint64) error {
+	c.P256VerifyBlock = setBig(c.P256VerifyBlock, n)
+	return nil
+}
+
+// GetEIP7702Transition implements EIP7702, set code transactions.
+func (c *ChainConfig) GetEIP7702Transition() *uint64 {
+	return bigNewU64(c.SetCodeBlock)
+}
+
+func (c *ChainConfig) SetEIP7702Transition(n *uint64) error {
+	c.SetCodeBlock = setBig(c.SetCodeBlock, n)
+	return nil
+}
+
+// GetEIP2935Transition implements EIP2935, serving historical block hashes from state.
+func (c *ChainConfig) GetEIP2935Transition() *uint64 {
+	return bigNewU64(c.HistoryStorageBlock)
+}
+
+func (c *ChainConfig) SetEIP2935Transition(n *uint64) error {
+	c.HistoryStorageBlock = setBig(c.HistoryStorageBlock, n)
+	return nil
+}
+
+// GetEIP7692Transition implements EIP7692, the EVM Object Format (EOFv1).
+func (c *ChainConfig) GetEIP7692Transition() *uint64 {
+	return bigNewU64(c.EOFBlock)
+}
+
+func (c *ChainConfig) SetEIP7692Transition(n *uint64) error {
+	c.EOFBlock = setBig(c.EOFBlock, n)
+	return nil
+}
+
 func (c *ChainConfig) GetECBP1100Transition() *uint64 {
 	return bigNewU64(c.ecbp1100Transition)
 }
@@ -488,6 +555,42 @@ func (c *ChainConfig) SetECBP1100DeactivateTransition(n *uint64) error {
 	return nil
 }
 
+func (c *ChainConfig) GetECBP1100AntigravityXCap() *uint64 {
+	return c.ecbp1100AntigravityXCap
+}
+
+func (c *ChainConfig) SetECBP1100AntigravityXCap(n *uint64) error {
+	c.ecbp1100AntigravityXCap = n
+	return nil
+}
+
+func (c *ChainConfig) GetECBP1100AntigravityAmplitude() *uint64 {
+	return c.ecbp1100AntigravityAmplitude
+}
+
+func (c *ChainConfig) SetECBP1100AntigravityAmplitude(n *uint64) error {
+	c.ecbp1100AntigravityAmplitude = n
+	return nil
+}
+
+func (c *ChainConfig) GetEBPMaxReorgDepthTransition() *uint64 {
+	return bigNewU64(c.ebpMaxReorgDepthTransition)
+}
+
+func (c *ChainConfig) SetEBPMaxReorgDepthTransition(n *uint64) error {
+	c.ebpMaxReorgDepthTransition = setBig(c.ebpMaxReorgDepthTransition, n)
+	return nil
+}
+
+func (c *ChainConfig) GetEBPMaxReorgDepth() *uint64 {
+	return c.ebpMaxReorgDepth
+}
+
+func (c *ChainConfig) SetEBPMaxReorgDepth(n *uint64) error {
+	c.ebpMaxReorgDepth = n
+	return nil
+}
+
 // GetEIP2315Transition implements EIP2537.
 // This logic is written but not configured for any Ethereum-supported networks, yet.
 func (c *ChainConfig) GetEIP2315Transition() *uint64 {
@@ -0,0 +1,78 @@
+#!/usr/bin/env bash
+# Crawls the Ethernova network, builds an EIP-1459 DNS discovery tree of the
+# nodes running the Ethernova fork schedule, signs it and publishes it as a
+# zone file and/or through RFC 2136 dynamic updates.
+#
+# Required:
+#   DNS_DOMAIN      domain serving the tree, e.g. all.nodes.example.org
+#   DNS_KEY         keystore file of the tree signing key
+# Optional:
+#   WORK_DIR        crawl and tree state, reused across runs (default: dnsdisc)
+#   CRAWL_TIMEOUT   duration of each crawl (default: 30m)
+#   NODE_LIMIT      maximum number of nodes in the tree (default: 200)
+#   BOOTNODES       comma separated bootnodes (default: networks/mainnet/bootnodes.txt)
+#   ZONE_DIR        directory receiving the zone file
+#   RFC2136_SERVER  DNS server accepting dynamic updates (host:port), along with
+#                   RFC2136_ZONE, RFC2136_TSIG_KEY and RFC2136_TSIG_SECRET
+#   DNS_KEY_PASSWORD  password of DNS_KEY, prompted for if unset
+set -euo pipefail
+
+ROOT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+
+DEVP2P="${DEVP2P:-$ROOT_DIR/build/bin/devp2p}"
+if [[ ! -x "$DEVP2P" ]]; then
+  DEVP2P="$(command -v devp2p || true)"
+fi
+if [[ -z "$DEVP2P" ]]; then
+  echo "devp2p not found (build it with 'go build -o build/bin/devp2p ./cmd/devp2p')." >&2
+  exit 1
+fi
+
+: "${DNS_DOMAIN:?DNS_DOMAIN must be set}"
+: "${DNS_KEY:?DNS_KEY must be set}"
+WORK_DIR="${WORK_DIR:-$ROOT_DIR/dnsdisc}"
+CRAWL_TIMEOUT="${CRAWL_TIMEOUT:-30m}"
+NODE_LIMIT="${NODE_LIMIT:-200}"
+BOOTNODES="${BOOTNODES:-}"
+ZONE_DIR="${ZONE_DIR:-}"
+RFC2136_SERVER="${RFC2136_SERVER:-}"
+
+if [[ -z "$ZONE_DIR" && -z "$RFC2136_SERVER" ]]; then
+  echo "Set ZONE_DIR and/or RFC2136_SERVER to publish the tree." >&2
+  exit 1
+fi
+if [[ -z "$BOOTNODES" && -f "$ROOT_DIR/networks/mainnet/bootnodes.txt" ]]; then
+  BOOTNODES="$(grep -vE '^[[:space:]]*(#|$)' "$ROOT_DIR/networks/mainnet/bootnodes.txt" | tr -d '\r' | paste -sd, - || true)"
+fi
+if [[ -z "$BOOTNODES" ]]; then
+  echo "No bootnodes to crawl from, set BOOTNODES or fill networks/mainnet/bootnodes.txt." >&2
+  exit 1
+fi
+
+TREE_DIR="$WORK_DIR/$DNS_DOMAIN"
+mkdir -p "$TREE_DIR"
+
+echo "Crawling the network for $CRAWL_TIMEOUT..."
+"$DEVP2P" discv4 crawl --bootnodes "$BOOTNODES" --timeout "$CRAWL_TIMEOUT" "$WORK_DIR/all-nodes.json"
+
+echo "Selecting Ethernova nodes..."
+"$DEVP2P" nodeset filter "$WORK_DIR/all-nodes.json" -eth-network ethernova -limit "$NODE_LIMIT" > "$TREE_DIR/nodes.json"
+"$DEVP2P" nodeset info "$TREE_DIR/nodes.json"
+
+echo "Signing the tree..."
+if [[ -n "${DNS_KEY_PASSWORD:-}" ]]; then
+  printf '%s\n' "$DNS_KEY_PASSWORD" | "$DEVP2P" dns sign --domain "$DNS_DOMAIN" "$TREE_DIR" "$DNS_KEY"
+else
+  "$DEVP2P" dns sign --domain "$DNS_DOMAIN" "$TREE_DIR" "$DNS_KEY"
+fi
+
+if [[ -n "$ZONE_DIR" ]]; then
+  "$DEVP2P" dns to-zonefile "$TREE_DIR" "$ZONE_DIR"
+fi
+if [[ -n "$RFC2136_SERVER" ]]; then
+  "$DEVP2P" dns to-rfc2136 "$TREE_DIR"
+fi
+
+URL="$(sed -n 's/.*"url": *"\([^"]*\)".*/\1/p' "$TREE_DIR/enrtree-info.json")"
+echo "Published $URL"
+echo "Nodes use it with --discovery.dns $URL"
@@ -111,10 +111,16 @@ type btHeaderMarshaling struct {
 }
 
 func (t *BlockTest) Run(snapshotter bool, scheme string, tracer vm.EVMLogger, postCheck func(error, *core.BlockChain)) (result error) {
-	config, ok := Forks[t.json.Network]
-	if !ok {
-		return UnsupportedForkError{t.json.Network}
+	config, err := getForkConfig(t.json.Network)
+	if err != nil {
+		return err
 	}
+	return t.RunWithConfig(config, snapshotter, scheme, tracer, postCheck)
+}
+
+// RunWithConfig runs the test with the given chain configuration instead of
+// the one named by the test's network.
+func (t *BlockTest) RunWithConfig(config ctypes.ChainConfigurator, snapshotter bool, scheme string, tracer vm.EVMLogger, postCheck func(error, *core.BlockChain)) (result error) {
 	// import pre accounts & construct test genesis block & state root
 	var (
 		db    = rawdb.NewMemoryDatabase()
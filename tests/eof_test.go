@@ -0,0 +1,49 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestEOF(t *testing.T) {
+	t.Parallel()
+	if !common.FileExist(eofTestDir) {
+		t.Skipf("directory %s does not exist", eofTestDir)
+	}
+	tm := new(testMatcher)
+	tm.walk(t, eofTestDir, func(t *testing.T, name string, test *EOFTest) {
+		if err := tm.checkFailure(t, test.Run()); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+// TestExecutionSpecEOF runs the EOF validation fixtures from execution-spec-tests.
+func TestExecutionSpecEOF(t *testing.T) {
+	if !common.FileExist(executionSpecEOFTestDir) {
+		t.Skipf("directory %s does not exist", executionSpecEOFTestDir)
+	}
+	tm := new(testMatcher)
+	tm.walk(t, executionSpecEOFTestDir, func(t *testing.T, name string, test *EOFTest) {
+		if err := tm.checkFailure(t, test.Run()); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 The core-geth Authors
+// This file is part of the core-geth library.
+//
+// The core-geth library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The core-geth library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the core-geth library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// EOFTest is the JSON structure of a single EOF container validation test.
+// Each test consists of a number of vectors, each of which holds a container
+// and the expected validation result per fork.
+type EOFTest struct {
+	Vectors map[string]eofVector `json:"vectors"`
+}
+
+type eofVector struct {
+	Code          hexutil.Bytes            `json:"code"`
+	ContainerKind string                   `json:"containerKind"`
+	Results       map[string]eofTestResult `json:"results"`
+}
+
+type eofTestResult struct {
+	Result    bool   `json:"result"`
+	Exception string `json:"exception,omitempty"`
+}
+
+// Run validates every vector of the test against the EOFv1 rule set, and
+// checks the outcome against the expected result of every listed fork.
+func (t *EOFTest) Run() error {
+	jt, err := vm.LookupEOFInstructionSet(Forks["EOFv1"], new(big.Int), new(uint64))
+	if err != nil {
+		return err
+	}
+	for name, vector := range t.Vectors {
+		err := validateEOFContainer(vector.Code, vector.ContainerKind == "INITCODE", &jt)
+		for fork, want := range vector.Results {
+			if want.Result && err != nil {
+				return fmt.Errorf("vector %s (%s): unexpected error: %v", name, fork, err)
+			}
+			if !want.Result && err == nil {
+				return fmt.Errorf("vector %s (%s): expected error %q, got none", name, fork, want.Exception)
+			}
+		}
+	}
+	return nil
+}
+
+func validateEOFContainer(code []byte, isInitCode bool, jt *vm.JumpTable) error {
+	var c vm.Container
+	if err := c.UnmarshalBinary(code); err != nil {
+		return err
+	}
+	return c.ValidateCode(jt, isInitCode)
+}
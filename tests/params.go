@@ -25,10 +25,12 @@ import (
 	"path/filepath"
 
 	"github.com/ethereum/go-ethereum/params/confp"
+	"github.com/ethereum/go-ethereum/params/confp/generic"
 	"github.com/ethereum/go-ethereum/params/types/coregeth"
 	"github.com/ethereum/go-ethereum/params/types/ctypes"
 	"github.com/ethereum/go-ethereum/params/types/genesisT"
 	"github.com/iancoleman/strcase"
+	"github.com/tidwall/gjson"
 )
 
 // coregethSpecsDir is where core-geth-style configuration files for testing are stored.
@@ -111,6 +113,32 @@ func readJSONFromFile(name string, value interface{}) (sha1sum []byte, err error
 	return bb[:], nil
 }
 
+// LoadChainConfig reads a chain configuration from the named file, so that
+// arbitrary networks (eg. core-geth chainspecs) can be used in place of the
+// predefined Forks. The file may contain either a bare chain configuration,
+// in any supported schema, or a genesis specification wrapping one.
+func LoadChainConfig(name string) (ctypes.ChainConfigurator, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if gjson.GetBytes(b, "config").IsObject() {
+		gen := new(genesisT.Genesis)
+		if err := json.Unmarshal(b, gen); err != nil {
+			return nil, fmt.Errorf("invalid genesis file %s: %w", name, err)
+		}
+		if gen.Config == nil {
+			return nil, fmt.Errorf("genesis file %s has no chain configuration", name)
+		}
+		return gen.Config, nil
+	}
+	config, err := generic.UnmarshalChainConfigurator(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chain configuration file %s: %w", name, err)
+	}
+	return config, nil
+}
+
 func init() {
 	if os.Getenv(CG_CHAINCONFIG_FEATURE_EQ_COREGETH_KEY) != "" {
 		log.Println("converting to CoreGeth Chain Config data type.")
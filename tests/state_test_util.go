@@ -62,6 +62,10 @@ type StateTest struct {
 type StateSubtest struct {
 	Fork  string
 	Index int
+
+	// Config, if set, replaces the chain configuration otherwise derived from
+	// Fork. The post state of Fork is still used to verify the result.
+	Config ctypes.ChainConfigurator
 }
 
 func (t *StateTest) UnmarshalJSON(in []byte) error {
@@ -168,15 +172,16 @@ type stTransactionMarshaling struct {
 // GetChainConfig takes a fork definition and returns a chain config.
 // The fork definition can be
 // - a plain forkname, e.g. `Byzantium`,
-// - a fork basename, and a list of EIPs to enable; e.g. `Byzantium+1884+1283`.
+// - a fork basename, and a list of EIPs to enable; e.g. `Byzantium+1884+1283`,
+// - a path to a chain configuration file (see LoadChainConfig) in place of
+// the fork basename; e.g. `ethernova.json` or `ethernova.json+1153`.
 func GetChainConfig(forkString string) (baseConfig ctypes.ChainConfigurator, eips []int, err error) {
 	var (
 		splitForks            = strings.Split(forkString, "+")
-		ok                    bool
 		baseName, eipsStrings = splitForks[0], splitForks[1:]
 	)
-	if baseConfig, ok = Forks[baseName]; !ok {
-		return nil, nil, UnsupportedForkError{baseName}
+	if baseConfig, err = getForkConfig(baseName); err != nil {
+		return nil, nil, err
 	}
 	for _, eip := range eipsStrings {
 		if eipNum, err := strconv.Atoi(eip); err != nil {
@@ -191,6 +196,18 @@ func GetChainConfig(forkString string) (baseConfig ctypes.ChainConfigurator, eip
 	return baseConfig, eips, nil
 }
 
+// getForkConfig returns the predefined configuration for the named fork,
+// falling back to reading it from a chain configuration file of that name.
+func getForkConfig(name string) (ctypes.ChainConfigurator, error) {
+	if config, ok := Forks[name]; ok {
+		return config, nil
+	}
+	if strings.HasSuffix(name, ".json") {
+		return LoadChainConfig(name)
+	}
+	return nil, UnsupportedForkError{name}
+}
+
 // Subtests returns all valid subtests of the test.
 func (t *StateTest) Subtests(skipForks []*regexp.Regexp) []StateSubtest {
 	var sub []StateSubtest
@@ -202,7 +219,7 @@ outer:
 			}
 		}
 		for i := range pss {
-			sub = append(sub, StateSubtest{fork, i})
+			sub = append(sub, StateSubtest{Fork: fork, Index: i})
 		}
 	}
 	return sub
@@ -287,6 +304,9 @@ func (t *StateTest) RunNoVerifyWithPost(subtest StateSubtest, vmconfig vm.Config
 // Remember to call state.Close after verifying the test result!
 func (t *StateTest) RunNoVerify(subtest StateSubtest, vmconfig vm.Config, snapshotter bool, scheme string) (state StateTestState, root common.Hash, err error) {
 	config, eips, err := GetChainConfig(subtest.Fork)
+	if subtest.Config != nil {
+		config, eips, err = subtest.Config, nil, nil
+	}
 	if err != nil {
 		return state, common.Hash{}, UnsupportedForkError{subtest.Fork}
 	}
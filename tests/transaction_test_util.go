@@ -55,7 +55,7 @@ func (tt *TransactionTest) Run(config ctypes.ChainConfigurator) error {
 			return nil, nil, err
 		}
 		// Intrinsic gas
-		requiredGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, isEIP2F, isEIP2028F, false)
+		requiredGas, err := core.IntrinsicGas(tx.Data(), tx.AccessList(), tx.SetCodeAuthorizations(), tx.To() == nil, isEIP2F, isEIP2028F, false)
 		if err != nil {
 			return nil, nil, err
 		}